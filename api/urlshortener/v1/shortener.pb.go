@@ -0,0 +1,1502 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v29.3.0
+// source: api/urlshortener/v1/shortener.proto
+
+package urlshortenerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ActivationWindow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NotBefore     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	NotAfter      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+	FallbackUrl   string                 `protobuf:"bytes,3,opt,name=fallback_url,json=fallbackUrl,proto3" json:"fallback_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActivationWindow) Reset() {
+	*x = ActivationWindow{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActivationWindow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActivationWindow) ProtoMessage() {}
+
+func (x *ActivationWindow) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActivationWindow.ProtoReflect.Descriptor instead.
+func (*ActivationWindow) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ActivationWindow) GetNotBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotBefore
+	}
+	return nil
+}
+
+func (x *ActivationWindow) GetNotAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotAfter
+	}
+	return nil
+}
+
+func (x *ActivationWindow) GetFallbackUrl() string {
+	if x != nil {
+		return x.FallbackUrl
+	}
+	return ""
+}
+
+type QueryOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UtmSource     string                 `protobuf:"bytes,1,opt,name=utm_source,json=utmSource,proto3" json:"utm_source,omitempty"`
+	UtmMedium     string                 `protobuf:"bytes,2,opt,name=utm_medium,json=utmMedium,proto3" json:"utm_medium,omitempty"`
+	UtmCampaign   string                 `protobuf:"bytes,3,opt,name=utm_campaign,json=utmCampaign,proto3" json:"utm_campaign,omitempty"`
+	PassQuery     bool                   `protobuf:"varint,4,opt,name=pass_query,json=passQuery,proto3" json:"pass_query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryOptions) Reset() {
+	*x = QueryOptions{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryOptions) ProtoMessage() {}
+
+func (x *QueryOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryOptions.ProtoReflect.Descriptor instead.
+func (*QueryOptions) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *QueryOptions) GetUtmSource() string {
+	if x != nil {
+		return x.UtmSource
+	}
+	return ""
+}
+
+func (x *QueryOptions) GetUtmMedium() string {
+	if x != nil {
+		return x.UtmMedium
+	}
+	return ""
+}
+
+func (x *QueryOptions) GetUtmCampaign() string {
+	if x != nil {
+		return x.UtmCampaign
+	}
+	return ""
+}
+
+func (x *QueryOptions) GetPassQuery() bool {
+	if x != nil {
+		return x.PassQuery
+	}
+	return false
+}
+
+type TargetingRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Os            string                 `protobuf:"bytes,1,opt,name=os,proto3" json:"os,omitempty"`
+	Device        string                 `protobuf:"bytes,2,opt,name=device,proto3" json:"device,omitempty"`
+	Language      string                 `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	Query         map[string]string      `protobuf:"bytes,4,rep,name=query,proto3" json:"query,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TargetUrl     string                 `protobuf:"bytes,5,opt,name=target_url,json=targetUrl,proto3" json:"target_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetingRule) Reset() {
+	*x = TargetingRule{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetingRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetingRule) ProtoMessage() {}
+
+func (x *TargetingRule) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetingRule.ProtoReflect.Descriptor instead.
+func (*TargetingRule) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TargetingRule) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *TargetingRule) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *TargetingRule) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *TargetingRule) GetQuery() map[string]string {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *TargetingRule) GetTargetUrl() string {
+	if x != nil {
+		return x.TargetUrl
+	}
+	return ""
+}
+
+type Destination struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Weight        int32                  `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Destination) Reset() {
+	*x = Destination{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Destination) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Destination) ProtoMessage() {}
+
+func (x *Destination) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Destination.ProtoReflect.Descriptor instead.
+func (*Destination) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Destination) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Destination) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type LinkMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Notes         string                 `protobuf:"bytes,4,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LinkMetadata) Reset() {
+	*x = LinkMetadata{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LinkMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkMetadata) ProtoMessage() {}
+
+func (x *LinkMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkMetadata.ProtoReflect.Descriptor instead.
+func (*LinkMetadata) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LinkMetadata) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *LinkMetadata) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *LinkMetadata) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *LinkMetadata) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+type ShortenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OriginalUrl   string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	MaxClicks     int32                  `protobuf:"varint,2,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	Window        *ActivationWindow      `protobuf:"bytes,3,opt,name=window,proto3" json:"window,omitempty"`
+	QueryOptions  *QueryOptions          `protobuf:"bytes,4,opt,name=query_options,json=queryOptions,proto3" json:"query_options,omitempty"`
+	Rules         []*TargetingRule       `protobuf:"bytes,5,rep,name=rules,proto3" json:"rules,omitempty"`
+	Destinations  []*Destination         `protobuf:"bytes,6,rep,name=destinations,proto3" json:"destinations,omitempty"`
+	Interstitial  bool                   `protobuf:"varint,7,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	Owner         string                 `protobuf:"bytes,8,opt,name=owner,proto3" json:"owner,omitempty"`
+	Metadata      *LinkMetadata          `protobuf:"bytes,9,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortenRequest) Reset() {
+	*x = ShortenRequest{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenRequest) ProtoMessage() {}
+
+func (x *ShortenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenRequest.ProtoReflect.Descriptor instead.
+func (*ShortenRequest) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ShortenRequest) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetMaxClicks() int32 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *ShortenRequest) GetWindow() *ActivationWindow {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetQueryOptions() *QueryOptions {
+	if x != nil {
+		return x.QueryOptions
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetRules() []*TargetingRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetDestinations() []*Destination {
+	if x != nil {
+		return x.Destinations
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+func (x *ShortenRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetMetadata() *LinkMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ShortenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortenResponse) Reset() {
+	*x = ShortenResponse{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenResponse) ProtoMessage() {}
+
+func (x *ShortenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenResponse.ProtoReflect.Descriptor instead.
+func (*ShortenResponse) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ShortenResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+type BatchShortenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      []*ShortenRequest      `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchShortenRequest) Reset() {
+	*x = BatchShortenRequest{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchShortenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchShortenRequest) ProtoMessage() {}
+
+func (x *BatchShortenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchShortenRequest.ProtoReflect.Descriptor instead.
+func (*BatchShortenRequest) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchShortenRequest) GetRequests() []*ShortenRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// Error is a failed item of a batch. Code is a google.rpc.Code, reason the
+// machine-readable reason of a rejected destination, if any.
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Error) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Error) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type BatchShortenResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*BatchShortenResult_ShortUrl
+	//	*BatchShortenResult_Error
+	Result        isBatchShortenResult_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchShortenResult) Reset() {
+	*x = BatchShortenResult{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchShortenResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchShortenResult) ProtoMessage() {}
+
+func (x *BatchShortenResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchShortenResult.ProtoReflect.Descriptor instead.
+func (*BatchShortenResult) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BatchShortenResult) GetResult() isBatchShortenResult_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BatchShortenResult) GetShortUrl() string {
+	if x != nil {
+		if x, ok := x.Result.(*BatchShortenResult_ShortUrl); ok {
+			return x.ShortUrl
+		}
+	}
+	return ""
+}
+
+func (x *BatchShortenResult) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Result.(*BatchShortenResult_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isBatchShortenResult_Result interface {
+	isBatchShortenResult_Result()
+}
+
+type BatchShortenResult_ShortUrl struct {
+	ShortUrl string `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3,oneof"`
+}
+
+type BatchShortenResult_Error struct {
+	Error *Error `protobuf:"bytes,2,opt,name=error,proto3,oneof"`
+}
+
+func (*BatchShortenResult_ShortUrl) isBatchShortenResult_Result() {}
+
+func (*BatchShortenResult_Error) isBatchShortenResult_Result() {}
+
+// BatchShortenResponse holds one result per request, in the same order.
+type BatchShortenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BatchShortenResult  `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchShortenResponse) Reset() {
+	*x = BatchShortenResponse{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchShortenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchShortenResponse) ProtoMessage() {}
+
+func (x *BatchShortenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchShortenResponse.ProtoReflect.Descriptor instead.
+func (*BatchShortenResponse) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BatchShortenResponse) GetResults() []*BatchShortenResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ResolveRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Code           string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	UserAgent      string                 `protobuf:"bytes,2,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	AcceptLanguage string                 `protobuf:"bytes,3,opt,name=accept_language,json=acceptLanguage,proto3" json:"accept_language,omitempty"`
+	// query is the raw query string of the visit, without the leading "?".
+	Query         string `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`
+	VisitorId     string `protobuf:"bytes,5,opt,name=visitor_id,json=visitorId,proto3" json:"visitor_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveRequest) Reset() {
+	*x = ResolveRequest{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveRequest) ProtoMessage() {}
+
+func (x *ResolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveRequest.ProtoReflect.Descriptor instead.
+func (*ResolveRequest) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ResolveRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ResolveRequest) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *ResolveRequest) GetAcceptLanguage() string {
+	if x != nil {
+		return x.AcceptLanguage
+	}
+	return ""
+}
+
+func (x *ResolveRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ResolveRequest) GetVisitorId() string {
+	if x != nil {
+		return x.VisitorId
+	}
+	return ""
+}
+
+type ResolveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Interstitial  bool                   `protobuf:"varint,2,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	Delay         int32                  `protobuf:"varint,3,opt,name=delay,proto3" json:"delay,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveResponse) Reset() {
+	*x = ResolveResponse{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveResponse) ProtoMessage() {}
+
+func (x *ResolveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveResponse.ProtoReflect.Descriptor instead.
+func (*ResolveResponse) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ResolveResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ResolveResponse) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+func (x *ResolveResponse) GetDelay() int32 {
+	if x != nil {
+		return x.Delay
+	}
+	return 0
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type Link struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	ClicksLeft    *int32                 `protobuf:"varint,3,opt,name=clicks_left,json=clicksLeft,proto3,oneof" json:"clicks_left,omitempty"`
+	Window        *ActivationWindow      `protobuf:"bytes,4,opt,name=window,proto3" json:"window,omitempty"`
+	QueryOptions  *QueryOptions          `protobuf:"bytes,5,opt,name=query_options,json=queryOptions,proto3" json:"query_options,omitempty"`
+	Rules         []*TargetingRule       `protobuf:"bytes,6,rep,name=rules,proto3" json:"rules,omitempty"`
+	Destinations  []*Destination         `protobuf:"bytes,7,rep,name=destinations,proto3" json:"destinations,omitempty"`
+	Interstitial  bool                   `protobuf:"varint,8,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	Owner         string                 `protobuf:"bytes,9,opt,name=owner,proto3" json:"owner,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Clicks        int64                  `protobuf:"varint,11,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	Metadata      *LinkMetadata          `protobuf:"bytes,12,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Link) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *Link) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *Link) GetClicksLeft() int32 {
+	if x != nil && x.ClicksLeft != nil {
+		return *x.ClicksLeft
+	}
+	return 0
+}
+
+func (x *Link) GetWindow() *ActivationWindow {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+func (x *Link) GetQueryOptions() *QueryOptions {
+	if x != nil {
+		return x.QueryOptions
+	}
+	return nil
+}
+
+func (x *Link) GetRules() []*TargetingRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *Link) GetDestinations() []*Destination {
+	if x != nil {
+		return x.Destinations
+	}
+	return nil
+}
+
+func (x *Link) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+func (x *Link) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Link) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Link) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+func (x *Link) GetMetadata() *LinkMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Owner         string                 `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	Host          string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	Tag           string                 `protobuf:"bytes,4,opt,name=tag,proto3" json:"tag,omitempty"`
+	Title         string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	Q             string                 `protobuf:"bytes,6,opt,name=q,proto3" json:"q,omitempty"`
+	CreatedFrom   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`
+	CreatedTo     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`
+	Sort          string                 `protobuf:"bytes,9,opt,name=sort,proto3" json:"sort,omitempty"`
+	Order         string                 `protobuf:"bytes,10,opt,name=order,proto3" json:"order,omitempty"`
+	Cursor        string                 `protobuf:"bytes,11,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,12,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ListRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ListRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *ListRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ListRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ListRequest) GetQ() string {
+	if x != nil {
+		return x.Q
+	}
+	return ""
+}
+
+func (x *ListRequest) GetCreatedFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return nil
+}
+
+func (x *ListRequest) GetCreatedTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return nil
+}
+
+func (x *ListRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListRequest) GetOrder() string {
+	if x != nil {
+		return x.Order
+	}
+	return ""
+}
+
+func (x *ListRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Links         []*Link                `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListResponse) GetLinks() []*Link {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *ListResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_urlshortener_v1_shortener_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_api_urlshortener_v1_shortener_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DeleteRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+var File_api_urlshortener_v1_shortener_proto protoreflect.FileDescriptor
+
+const file_api_urlshortener_v1_shortener_proto_rawDesc = "" +
+	"\n" +
+	"#api/urlshortener/v1/shortener.proto\x12\x0furlshortener.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa9\x01\n" +
+	"\x10ActivationWindow\x129\n" +
+	"\n" +
+	"not_before\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tnotBefore\x127\n" +
+	"\tnot_after\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bnotAfter\x12!\n" +
+	"\ffallback_url\x18\x03 \x01(\tR\vfallbackUrl\"\x8e\x01\n" +
+	"\fQueryOptions\x12\x1d\n" +
+	"\n" +
+	"utm_source\x18\x01 \x01(\tR\tutmSource\x12\x1d\n" +
+	"\n" +
+	"utm_medium\x18\x02 \x01(\tR\tutmMedium\x12!\n" +
+	"\futm_campaign\x18\x03 \x01(\tR\vutmCampaign\x12\x1d\n" +
+	"\n" +
+	"pass_query\x18\x04 \x01(\bR\tpassQuery\"\xed\x01\n" +
+	"\rTargetingRule\x12\x0e\n" +
+	"\x02os\x18\x01 \x01(\tR\x02os\x12\x16\n" +
+	"\x06device\x18\x02 \x01(\tR\x06device\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\x12?\n" +
+	"\x05query\x18\x04 \x03(\v2).urlshortener.v1.TargetingRule.QueryEntryR\x05query\x12\x1d\n" +
+	"\n" +
+	"target_url\x18\x05 \x01(\tR\ttargetUrl\x1a8\n" +
+	"\n" +
+	"QueryEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"7\n" +
+	"\vDestination\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x05R\x06weight\"p\n" +
+	"\fLinkMetadata\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x14\n" +
+	"\x05notes\x18\x04 \x01(\tR\x05notes\"\xbe\x03\n" +
+	"\x0eShortenRequest\x12!\n" +
+	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\x02 \x01(\x05R\tmaxClicks\x129\n" +
+	"\x06window\x18\x03 \x01(\v2!.urlshortener.v1.ActivationWindowR\x06window\x12B\n" +
+	"\rquery_options\x18\x04 \x01(\v2\x1d.urlshortener.v1.QueryOptionsR\fqueryOptions\x124\n" +
+	"\x05rules\x18\x05 \x03(\v2\x1e.urlshortener.v1.TargetingRuleR\x05rules\x12@\n" +
+	"\fdestinations\x18\x06 \x03(\v2\x1c.urlshortener.v1.DestinationR\fdestinations\x12\"\n" +
+	"\finterstitial\x18\a \x01(\bR\finterstitial\x12\x14\n" +
+	"\x05owner\x18\b \x01(\tR\x05owner\x129\n" +
+	"\bmetadata\x18\t \x01(\v2\x1d.urlshortener.v1.LinkMetadataR\bmetadata\".\n" +
+	"\x0fShortenResponse\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\"R\n" +
+	"\x13BatchShortenRequest\x12;\n" +
+	"\brequests\x18\x01 \x03(\v2\x1f.urlshortener.v1.ShortenRequestR\brequests\"M\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"m\n" +
+	"\x12BatchShortenResult\x12\x1d\n" +
+	"\tshort_url\x18\x01 \x01(\tH\x00R\bshortUrl\x12.\n" +
+	"\x05error\x18\x02 \x01(\v2\x16.urlshortener.v1.ErrorH\x00R\x05errorB\b\n" +
+	"\x06result\"U\n" +
+	"\x14BatchShortenResponse\x12=\n" +
+	"\aresults\x18\x01 \x03(\v2#.urlshortener.v1.BatchShortenResultR\aresults\"\xa1\x01\n" +
+	"\x0eResolveRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x02 \x01(\tR\tuserAgent\x12'\n" +
+	"\x0faccept_language\x18\x03 \x01(\tR\x0eacceptLanguage\x12\x14\n" +
+	"\x05query\x18\x04 \x01(\tR\x05query\x12\x1d\n" +
+	"\n" +
+	"visitor_id\x18\x05 \x01(\tR\tvisitorId\"]\n" +
+	"\x0fResolveResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\"\n" +
+	"\finterstitial\x18\x02 \x01(\bR\finterstitial\x12\x14\n" +
+	"\x05delay\x18\x03 \x01(\x05R\x05delay\" \n" +
+	"\n" +
+	"GetRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\"\xbb\x04\n" +
+	"\x04Link\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12$\n" +
+	"\vclicks_left\x18\x03 \x01(\x05H\x00R\n" +
+	"clicksLeft\x88\x01\x01\x129\n" +
+	"\x06window\x18\x04 \x01(\v2!.urlshortener.v1.ActivationWindowR\x06window\x12B\n" +
+	"\rquery_options\x18\x05 \x01(\v2\x1d.urlshortener.v1.QueryOptionsR\fqueryOptions\x124\n" +
+	"\x05rules\x18\x06 \x03(\v2\x1e.urlshortener.v1.TargetingRuleR\x05rules\x12@\n" +
+	"\fdestinations\x18\a \x03(\v2\x1c.urlshortener.v1.DestinationR\fdestinations\x12\"\n" +
+	"\finterstitial\x18\b \x01(\bR\finterstitial\x12\x14\n" +
+	"\x05owner\x18\t \x01(\tR\x05owner\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x16\n" +
+	"\x06clicks\x18\v \x01(\x03R\x06clicks\x129\n" +
+	"\bmetadata\x18\f \x01(\v2\x1d.urlshortener.v1.LinkMetadataR\bmetadataB\x0e\n" +
+	"\f_clicks_left\"\xd7\x02\n" +
+	"\vListRequest\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12\x10\n" +
+	"\x03tag\x18\x04 \x01(\tR\x03tag\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\x12\f\n" +
+	"\x01q\x18\x06 \x01(\tR\x01q\x12=\n" +
+	"\fcreated_from\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedFrom\x129\n" +
+	"\n" +
+	"created_to\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedTo\x12\x12\n" +
+	"\x04sort\x18\t \x01(\tR\x04sort\x12\x14\n" +
+	"\x05order\x18\n" +
+	" \x01(\tR\x05order\x12\x16\n" +
+	"\x06cursor\x18\v \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\f \x01(\x05R\x05limit\"\\\n" +
+	"\fListResponse\x12+\n" +
+	"\x05links\x18\x01 \x03(\v2\x15.urlshortener.v1.LinkR\x05links\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"#\n" +
+	"\rDeleteRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code2\xc9\x03\n" +
+	"\fUrlShortener\x12L\n" +
+	"\aShorten\x12\x1f.urlshortener.v1.ShortenRequest\x1a .urlshortener.v1.ShortenResponse\x12[\n" +
+	"\fBatchShorten\x12$.urlshortener.v1.BatchShortenRequest\x1a%.urlshortener.v1.BatchShortenResponse\x12L\n" +
+	"\aResolve\x12\x1f.urlshortener.v1.ResolveRequest\x1a .urlshortener.v1.ResolveResponse\x129\n" +
+	"\x03Get\x12\x1b.urlshortener.v1.GetRequest\x1a\x15.urlshortener.v1.Link\x12C\n" +
+	"\x04List\x12\x1c.urlshortener.v1.ListRequest\x1a\x1d.urlshortener.v1.ListResponse\x12@\n" +
+	"\x06Delete\x12\x1e.urlshortener.v1.DeleteRequest\x1a\x16.google.protobuf.EmptyBFZDgithub.com/AlexNov03/UrlShortener/api/urlshortener/v1;urlshortenerv1b\x06proto3"
+
+var (
+	file_api_urlshortener_v1_shortener_proto_rawDescOnce sync.Once
+	file_api_urlshortener_v1_shortener_proto_rawDescData []byte
+)
+
+func file_api_urlshortener_v1_shortener_proto_rawDescGZIP() []byte {
+	file_api_urlshortener_v1_shortener_proto_rawDescOnce.Do(func() {
+		file_api_urlshortener_v1_shortener_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_urlshortener_v1_shortener_proto_rawDesc), len(file_api_urlshortener_v1_shortener_proto_rawDesc)))
+	})
+	return file_api_urlshortener_v1_shortener_proto_rawDescData
+}
+
+var file_api_urlshortener_v1_shortener_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_api_urlshortener_v1_shortener_proto_goTypes = []any{
+	(*ActivationWindow)(nil),      // 0: urlshortener.v1.ActivationWindow
+	(*QueryOptions)(nil),          // 1: urlshortener.v1.QueryOptions
+	(*TargetingRule)(nil),         // 2: urlshortener.v1.TargetingRule
+	(*Destination)(nil),           // 3: urlshortener.v1.Destination
+	(*LinkMetadata)(nil),          // 4: urlshortener.v1.LinkMetadata
+	(*ShortenRequest)(nil),        // 5: urlshortener.v1.ShortenRequest
+	(*ShortenResponse)(nil),       // 6: urlshortener.v1.ShortenResponse
+	(*BatchShortenRequest)(nil),   // 7: urlshortener.v1.BatchShortenRequest
+	(*Error)(nil),                 // 8: urlshortener.v1.Error
+	(*BatchShortenResult)(nil),    // 9: urlshortener.v1.BatchShortenResult
+	(*BatchShortenResponse)(nil),  // 10: urlshortener.v1.BatchShortenResponse
+	(*ResolveRequest)(nil),        // 11: urlshortener.v1.ResolveRequest
+	(*ResolveResponse)(nil),       // 12: urlshortener.v1.ResolveResponse
+	(*GetRequest)(nil),            // 13: urlshortener.v1.GetRequest
+	(*Link)(nil),                  // 14: urlshortener.v1.Link
+	(*ListRequest)(nil),           // 15: urlshortener.v1.ListRequest
+	(*ListResponse)(nil),          // 16: urlshortener.v1.ListResponse
+	(*DeleteRequest)(nil),         // 17: urlshortener.v1.DeleteRequest
+	nil,                           // 18: urlshortener.v1.TargetingRule.QueryEntry
+	(*timestamppb.Timestamp)(nil), // 19: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 20: google.protobuf.Empty
+}
+var file_api_urlshortener_v1_shortener_proto_depIdxs = []int32{
+	19, // 0: urlshortener.v1.ActivationWindow.not_before:type_name -> google.protobuf.Timestamp
+	19, // 1: urlshortener.v1.ActivationWindow.not_after:type_name -> google.protobuf.Timestamp
+	18, // 2: urlshortener.v1.TargetingRule.query:type_name -> urlshortener.v1.TargetingRule.QueryEntry
+	0,  // 3: urlshortener.v1.ShortenRequest.window:type_name -> urlshortener.v1.ActivationWindow
+	1,  // 4: urlshortener.v1.ShortenRequest.query_options:type_name -> urlshortener.v1.QueryOptions
+	2,  // 5: urlshortener.v1.ShortenRequest.rules:type_name -> urlshortener.v1.TargetingRule
+	3,  // 6: urlshortener.v1.ShortenRequest.destinations:type_name -> urlshortener.v1.Destination
+	4,  // 7: urlshortener.v1.ShortenRequest.metadata:type_name -> urlshortener.v1.LinkMetadata
+	5,  // 8: urlshortener.v1.BatchShortenRequest.requests:type_name -> urlshortener.v1.ShortenRequest
+	8,  // 9: urlshortener.v1.BatchShortenResult.error:type_name -> urlshortener.v1.Error
+	9,  // 10: urlshortener.v1.BatchShortenResponse.results:type_name -> urlshortener.v1.BatchShortenResult
+	0,  // 11: urlshortener.v1.Link.window:type_name -> urlshortener.v1.ActivationWindow
+	1,  // 12: urlshortener.v1.Link.query_options:type_name -> urlshortener.v1.QueryOptions
+	2,  // 13: urlshortener.v1.Link.rules:type_name -> urlshortener.v1.TargetingRule
+	3,  // 14: urlshortener.v1.Link.destinations:type_name -> urlshortener.v1.Destination
+	19, // 15: urlshortener.v1.Link.created_at:type_name -> google.protobuf.Timestamp
+	4,  // 16: urlshortener.v1.Link.metadata:type_name -> urlshortener.v1.LinkMetadata
+	19, // 17: urlshortener.v1.ListRequest.created_from:type_name -> google.protobuf.Timestamp
+	19, // 18: urlshortener.v1.ListRequest.created_to:type_name -> google.protobuf.Timestamp
+	14, // 19: urlshortener.v1.ListResponse.links:type_name -> urlshortener.v1.Link
+	5,  // 20: urlshortener.v1.UrlShortener.Shorten:input_type -> urlshortener.v1.ShortenRequest
+	7,  // 21: urlshortener.v1.UrlShortener.BatchShorten:input_type -> urlshortener.v1.BatchShortenRequest
+	11, // 22: urlshortener.v1.UrlShortener.Resolve:input_type -> urlshortener.v1.ResolveRequest
+	13, // 23: urlshortener.v1.UrlShortener.Get:input_type -> urlshortener.v1.GetRequest
+	15, // 24: urlshortener.v1.UrlShortener.List:input_type -> urlshortener.v1.ListRequest
+	17, // 25: urlshortener.v1.UrlShortener.Delete:input_type -> urlshortener.v1.DeleteRequest
+	6,  // 26: urlshortener.v1.UrlShortener.Shorten:output_type -> urlshortener.v1.ShortenResponse
+	10, // 27: urlshortener.v1.UrlShortener.BatchShorten:output_type -> urlshortener.v1.BatchShortenResponse
+	12, // 28: urlshortener.v1.UrlShortener.Resolve:output_type -> urlshortener.v1.ResolveResponse
+	14, // 29: urlshortener.v1.UrlShortener.Get:output_type -> urlshortener.v1.Link
+	16, // 30: urlshortener.v1.UrlShortener.List:output_type -> urlshortener.v1.ListResponse
+	20, // 31: urlshortener.v1.UrlShortener.Delete:output_type -> google.protobuf.Empty
+	26, // [26:32] is the sub-list for method output_type
+	20, // [20:26] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_api_urlshortener_v1_shortener_proto_init() }
+func file_api_urlshortener_v1_shortener_proto_init() {
+	if File_api_urlshortener_v1_shortener_proto != nil {
+		return
+	}
+	file_api_urlshortener_v1_shortener_proto_msgTypes[9].OneofWrappers = []any{
+		(*BatchShortenResult_ShortUrl)(nil),
+		(*BatchShortenResult_Error)(nil),
+	}
+	file_api_urlshortener_v1_shortener_proto_msgTypes[14].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_urlshortener_v1_shortener_proto_rawDesc), len(file_api_urlshortener_v1_shortener_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_urlshortener_v1_shortener_proto_goTypes,
+		DependencyIndexes: file_api_urlshortener_v1_shortener_proto_depIdxs,
+		MessageInfos:      file_api_urlshortener_v1_shortener_proto_msgTypes,
+	}.Build()
+	File_api_urlshortener_v1_shortener_proto = out.File
+	file_api_urlshortener_v1_shortener_proto_goTypes = nil
+	file_api_urlshortener_v1_shortener_proto_depIdxs = nil
+}
@@ -2,11 +2,40 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/AlexNov03/UrlShortener/internal/app"
 )
 
+// command is a subcommand run instead of the API server.
+type command interface {
+	Init(args []string) error
+	Run() error
+}
+
+var commands = map[string]func() command{
+	"import":        func() command { return app.NewImportEntryPoint() },
+	"export":        func() command { return app.NewExportEntryPoint() },
+	"migrate-store": func() command { return app.NewMigrateStoreEntryPoint() },
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if newCommand, ok := commands[os.Args[1]]; ok {
+			entryPoint := newCommand()
+			err := entryPoint.Init(os.Args[2:])
+			if err != nil {
+				log.Fatalf("error while initializing %s: %v", os.Args[1], err)
+			}
+
+			err = entryPoint.Run()
+			if err != nil {
+				log.Fatalf("error while running %s: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
 	entryPoint := app.NewApiEntryPoint()
 	err := entryPoint.Init()
 	if err != nil {
@@ -1,26 +1,47 @@
 package app
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"flag"
+	"io/fs"
 	"log"
 	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/AlexNov03/UrlShortener/internal/adapters"
 	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
 	"github.com/AlexNov03/UrlShortener/internal/delivery"
+	"github.com/AlexNov03/UrlShortener/internal/grpcdelivery"
+	"github.com/AlexNov03/UrlShortener/internal/idempotency"
+	"github.com/AlexNov03/UrlShortener/internal/policy"
 	localrepo "github.com/AlexNov03/UrlShortener/internal/repository/local"
 	"github.com/AlexNov03/UrlShortener/internal/repository/pg"
 	"github.com/AlexNov03/UrlShortener/internal/server"
 	"github.com/AlexNov03/UrlShortener/internal/usecase"
-	"github.com/go-playground/validator/v10"
+	"github.com/AlexNov03/UrlShortener/internal/webhook"
+	"github.com/AlexNov03/UrlShortener/utils"
 )
 
+// idempotencyPurgeInterval is how often expired idempotency keys are deleted.
+const idempotencyPurgeInterval = time.Hour
+
+// deletedPurgeInterval is how often the deleted links past their retention
+// period are purged.
+const deletedPurgeInterval = time.Hour
+
 type ApiEntryPoint struct {
-	cfg    *bootstrap.Config
-	server *server.Server
-	db     *sql.DB
+	cfg        *bootstrap.Config
+	server     *server.Server
+	grpcServer *server.GrpcServer
+	db         *sql.DB
+	store      *localrepo.UrlRepository
+	links      *usecase.UrlUsecase
+	webhooks   *webhook.Worker
 }
 
 func NewApiEntryPoint() *ApiEntryPoint {
@@ -35,15 +56,24 @@ func (ae *ApiEntryPoint) Init() error {
 
 	ae.cfg = config
 
-	validator := validator.New(validator.WithRequiredStructEnabled())
+	validator := utils.NewValidator()
 
 	inMemory := flag.Bool("in-memory", true, "defines, whether app use in-memory or postgres db")
 
 	flag.Parse()
 
 	var repo usecase.UrlRepository
+	var idempotencyStore idempotency.Store
+	var webhookStore webhook.Store
 	if *inMemory == true {
-		repo = localrepo.NewUrlRepository()
+		store, err := openSnapshot(ae.cfg.Storage.SnapshotFile)
+		if err != nil {
+			return err
+		}
+		ae.store = store
+		repo = store
+		idempotencyStore = store
+		webhookStore = store
 		log.Printf("app is using in-memory db")
 	} else {
 		db, err := adapters.GetDB(ae.cfg)
@@ -51,29 +81,138 @@ func (ae *ApiEntryPoint) Init() error {
 			return err
 		}
 		ae.db = db
-		repo = pg.NewUrlRepository(db)
+		pgRepo := pg.NewUrlRepository(db)
+		repo = pgRepo
+		idempotencyStore = pgRepo
+		webhookStore = pgRepo
 		log.Printf("app is using postgres db")
 	}
 
+	ae.webhooks = webhook.NewWorker(webhookStore, ae.cfg.Webhooks)
+
 	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	uc := usecase.NewUrlUsecase(repo, rnd, ae.cfg)
+	checkers, err := policyCheckers(ae.cfg)
+	if err != nil {
+		return err
+	}
+
+	uc := usecase.NewUrlUsecase(repo, rnd, ae.cfg, checkers...)
+	ae.links = uc
 	deliv := delivery.NewUrlDelivery(uc, validator)
 
-	ae.server = server.NewServer(ae.cfg, deliv)
+	ae.server = server.NewServer(ae.cfg, deliv, idempotencyStore, uc)
 	ae.server.Init()
 
+	if ae.cfg.Grpc.Port != 0 {
+		ae.grpcServer = server.NewGrpcServer(ae.cfg, grpcdelivery.NewUrlService(uc, validator), uc)
+		ae.grpcServer.Init()
+	}
+
 	return nil
 }
 
+// policyCheckers builds the destination checkers configured in addition to the
+// built-in policy.
+func policyCheckers(cfg *bootstrap.Config) ([]policy.Checker, error) {
+	var checkers []policy.Checker
+	if cfg.Policy.BlocklistFile != "" {
+		blocklist, err := policy.NewBlocklist(cfg.Policy.BlocklistFile, cfg.Policy.BlocklistReload)
+		if err != nil {
+			return nil, err
+		}
+		checkers = append(checkers, blocklist)
+	}
+	return checkers, nil
+}
+
+// openSnapshot creates the in-memory store with the links of the snapshot
+// file, if any. A snapshot that does not exist yet leaves the store empty.
+func openSnapshot(path string) (*localrepo.UrlRepository, error) {
+	store := localrepo.NewUrlRepository()
+	if path == "" {
+		return store, nil
+	}
+
+	err := store.LoadSnapshot(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("snapshot %s does not exist yet, starting with no links", path)
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("links loaded from snapshot %s", path)
+	return store, nil
+}
+
+// saveSnapshots saves the in-memory store every snapshot interval until ctx
+// is done.
+func (ae *ApiEntryPoint) saveSnapshots(ctx context.Context) {
+	ticker := time.NewTicker(ae.cfg.Storage.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ae.store.SaveSnapshot(ae.cfg.Storage.SnapshotFile); err != nil {
+				log.Printf("error while saving snapshot: %v", err)
+			}
+		}
+	}
+}
+
 func (ae *ApiEntryPoint) Run() error {
 	log.Printf("api starting...")
-	defer ae.db.Close()
-	return ae.server.Run()
+	if ae.db != nil {
+		defer ae.db.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if ae.store != nil && ae.cfg.Storage.SnapshotFile != "" && ae.cfg.Storage.SnapshotInterval > 0 {
+		go ae.saveSnapshots(ctx)
+	}
+
+	if idempotent := ae.server.Idempotency(); idempotent != nil {
+		go idempotent.Purge(ctx, idempotencyPurgeInterval)
+	}
+
+	go ae.links.PurgeDeletedLinks(ctx, deletedPurgeInterval)
+	go ae.webhooks.Run(ctx)
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- ae.server.Run()
+	}()
+	if ae.grpcServer != nil {
+		go func() {
+			errs <- ae.grpcServer.Run()
+		}()
+	}
 
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return ae.Stop()
+	}
 }
 
 func (ae *ApiEntryPoint) Stop() error {
+	if ae.grpcServer != nil {
+		ae.grpcServer.Stop()
+	}
+	err := ae.server.Stop()
+	if ae.store != nil && ae.cfg.Storage.SnapshotFile != "" {
+		if saveErr := ae.store.SaveSnapshot(ae.cfg.Storage.SnapshotFile); saveErr != nil {
+			return saveErr
+		}
+		log.Printf("links saved to snapshot %s", ae.cfg.Storage.SnapshotFile)
+	}
 	log.Printf("api stopped")
-	return ae.server.Stop()
+	return err
 }
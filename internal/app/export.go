@@ -0,0 +1,156 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/adapters"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/repository/pg"
+	"github.com/AlexNov03/UrlShortener/internal/usecase"
+)
+
+// ExportEntryPoint runs the export subcommand, writing the links stored in
+// Postgres to a CSV or JSON Lines file, optionally gzipped.
+type ExportEntryPoint struct {
+	cfg    *bootstrap.Config
+	db     *sql.DB
+	uc     *usecase.UrlUsecase
+	opts   *models.ExportOptions
+	output string
+}
+
+func NewExportEntryPoint() *ExportEntryPoint {
+	return &ExportEntryPoint{}
+}
+
+// timeFlag parses an optional RFC 3339 timestamp flag.
+func timeFlag(target **time.Time) func(string) error {
+	return func(value string) error {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		*target = &t
+		return nil
+	}
+}
+
+func (ee *ExportEntryPoint) Init(args []string) error {
+	filter := models.LinkFilter{}
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "", "csv or jsonl, taken from the file extension by default")
+	gzip := flags.Bool("gzip", false, "compress the export, implied by a .gz extension")
+	flags.StringVar(&filter.Owner, "owner", "", "only export the links of this owner")
+	flags.StringVar(&filter.Domain, "domain", "", "only export the links to this domain and its subdomains")
+	flags.StringVar(&filter.Host, "host", "", "only export the links to this exact host")
+	flags.StringVar(&filter.Tag, "tag", "", "only export the links with this tag")
+	flags.StringVar(&filter.Title, "title", "", "only export the links with titles containing this text")
+	flags.StringVar(&filter.Search, "q", "", "only export the links matching this full text search")
+	flags.Func("created-from", "only export the links created at this RFC 3339 time or later", timeFlag(&filter.CreatedFrom))
+	flags.Func("created-to", "only export the links created before this RFC 3339 time", timeFlag(&filter.CreatedTo))
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return errors.New("usage: export [flags] [file, stdout by default]")
+	}
+
+	ee.output = "-"
+	if flags.NArg() == 1 {
+		ee.output = flags.Arg(0)
+	}
+
+	ee.opts = &models.ExportOptions{Format: *format, Gzip: *gzip, Filter: filter}
+	name := strings.ToLower(ee.output)
+	if trimmed, ok := strings.CutSuffix(name, ".gz"); ok {
+		ee.opts.Gzip = true
+		name = trimmed
+	}
+	if ee.opts.Format == "" {
+		ee.opts.Format = importFormats[filepath.Ext(name)]
+	}
+	if ee.opts.Format == "" {
+		ee.opts.Format = "csv"
+	}
+
+	config, err := bootstrap.ReadConfig()
+	if err != nil {
+		return err
+	}
+	ee.cfg = config
+
+	db, err := adapters.GetDB(ee.cfg)
+	if err != nil {
+		return err
+	}
+	ee.db = db
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ee.uc = usecase.NewUrlUsecase(pg.NewUrlRepository(db), rnd, ee.cfg)
+
+	return nil
+}
+
+func (ee *ExportEntryPoint) Run() error {
+	defer ee.db.Close()
+
+	if ee.output == "-" {
+		output := bufio.NewWriter(os.Stdout)
+		if _, err := ee.uc.Export(context.Background(), output, ee.opts); err != nil {
+			return err
+		}
+		return output.Flush()
+	}
+
+	// the export is written next to the output and only replaces it once
+	// complete, so a failed export never leaves a truncated backup behind
+	file, err := os.CreateTemp(filepath.Dir(ee.output), filepath.Base(ee.output)+".*.part")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	output := bufio.NewWriter(file)
+
+	log.Printf("exporting to %s...", ee.output)
+	count, err := ee.uc.Export(context.Background(), output, ee.opts)
+	if err != nil {
+		return fmt.Errorf("export stopped after %d links: %w", count, err)
+	}
+
+	if err := writeAll(output, file); err != nil {
+		return err
+	}
+	if err := os.Rename(file.Name(), ee.output); err != nil {
+		return err
+	}
+
+	log.Printf("export finished: %d links", count)
+	return nil
+}
+
+// writeAll flushes the buffered output to file and syncs it to disk.
+func writeAll(output *bufio.Writer, file *os.File) error {
+	if err := output.Flush(); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	return file.Close()
+}
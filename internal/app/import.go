@@ -0,0 +1,134 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/adapters"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/repository/pg"
+	"github.com/AlexNov03/UrlShortener/internal/usecase"
+)
+
+var importFormats = map[string]string{".csv": "csv", ".jsonl": "jsonl", ".ndjson": "jsonl"}
+
+// ImportEntryPoint runs the import subcommand, loading links from a CSV or
+// JSON Lines file into Postgres with their codes kept.
+type ImportEntryPoint struct {
+	cfg        *bootstrap.Config
+	db         *sql.DB
+	uc         *usecase.UrlUsecase
+	opts       *models.ImportOptions
+	input      string
+	errorsPath string
+}
+
+func NewImportEntryPoint() *ImportEntryPoint {
+	return &ImportEntryPoint{}
+}
+
+func (ie *ImportEntryPoint) Init(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "csv or jsonl, taken from the file extension by default")
+	dryRun := flags.Bool("dry-run", false, "validate the rows and look up conflicts without storing anything")
+	startLine := flags.Int("start-line", 0, "skip the rows before this line to resume an interrupted import")
+	owner := flags.String("owner", "", "owner of every imported link, overriding the owner column")
+	errorsPath := flags.String("errors", "import-errors.jsonl", "file the rejected rows are reported to")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: import [flags] <file or - for stdin>")
+	}
+
+	ie.input = flags.Arg(0)
+	ie.errorsPath = *errorsPath
+	ie.opts = &models.ImportOptions{Format: *format, DryRun: *dryRun, StartLine: *startLine, Owner: *owner}
+	if ie.opts.Format == "" {
+		ie.opts.Format = importFormats[strings.ToLower(filepath.Ext(ie.input))]
+	}
+
+	config, err := bootstrap.ReadConfig()
+	if err != nil {
+		return err
+	}
+	ie.cfg = config
+
+	db, err := adapters.GetDB(ie.cfg)
+	if err != nil {
+		return err
+	}
+	ie.db = db
+
+	checkers, err := policyCheckers(ie.cfg)
+	if err != nil {
+		return err
+	}
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ie.uc = usecase.NewUrlUsecase(pg.NewUrlRepository(db), rnd, ie.cfg, checkers...)
+
+	return nil
+}
+
+func (ie *ImportEntryPoint) Run() error {
+	defer ie.db.Close()
+
+	var input io.Reader = os.Stdin
+	if ie.input != "-" {
+		file, err := os.Open(ie.input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	errorsFile, err := os.Create(ie.errorsPath)
+	if err != nil {
+		return err
+	}
+	defer errorsFile.Close()
+
+	errorsWriter := bufio.NewWriter(errorsFile)
+	encoder := json.NewEncoder(errorsWriter)
+
+	log.Printf("importing %s...", ie.input)
+	report, err := ie.uc.Import(context.Background(), input, ie.opts, func(importError *models.ImportError) {
+		encoder.Encode(importError)
+	})
+
+	if flushErr := errorsWriter.Flush(); flushErr != nil {
+		log.Printf("unable to write import errors: %v", flushErr)
+	}
+
+	if report == nil {
+		return err
+	}
+
+	log.Printf("import finished: processed %d, imported %d, conflicts %d, invalid %d, over quota %d, last line %d, "+
+		"dry run %t", report.Processed, report.Imported, report.Conflicts, report.Invalid, report.OverQuota,
+		report.LastLine, report.DryRun)
+	if report.Conflicts+report.Invalid+report.OverQuota > 0 {
+		log.Printf("rejected rows are listed in %s", ie.errorsPath)
+	}
+
+	if err != nil {
+		return fmt.Errorf("import stopped, resume with -start-line %d: %w", report.LastLine+1, err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/AlexNov03/UrlShortener/internal/adapters"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	localrepo "github.com/AlexNov03/UrlShortener/internal/repository/local"
+	"github.com/AlexNov03/UrlShortener/internal/repository/pg"
+	"github.com/AlexNov03/UrlShortener/internal/usecase"
+)
+
+const (
+	backendMemory   = "memory"
+	backendPostgres = "postgres"
+)
+
+// MigrateStoreEntryPoint runs the migrate-store subcommand, copying every link
+// with its counters between the snapshot file of the in-memory store and
+// Postgres, then verifying the copy.
+type MigrateStoreEntryPoint struct {
+	cfg      *bootstrap.Config
+	db       *sql.DB
+	from     string
+	to       string
+	snapshot string
+	target   *localrepo.UrlRepository
+	migrator *usecase.Migrator
+}
+
+func NewMigrateStoreEntryPoint() *MigrateStoreEntryPoint {
+	return &MigrateStoreEntryPoint{}
+}
+
+func (me *MigrateStoreEntryPoint) Init(args []string) error {
+	config, err := bootstrap.ReadConfig()
+	if err != nil {
+		return err
+	}
+	me.cfg = config
+
+	flags := flag.NewFlagSet("migrate-store", flag.ContinueOnError)
+	flags.StringVar(&me.from, "from", backendMemory, "backend to copy the links from, memory or postgres")
+	flags.StringVar(&me.to, "to", backendPostgres, "backend to copy the links to, memory or postgres")
+	flags.StringVar(&me.snapshot, "snapshot", me.cfg.Storage.SnapshotFile, "snapshot file of the in-memory store")
+	batchSize := flags.Int("batch-size", 0, "links copied per batch, 500 by default")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New("usage: migrate-store [-from memory|postgres] [-to memory|postgres] [flags]")
+	}
+	if me.from == me.to {
+		return errors.New("the source and the target backends must differ")
+	}
+
+	source, err := me.repository(me.from, true)
+	if err != nil {
+		return err
+	}
+	target, err := me.repository(me.to, false)
+	if err != nil {
+		return err
+	}
+
+	me.migrator = usecase.NewMigrator(source, target, *batchSize)
+	return nil
+}
+
+// repository opens a backend. The snapshot of the in-memory store must exist
+// when it is the source; as a target, the links it may already have are kept.
+func (me *MigrateStoreEntryPoint) repository(backend string, source bool) (usecase.UrlRepository, error) {
+	switch backend {
+	case backendMemory:
+		if me.snapshot == "" {
+			return nil, errors.New("the snapshot file is not configured, set -snapshot")
+		}
+		if source {
+			store := localrepo.NewUrlRepository()
+			return store, store.LoadSnapshot(me.snapshot)
+		}
+		store, err := openSnapshot(me.snapshot)
+		if err != nil {
+			return nil, err
+		}
+		me.target = store
+		return store, nil
+	case backendPostgres:
+		db, err := adapters.GetDB(me.cfg)
+		if err != nil {
+			return nil, err
+		}
+		me.db = db
+		return pg.NewUrlRepository(db), nil
+	}
+	return nil, fmt.Errorf("unknown backend %q, expected memory or postgres", backend)
+}
+
+func (me *MigrateStoreEntryPoint) Run() error {
+	if me.db != nil {
+		defer me.db.Close()
+	}
+
+	ctx := context.Background()
+
+	log.Printf("migrating links from %s to %s...", me.from, me.to)
+	progress, err := me.migrator.Migrate(ctx, func(progress *models.MigrationProgress) {
+		log.Printf("processed %d links: copied %d, skipped %d already present",
+			progress.Processed, progress.Copied, progress.Skipped)
+	})
+	if me.target != nil {
+		// whatever was copied is kept, so that a rerun resumes from there
+		if saveErr := me.target.SaveSnapshot(me.snapshot); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("migration stopped after %d links, run it again to resume: %w", progress.Processed, err)
+	}
+	log.Printf("migration finished: processed %d, copied %d, skipped %d",
+		progress.Processed, progress.Copied, progress.Skipped)
+
+	check, err := me.migrator.Verify(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to verify the migration: %w", err)
+	}
+	log.Printf("source: %d links, checksum %s", check.SourceLinks, check.SourceChecksum)
+	log.Printf("target: %d of them, checksum %s", check.TargetLinks, check.TargetChecksum)
+
+	if check.Missing > 0 || check.Mismatched > 0 {
+		return fmt.Errorf("verification failed: %d links missing (%v), %d links differ (%v)",
+			check.Missing, check.MissingCodes, check.Mismatched, check.MismatchedCodes)
+	}
+	log.Printf("verification passed")
+	return nil
+}
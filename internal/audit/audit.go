@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Anonymous is the actor of changes made without an API key.
+const Anonymous = "anonymous"
+
+// Actions recorded in the audit log.
+const (
+	LinkCreate         = "link.create"
+	LinkUpdateWindow   = "link.update_window"
+	LinkUpdateRules    = "link.update_rules"
+	LinkUpdateMetadata = "link.update_metadata"
+	LinkDelete         = "link.delete"
+	LinkRestore        = "link.restore"
+	LinksImport        = "links.import"
+	WebhookCreate      = "webhook.create"
+	WebhookDelete      = "webhook.delete"
+	DeadLetterRetry    = "webhook.retry_dead_letter"
+	WorkspaceCreate    = "workspace.create"
+	MemberAdd          = "member.add"
+	MemberUpdateRole   = "member.update_role"
+	MemberRemove       = "member.remove"
+	ApiKeyCreate       = "api_key.create"
+	ApiKeyDelete       = "api_key.delete"
+)
+
+// Source is where a request comes from: the name of its API key, if valid,
+// and the address of the client.
+type Source struct {
+	Actor string
+	IP    string
+}
+
+type contextKey struct{}
+
+// WithSource returns a copy of ctx carrying source.
+func WithSource(ctx context.Context, source *Source) context.Context {
+	return context.WithValue(ctx, contextKey{}, source)
+}
+
+// SourceFromContext returns the source of a request. The principal set by the
+// authentication middleware takes precedence over the API key seen by the
+// audit middleware; requests without either are made by Anonymous.
+func SourceFromContext(ctx context.Context) *Source {
+	res := &Source{Actor: Anonymous}
+	if source, ok := ctx.Value(contextKey{}).(*Source); ok {
+		*res = *source
+	}
+	if principal := auth.FromContext(ctx); principal != nil {
+		res.Actor = principal.KeyName
+	}
+	return res
+}
+
+// actor returns the name of the API key of a request, without rejecting
+// requests whose key is missing or invalid: they are left to the routes
+// requiring authentication.
+func actor(ctx context.Context, authenticator *auth.Authenticator, header string) string {
+	if header == "" {
+		return Anonymous
+	}
+	principal, err := authenticator.AuthenticateHeader(ctx, header)
+	if err != nil {
+		return Anonymous
+	}
+	return principal.KeyName
+}
+
+// Middleware passes the source of requests to next in the request context.
+func Middleware(authenticator *auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+			source := &Source{Actor: actor(r.Context(), authenticator, r.Header.Get("Authorization")), IP: ip}
+			next.ServeHTTP(w, r.WithContext(WithSource(r.Context(), source)))
+		})
+	}
+}
+
+// UnaryInterceptor passes the source of gRPC calls to the handlers in the
+// context.
+func UnaryInterceptor(authenticator *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var header string
+		if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+			header = values[0]
+		}
+		source := &Source{Actor: actor(ctx, authenticator, header)}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			source.IP = p.Addr.String()
+			if ip, _, err := net.SplitHostPort(source.IP); err == nil {
+				source.IP = ip
+			}
+		}
+		return handler(WithSource(ctx, source), req)
+	}
+}
+
+// Hash returns the hash of entry chained to prevHash. It covers every field
+// but the hashes, the time being taken in microseconds as stored in Postgres.
+func Hash(entry *models.AuditEntry, prevHash string) string {
+	// the fields are marshalled in a fixed order and the raw values as they
+	// were stored, so the hash does not depend on the repository
+	content, _ := json.Marshal([]any{entry.ID, entry.Time.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		entry.Actor, entry.SourceIP, entry.Action, entry.Target, string(entry.Before), string(entry.After)})
+
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	h.Write([]byte{'\n'})
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify checks that entries, in ascending order of ids, continue the chain
+// ending with prevHash, and returns the index of the first entry that does
+// not, or -1.
+func Verify(entries []*models.AuditEntry, prevHash string) int {
+	for i, entry := range entries {
+		if entry.PrevHash != prevHash || entry.Hash != Hash(entry, prevHash) {
+			return i
+		}
+		prevHash = entry.Hash
+	}
+	return -1
+}
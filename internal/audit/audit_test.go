@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+
+	authenticator := auth.NewAuthenticator([]bootstrap.ApiKey{{Name: "migration", Key: "secret-1"}}, nil)
+
+	var source *Source
+	handler := Middleware(authenticator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		source = SourceFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		Name           string
+		Authorization  string
+		ExpectedSource *Source
+	}{
+		{Name: "valid key", Authorization: "Bearer secret-1", ExpectedSource: &Source{Actor: "migration", IP: "192.0.2.1"}},
+		{Name: "missing header", ExpectedSource: &Source{Actor: Anonymous, IP: "192.0.2.1"}},
+		{Name: "unknown key", Authorization: "Bearer secret-2", ExpectedSource: &Source{Actor: Anonymous, IP: "192.0.2.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodPost, "/api/shorten", nil)
+			if tt.Authorization != "" {
+				r.Header.Set("Authorization", tt.Authorization)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, tt.ExpectedSource, source)
+		})
+	}
+}
+
+func TestSourceFromContext(t *testing.T) {
+
+	assert.Equal(t, &Source{Actor: Anonymous}, SourceFromContext(context.Background()))
+
+	ctx := WithSource(context.Background(), &Source{Actor: Anonymous, IP: "192.0.2.1"})
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{KeyName: "migration"})
+	assert.Equal(t, &Source{Actor: "migration", IP: "192.0.2.1"}, SourceFromContext(ctx))
+}
+
+func TestHashChain(t *testing.T) {
+
+	first := &models.AuditEntry{ID: 1, Time: time.Date(2026, time.October, 19, 12, 0, 0, 1500, time.UTC),
+		Actor: "migration", Action: LinkCreate, Target: "Abc_def_gs", After: []byte(`{"short_url":"Abc_def_gs"}`)}
+	first.Hash = Hash(first, "")
+	second := &models.AuditEntry{ID: 2, Time: first.Time.Add(time.Second), Actor: "migration", Action: LinkDelete,
+		Target: "Abc_def_gs", Before: []byte(`{"short_url":"Abc_def_gs"}`), PrevHash: first.Hash}
+	second.Hash = Hash(second, first.Hash)
+
+	assert.Len(t, first.Hash, 64)
+	assert.Equal(t, -1, Verify([]*models.AuditEntry{first, second}, ""))
+
+	// the time is hashed as stored, in microseconds
+	rounded := *first
+	rounded.Time = rounded.Time.Truncate(time.Microsecond)
+	assert.Equal(t, first.Hash, Hash(&rounded, ""))
+
+	tests := []struct {
+		Name   string
+		Tamper func(entry *models.AuditEntry)
+	}{
+		{Name: "edited actor", Tamper: func(entry *models.AuditEntry) { entry.Actor = "admin" }},
+		{Name: "edited value", Tamper: func(entry *models.AuditEntry) { entry.Before = []byte(`{}`) }},
+		{Name: "edited time", Tamper: func(entry *models.AuditEntry) { entry.Time = entry.Time.Add(time.Hour) }},
+		{Name: "rehashed entry", Tamper: func(entry *models.AuditEntry) {
+			entry.Target = "Abc_def_gt"
+			entry.Hash = Hash(entry, entry.PrevHash)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tampered := *first
+			tt.Tamper(&tampered)
+			// an entry rehashed after an edit no longer precedes the next one
+			broken := Verify([]*models.AuditEntry{&tampered, second}, "")
+			assert.GreaterOrEqual(t, broken, 0)
+		})
+	}
+
+	// removing an entry breaks the chain as well
+	assert.Equal(t, 0, Verify([]*models.AuditEntry{second}, ""))
+}
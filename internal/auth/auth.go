@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Roles of the members of a workspace, each one granting the permissions of
+// the previous ones: viewers read links and their statistics, editors create
+// and change links, and admins manage the members, API keys and webhooks of
+// the workspace.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+var roleRanks = map[string]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3}
+
+// ValidRole reports whether role is one of the roles of workspace members.
+func ValidRole(role string) bool {
+	return roleRanks[role] > 0
+}
+
+// Principal is the API key a request was authenticated with. Keys with an
+// Owner are scoped to that workspace and act with Role there, on behalf of
+// Member for the keys issued in the workspace; keys without one are
+// unrestricted. KeyDigest is the Digest of the key, which unlike KeyName
+// identifies it uniquely.
+type Principal struct {
+	KeyName   string
+	KeyDigest string
+	Owner     string
+	Member    string
+	Role      string
+}
+
+// Unrestricted reports whether the principal may act in every workspace.
+func (p *Principal) Unrestricted() bool {
+	return p.Owner == ""
+}
+
+// Allows reports whether the principal may act with role in workspace.
+func (p *Principal) Allows(workspace, role string) bool {
+	if p.Unrestricted() {
+		return true
+	}
+	return p.Owner == workspace && roleRanks[p.Role] >= roleRanks[role]
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal of an authenticated request, or nil.
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(contextKey{}).(*Principal)
+	return principal
+}
+
+// KeyStore looks up the API keys issued in workspaces by the hex SHA-256
+// digests of their secrets, returning nil for unknown keys.
+type KeyStore interface {
+	LookupApiKey(ctx context.Context, digest string) (*Principal, error)
+}
+
+// Digest returns the hex SHA-256 digest API keys are stored and looked up by.
+func Digest(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticator checks the bearer API keys of requests against the configured
+// keys, then against the keys of the store, if any. Only key digests are
+// kept, so lookups don't compare secrets byte by byte.
+type Authenticator struct {
+	keys  map[[sha256.Size]byte]*Principal
+	store KeyStore
+}
+
+// NewAuthenticator creates an authenticator of the configured keys and of the
+// keys of store, which may be nil. Configured keys with an owner act as
+// admins of that workspace unless given another role.
+func NewAuthenticator(keys []bootstrap.ApiKey, store KeyStore) *Authenticator {
+	res := &Authenticator{keys: make(map[[sha256.Size]byte]*Principal, len(keys)), store: store}
+	for _, key := range keys {
+		if key.Key == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(key.Key))
+		principal := &Principal{KeyName: key.Name, KeyDigest: hex.EncodeToString(sum[:]), Owner: key.Owner}
+		if key.Owner != "" {
+			principal.Role = key.Role
+			if principal.Role == "" {
+				principal.Role = RoleAdmin
+			}
+		}
+		res.keys[sum] = principal
+	}
+	return res
+}
+
+// Authenticate returns the principal of the key in the Authorization header
+// of r.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	return a.AuthenticateHeader(r.Context(), r.Header.Get("Authorization"))
+}
+
+// AuthenticateHeader returns the principal of the key in the value of an
+// Authorization header, which gRPC calls pass as metadata.
+func (a *Authenticator) AuthenticateHeader(ctx context.Context, header string) (*Principal, error) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	token = strings.TrimSpace(token)
+	if !ok || token == "" {
+		return nil, utils.NewInternalError(http.StatusUnauthorized, "api key is required")
+	}
+
+	if principal, ok := a.keys[sha256.Sum256([]byte(token))]; ok {
+		return principal, nil
+	}
+
+	if a.store != nil {
+		digest := Digest(token)
+		principal, err := a.store.LookupApiKey(ctx, digest)
+		if err != nil {
+			return nil, fmt.Errorf("auth.Authenticator.AuthenticateHeader: %w", err)
+		}
+		if principal != nil {
+			res := *principal
+			res.KeyDigest = digest
+			return &res, nil
+		}
+	}
+	return nil, utils.NewInternalError(http.StatusUnauthorized, "api key is not valid")
+}
+
+// Middleware rejects requests without a valid API key with 401 and passes the
+// principal of the others to next in the request context.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			utils.ProcessError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// Forbidden returns the error rejecting principals below role.
+func Forbidden(role string) error {
+	return utils.NewInternalError(http.StatusForbidden, fmt.Sprintf("the api key needs the %s role", role))
+}
+
+// Require rejects the requests of principals below role in their workspace
+// with 403. It must be preceded by Middleware.
+func Require(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := FromContext(r.Context())
+			if principal == nil {
+				utils.ProcessError(w, utils.NewInternalError(http.StatusUnauthorized, "api key is required"))
+				return
+			}
+			if !principal.Allows(principal.Owner, role) {
+				utils.ProcessError(w, Forbidden(role))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UnaryInterceptor rejects calls of the gRPC methods mapped to the roles they
+// require without a valid API key in the authorization metadata with
+// Unauthenticated, and those of principals below the role with
+// PermissionDenied. The principal of the others is passed to the handler in
+// the context. Other methods are served without authentication.
+func (a *Authenticator) UnaryInterceptor(methods map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		role, ok := methods[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		var header string
+		if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+			header = values[0]
+		}
+
+		principal, err := a.AuthenticateHeader(ctx, header)
+		if err != nil {
+			var internalError *utils.InternalError
+			if !errors.As(err, &internalError) {
+				// the causes of server errors are not sent to clients
+				log.Printf("internal server error: %v", err)
+				return nil, status.Error(codes.Internal, http.StatusText(http.StatusInternalServerError))
+			}
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if !principal.Allows(principal.Owner, role) {
+			return nil, status.Error(codes.PermissionDenied, Forbidden(role).Error())
+		}
+		return handler(WithPrincipal(ctx, principal), req)
+	}
+}
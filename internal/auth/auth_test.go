@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// keyStore is a KeyStore of principals by key digest.
+type keyStore map[string]*Principal
+
+func (ks keyStore) LookupApiKey(ctx context.Context, digest string) (*Principal, error) {
+	if digest == Digest("broken") {
+		return nil, errors.New("connection refused")
+	}
+	return ks[digest], nil
+}
+
+func TestMiddleware(t *testing.T) {
+
+	authenticator := NewAuthenticator([]bootstrap.ApiKey{
+		{Name: "migration", Key: "secret-1", Owner: "team"},
+		{Name: "disabled", Key: ""},
+	}, keyStore{Digest("secret-3"): {KeyName: "team/ci", Owner: "team", Member: "alice", Role: RoleEditor}})
+
+	var principal *Principal
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal = FromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		Name              string
+		Authorization     string
+		ExpectedStatus    int
+		ExpectedPrincipal *Principal
+	}{
+		{Name: "valid key", Authorization: "Bearer secret-1", ExpectedStatus: http.StatusNoContent,
+			ExpectedPrincipal: &Principal{KeyName: "migration", KeyDigest: Digest("secret-1"), Owner: "team",
+				Role: RoleAdmin}},
+		{Name: "workspace key", Authorization: "Bearer secret-3", ExpectedStatus: http.StatusNoContent,
+			ExpectedPrincipal: &Principal{KeyName: "team/ci", KeyDigest: Digest("secret-3"), Owner: "team",
+				Member: "alice", Role: RoleEditor}},
+		{Name: "missing header", Authorization: "", ExpectedStatus: http.StatusUnauthorized},
+		{Name: "other scheme", Authorization: "Basic secret-1", ExpectedStatus: http.StatusUnauthorized},
+		{Name: "unknown key", Authorization: "Bearer secret-2", ExpectedStatus: http.StatusUnauthorized},
+		{Name: "empty key", Authorization: "Bearer ", ExpectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			principal = nil
+
+			r := httptest.NewRequest(http.MethodPost, "/api/import", nil)
+			if tt.Authorization != "" {
+				r.Header.Set("Authorization", tt.Authorization)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			assert.Equal(t, tt.ExpectedPrincipal, principal)
+			if tt.ExpectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+
+	authenticator := NewAuthenticator([]bootstrap.ApiKey{
+		{Name: "migration", Key: "secret-1", Owner: "team"},
+		{Name: "reader", Key: "secret-3", Owner: "team", Role: RoleViewer},
+	}, keyStore{})
+	interceptor := authenticator.UnaryInterceptor(map[string]string{"/svc/Delete": RoleEditor, "/svc/List": RoleViewer})
+
+	var principal *Principal
+	handler := func(ctx context.Context, req any) (any, error) {
+		principal = FromContext(ctx)
+		return "ok", nil
+	}
+
+	tests := []struct {
+		Name              string
+		Method            string
+		Authorization     string
+		ExpectedCode      codes.Code
+		ExpectedPrincipal *Principal
+	}{
+		{Name: "valid key", Method: "/svc/Delete", Authorization: "Bearer secret-1", ExpectedCode: codes.OK,
+			ExpectedPrincipal: &Principal{KeyName: "migration", KeyDigest: Digest("secret-1"), Owner: "team",
+				Role: RoleAdmin}},
+		{Name: "missing key", Method: "/svc/Delete", ExpectedCode: codes.Unauthenticated},
+		{Name: "role too low", Method: "/svc/Delete", Authorization: "Bearer secret-3",
+			ExpectedCode: codes.PermissionDenied},
+		{Name: "role high enough", Method: "/svc/List", Authorization: "Bearer secret-3", ExpectedCode: codes.OK,
+			ExpectedPrincipal: &Principal{KeyName: "reader", KeyDigest: Digest("secret-3"), Owner: "team",
+				Role: RoleViewer}},
+		{Name: "unknown key", Method: "/svc/Delete", Authorization: "Bearer secret-2", ExpectedCode: codes.Unauthenticated},
+		{Name: "unprotected method", Method: "/svc/Get", ExpectedCode: codes.OK},
+		{Name: "key store error", Method: "/svc/Delete", Authorization: "Bearer broken", ExpectedCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			principal = nil
+
+			ctx := context.Background()
+			if tt.Authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.Authorization))
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.Method}, handler)
+
+			assert.Equal(t, tt.ExpectedCode, status.Code(err))
+			assert.Equal(t, tt.ExpectedPrincipal, principal)
+			if tt.ExpectedCode == codes.Internal {
+				// the cause stays in the server log
+				assert.Equal(t, "Internal Server Error", status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestAuthenticateHeaderStoreError(t *testing.T) {
+
+	authenticator := NewAuthenticator(nil, keyStore{})
+
+	_, err := authenticator.AuthenticateHeader(context.Background(), "Bearer broken")
+	assert.EqualError(t, err, "auth.Authenticator.AuthenticateHeader: connection refused")
+}
+
+func TestPrincipalAllows(t *testing.T) {
+
+	tests := []struct {
+		Name      string
+		Principal *Principal
+		Workspace string
+		Role      string
+		Expected  bool
+	}{
+		{Name: "unrestricted", Principal: &Principal{KeyName: "ops"}, Workspace: "team", Role: RoleAdmin, Expected: true},
+		{Name: "viewer reads", Principal: &Principal{Owner: "team", Role: RoleViewer}, Workspace: "team",
+			Role: RoleViewer, Expected: true},
+		{Name: "viewer edits", Principal: &Principal{Owner: "team", Role: RoleViewer}, Workspace: "team",
+			Role: RoleEditor},
+		{Name: "editor edits", Principal: &Principal{Owner: "team", Role: RoleEditor}, Workspace: "team",
+			Role: RoleEditor, Expected: true},
+		{Name: "editor manages", Principal: &Principal{Owner: "team", Role: RoleEditor}, Workspace: "team",
+			Role: RoleAdmin},
+		{Name: "admin manages", Principal: &Principal{Owner: "team", Role: RoleAdmin}, Workspace: "team",
+			Role: RoleAdmin, Expected: true},
+		{Name: "other workspace", Principal: &Principal{Owner: "team", Role: RoleAdmin}, Workspace: "other",
+			Role: RoleViewer},
+		{Name: "unknown role", Principal: &Principal{Owner: "team", Role: "owner"}, Workspace: "team",
+			Role: RoleViewer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, tt.Principal.Allows(tt.Workspace, tt.Role))
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+
+	handler := Require(RoleEditor)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		Name           string
+		Principal      *Principal
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		{Name: "no principal", ExpectedStatus: http.StatusUnauthorized},
+		{Name: "unrestricted", Principal: &Principal{KeyName: "ops"}, ExpectedStatus: http.StatusNoContent},
+		{Name: "editor", Principal: &Principal{Owner: "team", Role: RoleEditor}, ExpectedStatus: http.StatusNoContent},
+		{Name: "admin", Principal: &Principal{Owner: "team", Role: RoleAdmin}, ExpectedStatus: http.StatusNoContent},
+		{Name: "viewer", Principal: &Principal{Owner: "team", Role: RoleViewer}, ExpectedStatus: http.StatusForbidden,
+			ExpectedBody: "the api key needs the editor role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodPut, "/api/v1/links/Abc_def_qA/rules", nil)
+			if tt.Principal != nil {
+				r = r.WithContext(WithPrincipal(r.Context(), tt.Principal))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.ExpectedBody)
+		})
+	}
+}
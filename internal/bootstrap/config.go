@@ -2,6 +2,7 @@ package bootstrap
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -22,9 +23,135 @@ type Server struct {
 	Port     int    `mapstructure:"port"`
 }
 
+// Links configures how links are resolved outside of their activation window,
+// how long interstitial pages count down before redirecting, how long
+// destination urls may be and how long deleted links can be restored before
+// they are purged. Zero values fall back to 404 "not active yet", 410
+// "expired", 5 seconds, 8192 characters and 30 days.
+type Links struct {
+	NotYetActiveStatus  int           `mapstructure:"not_yet_active_status"`
+	NotYetActiveMessage string        `mapstructure:"not_yet_active_message"`
+	ExpiredStatus       int           `mapstructure:"expired_status"`
+	ExpiredMessage      string        `mapstructure:"expired_message"`
+	InterstitialDelay   int           `mapstructure:"interstitial_delay"`
+	MaxUrlLength        int           `mapstructure:"max_url_length"`
+	DeletedRetention    time.Duration `mapstructure:"deleted_retention"`
+}
+
+// Qr configures QR code rendering. A zero CacheSize means 256 cached images.
+type Qr struct {
+	CacheSize int `mapstructure:"cache_size"`
+}
+
+// Policy configures which destinations links may point to. Empty
+// AllowedSchemes means http and https, nil Shorteners means the built-in list
+// of shortener domains. The blocklist file is optional.
+type Policy struct {
+	AllowedSchemes  []string      `mapstructure:"allowed_schemes"`
+	OwnDomains      []string      `mapstructure:"own_domains"`
+	Shorteners      []string      `mapstructure:"shorteners"`
+	BlocklistFile   string        `mapstructure:"blocklist_file"`
+	BlocklistReload time.Duration `mapstructure:"blocklist_reload"`
+}
+
+// Canonicalization configures the optional steps of destination
+// normalization. Nil TrackingParams means the built-in list of utm_* and ad
+// click ids; a trailing * in a name matches any suffix.
+type Canonicalization struct {
+	SortQuery      bool     `mapstructure:"sort_query"`
+	StripTracking  bool     `mapstructure:"strip_tracking"`
+	TrackingParams []string `mapstructure:"tracking_params"`
+}
+
+// ApiKey grants access to the endpoints requiring authentication. Keys with
+// an Owner are scoped to that workspace, where they act with Role (admin by
+// default); keys without one are unrestricted.
+type ApiKey struct {
+	Name  string `mapstructure:"name"`
+	Key   string `mapstructure:"key"`
+	Owner string `mapstructure:"owner"`
+	Role  string `mapstructure:"role"`
+}
+
+type Auth struct {
+	ApiKeys []ApiKey `mapstructure:"api_keys"`
+}
+
+// Import configures bulk imports. A zero BatchSize means 1000 rows per
+// insert.
+type Import struct {
+	BatchSize int `mapstructure:"batch_size"`
+}
+
+// Storage configures the persistence of the in-memory store. When
+// SnapshotFile is set, links are loaded from it on start and saved to it every
+// SnapshotInterval, if positive, and on shutdown.
+type Storage struct {
+	SnapshotFile     string        `mapstructure:"snapshot_file"`
+	SnapshotInterval time.Duration `mapstructure:"snapshot_interval"`
+}
+
+// Grpc configures the gRPC API, served alongside the REST API when Port is
+// set.
+type Grpc struct {
+	Port int `mapstructure:"port"`
+}
+
+// Idempotency configures how long the responses of requests sent with an
+// Idempotency-Key header are kept for retries. A zero TTL means 24 hours.
+type Idempotency struct {
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+// Webhooks configures the delivery of webhooks. Zero values fall back to
+// polling the outbox every second, 10 attempts per delivery, retries after
+// 10 seconds doubling up to an hour, a 10 seconds request timeout and
+// checking for expired links every minute.
+type Webhooks struct {
+	PollInterval        time.Duration `mapstructure:"poll_interval"`
+	MaxAttempts         int           `mapstructure:"max_attempts"`
+	InitialBackoff      time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff          time.Duration `mapstructure:"max_backoff"`
+	Timeout             time.Duration `mapstructure:"timeout"`
+	ExpiryCheckInterval time.Duration `mapstructure:"expiry_check_interval"`
+}
+
+// QuotaLimits caps the usage of an owner: the links it has that are not
+// deleted, and the links it creates and the resolutions of its links in a
+// calendar month (UTC). Zero values mean no limit.
+type QuotaLimits struct {
+	MaxActiveLinks     int64 `mapstructure:"max_active_links"`
+	MonthlyCreations   int64 `mapstructure:"monthly_creations"`
+	MonthlyResolutions int64 `mapstructure:"monthly_resolutions"`
+}
+
+// OwnerQuota replaces the default limits for Owner.
+type OwnerQuota struct {
+	Owner       string `mapstructure:"owner"`
+	QuotaLimits `mapstructure:",squash"`
+}
+
+// Quotas configures the limits of the owners of links. Links without an
+// owner are not limited.
+type Quotas struct {
+	Default QuotaLimits  `mapstructure:"default"`
+	Owners  []OwnerQuota `mapstructure:"owners"`
+}
+
 type Config struct {
-	Server   Server   `mapstructure:"server"`
-	Database Database `mapstructure:"database"`
+	Server           Server           `mapstructure:"server"`
+	Database         Database         `mapstructure:"database"`
+	Links            Links            `mapstructure:"links"`
+	Qr               Qr               `mapstructure:"qr"`
+	Policy           Policy           `mapstructure:"policy"`
+	Canonicalization Canonicalization `mapstructure:"canonicalization"`
+	Auth             Auth             `mapstructure:"auth"`
+	Import           Import           `mapstructure:"import"`
+	Storage          Storage          `mapstructure:"storage"`
+	Grpc             Grpc             `mapstructure:"grpc"`
+	Idempotency      Idempotency      `mapstructure:"idempotency"`
+	Webhooks         Webhooks         `mapstructure:"webhooks"`
+	Quotas           Quotas           `mapstructure:"quotas"`
 }
 
 func ReadConfig() (*Config, error) {
@@ -0,0 +1,69 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+func (ud *UrlDelivery) ListAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	inputData := &models.AuditFilter{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+		Target: query.Get("target"),
+		Order:  query.Get("order"),
+	}
+
+	var err error
+	if inputData.From, err = parseTime(query.Get("from")); err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+	if inputData.To, err = parseTime(query.Get("to")); err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		if inputData.Cursor, err = strconv.ParseInt(cursor, 10, 64); err != nil {
+			utils.ProcessBadRequestError(w, "incorrect input data")
+			return
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if inputData.Limit, err = strconv.Atoi(limit); err != nil {
+			utils.ProcessBadRequestError(w, "incorrect input data")
+			return
+		}
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	entries, err := ud.UC.ListAudit(r.Context(), inputData)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
+
+func (ud *UrlDelivery) VerifyAudit(w http.ResponseWriter, r *http.Request) {
+	verification, err := ud.UC.VerifyAudit(r.Context())
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(verification)
+}
@@ -0,0 +1,131 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/delivery/mocks"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAudit(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	from := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		Query                  string
+		Principal              *auth.Principal
+		ExpectedRespBody       string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful listing",
+			Setup: func() {
+				mockedUc.EXPECT().ListAudit(gomock.Any(), &models.AuditFilter{Actor: "ci", Action: "link.delete",
+					From: &from, Cursor: 12, Limit: 1}).Return(&models.AuditList{Entries: []models.AuditEntry{{ID: 11,
+					Time: at, Actor: "ci", Action: "link.delete", Target: "Abc_def_gs",
+					Before: []byte(`{"short_url":"Abc_def_gs"}`), PrevHash: "h10", Hash: "h11"}}, NextCursor: 11}, nil)
+			},
+			Query:     "?actor=ci&action=link.delete&from=2026-10-01T00:00:00Z&cursor=12&limit=1",
+			Principal: &auth.Principal{KeyName: "ci"},
+			ExpectedRespBody: `{"entries":[{"id":11,"time":"2026-10-19T12:00:00Z","actor":"ci","action":"link.delete",` +
+				`"target":"Abc_def_gs","before":{"short_url":"Abc_def_gs"},"prev_hash":"h10","hash":"h11"}],` +
+				`"next_cursor":11}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "test for owner-bound key",
+			Setup: func() {
+				mockedUc.EXPECT().ListAudit(gomock.Any(), gomock.Any()).Return(nil,
+					utils.NewInternalError(http.StatusForbidden, "the audit log needs an unrestricted API key"))
+			},
+			Principal:              &auth.Principal{KeyName: "team", Owner: "team"},
+			ExpectedRespStatusCode: http.StatusForbidden,
+		},
+		{
+			Name:                   "test for incorrect time",
+			Setup:                  func() {},
+			Query:                  "?to=yesterday",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for incorrect cursor",
+			Setup:                  func() {},
+			Query:                  "?cursor=abc",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for incorrect order",
+			Setup:                  func() {},
+			Query:                  "?order=random",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for too large limit",
+			Setup:                  func() {},
+			Query:                  "?limit=1001",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup()
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/audit"+tt.Query, nil)
+			if tt.Principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.Principal))
+			}
+			w := httptest.NewRecorder()
+
+			ud.ListAudit(w, r)
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, w.Code)
+			if tt.ExpectedRespBody != "" {
+				assert.JSONEq(t, tt.ExpectedRespBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestVerifyAudit(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	mockedUc.EXPECT().VerifyAudit(gomock.Any()).Return(&models.AuditVerification{Valid: false, Checked: 4,
+		BrokenAt: 5}, nil)
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/audit/verify", nil)
+	w := httptest.NewRecorder()
+	ud.VerifyAudit(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"valid":false,"checked":4,"broken_at":5}`, w.Body.String())
+
+	mockedUc.EXPECT().VerifyAudit(gomock.Any()).Return(nil,
+		utils.NewInternalError(http.StatusForbidden, "the audit log needs an unrestricted API key"))
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/audit/verify", nil)
+	w = httptest.NewRecorder()
+	ud.VerifyAudit(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,95 @@
+package delivery
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+var exportContentTypes = map[string]string{
+	"csv":   "text/csv; charset=utf-8",
+	"jsonl": "application/x-ndjson",
+}
+
+// exportResponse sends the headers of an export along with its first bytes,
+// so that errors occurring before anything is written can still be reported
+// with their status.
+type exportResponse struct {
+	w       http.ResponseWriter
+	opts    *models.ExportOptions
+	started bool
+}
+
+func (er *exportResponse) Write(p []byte) (int, error) {
+	if !er.started {
+		er.started = true
+
+		filename := "links." + er.opts.Format
+		contentType := exportContentTypes[er.opts.Format]
+		if er.opts.Gzip {
+			filename += ".gz"
+			contentType = "application/gzip"
+		}
+		er.w.Header().Set("Content-Type", contentType)
+		er.w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		er.w.WriteHeader(http.StatusOK)
+	}
+	return er.w.Write(p)
+}
+
+// Export streams the links matching the listing filters as CSV or JSON Lines,
+// gzipped when gzip is set. An API key bound to an owner only exports the
+// links of that owner.
+func (ud *UrlDelivery) Export(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := parseLinkFilter(query)
+	if err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+
+	inputData := &models.ExportOptions{Format: query.Get("format"), Filter: *filter}
+	if inputData.Format == "" {
+		inputData.Format = "csv"
+	}
+	if gzip := query.Get("gzip"); gzip != "" {
+		if inputData.Gzip, err = strconv.ParseBool(gzip); err != nil {
+			utils.ProcessBadRequestError(w, "incorrect input data")
+			return
+		}
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	// exports take longer than the server timeouts meant for single links
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	resp := &exportResponse{w: w, opts: inputData}
+
+	count, err := ud.UC.Export(ctx, resp, inputData)
+	if err != nil && !resp.started {
+		utils.ProcessError(w, err)
+		return
+	}
+	if err != nil {
+		// the status is already sent, dropping the connection tells the
+		// client that the export is incomplete
+		log.Printf("error while exporting after %d links: %v", count, err)
+		panic(http.ErrAbortHandler)
+	}
+	if !resp.started {
+		resp.Write(nil)
+	}
+}
@@ -0,0 +1,170 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/delivery/mocks"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	createdFrom := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	writeExport := func(data string) func(context.Context, io.Writer, *models.ExportOptions) (int, error) {
+		return func(ctx context.Context, w io.Writer, opts *models.ExportOptions) (int, error) {
+			if data != "" {
+				w.Write([]byte(data))
+			}
+			return 1, nil
+		}
+	}
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		Query                  string
+		Principal              *auth.Principal
+		ExpectedRespBody       string
+		ExpectedContentType    string
+		ExpectedDisposition    string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful csv export with filters",
+			Setup: func() {
+				mockedUc.EXPECT().Export(gomock.Any(), gomock.Any(), &models.ExportOptions{Format: "csv",
+					Filter: models.LinkFilter{Tag: "promo", Domain: "example.com", CreatedFrom: &createdFrom}}).DoAndReturn(
+					writeExport("code,url\n"))
+			},
+			Query:                  "?tag=promo&domain=example.com&created_from=2025-03-01T00:00:00Z",
+			ExpectedRespBody:       "code,url\n",
+			ExpectedContentType:    "text/csv; charset=utf-8",
+			ExpectedDisposition:    `attachment; filename="links.csv"`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "successful gzipped jsonl export with a workspace key",
+			Setup: func() {
+				// the usecase scopes the filter to the workspace of the key
+				mockedUc.EXPECT().Export(gomock.Any(), gomock.Any(), &models.ExportOptions{Format: "jsonl", Gzip: true,
+					Filter: models.LinkFilter{Owner: "other"}}).DoAndReturn(writeExport("data"))
+			},
+			Query:                  "?format=jsonl&gzip=true&owner=other",
+			Principal:              &auth.Principal{KeyName: "backup", Owner: "team"},
+			ExpectedRespBody:       "data",
+			ExpectedContentType:    "application/gzip",
+			ExpectedDisposition:    `attachment; filename="links.jsonl.gz"`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "successful empty export",
+			Setup: func() {
+				mockedUc.EXPECT().Export(gomock.Any(), gomock.Any(), &models.ExportOptions{Format: "jsonl"}).DoAndReturn(
+					writeExport(""))
+			},
+			Query:                  "?format=jsonl",
+			ExpectedRespBody:       "",
+			ExpectedContentType:    "application/x-ndjson",
+			ExpectedDisposition:    `attachment; filename="links.jsonl"`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "test for error before the export starts",
+			Setup: func() {
+				mockedUc.EXPECT().Export(gomock.Any(), gomock.Any(), gomock.Any()).Return(0,
+					&utils.InternalError{Code: http.StatusBadRequest, Message: "incorrect host in filter"})
+			},
+			Query: "?host=xn--a.com",
+			ExpectedRespBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"incorrect host in filter",` +
+				`"code":"bad_request"}` + "\n",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for unknown format",
+			Setup:                  func() {},
+			Query:                  "?format=xml",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for incorrect gzip",
+			Setup:                  func() {},
+			Query:                  "?gzip=maybe",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for incorrect creation time",
+			Setup:                  func() {},
+			Query:                  "?created_to=yesterday",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, "/api/export"+tt.Query, nil)
+			if tt.Principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.Principal))
+			}
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			ud.Export(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, resp.StatusCode)
+			if tt.ExpectedRespStatusCode == http.StatusOK {
+				assert.Equal(t, tt.ExpectedRespBody, w.Body.String())
+				assert.Equal(t, tt.ExpectedContentType, resp.Header.Get("Content-Type"))
+				assert.Equal(t, tt.ExpectedDisposition, resp.Header.Get("Content-Disposition"))
+			} else if tt.ExpectedRespBody != "" {
+				assert.Equal(t, tt.ExpectedRespBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestExportInterrupted(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	mockedUc.EXPECT().Export(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, w io.Writer, opts *models.ExportOptions) (int, error) {
+			w.Write([]byte("code,url\n"))
+			return 1, errors.New("connection reset")
+		})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/export", nil)
+	w := httptest.NewRecorder()
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() { ud.Export(w, r) })
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,86 @@
+package delivery
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+// maxReportedImportErrors caps the errors listed in the response of an
+// import; the counters of the report still cover every row.
+const maxReportedImportErrors = 1000
+
+var importContentTypes = map[string]string{
+	"text/csv":             "csv",
+	"application/jsonl":    "jsonl",
+	"application/x-ndjson": "jsonl",
+}
+
+// Import streams a CSV or JSON Lines body into the store. The format is taken
+// from the format parameter or the Content-Type header. Links imported with an
+// API key bound to an owner belong to that owner.
+func (ud *UrlDelivery) Import(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	query := r.URL.Query()
+
+	inputData := &models.ImportOptions{Format: query.Get("format")}
+	if inputData.Format == "" {
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		inputData.Format = importContentTypes[contentType]
+	}
+
+	var err error
+	if dryRun := query.Get("dry_run"); dryRun != "" {
+		if inputData.DryRun, err = strconv.ParseBool(dryRun); err != nil {
+			utils.ProcessBadRequestError(w, "incorrect input data")
+			return
+		}
+	}
+	if startLine := query.Get("start_line"); startLine != "" {
+		if inputData.StartLine, err = strconv.Atoi(startLine); err != nil {
+			utils.ProcessBadRequestError(w, "incorrect input data")
+			return
+		}
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	inputData.Owner = principalOwner(ctx)
+
+	// imports take longer than the server timeouts meant for single links
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	var importErrors []models.ImportError
+	truncated := false
+
+	report, err := ud.UC.Import(ctx, r.Body, inputData, func(importError *models.ImportError) {
+		if len(importErrors) == maxReportedImportErrors {
+			truncated = true
+			return
+		}
+		importErrors = append(importErrors, *importError)
+	})
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	report.Errors = importErrors
+	report.ErrorsTruncated = truncated
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
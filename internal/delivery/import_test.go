@@ -0,0 +1,139 @@
+package delivery
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/delivery/mocks"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	body := "code,url\nabc,https://example.com\n"
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		Query                  string
+		ContentType            string
+		Principal              *auth.Principal
+		ExpectedRespBody       string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful import with errors",
+			Setup: func() {
+				mockedUc.EXPECT().Import(gomock.Any(), gomock.Any(), &models.ImportOptions{Format: "csv", Owner: "team"},
+					gomock.Any()).DoAndReturn(func(ctx context.Context, r io.Reader, opts *models.ImportOptions,
+					onError func(*models.ImportError)) (*models.ImportReport, error) {
+
+					data, err := io.ReadAll(r)
+					assert.NoError(t, err)
+					assert.Equal(t, body, string(data))
+
+					onError(&models.ImportError{Line: 3, Code: "def", Reason: "conflict", Message: "this shortUrl already exists"})
+					return &models.ImportReport{Processed: 2, Imported: 1, Conflicts: 1, LastLine: 3}, nil
+				})
+			},
+			Query:     "",
+			Principal: &auth.Principal{KeyName: "ci", Owner: "team"},
+			ExpectedRespBody: `{"processed":2,"imported":1,"conflicts":1,"invalid":0,"over_quota":0,"last_line":3,` +
+				`"errors":[{"line":3,"code":"def","reason":"conflict","message":"this shortUrl already exists"}]}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "successful dry run with format from content type",
+			Setup: func() {
+				mockedUc.EXPECT().Import(gomock.Any(), gomock.Any(), &models.ImportOptions{Format: "jsonl", DryRun: true,
+					StartLine: 10}, gomock.Any()).Return(&models.ImportReport{DryRun: true, Processed: 1, Imported: 1,
+					LastLine: 11}, nil)
+			},
+			Query:       "?dry_run=true&start_line=10",
+			ContentType: "application/x-ndjson; charset=utf-8",
+			ExpectedRespBody: `{"dry_run":true,"processed":1,"imported":1,"conflicts":0,"invalid":0,"over_quota":0,` +
+				`"last_line":11}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "test for stopped import",
+			Setup: func() {
+				mockedUc.EXPECT().Import(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+					&models.ImportReport{LastLine: 2}, &utils.InternalError{Code: http.StatusBadRequest,
+						Message: `unexpected csv column "name"`})
+			},
+			Query:                  "?format=csv",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for missing format",
+			Setup:                  func() {},
+			Query:                  "",
+			ContentType:            "text/plain",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for unknown format",
+			Setup:                  func() {},
+			Query:                  "?format=xml",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for incorrect dry_run",
+			Setup:                  func() {},
+			Query:                  "?format=csv&dry_run=maybe",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for negative start_line",
+			Setup:                  func() {},
+			Query:                  "?format=csv&start_line=-1",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodPost, "/api/import"+tt.Query, strings.NewReader(body))
+			if tt.ContentType != "" {
+				r.Header.Set("Content-Type", tt.ContentType)
+			} else {
+				r.Header.Set("Content-Type", "text/csv")
+			}
+			if tt.Principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.Principal))
+			}
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			ud.Import(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, resp.StatusCode)
+			if tt.ExpectedRespBody != "" {
+				assert.JSONEq(t, tt.ExpectedRespBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -6,8 +6,10 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
+	models "github.com/AlexNov03/UrlShortener/internal/models"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,32 +36,473 @@ func (m *MockUrlUsecase) EXPECT() *MockUrlUsecaseMockRecorder {
 	return m.recorder
 }
 
+// AddMember mocks base method.
+func (m *MockUrlUsecase) AddMember(ctx context.Context, workspace string, member *models.Member) (*models.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", ctx, workspace, member)
+	ret0, _ := ret[0].(*models.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockUrlUsecaseMockRecorder) AddMember(ctx, workspace, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockUrlUsecase)(nil).AddMember), ctx, workspace, member)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockUrlUsecase) CreateWebhook(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, subscription)
+	ret0, _ := ret[0].(*models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockUrlUsecaseMockRecorder) CreateWebhook(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockUrlUsecase)(nil).CreateWebhook), ctx, subscription)
+}
+
+// CreateWorkspace mocks base method.
+func (m *MockUrlUsecase) CreateWorkspace(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspace", ctx, workspace)
+	ret0, _ := ret[0].(*models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkspace indicates an expected call of CreateWorkspace.
+func (mr *MockUrlUsecaseMockRecorder) CreateWorkspace(ctx, workspace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockUrlUsecase)(nil).CreateWorkspace), ctx, workspace)
+}
+
+// CreateWorkspaceKey mocks base method.
+func (m *MockUrlUsecase) CreateWorkspaceKey(ctx context.Context, workspace string, key *models.WorkspaceKey) (*models.WorkspaceKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspaceKey", ctx, workspace, key)
+	ret0, _ := ret[0].(*models.WorkspaceKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkspaceKey indicates an expected call of CreateWorkspaceKey.
+func (mr *MockUrlUsecaseMockRecorder) CreateWorkspaceKey(ctx, workspace, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspaceKey", reflect.TypeOf((*MockUrlUsecase)(nil).CreateWorkspaceKey), ctx, workspace, key)
+}
+
+// DeleteLink mocks base method.
+func (m *MockUrlUsecase) DeleteLink(ctx context.Context, shortUrl, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLink", ctx, shortUrl, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLink indicates an expected call of DeleteLink.
+func (mr *MockUrlUsecaseMockRecorder) DeleteLink(ctx, shortUrl, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLink", reflect.TypeOf((*MockUrlUsecase)(nil).DeleteLink), ctx, shortUrl, owner)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockUrlUsecase) DeleteWebhook(ctx context.Context, id int64, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockUrlUsecaseMockRecorder) DeleteWebhook(ctx, id, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockUrlUsecase)(nil).DeleteWebhook), ctx, id, owner)
+}
+
+// DeleteWorkspaceKey mocks base method.
+func (m *MockUrlUsecase) DeleteWorkspaceKey(ctx context.Context, workspace string, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspaceKey", ctx, workspace, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspaceKey indicates an expected call of DeleteWorkspaceKey.
+func (mr *MockUrlUsecaseMockRecorder) DeleteWorkspaceKey(ctx, workspace, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspaceKey", reflect.TypeOf((*MockUrlUsecase)(nil).DeleteWorkspaceKey), ctx, workspace, id)
+}
+
+// Export mocks base method.
+func (m *MockUrlUsecase) Export(ctx context.Context, w io.Writer, opts *models.ExportOptions) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, w, opts)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockUrlUsecaseMockRecorder) Export(ctx, w, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockUrlUsecase)(nil).Export), ctx, w, opts)
+}
+
+// GetLink mocks base method.
+func (m *MockUrlUsecase) GetLink(ctx context.Context, shortUrl string) (*models.LinkData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLink", ctx, shortUrl)
+	ret0, _ := ret[0].(*models.LinkData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLink indicates an expected call of GetLink.
+func (mr *MockUrlUsecaseMockRecorder) GetLink(ctx, shortUrl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLink", reflect.TypeOf((*MockUrlUsecase)(nil).GetLink), ctx, shortUrl)
+}
+
 // GetOriginalUrl mocks base method.
-func (m *MockUrlUsecase) GetOriginalUrl(ctx context.Context, shortUrl string) (string, error) {
+func (m *MockUrlUsecase) GetOriginalUrl(ctx context.Context, shortUrl string, visitor *models.VisitorData) (*models.Redirect, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOriginalUrl", ctx, shortUrl)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetOriginalUrl", ctx, shortUrl, visitor)
+	ret0, _ := ret[0].(*models.Redirect)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOriginalUrl indicates an expected call of GetOriginalUrl.
-func (mr *MockUrlUsecaseMockRecorder) GetOriginalUrl(ctx, shortUrl interface{}) *gomock.Call {
+func (mr *MockUrlUsecaseMockRecorder) GetOriginalUrl(ctx, shortUrl, visitor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOriginalUrl", reflect.TypeOf((*MockUrlUsecase)(nil).GetOriginalUrl), ctx, shortUrl, visitor)
+}
+
+// GetPreview mocks base method.
+func (m *MockUrlUsecase) GetPreview(ctx context.Context, shortUrl string) (*models.PreviewData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreview", ctx, shortUrl)
+	ret0, _ := ret[0].(*models.PreviewData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreview indicates an expected call of GetPreview.
+func (mr *MockUrlUsecaseMockRecorder) GetPreview(ctx, shortUrl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreview", reflect.TypeOf((*MockUrlUsecase)(nil).GetPreview), ctx, shortUrl)
+}
+
+// GetQrCode mocks base method.
+func (m *MockUrlUsecase) GetQrCode(ctx context.Context, shortUrl string, opts *models.QrOptions) (*models.QrCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQrCode", ctx, shortUrl, opts)
+	ret0, _ := ret[0].(*models.QrCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQrCode indicates an expected call of GetQrCode.
+func (mr *MockUrlUsecaseMockRecorder) GetQrCode(ctx, shortUrl, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQrCode", reflect.TypeOf((*MockUrlUsecase)(nil).GetQrCode), ctx, shortUrl, opts)
+}
+
+// GetStats mocks base method.
+func (m *MockUrlUsecase) GetStats(ctx context.Context, shortUrl string) (*models.LinkStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, shortUrl)
+	ret0, _ := ret[0].(*models.LinkStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockUrlUsecaseMockRecorder) GetStats(ctx, shortUrl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockUrlUsecase)(nil).GetStats), ctx, shortUrl)
+}
+
+// GetUsage mocks base method.
+func (m *MockUrlUsecase) GetUsage(ctx context.Context, owner string) (*models.UsageReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", ctx, owner)
+	ret0, _ := ret[0].(*models.UsageReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockUrlUsecaseMockRecorder) GetUsage(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockUrlUsecase)(nil).GetUsage), ctx, owner)
+}
+
+// GetWorkspace mocks base method.
+func (m *MockUrlUsecase) GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspace", ctx, slug)
+	ret0, _ := ret[0].(*models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspace indicates an expected call of GetWorkspace.
+func (mr *MockUrlUsecaseMockRecorder) GetWorkspace(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspace", reflect.TypeOf((*MockUrlUsecase)(nil).GetWorkspace), ctx, slug)
+}
+
+// Import mocks base method.
+func (m *MockUrlUsecase) Import(ctx context.Context, r io.Reader, opts *models.ImportOptions, onError func(*models.ImportError)) (*models.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, r, opts, onError)
+	ret0, _ := ret[0].(*models.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockUrlUsecaseMockRecorder) Import(ctx, r, opts, onError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockUrlUsecase)(nil).Import), ctx, r, opts, onError)
+}
+
+// ListAudit mocks base method.
+func (m *MockUrlUsecase) ListAudit(ctx context.Context, filter *models.AuditFilter) (*models.AuditList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAudit", ctx, filter)
+	ret0, _ := ret[0].(*models.AuditList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAudit indicates an expected call of ListAudit.
+func (mr *MockUrlUsecaseMockRecorder) ListAudit(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAudit", reflect.TypeOf((*MockUrlUsecase)(nil).ListAudit), ctx, filter)
+}
+
+// ListDeadLetters mocks base method.
+func (m *MockUrlUsecase) ListDeadLetters(ctx context.Context, owner string, limit int) (*models.DeadLetterList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeadLetters", ctx, owner, limit)
+	ret0, _ := ret[0].(*models.DeadLetterList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeadLetters indicates an expected call of ListDeadLetters.
+func (mr *MockUrlUsecaseMockRecorder) ListDeadLetters(ctx, owner, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeadLetters", reflect.TypeOf((*MockUrlUsecase)(nil).ListDeadLetters), ctx, owner, limit)
+}
+
+// ListLinks mocks base method.
+func (m *MockUrlUsecase) ListLinks(ctx context.Context, filter *models.LinkFilter) (*models.LinkList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLinks", ctx, filter)
+	ret0, _ := ret[0].(*models.LinkList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLinks indicates an expected call of ListLinks.
+func (mr *MockUrlUsecaseMockRecorder) ListLinks(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLinks", reflect.TypeOf((*MockUrlUsecase)(nil).ListLinks), ctx, filter)
+}
+
+// ListMembers mocks base method.
+func (m *MockUrlUsecase) ListMembers(ctx context.Context, workspace string) (*models.MemberList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, workspace)
+	ret0, _ := ret[0].(*models.MemberList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockUrlUsecaseMockRecorder) ListMembers(ctx, workspace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockUrlUsecase)(nil).ListMembers), ctx, workspace)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockUrlUsecase) ListWebhooks(ctx context.Context, owner string) (*models.WebhookList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, owner)
+	ret0, _ := ret[0].(*models.WebhookList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockUrlUsecaseMockRecorder) ListWebhooks(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockUrlUsecase)(nil).ListWebhooks), ctx, owner)
+}
+
+// ListWorkspaceKeys mocks base method.
+func (m *MockUrlUsecase) ListWorkspaceKeys(ctx context.Context, workspace string) (*models.WorkspaceKeyList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkspaceKeys", ctx, workspace)
+	ret0, _ := ret[0].(*models.WorkspaceKeyList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkspaceKeys indicates an expected call of ListWorkspaceKeys.
+func (mr *MockUrlUsecaseMockRecorder) ListWorkspaceKeys(ctx, workspace interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOriginalUrl", reflect.TypeOf((*MockUrlUsecase)(nil).GetOriginalUrl), ctx, shortUrl)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkspaceKeys", reflect.TypeOf((*MockUrlUsecase)(nil).ListWorkspaceKeys), ctx, workspace)
+}
+
+// ListWorkspaces mocks base method.
+func (m *MockUrlUsecase) ListWorkspaces(ctx context.Context) (*models.WorkspaceList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkspaces", ctx)
+	ret0, _ := ret[0].(*models.WorkspaceList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkspaces indicates an expected call of ListWorkspaces.
+func (mr *MockUrlUsecaseMockRecorder) ListWorkspaces(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkspaces", reflect.TypeOf((*MockUrlUsecase)(nil).ListWorkspaces), ctx)
+}
+
+// RemoveMember mocks base method.
+func (m *MockUrlUsecase) RemoveMember(ctx context.Context, workspace, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, workspace, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockUrlUsecaseMockRecorder) RemoveMember(ctx, workspace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockUrlUsecase)(nil).RemoveMember), ctx, workspace, name)
+}
+
+// RestoreLink mocks base method.
+func (m *MockUrlUsecase) RestoreLink(ctx context.Context, shortUrl, owner string) (*models.LinkData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreLink", ctx, shortUrl, owner)
+	ret0, _ := ret[0].(*models.LinkData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreLink indicates an expected call of RestoreLink.
+func (mr *MockUrlUsecaseMockRecorder) RestoreLink(ctx, shortUrl, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreLink", reflect.TypeOf((*MockUrlUsecase)(nil).RestoreLink), ctx, shortUrl, owner)
+}
+
+// RetryDeadLetter mocks base method.
+func (m *MockUrlUsecase) RetryDeadLetter(ctx context.Context, id int64, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryDeadLetter", ctx, id, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryDeadLetter indicates an expected call of RetryDeadLetter.
+func (mr *MockUrlUsecaseMockRecorder) RetryDeadLetter(ctx, id, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryDeadLetter", reflect.TypeOf((*MockUrlUsecase)(nil).RetryDeadLetter), ctx, id, owner)
 }
 
 // ShortenUrl mocks base method.
-func (m *MockUrlUsecase) ShortenUrl(ctx context.Context, originalUrl string) (string, error) {
+func (m *MockUrlUsecase) ShortenUrl(ctx context.Context, data *models.OrigUrlData) (*models.ShortUrlData, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ShortenUrl", ctx, originalUrl)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "ShortenUrl", ctx, data)
+	ret0, _ := ret[0].(*models.ShortUrlData)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ShortenUrl indicates an expected call of ShortenUrl.
-func (mr *MockUrlUsecaseMockRecorder) ShortenUrl(ctx, originalUrl interface{}) *gomock.Call {
+func (mr *MockUrlUsecaseMockRecorder) ShortenUrl(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShortenUrl", reflect.TypeOf((*MockUrlUsecase)(nil).ShortenUrl), ctx, data)
+}
+
+// UpdateMemberRole mocks base method.
+func (m *MockUrlUsecase) UpdateMemberRole(ctx context.Context, workspace, name, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMemberRole", ctx, workspace, name, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMemberRole indicates an expected call of UpdateMemberRole.
+func (mr *MockUrlUsecaseMockRecorder) UpdateMemberRole(ctx, workspace, name, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRole", reflect.TypeOf((*MockUrlUsecase)(nil).UpdateMemberRole), ctx, workspace, name, role)
+}
+
+// UpdateMetadata mocks base method.
+func (m *MockUrlUsecase) UpdateMetadata(ctx context.Context, shortUrl string, metadata *models.LinkMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMetadata", ctx, shortUrl, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMetadata indicates an expected call of UpdateMetadata.
+func (mr *MockUrlUsecaseMockRecorder) UpdateMetadata(ctx, shortUrl, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadata", reflect.TypeOf((*MockUrlUsecase)(nil).UpdateMetadata), ctx, shortUrl, metadata)
+}
+
+// UpdateRules mocks base method.
+func (m *MockUrlUsecase) UpdateRules(ctx context.Context, shortUrl string, rules []models.TargetingRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRules", ctx, shortUrl, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRules indicates an expected call of UpdateRules.
+func (mr *MockUrlUsecaseMockRecorder) UpdateRules(ctx, shortUrl, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRules", reflect.TypeOf((*MockUrlUsecase)(nil).UpdateRules), ctx, shortUrl, rules)
+}
+
+// UpdateWindow mocks base method.
+func (m *MockUrlUsecase) UpdateWindow(ctx context.Context, shortUrl string, window *models.ActivationWindow) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWindow", ctx, shortUrl, window)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWindow indicates an expected call of UpdateWindow.
+func (mr *MockUrlUsecaseMockRecorder) UpdateWindow(ctx, shortUrl, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWindow", reflect.TypeOf((*MockUrlUsecase)(nil).UpdateWindow), ctx, shortUrl, window)
+}
+
+// VerifyAudit mocks base method.
+func (m *MockUrlUsecase) VerifyAudit(ctx context.Context) (*models.AuditVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAudit", ctx)
+	ret0, _ := ret[0].(*models.AuditVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAudit indicates an expected call of VerifyAudit.
+func (mr *MockUrlUsecaseMockRecorder) VerifyAudit(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShortenUrl", reflect.TypeOf((*MockUrlUsecase)(nil).ShortenUrl), ctx, originalUrl)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAudit", reflect.TypeOf((*MockUrlUsecase)(nil).VerifyAudit), ctx)
 }
@@ -0,0 +1,33 @@
+package delivery
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var (
+	previewTemplate      = template.Must(template.ParseFS(templateFS, "templates/preview.html"))
+	interstitialTemplate = template.Must(template.ParseFS(templateFS, "templates/interstitial.html"))
+)
+
+// renderPage executes tmpl into a buffer first, so a failing template results
+// in a clean 500 instead of a half-written page.
+func renderPage(w http.ResponseWriter, tmpl *template.Template, data any) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		utils.ProcessInternalServerError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
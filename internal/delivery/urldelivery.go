@@ -3,7 +3,11 @@ package delivery
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/AlexNov03/UrlShortener/internal/models"
 	"github.com/AlexNov03/UrlShortener/utils"
@@ -12,10 +16,45 @@ import (
 )
 
 type UrlUsecase interface {
-	ShortenUrl(ctx context.Context, originalUrl string) (string, error)
-	GetOriginalUrl(ctx context.Context, shortUrl string) (string, error)
+	ShortenUrl(ctx context.Context, data *models.OrigUrlData) (*models.ShortUrlData, error)
+	GetOriginalUrl(ctx context.Context, shortUrl string, visitor *models.VisitorData) (*models.Redirect, error)
+	GetPreview(ctx context.Context, shortUrl string) (*models.PreviewData, error)
+	GetLink(ctx context.Context, shortUrl string) (*models.LinkData, error)
+	UpdateWindow(ctx context.Context, shortUrl string, window *models.ActivationWindow) error
+	UpdateRules(ctx context.Context, shortUrl string, rules []models.TargetingRule) error
+	GetStats(ctx context.Context, shortUrl string) (*models.LinkStats, error)
+	GetQrCode(ctx context.Context, shortUrl string, opts *models.QrOptions) (*models.QrCode, error)
+	UpdateMetadata(ctx context.Context, shortUrl string, metadata *models.LinkMetadata) error
+	DeleteLink(ctx context.Context, shortUrl string, owner string) error
+	RestoreLink(ctx context.Context, shortUrl string, owner string) (*models.LinkData, error)
+	ListLinks(ctx context.Context, filter *models.LinkFilter) (*models.LinkList, error)
+	Import(ctx context.Context, r io.Reader, opts *models.ImportOptions,
+		onError func(*models.ImportError)) (*models.ImportReport, error)
+	Export(ctx context.Context, w io.Writer, opts *models.ExportOptions) (int, error)
+	CreateWebhook(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context, owner string) (*models.WebhookList, error)
+	DeleteWebhook(ctx context.Context, id int64, owner string) error
+	ListDeadLetters(ctx context.Context, owner string, limit int) (*models.DeadLetterList, error)
+	RetryDeadLetter(ctx context.Context, id int64, owner string) error
+	ListAudit(ctx context.Context, filter *models.AuditFilter) (*models.AuditList, error)
+	VerifyAudit(ctx context.Context) (*models.AuditVerification, error)
+	GetUsage(ctx context.Context, owner string) (*models.UsageReport, error)
+	CreateWorkspace(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error)
+	ListWorkspaces(ctx context.Context) (*models.WorkspaceList, error)
+	GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error)
+	AddMember(ctx context.Context, workspace string, member *models.Member) (*models.Member, error)
+	ListMembers(ctx context.Context, workspace string) (*models.MemberList, error)
+	UpdateMemberRole(ctx context.Context, workspace, name, role string) error
+	RemoveMember(ctx context.Context, workspace, name string) error
+	CreateWorkspaceKey(ctx context.Context, workspace string, key *models.WorkspaceKey) (*models.WorkspaceKey, error)
+	ListWorkspaceKeys(ctx context.Context, workspace string) (*models.WorkspaceKeyList, error)
+	DeleteWorkspaceKey(ctx context.Context, workspace string, id int64) error
 }
 
+// visitorCookie holds the visitor id used for sticky assignment of split
+// destinations.
+const visitorCookie = "visitor_id"
+
 type UrlDelivery struct {
 	UC        UrlUsecase
 	validator *validator.Validate
@@ -39,20 +78,20 @@ func (ud *UrlDelivery) ShortenUrl(w http.ResponseWriter, r *http.Request) {
 
 	err = ud.validator.Struct(inputData)
 	if err != nil {
-		utils.ProcessBadRequestError(w, "incorrect fields in input data")
+		utils.ProcessValidationError(w, err)
 		return
 	}
 
 	ctx := r.Context()
 
-	shortenedUrl, err := ud.UC.ShortenUrl(ctx, inputData.OriginalUrl)
+	shortenedUrl, err := ud.UC.ShortenUrl(ctx, inputData)
 	if err != nil {
 		utils.ProcessError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(&models.ShortUrlData{ShortUrl: shortenedUrl})
+	json.NewEncoder(w).Encode(shortenedUrl)
 
 }
 
@@ -62,12 +101,319 @@ func (ud *UrlDelivery) GetOriginalUrl(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	origUrl, err := ud.UC.GetOriginalUrl(ctx, shortUrl)
+	visitor := &models.VisitorData{
+		UserAgent:      r.UserAgent(),
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+		Query:          r.URL.Query(),
+	}
+	if cookie, err := r.Cookie(visitorCookie); err == nil {
+		visitor.VisitorID = cookie.Value
+	}
+
+	redirect, err := ud.UC.GetOriginalUrl(ctx, shortUrl, visitor)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	if redirect.Interstitial {
+		renderPage(w, interstitialTemplate, redirect)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&models.OrigUrlData{OriginalUrl: redirect.Url})
+}
+
+func (ud *UrlDelivery) GetPreview(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shortUrl := vars["shortened_url"]
+
+	ctx := r.Context()
+
+	preview, err := ud.UC.GetPreview(ctx, shortUrl)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	renderPage(w, previewTemplate, preview)
+}
+
+func (ud *UrlDelivery) GetLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shortUrl := vars["shortened_url"]
+
+	ctx := r.Context()
+
+	link, err := ud.UC.GetLink(ctx, shortUrl)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(link)
+}
+
+func (ud *UrlDelivery) UpdateWindow(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+	shortUrl := vars["shortened_url"]
+
+	inputData := &models.ActivationWindow{}
+
+	err := json.NewDecoder(r.Body).Decode(inputData)
+	if err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	err = ud.UC.UpdateWindow(ctx, shortUrl, inputData)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ud *UrlDelivery) UpdateRules(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+	shortUrl := vars["shortened_url"]
+
+	inputData := &models.RulesData{}
+
+	err := json.NewDecoder(r.Body).Decode(inputData)
+	if err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	err = ud.UC.UpdateRules(ctx, shortUrl, inputData.Rules)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ud *UrlDelivery) GetStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shortUrl := vars["shortened_url"]
+
+	ctx := r.Context()
+
+	stats, err := ud.UC.GetStats(ctx, shortUrl)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(stats)
+}
+
+func (ud *UrlDelivery) GetQrCode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shortUrl := vars["shortened_url"]
+
+	query := r.URL.Query()
+
+	inputData := &models.QrOptions{
+		Format:     query.Get("format"),
+		Level:      query.Get("level"),
+		Foreground: query.Get("fg"),
+		Background: query.Get("bg"),
+	}
+
+	if size := query.Get("size"); size != "" {
+		value, err := strconv.Atoi(size)
+		if err != nil {
+			utils.ProcessBadRequestError(w, "incorrect input data")
+			return
+		}
+		inputData.Size = value
+	}
+
+	if margin := query.Get("margin"); margin != "" {
+		value, err := strconv.Atoi(margin)
+		if err != nil {
+			utils.ProcessBadRequestError(w, "incorrect input data")
+			return
+		}
+		inputData.Margin = &value
+	}
+
+	err := ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	code, err := ud.UC.GetQrCode(ctx, shortUrl, inputData)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", code.ContentType)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	w.Write(code.Data)
+}
+
+func (ud *UrlDelivery) UpdateMetadata(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+	shortUrl := vars["shortened_url"]
+
+	inputData := &models.LinkMetadata{}
+
+	err := json.NewDecoder(r.Body).Decode(inputData)
+	if err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	err = ud.UC.UpdateMetadata(ctx, shortUrl, inputData)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ud *UrlDelivery) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shortUrl := vars["shortened_url"]
+
+	ctx := r.Context()
+
+	err := ud.UC.DeleteLink(ctx, shortUrl, principalOwner(ctx))
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ud *UrlDelivery) RestoreLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shortUrl := vars["shortened_url"]
+
+	ctx := r.Context()
+
+	link, err := ud.UC.RestoreLink(ctx, shortUrl, principalOwner(ctx))
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(link)
+}
+
+// parseTime parses an optional RFC 3339 timestamp of the query string.
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	res, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// parseLinkFilter reads the link filters shared by the listing and the export
+// from the query string.
+func parseLinkFilter(query url.Values) (*models.LinkFilter, error) {
+	res := &models.LinkFilter{
+		Owner:  query.Get("owner"),
+		Domain: query.Get("domain"),
+		Host:   query.Get("host"),
+		Tag:    query.Get("tag"),
+		Title:  query.Get("title"),
+		Search: query.Get("q"),
+	}
+
+	var err error
+	if res.CreatedFrom, err = parseTime(query.Get("created_from")); err != nil {
+		return nil, err
+	}
+	if res.CreatedTo, err = parseTime(query.Get("created_to")); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (ud *UrlDelivery) ListLinks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	inputData, err := parseLinkFilter(query)
+	if err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+	inputData.Sort = query.Get("sort")
+	inputData.Order = query.Get("order")
+	inputData.Cursor = query.Get("cursor")
+
+	if limit := query.Get("limit"); limit != "" {
+		if inputData.Limit, err = strconv.Atoi(limit); err != nil {
+			utils.ProcessBadRequestError(w, "incorrect input data")
+			return
+		}
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	links, err := ud.UC.ListLinks(ctx, inputData)
 	if err != nil {
 		utils.ProcessError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(&models.OrigUrlData{OriginalUrl: origUrl})
+	json.NewEncoder(w).Encode(links)
 }
@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/AlexNov03/UrlShortener/internal/auth"
 	"github.com/AlexNov03/UrlShortener/internal/delivery/mocks"
 	"github.com/AlexNov03/UrlShortener/internal/models"
 	"github.com/AlexNov03/UrlShortener/utils"
-	"github.com/go-playground/validator/v10"
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +30,7 @@ func TestShortenUrlOK(t *testing.T) {
 	originalUrl := "http://ya.ru"
 	shortUrl := "http://localhost:8080/Abc_def_qA"
 
-	validator := validator.New(validator.WithRequiredStructEnabled())
+	validator := utils.NewValidator()
 
 	ud := NewUrlDelivery(mockedUc, validator)
 
@@ -42,7 +44,7 @@ func TestShortenUrlOK(t *testing.T) {
 		{
 			Name: "successful getting shorten url",
 			Setup: func(ctx context.Context) {
-				mockedUc.EXPECT().ShortenUrl(ctx, originalUrl).Return(shortUrl, nil)
+				mockedUc.EXPECT().ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: originalUrl}).Return(&models.ShortUrlData{ShortUrl: shortUrl}, nil)
 			},
 			ReqBody: models.OrigUrlData{
 				OriginalUrl: originalUrl,
@@ -52,6 +54,20 @@ func TestShortenUrlOK(t *testing.T) {
 			},
 			ExpectedRespStatusCode: http.StatusOK,
 		},
+		{
+			Name: "successful getting one-time shorten url",
+			Setup: func(ctx context.Context) {
+				mockedUc.EXPECT().ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: originalUrl, MaxClicks: 1}).Return(&models.ShortUrlData{ShortUrl: shortUrl}, nil)
+			},
+			ReqBody: models.OrigUrlData{
+				OriginalUrl: originalUrl,
+				MaxClicks:   1,
+			},
+			ExpectedRespBody: models.ShortUrlData{
+				ShortUrl: shortUrl,
+			},
+			ExpectedRespStatusCode: http.StatusOK,
+		},
 	}
 
 	for _, tt := range tests {
@@ -92,7 +108,7 @@ func TestShortenUrlFail(t *testing.T) {
 
 	originalUrl := "http://ya.ru"
 
-	validator := validator.New(validator.WithRequiredStructEnabled())
+	validator := utils.NewValidator()
 
 	ud := NewUrlDelivery(mockedUc, validator)
 
@@ -100,31 +116,60 @@ func TestShortenUrlFail(t *testing.T) {
 		Name                   string
 		Setup                  func(context.Context)
 		ReqBody                string
-		ExpectedRespBody       utils.RestError
+		ExpectedRespBody       utils.Problem
 		ExpectedRespStatusCode int
 	}{
 		{
 			Name:    "test for incorrect field names",
 			Setup:   func(ctx context.Context) {},
 			ReqBody: fmt.Sprintf(`{"original_ur":"%s"}`, originalUrl),
-			ExpectedRespBody: utils.RestError{
-				Error: "incorrect fields in input data",
+			ExpectedRespBody: utils.Problem{
+				Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "incorrect fields in input data", Code: "validation_failed",
+				Errors: []utils.FieldError{{Field: "original_url", Code: "required", Message: "is required"}},
+			},
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:    "test for negative max_clicks",
+			Setup:   func(ctx context.Context) {},
+			ReqBody: fmt.Sprintf(`{"original_url":"%s","max_clicks":-1}`, originalUrl),
+			ExpectedRespBody: utils.Problem{
+				Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "incorrect fields in input data", Code: "validation_failed",
+				Errors: []utils.FieldError{{Field: "max_clicks", Code: "min", Message: "must be at least 1"}},
 			},
 			ExpectedRespStatusCode: http.StatusBadRequest,
 		},
 		{
 			Name: "test for incorrect original_url format",
 			Setup: func(ctx context.Context) {
-				mockedUc.EXPECT().ShortenUrl(ctx, "http:/localhost/ya.ru").Return("",
+				mockedUc.EXPECT().ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http:/localhost/ya.ru"}).Return(nil,
 					utils.NewInternalError(http.StatusBadRequest, "original url does not fits the url format"),
 				)
 			},
 			ReqBody: fmt.Sprintf(`{"original_url":"http:/localhost/ya.ru"}`),
-			ExpectedRespBody: utils.RestError{
-				Error: "original url does not fits the url format",
+			ExpectedRespBody: utils.Problem{
+				Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "original url does not fits the url format", Code: "bad_request",
 			},
 			ExpectedRespStatusCode: http.StatusBadRequest,
 		},
+		{
+			Name: "test for disallowed destination",
+			Setup: func(ctx context.Context) {
+				mockedUc.EXPECT().ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "javascript:alert(1)"}).Return(nil,
+					&utils.InternalError{Code: http.StatusUnprocessableEntity, Message: "destination url is not allowed",
+						Reason: "scheme_not_allowed"},
+				)
+			},
+			ReqBody: `{"original_url":"javascript:alert(1)"}`,
+			ExpectedRespBody: utils.Problem{
+				Type: "about:blank", Title: "Unprocessable Entity", Status: http.StatusUnprocessableEntity,
+				Detail: "destination url is not allowed", Code: "unprocessable", Reason: "scheme_not_allowed",
+			},
+			ExpectedRespStatusCode: http.StatusUnprocessableEntity,
+		},
 	}
 
 	for _, tt := range tests {
@@ -144,7 +189,7 @@ func TestShortenUrlFail(t *testing.T) {
 
 			assert.Equal(t, tt.ExpectedRespStatusCode, resp.StatusCode)
 
-			resultRespBody := utils.RestError{}
+			resultRespBody := utils.Problem{}
 
 			json.NewDecoder(resp.Body).Decode(&resultRespBody)
 
@@ -164,7 +209,7 @@ func TestGetOriginalUrlOK(t *testing.T) {
 	shortUrl := "/Abc_def_qA"
 	shortUrlSuffix := "Abc_def_qA"
 
-	validator := validator.New(validator.WithRequiredStructEnabled())
+	validator := utils.NewValidator()
 
 	ud := NewUrlDelivery(mockedUc, validator)
 
@@ -181,7 +226,8 @@ func TestGetOriginalUrlOK(t *testing.T) {
 		{
 			Name: "successful getting original url",
 			Setup: func(ctx context.Context) {
-				mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), shortUrlSuffix).Return(originalUrl, nil)
+				mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), shortUrlSuffix, &models.VisitorData{
+					UserAgent: "Go-http-client/1.1", AcceptLanguage: "ru-RU", Query: url.Values{}}).Return(&models.Redirect{Url: originalUrl}, nil)
 			},
 			ReqBody: models.ShortUrlData{
 				ShortUrl: shortUrl,
@@ -197,6 +243,8 @@ func TestGetOriginalUrlOK(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 
 			r := httptest.NewRequest(http.MethodGet, shortUrl, nil)
+			r.Header.Set("User-Agent", "Go-http-client/1.1")
+			r.Header.Set("Accept-Language", "ru-RU")
 			w := httptest.NewRecorder()
 
 			tt.Setup(r.Context())
@@ -227,7 +275,7 @@ func TestGetOriginalUrlFail(t *testing.T) {
 	shortUrl := "/Abc_def_qA"
 	shortUrlSuffix := "Abc_def_qA"
 
-	validator := validator.New(validator.WithRequiredStructEnabled())
+	validator := utils.NewValidator()
 
 	ud := NewUrlDelivery(mockedUc, validator)
 
@@ -238,23 +286,39 @@ func TestGetOriginalUrlFail(t *testing.T) {
 		Name                   string
 		Setup                  func(context.Context)
 		ReqBody                models.ShortUrlData
-		ExpectedRespBody       utils.RestError
+		ExpectedRespBody       utils.Problem
 		ExpectedRespStatusCode int
 	}{
 		{
 			Name: "error while getting original url",
 			Setup: func(ctx context.Context) {
-				mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), shortUrlSuffix).Return("",
+				mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), shortUrlSuffix, gomock.Any()).Return(nil,
 					utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl"))
 			},
 			ReqBody: models.ShortUrlData{
 				ShortUrl: shortUrl,
 			},
-			ExpectedRespBody: utils.RestError{
-				Error: "no originalUrl match this shortUrl",
+			ExpectedRespBody: utils.Problem{
+				Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound,
+				Detail: "no originalUrl match this shortUrl", Code: "not_found",
 			},
 			ExpectedRespStatusCode: http.StatusNotFound,
 		},
+		{
+			Name: "error while getting exhausted url",
+			Setup: func(ctx context.Context) {
+				mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), shortUrlSuffix, gomock.Any()).Return(nil,
+					utils.NewInternalError(http.StatusGone, "this shortUrl has reached its click limit"))
+			},
+			ReqBody: models.ShortUrlData{
+				ShortUrl: shortUrl,
+			},
+			ExpectedRespBody: utils.Problem{
+				Type: "about:blank", Title: "Gone", Status: http.StatusGone,
+				Detail: "this shortUrl has reached its click limit", Code: "gone",
+			},
+			ExpectedRespStatusCode: http.StatusGone,
+		},
 	}
 
 	for _, tt := range tests {
@@ -272,7 +336,7 @@ func TestGetOriginalUrlFail(t *testing.T) {
 
 			assert.Equal(t, tt.ExpectedRespStatusCode, resp.StatusCode)
 
-			resultRespBody := utils.RestError{}
+			resultRespBody := utils.Problem{}
 
 			json.NewDecoder(resp.Body).Decode(&resultRespBody)
 
@@ -280,3 +344,812 @@ func TestGetOriginalUrlFail(t *testing.T) {
 		})
 	}
 }
+
+func TestGetLink(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/links/{shortened_url}", ud.GetLink).Methods(http.MethodGet)
+
+	notAfter := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		ExpectedRespBody       string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful getting link",
+			Setup: func() {
+				mockedUc.EXPECT().GetLink(gomock.Any(), "Abc_def_qA").Return(&models.LinkData{
+					ShortUrl: "http://localhost:8080/Abc_def_qA", OriginalUrl: "http://ya.ru",
+					ActivationWindow: models.ActivationWindow{NotAfter: &notAfter}, Interstitial: true,
+					CreatedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}, nil)
+			},
+			ExpectedRespBody: `{"shortened_url":"http://localhost:8080/Abc_def_qA","original_url":"http://ya.ru",` +
+				`"not_after":"2025-04-01T00:00:00Z","interstitial":true,"created_at":"2025-03-01T12:00:00Z","clicks":0}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "error while getting link",
+			Setup: func() {
+				mockedUc.EXPECT().GetLink(gomock.Any(), "Abc_def_qA").Return(nil,
+					utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl"))
+			},
+			ExpectedRespBody: `{"type":"about:blank","title":"Not Found","status":404,` +
+				`"detail":"no originalUrl match this shortUrl","code":"not_found"}`,
+			ExpectedRespStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, "/api/links/Abc_def_qA", nil)
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			router.ServeHTTP(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, resp.StatusCode)
+			assert.JSONEq(t, tt.ExpectedRespBody, w.Body.String())
+		})
+	}
+}
+
+func TestUpdateWindow(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/links/{shortened_url}/window", ud.UpdateWindow).Methods(http.MethodPut)
+
+	notBefore := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		ReqBody                string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful updating window",
+			Setup: func() {
+				mockedUc.EXPECT().UpdateWindow(gomock.Any(), "Abc_def_qA", &models.ActivationWindow{
+					NotBefore: &notBefore, FallbackUrl: "http://ya.ru/soon"}).Return(nil)
+			},
+			ReqBody:                `{"not_before":"2025-03-01T00:00:00Z","fallback_url":"http://ya.ru/soon"}`,
+			ExpectedRespStatusCode: http.StatusNoContent,
+		},
+		{
+			Name:                   "test for incorrect fallback_url",
+			Setup:                  func() {},
+			ReqBody:                `{"fallback_url":"not a url"}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for incorrect timestamp",
+			Setup:                  func() {},
+			ReqBody:                `{"not_before":"tomorrow"}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodPut, "/api/links/Abc_def_qA/window", bytes.NewReader([]byte(tt.ReqBody)))
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			router.ServeHTTP(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestUpdateRules(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/links/{shortened_url}/rules", ud.UpdateRules).Methods(http.MethodPut)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		ReqBody                string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful updating rules",
+			Setup: func() {
+				mockedUc.EXPECT().UpdateRules(gomock.Any(), "Abc_def_qA", []models.TargetingRule{
+					{OS: "ios", TargetUrl: "https://apps.apple.com/app"},
+					{Language: "ru", Query: map[string]string{"promo": ""}, TargetUrl: "https://ya.ru"},
+				}).Return(nil)
+			},
+			ReqBody: `{"rules":[{"os":"ios","target_url":"https://apps.apple.com/app"},` +
+				`{"language":"ru","query":{"promo":""},"target_url":"https://ya.ru"}]}`,
+			ExpectedRespStatusCode: http.StatusNoContent,
+		},
+		{
+			Name:                   "test for unknown os",
+			Setup:                  func() {},
+			ReqBody:                `{"rules":[{"os":"symbian","target_url":"https://ya.ru"}]}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for missing target_url",
+			Setup:                  func() {},
+			ReqBody:                `{"rules":[{"os":"ios"}]}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name: "error from usecase",
+			Setup: func() {
+				mockedUc.EXPECT().UpdateRules(gomock.Any(), "Abc_def_qA", []models.TargetingRule{
+					{TargetUrl: "https://ya.ru"}}).Return(
+					utils.NewInternalError(http.StatusBadRequest, "targeting rule must have at least one condition"))
+			},
+			ReqBody:                `{"rules":[{"target_url":"https://ya.ru"}]}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodPut, "/api/links/Abc_def_qA/rules", bytes.NewReader([]byte(tt.ReqBody)))
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			router.ServeHTTP(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestGetOriginalUrlVisitorCookie(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/{shortened_url}", ud.GetOriginalUrl).Methods(http.MethodGet)
+
+	mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), "Abc_def_qA", &models.VisitorData{
+		Query: url.Values{"ref": {"x"}}, VisitorID: "visitor-1"}).Return(&models.Redirect{Url: "http://ya.ru/b"}, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/Abc_def_qA?ref=x", nil)
+	r.Header.Del("User-Agent")
+	r.AddCookie(&http.Cookie{Name: "visitor_id", Value: "visitor-1"})
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"original_url":"http://ya.ru/b"}`, w.Body.String())
+}
+
+func TestGetStats(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/links/{shortened_url}/stats", ud.GetStats).Methods(http.MethodGet)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		ExpectedRespBody       string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful getting stats",
+			Setup: func() {
+				mockedUc.EXPECT().GetStats(gomock.Any(), "Abc_def_qA").Return(&models.LinkStats{
+					ShortUrl: "http://localhost:8080/Abc_def_qA", Clicks: 3,
+					Variants: []models.VariantStats{{Url: "http://ya.ru/a", Weight: 1, Clicks: 1},
+						{Url: "http://ya.ru/b", Weight: 1, Clicks: 2}}}, nil)
+			},
+			ExpectedRespBody: `{"shortened_url":"http://localhost:8080/Abc_def_qA","clicks":3,"variants":[` +
+				`{"url":"http://ya.ru/a","weight":1,"clicks":1},{"url":"http://ya.ru/b","weight":1,"clicks":2}]}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "error while getting stats",
+			Setup: func() {
+				mockedUc.EXPECT().GetStats(gomock.Any(), "Abc_def_qA").Return(nil,
+					utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl"))
+			},
+			ExpectedRespBody: `{"type":"about:blank","title":"Not Found","status":404,` +
+				`"detail":"no originalUrl match this shortUrl","code":"not_found"}`,
+			ExpectedRespStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, "/api/links/Abc_def_qA/stats", nil)
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, w.Code)
+			assert.JSONEq(t, tt.ExpectedRespBody, w.Body.String())
+		})
+	}
+}
+
+func TestGetQrCode(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/links/{shortened_url}/qr", ud.GetQrCode).Methods(http.MethodGet)
+
+	margin := 2
+
+	tests := []struct {
+		Name                    string
+		Query                   string
+		Setup                   func()
+		ExpectedRespStatusCode  int
+		ExpectedRespContentType string
+	}{
+		{
+			Name:  "successful getting svg qr code",
+			Query: "?format=svg&size=512&level=Q&margin=2&fg=%23112233&bg=ffffff",
+			Setup: func() {
+				mockedUc.EXPECT().GetQrCode(gomock.Any(), "Abc_def_qA", &models.QrOptions{Format: "svg", Size: 512,
+					Level: "Q", Margin: &margin, Foreground: "#112233", Background: "ffffff"}).Return(
+					&models.QrCode{ContentType: "image/svg+xml", Data: []byte("<svg/>")}, nil)
+			},
+			ExpectedRespStatusCode:  http.StatusOK,
+			ExpectedRespContentType: "image/svg+xml",
+		},
+		{
+			Name:                   "test for non-numeric size",
+			Query:                  "?size=big",
+			Setup:                  func() {},
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for non-numeric margin",
+			Query:                  "?margin=wide",
+			Setup:                  func() {},
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for unsupported format",
+			Query:                  "?format=gif",
+			Setup:                  func() {},
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for unsupported level",
+			Query:                  "?level=X",
+			Setup:                  func() {},
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:  "error while getting qr code",
+			Query: "",
+			Setup: func() {
+				mockedUc.EXPECT().GetQrCode(gomock.Any(), "Abc_def_qA", &models.QrOptions{}).Return(nil,
+					utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl"))
+			},
+			ExpectedRespStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, "/api/links/Abc_def_qA/qr"+tt.Query, nil)
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, w.Code)
+			if tt.ExpectedRespContentType != "" {
+				assert.Equal(t, tt.ExpectedRespContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, "<svg/>", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetOriginalUrlInterstitial(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/{shortened_url}", ud.GetOriginalUrl).Methods(http.MethodGet)
+
+	tests := []struct {
+		Name         string
+		Url          string
+		ExpectedHref string
+	}{
+		{
+			Name:         "http destination",
+			Url:          "http://ya.ru/?a=1&b=<2>",
+			ExpectedHref: `href="http://ya.ru/?a=1&amp;b=%3c2%3e"`,
+		},
+		{
+			Name:         "unsafe destination is not linked",
+			Url:          "javascript:alert(1)",
+			ExpectedHref: `href="#ZgotmplZ"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), "Abc_def_qA", gomock.Any()).Return(
+				&models.Redirect{Url: tt.Url, Interstitial: true, Delay: 7}, nil)
+
+			r := httptest.NewRequest(http.MethodGet, "/Abc_def_qA", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Body.String(), tt.ExpectedHref)
+			assert.Contains(t, w.Body.String(), `<span id="countdown">7</span>`)
+			assert.Contains(t, w.Body.String(), "var left =  7 ;")
+		})
+	}
+}
+
+func TestGetPreview(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/{shortened_url}+", ud.GetPreview).Methods(http.MethodGet)
+	router.HandleFunc("/{shortened_url}", ud.GetPreview).Methods(http.MethodGet).Queries("preview", "1")
+	router.HandleFunc("/{shortened_url}", ud.GetOriginalUrl).Methods(http.MethodGet)
+
+	preview := &models.PreviewData{ShortUrl: "http://localhost:8080/Abc_def_qA", OriginalUrl: "https://ya.ru/search?q=<b>",
+		Host: "ya.ru", CreatedAt: time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC), Clicks: 42}
+
+	tests := []struct {
+		Name                   string
+		Target                 string
+		Setup                  func()
+		ExpectedRespStatusCode int
+		ExpectedRespBody       []string
+	}{
+		{
+			Name:   "preview by plus suffix",
+			Target: "/Abc_def_qA+",
+			Setup: func() {
+				mockedUc.EXPECT().GetPreview(gomock.Any(), "Abc_def_qA").Return(preview, nil)
+			},
+			ExpectedRespStatusCode: http.StatusOK,
+			ExpectedRespBody: []string{`<p class="host">ya.ru</p>`, "https://ya.ru/search?q=&lt;b&gt;",
+				"2025-03-01 12:30 UTC", "<dd>42</dd>"},
+		},
+		{
+			Name:   "preview by query parameter",
+			Target: "/Abc_def_qA?preview=1",
+			Setup: func() {
+				mockedUc.EXPECT().GetPreview(gomock.Any(), "Abc_def_qA").Return(preview, nil)
+			},
+			ExpectedRespStatusCode: http.StatusOK,
+			ExpectedRespBody:       []string{`<p class="host">ya.ru</p>`},
+		},
+		{
+			Name:   "error while getting preview",
+			Target: "/Abc_def_qA+",
+			Setup: func() {
+				mockedUc.EXPECT().GetPreview(gomock.Any(), "Abc_def_qA").Return(nil,
+					utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl"))
+			},
+			ExpectedRespStatusCode: http.StatusNotFound,
+			ExpectedRespBody:       []string{`"detail":"no originalUrl match this shortUrl","code":"not_found"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, tt.Target, nil)
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, w.Code)
+			for _, expected := range tt.ExpectedRespBody {
+				assert.Contains(t, w.Body.String(), expected)
+			}
+		})
+	}
+}
+
+func TestUpdateMetadata(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/links/{shortened_url}/metadata", ud.UpdateMetadata).Methods(http.MethodPut)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		ReqBody                string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful updating metadata",
+			Setup: func() {
+				mockedUc.EXPECT().UpdateMetadata(gomock.Any(), "Abc_def_qA", &models.LinkMetadata{
+					Title: "Spring sale", Notes: "for the newsletter", Tags: []string{"promo", "spring"}}).Return(nil)
+			},
+			ReqBody:                `{"title":"Spring sale","notes":"for the newsletter","tags":["promo","spring"]}`,
+			ExpectedRespStatusCode: http.StatusNoContent,
+		},
+		{
+			Name:                   "test for incorrect json",
+			Setup:                  func() {},
+			ReqBody:                `{"title":`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for too long tag",
+			Setup:                  func() {},
+			ReqBody:                fmt.Sprintf(`{"tags":["%065d"]}`, 0),
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for empty tag",
+			Setup:                  func() {},
+			ReqBody:                `{"tags":[""]}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name: "test for missing link",
+			Setup: func() {
+				mockedUc.EXPECT().UpdateMetadata(gomock.Any(), "Abc_def_qA", &models.LinkMetadata{Title: "t"}).Return(
+					&utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+			},
+			ReqBody:                `{"title":"t"}`,
+			ExpectedRespStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodPut, "/api/links/Abc_def_qA/metadata", bytes.NewReader([]byte(tt.ReqBody)))
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			router.ServeHTTP(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestDeleteLink(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/links/{shortened_url}", ud.DeleteLink).Methods(http.MethodDelete)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		Principal              *auth.Principal
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful deleting link",
+			Setup: func() {
+				mockedUc.EXPECT().DeleteLink(gomock.Any(), "Abc_def_qA", "").Return(nil)
+			},
+			ExpectedRespStatusCode: http.StatusNoContent,
+		},
+		{
+			Name: "successful deleting link of the key owner",
+			Setup: func() {
+				mockedUc.EXPECT().DeleteLink(gomock.Any(), "Abc_def_qA", "team").Return(nil)
+			},
+			Principal:              &auth.Principal{KeyName: "ci", Owner: "team"},
+			ExpectedRespStatusCode: http.StatusNoContent,
+		},
+		{
+			Name: "test for missing link",
+			Setup: func() {
+				mockedUc.EXPECT().DeleteLink(gomock.Any(), "Abc_def_qA", "").Return(
+					&utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+			},
+			ExpectedRespStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodDelete, "/api/links/Abc_def_qA", nil)
+			if tt.Principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.Principal))
+			}
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			router.ServeHTTP(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestRestoreLink(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/links/{shortened_url}/restore", ud.RestoreLink).Methods(http.MethodPost)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		Principal              *auth.Principal
+		ExpectedRespBody       string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful restoring link of the key owner",
+			Setup: func() {
+				mockedUc.EXPECT().RestoreLink(gomock.Any(), "Abc_def_qA", "team").Return(&models.LinkData{
+					ShortUrl: "http://localhost:8080/Abc_def_qA", OriginalUrl: "http://ya.ru"}, nil)
+			},
+			Principal: &auth.Principal{KeyName: "ci", Owner: "team"},
+			ExpectedRespBody: `{"shortened_url":"http://localhost:8080/Abc_def_qA","original_url":"http://ya.ru",` +
+				`"created_at":"0001-01-01T00:00:00Z","clicks":0}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "test for link past its retention",
+			Setup: func() {
+				mockedUc.EXPECT().RestoreLink(gomock.Any(), "Abc_def_qA", "").Return(nil,
+					&utils.InternalError{Code: http.StatusNotFound, Message: "no restorable link match this shortUrl"})
+			},
+			ExpectedRespStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/links/Abc_def_qA/restore", nil)
+			if tt.Principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.Principal))
+			}
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, w.Code)
+			if tt.ExpectedRespBody != "" {
+				assert.JSONEq(t, tt.ExpectedRespBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestListLinks(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	validator := utils.NewValidator()
+
+	ud := NewUrlDelivery(mockedUc, validator)
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		Query                  string
+		ExpectedRespBody       string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful listing with filters",
+			Setup: func() {
+				mockedUc.EXPECT().ListLinks(gomock.Any(), &models.LinkFilter{Tag: "promo", Title: "sale", Limit: 50}).Return(
+					&models.LinkList{Links: []models.LinkData{{ShortUrl: "http://localhost:8080/Abc_def_qA",
+						OriginalUrl: "http://ya.ru", CreatedAt: createdAt,
+						LinkMetadata: models.LinkMetadata{Title: "Spring sale", Tags: []string{"promo"}}}}}, nil)
+			},
+			Query: "?tag=promo&title=sale&limit=50",
+			ExpectedRespBody: `{"links":[{"shortened_url":"http://localhost:8080/Abc_def_qA","original_url":"http://ya.ru",` +
+				`"created_at":"2025-03-01T12:00:00Z","clicks":0,"title":"Spring sale","tags":["promo"]}]}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "successful listing without filters",
+			Setup: func() {
+				mockedUc.EXPECT().ListLinks(gomock.Any(), &models.LinkFilter{}).Return(
+					&models.LinkList{Links: []models.LinkData{}}, nil)
+			},
+			Query:                  "",
+			ExpectedRespBody:       `{"links":[]}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "successful listing with search, range and order",
+			Setup: func() {
+				mockedUc.EXPECT().ListLinks(gomock.Any(), &models.LinkFilter{Owner: "team", Domain: "example.com",
+					Host: "www.example.com", Search: "spring sale", CreatedFrom: &createdAt, Sort: "clicks", Order: "asc",
+					Cursor: "abc"}).Return(&models.LinkList{Links: []models.LinkData{}, NextCursor: "def"}, nil)
+			},
+			Query: "?owner=team&domain=example.com&host=www.example.com&q=spring+sale&created_from=2025-03-01T12:00:00Z" +
+				"&sort=clicks&order=asc&cursor=abc",
+			ExpectedRespBody:       `{"links":[],"next_cursor":"def"}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name:                   "test for incorrect limit",
+			Setup:                  func() {},
+			Query:                  "?limit=abc",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for incorrect creation time",
+			Setup:                  func() {},
+			Query:                  "?created_to=yesterday",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for unknown sort",
+			Setup:                  func() {},
+			Query:                  "?sort=title",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for too big limit",
+			Setup:                  func() {},
+			Query:                  "?limit=5000",
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, "/api/links"+tt.Query, nil)
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			ud.ListLinks(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, resp.StatusCode)
+			if tt.ExpectedRespBody != "" {
+				assert.JSONEq(t, tt.ExpectedRespBody, w.Body.String())
+			}
+		})
+	}
+}
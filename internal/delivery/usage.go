@@ -0,0 +1,27 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+// GetUsage reports the usage and the quota of an owner. API keys bound to an
+// owner always get the usage of that owner, other keys name it in the owner
+// query parameter.
+func (ud *UrlDelivery) GetUsage(w http.ResponseWriter, r *http.Request) {
+	owner := principalOwner(r.Context())
+	if owner == "" {
+		owner = r.URL.Query().Get("owner")
+	}
+
+	usage, err := ud.UC.GetUsage(r.Context(), owner)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(usage)
+}
@@ -0,0 +1,88 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/delivery/mocks"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUsage(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		Query                  string
+		Principal              *auth.Principal
+		ExpectedRespBody       string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful getting usage of the key owner",
+			Setup: func() {
+				mockedUc.EXPECT().GetUsage(gomock.Any(), "team").Return(&models.UsageReport{Owner: "team",
+					Month: "2026-10", ActiveLinks: 3, Creations: 5, Resolutions: 120,
+					Quota: models.Quota{MaxActiveLinks: 100, MonthlyCreations: 50}}, nil)
+			},
+			Query:     "?owner=other",
+			Principal: &auth.Principal{KeyName: "team", Owner: "team"},
+			ExpectedRespBody: `{"owner":"team","month":"2026-10","active_links":3,"creations":5,"resolutions":120,` +
+				`"quota":{"max_active_links":100,"monthly_creations":50}}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "successful getting usage of a named owner",
+			Setup: func() {
+				mockedUc.EXPECT().GetUsage(gomock.Any(), "other").Return(&models.UsageReport{Owner: "other",
+					Month: "2026-10"}, nil)
+			},
+			Query:     "?owner=other",
+			Principal: &auth.Principal{KeyName: "ci"},
+			ExpectedRespBody: `{"owner":"other","month":"2026-10","active_links":0,"creations":0,"resolutions":0,` +
+				`"quota":{}}`,
+			ExpectedRespStatusCode: http.StatusOK,
+		},
+		{
+			Name: "test for missing owner",
+			Setup: func() {
+				mockedUc.EXPECT().GetUsage(gomock.Any(), "").Return(nil,
+					utils.NewInternalError(http.StatusBadRequest, "owner is required"))
+			},
+			Principal:              &auth.Principal{KeyName: "ci"},
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup()
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/usage"+tt.Query, nil)
+			if tt.Principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.Principal))
+			}
+			w := httptest.NewRecorder()
+
+			ud.GetUsage(w, r)
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, w.Code)
+			if tt.ExpectedRespBody != "" {
+				assert.JSONEq(t, tt.ExpectedRespBody, w.Body.String())
+			}
+		})
+	}
+}
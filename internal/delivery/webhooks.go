@@ -0,0 +1,128 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/gorilla/mux"
+)
+
+// maxDeadLetterLimit bounds the dead letters listed per request.
+const maxDeadLetterLimit = 1000
+
+// principalOwner returns the owner of the API key of the request, if any.
+func principalOwner(ctx context.Context) string {
+	if principal := auth.FromContext(ctx); principal != nil {
+		return principal.Owner
+	}
+	return ""
+}
+
+// CreateWebhook subscribes a url to link events. Subscriptions created with an
+// API key bound to an owner only receive the events of that owner's links.
+func (ud *UrlDelivery) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	inputData := &models.WebhookSubscription{}
+
+	err := json.NewDecoder(r.Body).Decode(inputData)
+	if err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	inputData.Owner = principalOwner(ctx)
+
+	subscription, err := ud.UC.CreateWebhook(ctx, inputData)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subscription)
+}
+
+func (ud *UrlDelivery) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	webhooks, err := ud.UC.ListWebhooks(ctx, principalOwner(ctx))
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+func (ud *UrlDelivery) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		utils.ProcessError(w, utils.NewInternalError(http.StatusNotFound, "no webhook with this id"))
+		return
+	}
+
+	ctx := r.Context()
+
+	err = ud.UC.DeleteWebhook(ctx, id, principalOwner(ctx))
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ud *UrlDelivery) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	var limit int
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDeadLetterLimit {
+			utils.ProcessBadRequestError(w, "incorrect input data")
+			return
+		}
+	}
+
+	ctx := r.Context()
+
+	deadLetters, err := ud.UC.ListDeadLetters(ctx, principalOwner(ctx), limit)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deadLetters)
+}
+
+func (ud *UrlDelivery) RetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		utils.ProcessError(w, utils.NewInternalError(http.StatusNotFound, "no dead letter with this id"))
+		return
+	}
+
+	ctx := r.Context()
+
+	err = ud.UC.RetryDeadLetter(ctx, id, principalOwner(ctx))
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,132 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/delivery/mocks"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWebhook(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	createdAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		Body                   string
+		ExpectedRespBody       string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful subscription",
+			Setup: func() {
+				mockedUc.EXPECT().CreateWebhook(gomock.Any(), &models.WebhookSubscription{Url: "https://example.com/hook",
+					Events: []string{"link.created"}, Owner: "team"}).Return(&models.WebhookSubscription{ID: 7,
+					Url: "https://example.com/hook", Secret: "0123456789abcdef", Events: []string{"link.created"},
+					Owner: "team", CreatedAt: createdAt}, nil)
+			},
+			Body: `{"url":"https://example.com/hook","events":["link.created"],"owner":"other"}`,
+			ExpectedRespBody: `{"id":7,"url":"https://example.com/hook","secret":"0123456789abcdef",` +
+				`"events":["link.created"],"owner":"team","created_at":"2026-10-19T12:00:00Z"}`,
+			ExpectedRespStatusCode: http.StatusCreated,
+		},
+		{
+			Name:                   "test for unknown event",
+			Setup:                  func() {},
+			Body:                   `{"url":"https://example.com/hook","events":["link.visited"]}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for missing events",
+			Setup:                  func() {},
+			Body:                   `{"url":"https://example.com/hook"}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for short secret",
+			Setup:                  func() {},
+			Body:                   `{"url":"https://example.com/hook","events":["link.created"],"secret":"short"}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(tt.Body))
+			r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{KeyName: "ci", Owner: "team"}))
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			ud.CreateWebhook(w, r)
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, w.Code)
+			if tt.ExpectedRespBody != "" {
+				assert.JSONEq(t, tt.ExpectedRespBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDeleteWebhook(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	mockedUc.EXPECT().DeleteWebhook(gomock.Any(), int64(7), "").Return(nil)
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/7", nil), map[string]string{"id": "7"})
+	w := httptest.NewRecorder()
+	ud.DeleteWebhook(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	r = mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/x", nil), map[string]string{"id": "x"})
+	w = httptest.NewRecorder()
+	ud.DeleteWebhook(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestListDeadLetters(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	mockedUc.EXPECT().ListDeadLetters(gomock.Any(), "team", 10).Return(&models.DeadLetterList{
+		DeadLetters: []models.WebhookDelivery{}}, nil)
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/dead-letters?limit=10", nil)
+	r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{KeyName: "ci", Owner: "team"}))
+	w := httptest.NewRecorder()
+	ud.ListDeadLetters(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"dead_letters":[]}`, w.Body.String())
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/dead-letters?limit=0", nil)
+	w = httptest.NewRecorder()
+	ud.ListDeadLetters(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,196 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/gorilla/mux"
+)
+
+func (ud *UrlDelivery) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	inputData := &models.Workspace{}
+
+	err := json.NewDecoder(r.Body).Decode(inputData)
+	if err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	workspace, err := ud.UC.CreateWorkspace(r.Context(), inputData)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(workspace)
+}
+
+func (ud *UrlDelivery) ListWorkspaces(w http.ResponseWriter, r *http.Request) {
+	workspaces, err := ud.UC.ListWorkspaces(r.Context())
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(workspaces)
+}
+
+func (ud *UrlDelivery) GetWorkspace(w http.ResponseWriter, r *http.Request) {
+	workspace, err := ud.UC.GetWorkspace(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(workspace)
+}
+
+func (ud *UrlDelivery) AddMember(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	inputData := &models.Member{}
+
+	err := json.NewDecoder(r.Body).Decode(inputData)
+	if err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	member, err := ud.UC.AddMember(r.Context(), mux.Vars(r)["slug"], inputData)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(member)
+}
+
+func (ud *UrlDelivery) ListMembers(w http.ResponseWriter, r *http.Request) {
+	members, err := ud.UC.ListMembers(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(members)
+}
+
+func (ud *UrlDelivery) UpdateMemberRole(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+
+	inputData := &models.RoleData{}
+
+	err := json.NewDecoder(r.Body).Decode(inputData)
+	if err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	err = ud.UC.UpdateMemberRole(r.Context(), vars["slug"], vars["name"], inputData.Role)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ud *UrlDelivery) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	err := ud.UC.RemoveMember(r.Context(), vars["slug"], vars["name"])
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateWorkspaceKey issues an API key to a member of a workspace. The key is
+// only returned in this response.
+func (ud *UrlDelivery) CreateWorkspaceKey(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	inputData := &models.WorkspaceKey{}
+
+	err := json.NewDecoder(r.Body).Decode(inputData)
+	if err != nil {
+		utils.ProcessBadRequestError(w, "incorrect input data")
+		return
+	}
+
+	err = ud.validator.Struct(inputData)
+	if err != nil {
+		utils.ProcessValidationError(w, err)
+		return
+	}
+
+	key, err := ud.UC.CreateWorkspaceKey(r.Context(), mux.Vars(r)["slug"], inputData)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(key)
+}
+
+func (ud *UrlDelivery) ListWorkspaceKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := ud.UC.ListWorkspaceKeys(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keys)
+}
+
+func (ud *UrlDelivery) DeleteWorkspaceKey(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		utils.ProcessError(w, utils.NewInternalError(http.StatusNotFound, "no api key with this id"))
+		return
+	}
+
+	err = ud.UC.DeleteWorkspaceKey(r.Context(), vars["slug"], id)
+	if err != nil {
+		utils.ProcessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
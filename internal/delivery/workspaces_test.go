@@ -0,0 +1,164 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/delivery/mocks"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddMember(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	createdAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name                   string
+		Setup                  func()
+		Body                   string
+		ExpectedRespBody       string
+		ExpectedRespStatusCode int
+	}{
+		{
+			Name: "successful adding",
+			Setup: func() {
+				mockedUc.EXPECT().AddMember(gomock.Any(), "team", &models.Member{Name: "alice", Role: "editor"}).Return(
+					&models.Member{Workspace: "team", Name: "alice", Role: "editor", CreatedAt: createdAt}, nil)
+			},
+			Body:                   `{"name":"alice","role":"editor"}`,
+			ExpectedRespBody:       `{"name":"alice","role":"editor","created_at":"2026-10-19T12:00:00Z"}`,
+			ExpectedRespStatusCode: http.StatusCreated,
+		},
+		{
+			Name: "test for forbidden key",
+			Setup: func() {
+				mockedUc.EXPECT().AddMember(gomock.Any(), "team", &models.Member{Name: "alice", Role: "editor"}).Return(
+					nil, utils.NewInternalError(http.StatusForbidden, "the api key needs the admin role"))
+			},
+			Body:                   `{"name":"alice","role":"editor"}`,
+			ExpectedRespStatusCode: http.StatusForbidden,
+		},
+		{
+			Name:                   "test for unknown role",
+			Setup:                  func() {},
+			Body:                   `{"name":"alice","role":"owner"}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:                   "test for missing name",
+			Setup:                  func() {},
+			Body:                   `{"role":"viewer"}`,
+			ExpectedRespStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/workspaces/team/members", strings.NewReader(tt.Body))
+			r = mux.SetURLVars(r, map[string]string{"slug": "team"})
+			w := httptest.NewRecorder()
+
+			tt.Setup()
+
+			ud.AddMember(w, r)
+
+			assert.Equal(t, tt.ExpectedRespStatusCode, w.Code)
+			if tt.ExpectedRespBody != "" {
+				assert.JSONEq(t, tt.ExpectedRespBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateMemberRole(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	vars := map[string]string{"slug": "team", "name": "alice"}
+
+	mockedUc.EXPECT().UpdateMemberRole(gomock.Any(), "team", "alice", "admin").Return(nil)
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/api/v1/workspaces/team/members/alice",
+		strings.NewReader(`{"role":"admin"}`)), vars)
+	w := httptest.NewRecorder()
+	ud.UpdateMemberRole(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	r = mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/api/v1/workspaces/team/members/alice",
+		strings.NewReader(`{"role":"owner"}`)), vars)
+	w = httptest.NewRecorder()
+	ud.UpdateMemberRole(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateWorkspaceKey(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	createdAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	mockedUc.EXPECT().CreateWorkspaceKey(gomock.Any(), "team", &models.WorkspaceKey{Name: "deploy", Member: "ci"}).
+		Return(&models.WorkspaceKey{ID: 3, Workspace: "team", Name: "deploy", Member: "ci", Key: "SECRET",
+			Digest: "digest", CreatedAt: createdAt}, nil)
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/v1/workspaces/team/keys",
+		strings.NewReader(`{"name":"deploy","member":"ci"}`)), map[string]string{"slug": "team"})
+	w := httptest.NewRecorder()
+	ud.CreateWorkspaceKey(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	// the digest is never returned
+	assert.JSONEq(t, `{"id":3,"name":"deploy","member":"ci","key":"SECRET","created_at":"2026-10-19T12:00:00Z"}`,
+		w.Body.String())
+
+	r = mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/v1/workspaces/team/keys",
+		strings.NewReader(`{"name":"deploy"}`)), map[string]string{"slug": "team"})
+	w = httptest.NewRecorder()
+	ud.CreateWorkspaceKey(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteWorkspaceKey(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	ud := NewUrlDelivery(mockedUc, utils.NewValidator())
+
+	mockedUc.EXPECT().DeleteWorkspaceKey(gomock.Any(), "team", int64(3)).Return(nil)
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/v1/workspaces/team/keys/3", nil),
+		map[string]string{"slug": "team", "id": "3"})
+	w := httptest.NewRecorder()
+	ud.DeleteWorkspaceKey(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	r = mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/api/v1/workspaces/team/keys/x", nil),
+		map[string]string{"slug": "team", "id": "x"})
+	w = httptest.NewRecorder()
+	ud.DeleteWorkspaceKey(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
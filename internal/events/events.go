@@ -0,0 +1,98 @@
+package events
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+// Types of the link lifecycle events.
+const (
+	LinkCreated         = "link.created"
+	LinkUpdated         = "link.updated"
+	LinkDeleted         = "link.deleted"
+	LinkRestored        = "link.restored"
+	LinkExpired         = "link.expired"
+	LinkClicksThreshold = "link.clicks_threshold"
+)
+
+// Reasons of link.expired events.
+const (
+	ReasonClickLimit = "click_limit"
+	ReasonNotAfter   = "not_after"
+)
+
+func newEvent(typ, code, owner string) *models.WebhookEvent {
+	return &models.WebhookEvent{ID: rand.Text(), Type: typ, CreatedAt: time.Now().UTC(), Code: code, Owner: owner}
+}
+
+// Created returns the event of a created link.
+func Created(data *models.UrlData) *models.WebhookEvent {
+	res := newEvent(LinkCreated, data.ShortUrl, data.Owner)
+	createdAt := data.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = res.CreatedAt
+	}
+	res.Link = &models.ExportRecord{
+		Code:             data.ShortUrl,
+		Url:              data.OriginalUrl,
+		Owner:            data.Owner,
+		CreatedAt:        createdAt.UTC(),
+		ClicksLeft:       data.ClicksLeft,
+		ActivationWindow: data.ActivationWindow,
+		QueryOptions:     data.QueryOptions,
+		Rules:            data.Rules,
+		Destinations:     data.Destinations,
+		Interstitial:     data.Interstitial,
+		LinkMetadata:     data.LinkMetadata,
+	}
+	return res
+}
+
+// WindowUpdated returns the event of a link whose activation window changed.
+func WindowUpdated(code, owner string, window *models.ActivationWindow) *models.WebhookEvent {
+	res := newEvent(LinkUpdated, code, owner)
+	res.Changes = &models.LinkChanges{Fields: []string{"window"}, Window: window}
+	return res
+}
+
+// RulesUpdated returns the event of a link whose targeting rules changed.
+func RulesUpdated(code, owner string, rules []models.TargetingRule) *models.WebhookEvent {
+	res := newEvent(LinkUpdated, code, owner)
+	res.Changes = &models.LinkChanges{Fields: []string{"rules"}, Rules: rules}
+	return res
+}
+
+// MetadataUpdated returns the event of a link whose metadata changed.
+func MetadataUpdated(code, owner string, metadata *models.LinkMetadata) *models.WebhookEvent {
+	res := newEvent(LinkUpdated, code, owner)
+	res.Changes = &models.LinkChanges{Fields: []string{"metadata"}, Metadata: metadata}
+	return res
+}
+
+// Deleted returns the event of a deleted link.
+func Deleted(code, owner string) *models.WebhookEvent {
+	return newEvent(LinkDeleted, code, owner)
+}
+
+// Restored returns the event of a deleted link that was restored.
+func Restored(code, owner string) *models.WebhookEvent {
+	return newEvent(LinkRestored, code, owner)
+}
+
+// Expired returns the event of a link that ran out of clicks or passed the
+// end of its activation window, as told by reason.
+func Expired(code, owner, reason string) *models.WebhookEvent {
+	res := newEvent(LinkExpired, code, owner)
+	res.Reason = reason
+	return res
+}
+
+// ClicksReached returns the event of a link whose click count reached clicks.
+// It is only delivered to the subscriptions listing clicks as a threshold.
+func ClicksReached(code, owner string, clicks int64) *models.WebhookEvent {
+	res := newEvent(LinkClicksThreshold, code, owner)
+	res.Clicks = clicks
+	return res
+}
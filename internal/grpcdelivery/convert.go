@@ -0,0 +1,183 @@
+package grpcdelivery
+
+import (
+	"time"
+
+	urlshortenerv1 "github.com/AlexNov03/UrlShortener/api/urlshortener/v1"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func timeFromProto(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	res := ts.AsTime()
+	return &res
+}
+
+func timeToProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func windowFromProto(window *urlshortenerv1.ActivationWindow) models.ActivationWindow {
+	return models.ActivationWindow{
+		NotBefore:   timeFromProto(window.GetNotBefore()),
+		NotAfter:    timeFromProto(window.GetNotAfter()),
+		FallbackUrl: window.GetFallbackUrl(),
+	}
+}
+
+func windowToProto(window *models.ActivationWindow) *urlshortenerv1.ActivationWindow {
+	if window.NotBefore == nil && window.NotAfter == nil && window.FallbackUrl == "" {
+		return nil
+	}
+	return &urlshortenerv1.ActivationWindow{
+		NotBefore:   timeToProto(window.NotBefore),
+		NotAfter:    timeToProto(window.NotAfter),
+		FallbackUrl: window.FallbackUrl,
+	}
+}
+
+func queryOptionsFromProto(options *urlshortenerv1.QueryOptions) models.QueryOptions {
+	return models.QueryOptions{
+		UtmSource:   options.GetUtmSource(),
+		UtmMedium:   options.GetUtmMedium(),
+		UtmCampaign: options.GetUtmCampaign(),
+		PassQuery:   options.GetPassQuery(),
+	}
+}
+
+func queryOptionsToProto(options *models.QueryOptions) *urlshortenerv1.QueryOptions {
+	if *options == (models.QueryOptions{}) {
+		return nil
+	}
+	return &urlshortenerv1.QueryOptions{
+		UtmSource:   options.UtmSource,
+		UtmMedium:   options.UtmMedium,
+		UtmCampaign: options.UtmCampaign,
+		PassQuery:   options.PassQuery,
+	}
+}
+
+func rulesFromProto(rules []*urlshortenerv1.TargetingRule) []models.TargetingRule {
+	if rules == nil {
+		return nil
+	}
+	res := make([]models.TargetingRule, len(rules))
+	for i, rule := range rules {
+		res[i] = models.TargetingRule{OS: rule.GetOs(), Device: rule.GetDevice(), Language: rule.GetLanguage(),
+			Query: rule.GetQuery(), TargetUrl: rule.GetTargetUrl()}
+	}
+	return res
+}
+
+func rulesToProto(rules []models.TargetingRule) []*urlshortenerv1.TargetingRule {
+	if rules == nil {
+		return nil
+	}
+	res := make([]*urlshortenerv1.TargetingRule, len(rules))
+	for i, rule := range rules {
+		res[i] = &urlshortenerv1.TargetingRule{Os: rule.OS, Device: rule.Device, Language: rule.Language,
+			Query: rule.Query, TargetUrl: rule.TargetUrl}
+	}
+	return res
+}
+
+func destinationsFromProto(destinations []*urlshortenerv1.Destination) []models.Destination {
+	if destinations == nil {
+		return nil
+	}
+	res := make([]models.Destination, len(destinations))
+	for i, destination := range destinations {
+		res[i] = models.Destination{Url: destination.GetUrl(), Weight: int(destination.GetWeight())}
+	}
+	return res
+}
+
+func destinationsToProto(destinations []models.Destination) []*urlshortenerv1.Destination {
+	if destinations == nil {
+		return nil
+	}
+	res := make([]*urlshortenerv1.Destination, len(destinations))
+	for i, destination := range destinations {
+		res[i] = &urlshortenerv1.Destination{Url: destination.Url, Weight: int32(destination.Weight)}
+	}
+	return res
+}
+
+func metadataFromProto(metadata *urlshortenerv1.LinkMetadata) models.LinkMetadata {
+	return models.LinkMetadata{
+		Title:       metadata.GetTitle(),
+		Description: metadata.GetDescription(),
+		Tags:        metadata.GetTags(),
+		Notes:       metadata.GetNotes(),
+	}
+}
+
+func metadataToProto(metadata *models.LinkMetadata) *urlshortenerv1.LinkMetadata {
+	if metadata.Title == "" && metadata.Description == "" && len(metadata.Tags) == 0 && metadata.Notes == "" {
+		return nil
+	}
+	return &urlshortenerv1.LinkMetadata{
+		Title:       metadata.Title,
+		Description: metadata.Description,
+		Tags:        metadata.Tags,
+		Notes:       metadata.Notes,
+	}
+}
+
+func shortenRequestFromProto(req *urlshortenerv1.ShortenRequest) *models.OrigUrlData {
+	return &models.OrigUrlData{
+		OriginalUrl:      req.GetOriginalUrl(),
+		MaxClicks:        int(req.GetMaxClicks()),
+		ActivationWindow: windowFromProto(req.GetWindow()),
+		QueryOptions:     queryOptionsFromProto(req.GetQueryOptions()),
+		Rules:            rulesFromProto(req.GetRules()),
+		Destinations:     destinationsFromProto(req.GetDestinations()),
+		Interstitial:     req.GetInterstitial(),
+		Owner:            req.GetOwner(),
+		LinkMetadata:     metadataFromProto(req.GetMetadata()),
+	}
+}
+
+func linkToProto(link *models.LinkData) *urlshortenerv1.Link {
+	res := &urlshortenerv1.Link{
+		ShortUrl:     link.ShortUrl,
+		OriginalUrl:  link.OriginalUrl,
+		Window:       windowToProto(&link.ActivationWindow),
+		QueryOptions: queryOptionsToProto(&link.QueryOptions),
+		Rules:        rulesToProto(link.Rules),
+		Destinations: destinationsToProto(link.Destinations),
+		Interstitial: link.Interstitial,
+		Owner:        link.Owner,
+		CreatedAt:    timestamppb.New(link.CreatedAt),
+		Clicks:       link.Clicks,
+		Metadata:     metadataToProto(&link.LinkMetadata),
+	}
+	if link.ClicksLeft != nil {
+		left := int32(*link.ClicksLeft)
+		res.ClicksLeft = &left
+	}
+	return res
+}
+
+func listRequestFromProto(req *urlshortenerv1.ListRequest) *models.LinkFilter {
+	return &models.LinkFilter{
+		Owner:       req.GetOwner(),
+		Domain:      req.GetDomain(),
+		Host:        req.GetHost(),
+		Tag:         req.GetTag(),
+		Title:       req.GetTitle(),
+		Search:      req.GetQ(),
+		CreatedFrom: timeFromProto(req.GetCreatedFrom()),
+		CreatedTo:   timeFromProto(req.GetCreatedTo()),
+		Sort:        req.GetSort(),
+		Order:       req.GetOrder(),
+		Cursor:      req.GetCursor(),
+		Limit:       int(req.GetLimit()),
+	}
+}
@@ -0,0 +1,97 @@
+package grpcdelivery
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/AlexNov03/UrlShortener/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is the domain of the ErrorInfo details carrying the reason of
+// an error.
+const errorDomain = "urlshortener"
+
+// statusCodes maps the HTTP statuses of utils.InternalError to gRPC codes.
+// Other client errors are reported as InvalidArgument, other server errors as
+// Internal.
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusRequestTimeout:        codes.DeadlineExceeded,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusGone:                  codes.FailedPrecondition,
+	http.StatusPreconditionFailed:    codes.FailedPrecondition,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusNotImplemented:        codes.Unimplemented,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+}
+
+func statusCode(httpStatus int) codes.Code {
+	if code, ok := statusCodes[httpStatus]; ok {
+		return code
+	}
+	if httpStatus >= 500 {
+		return codes.Internal
+	}
+	return codes.InvalidArgument
+}
+
+// toStatus converts an error of the usecase to a gRPC status, the way
+// utils.ProcessError reports it over HTTP. The reason of an InternalError is
+// attached as ErrorInfo details. Messages of server errors are logged and not
+// sent to clients.
+func toStatus(err error) *status.Status {
+	var internalError *utils.InternalError
+
+	if ok := errors.As(err, &internalError); ok {
+		message := internalError.Message
+		if internalError.Code >= 500 {
+			log.Printf("internal server error: %v", err)
+			message = http.StatusText(internalError.Code)
+		}
+		res := status.New(statusCode(internalError.Code), message)
+		if internalError.Reason != "" {
+			if detailed, detailsErr := res.WithDetails(&errdetails.ErrorInfo{Reason: internalError.Reason,
+				Domain: errorDomain}); detailsErr == nil {
+				res = detailed
+			}
+		}
+		return res
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("error deadline exceeded: %v", err)
+		return status.New(codes.DeadlineExceeded, "request timed out")
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return status.New(codes.Canceled, err.Error())
+	}
+
+	log.Printf("unknown error: %v", err)
+	return status.New(codes.Internal, http.StatusText(http.StatusInternalServerError))
+}
+
+func statusError(err error) error {
+	return toStatus(err).Err()
+}
+
+// statusReason returns the reason attached to st by toStatus, if any.
+func statusReason(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == errorDomain {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package grpcdelivery
+
+import (
+	"context"
+	"net/url"
+
+	urlshortenerv1 "github.com/AlexNov03/UrlShortener/api/urlshortener/v1"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/delivery"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// maxBatchSize limits the number of links shortened by a single BatchShorten
+// call.
+const maxBatchSize = 100
+
+// UrlService serves the gRPC API with the same usecase as the REST API.
+type UrlService struct {
+	urlshortenerv1.UnimplementedUrlShortenerServer
+	UC        delivery.UrlUsecase
+	validator *validator.Validate
+}
+
+func NewUrlService(uc delivery.UrlUsecase, validator *validator.Validate) *UrlService {
+	return &UrlService{UC: uc, validator: validator}
+}
+
+func (us *UrlService) shorten(ctx context.Context, req *urlshortenerv1.ShortenRequest) (string, error) {
+	inputData := shortenRequestFromProto(req)
+
+	err := us.validator.Struct(inputData)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, "incorrect fields in input data")
+	}
+
+	shortenedUrl, err := us.UC.ShortenUrl(ctx, inputData)
+	if err != nil {
+		return "", statusError(err)
+	}
+	return shortenedUrl.ShortUrl, nil
+}
+
+func (us *UrlService) Shorten(ctx context.Context, req *urlshortenerv1.ShortenRequest) (*urlshortenerv1.ShortenResponse, error) {
+	shortUrl, err := us.shorten(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &urlshortenerv1.ShortenResponse{ShortUrl: shortUrl}, nil
+}
+
+func (us *UrlService) BatchShorten(ctx context.Context, req *urlshortenerv1.BatchShortenRequest) (*urlshortenerv1.BatchShortenResponse, error) {
+	if len(req.GetRequests()) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch can't contain more than %d links", maxBatchSize)
+	}
+
+	res := &urlshortenerv1.BatchShortenResponse{Results: make([]*urlshortenerv1.BatchShortenResult, 0, len(req.GetRequests()))}
+	for _, item := range req.GetRequests() {
+		if err := ctx.Err(); err != nil {
+			return nil, statusError(err)
+		}
+
+		shortUrl, err := us.shorten(ctx, item)
+		if err != nil {
+			st := status.Convert(err)
+			res.Results = append(res.Results, &urlshortenerv1.BatchShortenResult{
+				Result: &urlshortenerv1.BatchShortenResult_Error{Error: &urlshortenerv1.Error{
+					Code: int32(st.Code()), Message: st.Message(), Reason: statusReason(st)}},
+			})
+			continue
+		}
+		res.Results = append(res.Results, &urlshortenerv1.BatchShortenResult{
+			Result: &urlshortenerv1.BatchShortenResult_ShortUrl{ShortUrl: shortUrl},
+		})
+	}
+	return res, nil
+}
+
+func (us *UrlService) Resolve(ctx context.Context, req *urlshortenerv1.ResolveRequest) (*urlshortenerv1.ResolveResponse, error) {
+	query, err := url.ParseQuery(req.GetQuery())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "incorrect input data")
+	}
+
+	visitor := &models.VisitorData{
+		UserAgent:      req.GetUserAgent(),
+		AcceptLanguage: req.GetAcceptLanguage(),
+		Query:          query,
+		VisitorID:      req.GetVisitorId(),
+	}
+
+	redirect, err := us.UC.GetOriginalUrl(ctx, req.GetCode(), visitor)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &urlshortenerv1.ResolveResponse{Url: redirect.Url, Interstitial: redirect.Interstitial,
+		Delay: int32(redirect.Delay)}, nil
+}
+
+func (us *UrlService) Get(ctx context.Context, req *urlshortenerv1.GetRequest) (*urlshortenerv1.Link, error) {
+	link, err := us.UC.GetLink(ctx, req.GetCode())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return linkToProto(link), nil
+}
+
+func (us *UrlService) List(ctx context.Context, req *urlshortenerv1.ListRequest) (*urlshortenerv1.ListResponse, error) {
+	inputData := listRequestFromProto(req)
+
+	err := us.validator.Struct(inputData)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "incorrect fields in input data")
+	}
+
+	links, err := us.UC.ListLinks(ctx, inputData)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	res := &urlshortenerv1.ListResponse{Links: make([]*urlshortenerv1.Link, len(links.Links)),
+		NextCursor: links.NextCursor}
+	for i := range links.Links {
+		res.Links[i] = linkToProto(&links.Links[i])
+	}
+	return res, nil
+}
+
+// Delete deletes a link. Keys bound to an owner may only delete the links of
+// that owner.
+func (us *UrlService) Delete(ctx context.Context, req *urlshortenerv1.DeleteRequest) (*emptypb.Empty, error) {
+	var owner string
+	if principal := auth.FromContext(ctx); principal != nil {
+		owner = principal.Owner
+	}
+
+	err := us.UC.DeleteLink(ctx, req.GetCode(), owner)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
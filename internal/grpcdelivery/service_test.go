@@ -0,0 +1,375 @@
+package grpcdelivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	urlshortenerv1 "github.com/AlexNov03/UrlShortener/api/urlshortener/v1"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/delivery/mocks"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newTestClient serves service on an in-process listener and returns a client
+// connected to it.
+func newTestClient(t *testing.T, service *UrlService, opts ...grpc.ServerOption) urlshortenerv1.UrlShortenerClient {
+	lis := bufconn.Listen(1024 * 1024)
+
+	server := grpc.NewServer(opts...)
+	urlshortenerv1.RegisterUrlShortenerServer(server, service)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return urlshortenerv1.NewUrlShortenerClient(conn)
+}
+
+func newTestService(t *testing.T) (*mocks.MockUrlUsecase, *UrlService) {
+	ctrl := gomock.NewController(t)
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+	return mockedUc, NewUrlService(mockedUc, validator.New(validator.WithRequiredStructEnabled()))
+}
+
+func TestShorten(t *testing.T) {
+
+	mockedUc, service := newTestService(t)
+	client := newTestClient(t, service)
+	ctx := context.Background()
+
+	notAfter := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name             string
+		Setup            func()
+		Req              *urlshortenerv1.ShortenRequest
+		ExpectedShortUrl string
+		ExpectedCode     codes.Code
+		ExpectedReason   string
+	}{
+		{
+			Name: "successful shortening",
+			Setup: func() {
+				mockedUc.EXPECT().ShortenUrl(gomock.Any(), &models.OrigUrlData{
+					OriginalUrl:      "https://example.com",
+					MaxClicks:        3,
+					ActivationWindow: models.ActivationWindow{NotAfter: &notAfter},
+					QueryOptions:     models.QueryOptions{UtmSource: "newsletter"},
+					Rules:            []models.TargetingRule{{OS: "ios", TargetUrl: "https://apps.apple.com/app"}},
+					Owner:            "team",
+					LinkMetadata:     models.LinkMetadata{Title: "Spring sale", Tags: []string{"promo"}},
+				}).Return(&models.ShortUrlData{ShortUrl: "http://localhost:8080/Abc_def_qA"}, nil)
+			},
+			Req: &urlshortenerv1.ShortenRequest{
+				OriginalUrl:  "https://example.com",
+				MaxClicks:    3,
+				Window:       &urlshortenerv1.ActivationWindow{NotAfter: timestamppb.New(notAfter)},
+				QueryOptions: &urlshortenerv1.QueryOptions{UtmSource: "newsletter"},
+				Rules:        []*urlshortenerv1.TargetingRule{{Os: "ios", TargetUrl: "https://apps.apple.com/app"}},
+				Owner:        "team",
+				Metadata:     &urlshortenerv1.LinkMetadata{Title: "Spring sale", Tags: []string{"promo"}},
+			},
+			ExpectedShortUrl: "http://localhost:8080/Abc_def_qA",
+			ExpectedCode:     codes.OK,
+		},
+		{
+			Name:         "test for missing original url",
+			Setup:        func() {},
+			Req:          &urlshortenerv1.ShortenRequest{},
+			ExpectedCode: codes.InvalidArgument,
+		},
+		{
+			Name:  "test for a single destination",
+			Setup: func() {},
+			Req: &urlshortenerv1.ShortenRequest{OriginalUrl: "https://example.com",
+				Destinations: []*urlshortenerv1.Destination{{Url: "https://a.example.com", Weight: 1}}},
+			ExpectedCode: codes.InvalidArgument,
+		},
+		{
+			Name: "test for a rejected destination",
+			Setup: func() {
+				mockedUc.EXPECT().ShortenUrl(gomock.Any(), gomock.Any()).Return(nil, &utils.InternalError{
+					Code: http.StatusUnprocessableEntity, Message: "destination url is not allowed", Reason: "blocked"})
+			},
+			Req:            &urlshortenerv1.ShortenRequest{OriginalUrl: "https://malware.example"},
+			ExpectedCode:   codes.InvalidArgument,
+			ExpectedReason: "blocked",
+		},
+		{
+			Name: "test for a taken short url",
+			Setup: func() {
+				mockedUc.EXPECT().ShortenUrl(gomock.Any(), gomock.Any()).Return(nil,
+					utils.NewInternalError(http.StatusConflict, "short url already exists"))
+			},
+			Req:          &urlshortenerv1.ShortenRequest{OriginalUrl: "https://example.com"},
+			ExpectedCode: codes.AlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup()
+
+			resp, err := client.Shorten(ctx, tt.Req)
+
+			st := status.Convert(err)
+			assert.Equal(t, tt.ExpectedCode, st.Code())
+			assert.Equal(t, tt.ExpectedShortUrl, resp.GetShortUrl())
+
+			var reason string
+			for _, detail := range st.Details() {
+				if info, ok := detail.(*errdetails.ErrorInfo); ok {
+					reason = info.GetReason()
+				}
+			}
+			assert.Equal(t, tt.ExpectedReason, reason)
+		})
+	}
+}
+
+func TestBatchShorten(t *testing.T) {
+
+	mockedUc, service := newTestService(t)
+	client := newTestClient(t, service)
+	ctx := context.Background()
+
+	gomock.InOrder(
+		mockedUc.EXPECT().ShortenUrl(gomock.Any(), &models.OrigUrlData{OriginalUrl: "https://a.example.com"}).Return(
+			&models.ShortUrlData{ShortUrl: "http://localhost:8080/Abc_def_qA"}, nil),
+		mockedUc.EXPECT().ShortenUrl(gomock.Any(), &models.OrigUrlData{OriginalUrl: "http://10.0.0.1"}).Return(nil,
+			&utils.InternalError{Code: http.StatusUnprocessableEntity, Message: "destination url is not allowed",
+				Reason: "private_address"}),
+	)
+
+	resp, err := client.BatchShorten(ctx, &urlshortenerv1.BatchShortenRequest{Requests: []*urlshortenerv1.ShortenRequest{
+		{OriginalUrl: "https://a.example.com"},
+		{OriginalUrl: "http://10.0.0.1"},
+		{},
+	}})
+	require.NoError(t, err)
+
+	expected := []*urlshortenerv1.BatchShortenResult{
+		{Result: &urlshortenerv1.BatchShortenResult_ShortUrl{ShortUrl: "http://localhost:8080/Abc_def_qA"}},
+		{Result: &urlshortenerv1.BatchShortenResult_Error{Error: &urlshortenerv1.Error{
+			Code: int32(codes.InvalidArgument), Message: "destination url is not allowed", Reason: "private_address"}}},
+		{Result: &urlshortenerv1.BatchShortenResult_Error{Error: &urlshortenerv1.Error{
+			Code: int32(codes.InvalidArgument), Message: "incorrect fields in input data"}}},
+	}
+	require.Len(t, resp.GetResults(), len(expected))
+	for i := range expected {
+		assert.True(t, proto.Equal(expected[i], resp.GetResults()[i]), "result %d: %v", i, resp.GetResults()[i])
+	}
+
+	_, err = client.BatchShorten(ctx, &urlshortenerv1.BatchShortenRequest{
+		Requests: make([]*urlshortenerv1.ShortenRequest, maxBatchSize+1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestResolve(t *testing.T) {
+
+	mockedUc, service := newTestService(t)
+	client := newTestClient(t, service)
+	ctx := context.Background()
+
+	tests := []struct {
+		Name         string
+		Setup        func()
+		Req          *urlshortenerv1.ResolveRequest
+		ExpectedResp *urlshortenerv1.ResolveResponse
+		ExpectedCode codes.Code
+	}{
+		{
+			Name: "successful resolving",
+			Setup: func() {
+				mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), "Abc_def_qA", &models.VisitorData{
+					UserAgent: "Mozilla/5.0 (iPhone)", AcceptLanguage: "de-DE", Query: url.Values{"ref": {"mail"}},
+					VisitorID: "v1"}).Return(&models.Redirect{Url: "https://example.com", Interstitial: true, Delay: 5}, nil)
+			},
+			Req: &urlshortenerv1.ResolveRequest{Code: "Abc_def_qA", UserAgent: "Mozilla/5.0 (iPhone)",
+				AcceptLanguage: "de-DE", Query: "ref=mail", VisitorId: "v1"},
+			ExpectedResp: &urlshortenerv1.ResolveResponse{Url: "https://example.com", Interstitial: true, Delay: 5},
+			ExpectedCode: codes.OK,
+		},
+		{
+			Name:         "test for incorrect query",
+			Setup:        func() {},
+			Req:          &urlshortenerv1.ResolveRequest{Code: "Abc_def_qA", Query: "a=%zz"},
+			ExpectedCode: codes.InvalidArgument,
+		},
+		{
+			Name: "test for expired link",
+			Setup: func() {
+				mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), "Abc_def_qA", gomock.Any()).Return(nil,
+					utils.NewInternalError(http.StatusGone, "expired"))
+			},
+			Req:          &urlshortenerv1.ResolveRequest{Code: "Abc_def_qA"},
+			ExpectedCode: codes.FailedPrecondition,
+		},
+		{
+			Name: "test for missing link",
+			Setup: func() {
+				mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), "Abc_def_qA", gomock.Any()).Return(nil,
+					utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl"))
+			},
+			Req:          &urlshortenerv1.ResolveRequest{Code: "Abc_def_qA"},
+			ExpectedCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup()
+
+			resp, err := client.Resolve(ctx, tt.Req)
+
+			assert.Equal(t, tt.ExpectedCode, status.Code(err))
+			if tt.ExpectedResp != nil {
+				assert.True(t, proto.Equal(tt.ExpectedResp, resp), "got %v", resp)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+
+	mockedUc, service := newTestService(t)
+	client := newTestClient(t, service)
+	ctx := context.Background()
+
+	clicksLeft := 2
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mockedUc.EXPECT().GetLink(gomock.Any(), "Abc_def_qA").Return(&models.LinkData{
+		ShortUrl: "http://localhost:8080/Abc_def_qA", OriginalUrl: "https://example.com", ClicksLeft: &clicksLeft,
+		Destinations: []models.Destination{{Url: "https://a.example.com", Weight: 1}, {Url: "https://b.example.com", Weight: 3}},
+		Owner:        "team", CreatedAt: createdAt, Clicks: 7,
+	}, nil)
+
+	link, err := client.Get(ctx, &urlshortenerv1.GetRequest{Code: "Abc_def_qA"})
+	require.NoError(t, err)
+
+	left := int32(2)
+	expected := &urlshortenerv1.Link{
+		ShortUrl: "http://localhost:8080/Abc_def_qA", OriginalUrl: "https://example.com", ClicksLeft: &left,
+		Destinations: []*urlshortenerv1.Destination{{Url: "https://a.example.com", Weight: 1},
+			{Url: "https://b.example.com", Weight: 3}},
+		Owner: "team", CreatedAt: timestamppb.New(createdAt), Clicks: 7,
+	}
+	assert.True(t, proto.Equal(expected, link), "got %v", link)
+
+	mockedUc.EXPECT().GetLink(gomock.Any(), "Abc_def_qB").Return(nil, context.DeadlineExceeded)
+
+	_, err = client.Get(ctx, &urlshortenerv1.GetRequest{Code: "Abc_def_qB"})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestList(t *testing.T) {
+
+	mockedUc, service := newTestService(t)
+	client := newTestClient(t, service)
+	ctx := context.Background()
+
+	createdFrom := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mockedUc.EXPECT().ListLinks(gomock.Any(), &models.LinkFilter{Owner: "team", Tag: "promo", Search: "spring",
+		CreatedFrom: &createdFrom, Sort: "clicks", Order: "desc", Cursor: "abc", Limit: 2}).Return(&models.LinkList{
+		Links:      []models.LinkData{{ShortUrl: "http://localhost:8080/Abc_def_qA", CreatedAt: createdFrom}},
+		NextCursor: "def",
+	}, nil)
+
+	resp, err := client.List(ctx, &urlshortenerv1.ListRequest{Owner: "team", Tag: "promo", Q: "spring",
+		CreatedFrom: timestamppb.New(createdFrom), Sort: "clicks", Order: "desc", Cursor: "abc", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "def", resp.GetNextCursor())
+	require.Len(t, resp.GetLinks(), 1)
+	assert.Equal(t, "http://localhost:8080/Abc_def_qA", resp.GetLinks()[0].GetShortUrl())
+
+	_, err = client.List(ctx, &urlshortenerv1.ListRequest{Sort: "title"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestDelete(t *testing.T) {
+
+	mockedUc, service := newTestService(t)
+	client := newTestClient(t, service, grpc.UnaryInterceptor(func(ctx context.Context, req any,
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(auth.WithPrincipal(ctx, &auth.Principal{KeyName: "ci", Owner: "team"}), req)
+	}))
+	ctx := context.Background()
+
+	mockedUc.EXPECT().DeleteLink(gomock.Any(), "Abc_def_qA", "team").Return(nil)
+	_, err := client.Delete(ctx, &urlshortenerv1.DeleteRequest{Code: "Abc_def_qA"})
+	assert.NoError(t, err)
+
+	mockedUc.EXPECT().DeleteLink(gomock.Any(), "Abc_def_qB", "team").Return(
+		utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl"))
+	_, err = client.Delete(ctx, &urlshortenerv1.DeleteRequest{Code: "Abc_def_qB"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestStatusCode(t *testing.T) {
+
+	tests := []struct {
+		HttpStatus   int
+		ExpectedCode codes.Code
+	}{
+		{HttpStatus: http.StatusBadRequest, ExpectedCode: codes.InvalidArgument},
+		{HttpStatus: http.StatusUnauthorized, ExpectedCode: codes.Unauthenticated},
+		{HttpStatus: http.StatusForbidden, ExpectedCode: codes.PermissionDenied},
+		{HttpStatus: http.StatusNotFound, ExpectedCode: codes.NotFound},
+		{HttpStatus: http.StatusConflict, ExpectedCode: codes.AlreadyExists},
+		{HttpStatus: http.StatusGone, ExpectedCode: codes.FailedPrecondition},
+		{HttpStatus: http.StatusTooManyRequests, ExpectedCode: codes.ResourceExhausted},
+		{HttpStatus: http.StatusTeapot, ExpectedCode: codes.InvalidArgument},
+		{HttpStatus: http.StatusInternalServerError, ExpectedCode: codes.Internal},
+		{HttpStatus: http.StatusBadGateway, ExpectedCode: codes.Internal},
+		{HttpStatus: http.StatusServiceUnavailable, ExpectedCode: codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.ExpectedCode, statusCode(tt.HttpStatus), "status %d", tt.HttpStatus)
+	}
+}
+
+func TestToStatus(t *testing.T) {
+
+	st := toStatus(fmt.Errorf("pg.UrlRepository.GetUrl: %w",
+		utils.NewInternalError(http.StatusInternalServerError, "connection refused")))
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, "Internal Server Error", st.Message())
+
+	st = toStatus(errors.New("something failed"))
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, "Internal Server Error", st.Message())
+
+	st = toStatus(utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl"))
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "no originalUrl match this shortUrl", st.Message())
+}
@@ -0,0 +1,213 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+const (
+	// Header carries the idempotency key of a request.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed for retries.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	maxBodySize  = 1 << 20
+
+	defaultTTL = 24 * time.Hour
+	// lockTimeout bounds how long duplicates wait for the first request,
+	// which may have been abandoned, before taking its key over.
+	lockTimeout  = time.Minute
+	pollInterval = 100 * time.Millisecond
+)
+
+// Store keeps the records of the requests sent with idempotency keys.
+type Store interface {
+	AcquireIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord, now time.Time) (*models.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error
+	ReleaseIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord, now time.Time) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Middleware makes requests sent with an Idempotency-Key header safe to
+// retry. The response to the first request with a key is stored per API key
+// and replayed for retries with the same key and body, duplicates sent while
+// the first request is in progress wait for it, and a key reused with a
+// different body is rejected with 422. API keys are told apart by their
+// digests, taken from the principal the authentication middleware, which must
+// run first, puts in the request context. Requests without a principal share
+// a scope. Server errors are not stored, so the requests can be retried.
+type Middleware struct {
+	store        Store
+	ttl          time.Duration
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+func NewMiddleware(store Store, ttl time.Duration) *Middleware {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &Middleware{store: store, ttl: ttl, pollInterval: pollInterval, now: time.Now}
+}
+
+// Handler wraps next with the idempotency checks.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxKeyLength {
+			utils.ProcessBadRequestError(w, "idempotency key is too long")
+			return
+		}
+
+		var scope string
+		if principal := auth.FromContext(r.Context()); principal != nil {
+			scope = principal.KeyDigest
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				utils.ProcessError(w, utils.NewInternalError(http.StatusRequestEntityTooLarge, "request body is too large"))
+				return
+			}
+			utils.ProcessBadRequestError(w, "incorrect input data")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256(body)
+		record := &models.IdempotencyRecord{Scope: scope, Key: key, RequestHash: hex.EncodeToString(hash[:])}
+
+		for {
+			now := m.now()
+			record.LockedUntil, record.ExpiresAt = now.Add(lockTimeout), now.Add(m.ttl)
+
+			existing, err := m.store.AcquireIdempotencyKey(r.Context(), record, now)
+			if err != nil {
+				utils.ProcessError(w, err)
+				return
+			}
+
+			switch {
+			case existing == nil:
+				m.serve(w, r, next, record)
+				return
+			case existing.RequestHash != record.RequestHash:
+				utils.ProcessError(w, &utils.InternalError{Code: http.StatusUnprocessableEntity,
+					Message: "idempotency key was used with a different request", Reason: "idempotency_key_reused"})
+				return
+			case existing.Status != 0:
+				replay(w, existing)
+				return
+			}
+
+			select {
+			case <-r.Context().Done():
+				utils.ProcessError(w, r.Context().Err())
+				return
+			case <-time.After(m.pollInterval):
+			}
+		}
+	})
+}
+
+// serve passes the request holding the key of record to next and stores the
+// response, or releases the key when next fails.
+func (m *Middleware) serve(w http.ResponseWriter, r *http.Request, next http.Handler,
+	record *models.IdempotencyRecord) {
+	// the response is stored even if the client is gone
+	ctx := context.WithoutCancel(r.Context())
+
+	rec := &responseRecorder{ResponseWriter: w}
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if err := m.store.ReleaseIdempotencyKey(ctx, record, m.now()); err != nil {
+			log.Printf("error while releasing idempotency key: %v", err)
+		}
+	}()
+
+	next.ServeHTTP(rec, r)
+
+	if rec.status == 0 || rec.status >= http.StatusInternalServerError {
+		return
+	}
+	record.Status, record.ContentType, record.Body = rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes()
+	if err := m.store.CompleteIdempotencyKey(ctx, record); err != nil {
+		log.Printf("error while storing idempotent response: %v", err)
+		return
+	}
+	completed = true
+}
+
+func replay(w http.ResponseWriter, record *models.IdempotencyRecord) {
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	w.Write(record.Body)
+}
+
+// responseRecorder passes a response through, keeping its status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(data []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	rr.body.Write(data)
+	return rr.ResponseWriter.Write(data)
+}
+
+// Purge deletes the expired records every interval until ctx is done.
+func (m *Middleware) Purge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := m.store.DeleteExpiredIdempotencyKeys(ctx, m.now())
+			if err != nil {
+				log.Printf("error while purging idempotency keys: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("%d expired idempotency keys purged", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/repository/local"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMiddleware() *Middleware {
+	res := NewMiddleware(local.NewUrlRepository(), time.Hour)
+	res.pollInterval = time.Millisecond
+	return res
+}
+
+var testAuthenticator = auth.NewAuthenticator([]bootstrap.ApiKey{
+	{Name: "runner", Key: "secret-1"},
+	{Name: "other", Key: "secret-2"},
+	{Key: "secret-3"},
+	{Key: "secret-4"},
+}, nil)
+
+// authenticated passes the requests with an Authorization header through the
+// authentication middleware, as the server does, and the others to next.
+func authenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		testAuthenticator.Middleware(next).ServeHTTP(w, r)
+	})
+}
+
+// countingHandler responds with the number of requests it served and the
+// body of the request, failing with 500 when the body is "fail".
+func countingHandler(calls *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "fail" {
+			utils.ProcessInternalServerError(w, "failed")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"call":%d,"body":%q}`, n, body)
+	})
+}
+
+func send(handler http.Handler, key, authorization, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/links", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(Header, key)
+	}
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+
+	var calls atomic.Int32
+	handler := authenticated(newTestMiddleware().Handler(countingHandler(&calls)))
+
+	w := send(handler, "key-1", "Bearer secret-1", "a")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"call":1,"body":"a"}`, w.Body.String())
+	assert.Empty(t, w.Header().Get(ReplayedHeader))
+
+	w = send(handler, "key-1", "Bearer secret-1", "a")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"call":1,"body":"a"}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(ReplayedHeader))
+
+	w = send(handler, "key-1", "Bearer secret-1", "b")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	problem := &utils.Problem{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(problem))
+	assert.Equal(t, "idempotency_key_reused", problem.Reason)
+
+	// keys are scoped to API keys
+	w = send(handler, "key-1", "Bearer secret-2", "b")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"call":2,"body":"b"}`, w.Body.String())
+
+	w = send(handler, "key-1", "", "c")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"call":3,"body":"c"}`, w.Body.String())
+
+	// requests without keys are not deduplicated
+	send(handler, "", "", "c")
+	w = send(handler, "", "", "c")
+	assert.JSONEq(t, `{"call":5,"body":"c"}`, w.Body.String())
+
+	assert.Equal(t, int32(5), calls.Load())
+}
+
+func TestMiddlewareServerError(t *testing.T) {
+
+	var calls atomic.Int32
+	handler := newTestMiddleware().Handler(countingHandler(&calls))
+
+	w := send(handler, "key-1", "", "fail")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = send(handler, "key-1", "", "fail")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get(ReplayedHeader))
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestMiddlewareConcurrentDuplicates(t *testing.T) {
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := newTestMiddleware().Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, `{"shortened_url":"http://localhost:8080/Abc_def_qA"}`)
+	}))
+
+	const duplicates = 5
+	responses := make([]*httptest.ResponseRecorder, duplicates)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		responses[0] = send(handler, "key-1", "", "a")
+	}()
+	<-started
+
+	for i := 1; i < duplicates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = send(handler, "key-1", "", "a")
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, w := range responses {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"shortened_url":"http://localhost:8080/Abc_def_qA"}`, w.Body.String())
+	}
+}
+
+func TestMiddlewareExpiredKey(t *testing.T) {
+
+	var calls atomic.Int32
+	m := newTestMiddleware()
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	handler := m.Handler(countingHandler(&calls))
+
+	send(handler, "key-1", "", "a")
+	now = now.Add(time.Hour)
+	w := send(handler, "key-1", "", "b")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"call":2,"body":"b"}`, w.Body.String())
+}
+
+func TestMiddlewareRejects(t *testing.T) {
+
+	var calls atomic.Int32
+	handler := newTestMiddleware().Handler(countingHandler(&calls))
+
+	w := send(handler, strings.Repeat("k", maxKeyLength+1), "", "a")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send(handler, "key-1", "", strings.Repeat("a", maxBodySize+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+func TestMiddlewareUnnamedKeys(t *testing.T) {
+
+	var calls atomic.Int32
+	handler := authenticated(newTestMiddleware().Handler(countingHandler(&calls)))
+
+	// keys without names are scoped apart from each other and from requests
+	// without keys
+	w := send(handler, "key-1", "Bearer secret-3", "a")
+	assert.JSONEq(t, `{"call":1,"body":"a"}`, w.Body.String())
+	w = send(handler, "key-1", "Bearer secret-4", "a")
+	assert.JSONEq(t, `{"call":2,"body":"a"}`, w.Body.String())
+	assert.Empty(t, w.Header().Get(ReplayedHeader))
+	w = send(handler, "key-1", "", "a")
+	assert.JSONEq(t, `{"call":3,"body":"a"}`, w.Body.String())
+
+	w = send(handler, "key-1", "Bearer secret-3", "a")
+	assert.JSONEq(t, `{"call":1,"body":"a"}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(ReplayedHeader))
+}
+
+func TestMiddlewarePrincipalFromContext(t *testing.T) {
+
+	var calls atomic.Int32
+	handler := newTestMiddleware().Handler(countingHandler(&calls))
+
+	// the principal set by the authentication middleware is used as is, the
+	// Authorization header is not checked again
+	principal := &auth.Principal{KeyName: "ws/deploy", KeyDigest: auth.Digest("workspace-key"), Owner: "team"}
+	for range 2 {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/links", strings.NewReader("a"))
+		r.Header.Set(Header, "key-1")
+		r.Header.Set("Authorization", "Bearer unknown")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"call":1,"body":"a"}`, w.Body.String())
+	}
+
+	w := send(handler, "key-1", "", "a")
+	assert.JSONEq(t, `{"call":2,"body":"a"}`, w.Body.String())
+}
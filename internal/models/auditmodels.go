@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry records a change made through the API: who made it, from where,
+// and the affected values before and after it. Entries are chained by their
+// hashes, each covering the hash of the previous entry, so editing, removing
+// or reordering entries breaks the chain.
+type AuditEntry struct {
+	ID       int64           `json:"id"`
+	Time     time.Time       `json:"time"`
+	Actor    string          `json:"actor"`
+	SourceIP string          `json:"source_ip,omitempty"`
+	Action   string          `json:"action"`
+	Target   string          `json:"target"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+}
+
+// AuditFilter selects audit entries; entries are listed newest first unless
+// Order is asc. Cursor is the id of the last entry of the previous page.
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Target string
+	From   *time.Time
+	To     *time.Time
+	Order  string `validate:"omitempty,oneof=asc desc"`
+	Cursor int64  `validate:"min=0"`
+	Limit  int    `validate:"omitempty,min=1,max=1000"`
+}
+
+type AuditList struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor int64        `json:"next_cursor,omitempty"`
+}
+
+// AuditVerification is the result of checking the hash chain of the audit
+// log. BrokenAt is the id of the first entry not matching the chain.
+type AuditVerification struct {
+	Valid    bool  `json:"valid"`
+	Checked  int   `json:"checked"`
+	BrokenAt int64 `json:"broken_at,omitempty"`
+}
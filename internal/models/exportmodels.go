@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ExportRecord is one link of an export. Its fields are a superset of
+// ImportRecord, the code being the short code of the link.
+type ExportRecord struct {
+	Code       string    `json:"code"`
+	Url        string    `json:"url"`
+	Owner      string    `json:"owner,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	Clicks     int64     `json:"clicks"`
+	ClicksLeft *int      `json:"clicks_left,omitempty"`
+	ActivationWindow
+	QueryOptions
+	Rules        []TargetingRule `json:"rules,omitempty"`
+	Destinations []Destination   `json:"destinations,omitempty"`
+	Interstitial bool            `json:"interstitial,omitempty"`
+	LinkMetadata
+}
+
+// ExportOptions control an export of the links matching Filter, oldest
+// first. The sort, order, cursor and limit of Filter are ignored.
+type ExportOptions struct {
+	Format string `validate:"required,oneof=csv jsonl"`
+	Gzip   bool
+	Filter LinkFilter
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// IdempotencyRecord is the response to a request sent with an idempotency
+// key, kept to be replayed for retries of the request. Keys are unique within
+// a scope, the API key of the requests. Status is zero while the first request
+// is in progress; if it takes longer than LockedUntil, a retry takes the key
+// over. Records are kept until ExpiresAt.
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	RequestHash string
+	Status      int
+	ContentType string
+	Body        []byte
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// ImportRecord is one row of a bulk import. Code is kept as the short code
+// of the imported link.
+type ImportRecord struct {
+	Code      string     `json:"code" validate:"required,max=64"`
+	Url       string     `json:"url" validate:"required"`
+	Owner     string     `json:"owner,omitempty" validate:"max=255"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	LinkMetadata
+}
+
+// ImportOptions control a bulk import. Rows starting before StartLine are
+// skipped, so an interrupted import can be resumed after the last line it
+// reported. A non-empty Owner is assigned to every imported link instead of
+// the owners of the rows.
+type ImportOptions struct {
+	Format    string `validate:"required,oneof=csv jsonl"`
+	DryRun    bool
+	StartLine int `validate:"min=0"`
+	Owner     string
+}
+
+// ImportError describes a row that was not imported.
+type ImportError struct {
+	Line    int    `json:"line"`
+	Code    string `json:"code,omitempty"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// ImportReport summarizes a bulk import. Every row up to LastLine has been
+// either imported or reported as an error.
+type ImportReport struct {
+	DryRun          bool          `json:"dry_run,omitempty"`
+	Processed       int           `json:"processed"`
+	Imported        int           `json:"imported"`
+	Conflicts       int           `json:"conflicts"`
+	Invalid         int           `json:"invalid"`
+	OverQuota       int           `json:"over_quota"`
+	LastLine        int           `json:"last_line"`
+	Errors          []ImportError `json:"errors,omitempty"`
+	ErrorsTruncated bool          `json:"errors_truncated,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// StoredLink is a link together with its click counters, as copied between
+// storage backends. Clicks of UrlData is the total, VariantClicks counts the
+// clicks of each destination of a split link.
+type StoredLink struct {
+	UrlData
+	VariantClicks map[int]int64
+}
+
+// MigrationProgress counts the links a migration went through so far. Links
+// whose short url is already taken in the target are skipped.
+type MigrationProgress struct {
+	Processed int
+	Copied    int
+	Skipped   int
+}
+
+// MigrationCheck compares the links of the source of a migration with the
+// same links in the target. Checksums cover every field and counter of the
+// links; MissingCodes and MismatchedCodes list a few of the differing links.
+type MigrationCheck struct {
+	SourceLinks     int
+	TargetLinks     int
+	Missing         int
+	Mismatched      int
+	SourceChecksum  string
+	TargetChecksum  string
+	MissingCodes    []string
+	MismatchedCodes []string
+}
@@ -1,14 +1,202 @@
 package models
 
+import (
+	"net/url"
+	"time"
+)
+
+type ActivationWindow struct {
+	NotBefore   *time.Time `json:"not_before,omitempty"`
+	NotAfter    *time.Time `json:"not_after,omitempty"`
+	FallbackUrl string     `json:"fallback_url,omitempty" validate:"omitempty,url"`
+}
+
+// TargetingRule redirects visitors matching every non-empty condition to
+// TargetUrl. An empty value in Query only requires the parameter to be present.
+type TargetingRule struct {
+	OS        string            `json:"os,omitempty" validate:"omitempty,oneof=ios android windows macos linux"`
+	Device    string            `json:"device,omitempty" validate:"omitempty,oneof=mobile tablet desktop"`
+	Language  string            `json:"language,omitempty"`
+	Query     map[string]string `json:"query,omitempty"`
+	TargetUrl string            `json:"target_url" validate:"required,url"`
+}
+
+// QueryOptions controls query parameters added to the destination on
+// resolution. Parameters already present in the destination are kept as is.
+type QueryOptions struct {
+	UtmSource   string `json:"utm_source,omitempty"`
+	UtmMedium   string `json:"utm_medium,omitempty"`
+	UtmCampaign string `json:"utm_campaign,omitempty"`
+	PassQuery   bool   `json:"pass_query,omitempty"`
+}
+
+// LinkMetadata describes a link for the people managing it and is never shown
+// to visitors.
+type LinkMetadata struct {
+	Title       string   `json:"title,omitempty" validate:"max=255"`
+	Description string   `json:"description,omitempty" validate:"max=2000"`
+	Tags        []string `json:"tags,omitempty" validate:"max=50,dive,required,max=64"`
+	Notes       string   `json:"notes,omitempty" validate:"max=10000"`
+}
+
+// LinkFilter selects and orders links for the listing. Tag matches
+// case-insensitively, Title is a case-insensitive substring and Search matches
+// whole words of the destination and the title. Host is the exact destination
+// host, while Domain also matches its subdomains. CreatedFrom is inclusive,
+// CreatedTo exclusive.
+type LinkFilter struct {
+	Owner       string
+	Domain      string
+	Host        string
+	Tag         string
+	Title       string
+	Search      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        string `validate:"omitempty,oneof=created_at clicks"`
+	Order       string `validate:"omitempty,oneof=asc desc"`
+	Cursor      string
+	After       *LinkCursor
+	Limit       int `validate:"omitempty,min=1,max=1000"`
+}
+
+// LinkCursor is the position of the last link of a listing page, the next
+// page starting right after it. It is only valid for the order it was
+// issued for.
+type LinkCursor struct {
+	Sort      string    `json:"s"`
+	Order     string    `json:"o"`
+	CreatedAt time.Time `json:"t"`
+	Clicks    int64     `json:"c"`
+	ShortUrl  string    `json:"u"`
+}
+
+// Destination is one variant of an A/B split link, served to a share of
+// visitors proportional to its weight.
+type Destination struct {
+	Url    string `json:"url" validate:"required,url"`
+	Weight int    `json:"weight" validate:"min=1,max=10000"`
+}
+
 type UrlData struct {
 	OriginalUrl string
 	ShortUrl    string
+	ClicksLeft  *int
+	ActivationWindow
+	QueryOptions
+	Rules        []TargetingRule
+	Destinations []Destination
+	Interstitial bool
+	Owner        string
+	CreatedAt    time.Time
+	Clicks       int64
+	// DeletedAt is set on the tombstones of deleted links, which keep their
+	// codes until purged.
+	DeletedAt *time.Time
+	LinkMetadata
 }
 
 type OrigUrlData struct {
 	OriginalUrl string `json:"original_url" validate:"required"`
+	MaxClicks   int    `json:"max_clicks,omitempty" validate:"omitempty,min=1"`
+	ActivationWindow
+	QueryOptions
+	Rules        []TargetingRule `json:"rules,omitempty" validate:"dive"`
+	Destinations []Destination   `json:"destinations,omitempty" validate:"omitempty,min=2,dive"`
+	Interstitial bool            `json:"interstitial,omitempty"`
+	Owner        string          `json:"owner,omitempty" validate:"max=255"`
+	Qr           *QrOptions      `json:"qr,omitempty"`
+	LinkMetadata
+}
+
+type RulesData struct {
+	Rules []TargetingRule `json:"rules" validate:"dive"`
+}
+
+// VisitorData describes the request a short link is being resolved for.
+type VisitorData struct {
+	UserAgent      string
+	AcceptLanguage string
+	Query          url.Values
+	VisitorID      string
+}
+
+// Redirect is the result of resolving a short link. Interstitial links are
+// shown on a page counting down Delay seconds before following Url.
+type Redirect struct {
+	Url          string
+	Interstitial bool
+	Delay        int
+}
+
+// PreviewData is shown on the preview page of a link instead of redirecting.
+type PreviewData struct {
+	ShortUrl    string
+	OriginalUrl string
+	Host        string
+	CreatedAt   time.Time
+	Clicks      int64
+}
+
+// ClickData counts resolutions of a link, in total and per served
+// destination index.
+type ClickData struct {
+	Clicks        int64
+	VariantClicks map[int]int64
+}
+
+type VariantStats struct {
+	Url    string `json:"url"`
+	Weight int    `json:"weight"`
+	Clicks int64  `json:"clicks"`
+}
+
+type LinkStats struct {
+	ShortUrl string         `json:"shortened_url"`
+	Clicks   int64          `json:"clicks"`
+	Variants []VariantStats `json:"variants,omitempty"`
 }
 
 type ShortUrlData struct {
 	ShortUrl string `json:"shortened_url"`
+	QrCode   string `json:"qr_code,omitempty"`
+}
+
+// QrOptions configure QR code rendering. Zero values are replaced with
+// defaults: a 256px PNG with medium error correction, a 4 module margin and
+// black on white. Colours are #rgb or #rrggbb, the # being optional.
+type QrOptions struct {
+	Format     string `json:"format,omitempty" validate:"omitempty,oneof=png svg"`
+	Size       int    `json:"size,omitempty" validate:"omitempty,min=32,max=4096"`
+	Level      string `json:"level,omitempty" validate:"omitempty,oneof=L M Q H"`
+	Margin     *int   `json:"margin,omitempty" validate:"omitempty,min=0,max=32"`
+	Foreground string `json:"foreground,omitempty"`
+	Background string `json:"background,omitempty"`
+}
+
+type QrCode struct {
+	ContentType string
+	Data        []byte
+}
+
+type LinkData struct {
+	ShortUrl    string `json:"shortened_url"`
+	OriginalUrl string `json:"original_url"`
+	ClicksLeft  *int   `json:"clicks_left,omitempty"`
+	ActivationWindow
+	QueryOptions
+	Rules        []TargetingRule `json:"rules,omitempty"`
+	Destinations []Destination   `json:"destinations,omitempty"`
+	Interstitial bool            `json:"interstitial,omitempty"`
+	Owner        string          `json:"owner,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Clicks       int64           `json:"clicks"`
+	LinkMetadata
+}
+
+// LinkList is a page of the link listing. NextCursor is empty on the last
+// page.
+type LinkList struct {
+	Links      []LinkData `json:"links"`
+	NextCursor string     `json:"next_cursor,omitempty"`
 }
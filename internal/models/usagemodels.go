@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Usage counts what an owner uses: its links that are not deleted, and the
+// links it created and the resolutions of its links in Month.
+type Usage struct {
+	Owner       string
+	Month       time.Time
+	ActiveLinks int64
+	Creations   int64
+	Resolutions int64
+}
+
+// Quota caps the usage of an owner. Zero values mean no limit.
+type Quota struct {
+	MaxActiveLinks     int64 `json:"max_active_links,omitempty"`
+	MonthlyCreations   int64 `json:"monthly_creations,omitempty"`
+	MonthlyResolutions int64 `json:"monthly_resolutions,omitempty"`
+}
+
+// UsageReport is the usage of an owner in the current month, formatted as
+// YYYY-MM, along with its quota.
+type UsageReport struct {
+	Owner       string `json:"owner"`
+	Month       string `json:"month"`
+	ActiveLinks int64  `json:"active_links"`
+	Creations   int64  `json:"creations"`
+	Resolutions int64  `json:"resolutions"`
+	Quota       Quota  `json:"quota"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscription delivers the events of the listed types to Url, signed
+// with Secret. Subscriptions of an owner receive the events of its links,
+// subscriptions without an owner the events of every link. Events of the
+// link.clicks_threshold type are only sent for the listed ClickThresholds.
+type WebhookSubscription struct {
+	ID              int64     `json:"id"`
+	Url             string    `json:"url" validate:"required,url,max=2048"`
+	Secret          string    `json:"secret,omitempty" validate:"omitempty,min=16,max=255"`
+	Events          []string  `json:"events" validate:"required,min=1,dive,oneof=link.created link.updated link.deleted link.restored link.expired link.clicks_threshold"`
+	ClickThresholds []int64   `json:"click_thresholds,omitempty" validate:"max=20,dive,min=1"`
+	Owner           string    `json:"owner,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type WebhookList struct {
+	Webhooks []WebhookSubscription `json:"webhooks"`
+}
+
+// WebhookEvent is the payload of a webhook. Link is the created link, Changes
+// the updated fields of a link, Reason why a link expired: click_limit or
+// not_after, and Clicks the click threshold a link reached.
+type WebhookEvent struct {
+	ID        string        `json:"id"`
+	Type      string        `json:"type"`
+	CreatedAt time.Time     `json:"created_at"`
+	Code      string        `json:"code"`
+	Owner     string        `json:"owner,omitempty"`
+	Link      *ExportRecord `json:"link,omitempty"`
+	Changes   *LinkChanges  `json:"changes,omitempty"`
+	Reason    string        `json:"reason,omitempty"`
+	Clicks    int64         `json:"clicks,omitempty"`
+}
+
+// LinkChanges lists the updated fields of a link with their new values, which
+// are omitted when they were cleared.
+type LinkChanges struct {
+	Fields   []string          `json:"fields"`
+	Window   *ActivationWindow `json:"window,omitempty"`
+	Rules    []TargetingRule   `json:"rules,omitempty"`
+	Metadata *LinkMetadata     `json:"metadata,omitempty"`
+}
+
+// WebhookDelivery is an event waiting in the outbox to be delivered to a
+// subscription. Failed deliveries are retried at NextAttemptAt until they
+// run out of attempts and become dead letters.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	Url            string          `json:"url"`
+	Secret         string          `json:"-"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastError      string          `json:"last_error,omitempty"`
+	Dead           bool            `json:"-"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type DeadLetterList struct {
+	DeadLetters []WebhookDelivery `json:"dead_letters"`
+}
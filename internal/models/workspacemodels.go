@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Workspace is a team owning links: the owner of a link is the slug of its
+// workspace. Members of a workspace act in it with their role, through the
+// API keys issued to them.
+type Workspace struct {
+	Slug      string    `json:"slug" validate:"required,max=255"`
+	Name      string    `json:"name,omitempty" validate:"max=255"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspaceList struct {
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+// Member is a member of a workspace with a role: viewer, editor or admin.
+type Member struct {
+	Workspace string    `json:"-"`
+	Name      string    `json:"name" validate:"required,max=255"`
+	Role      string    `json:"role" validate:"required,oneof=viewer editor admin"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MemberList struct {
+	Members []Member `json:"members"`
+}
+
+type RoleData struct {
+	Role string `json:"role" validate:"required,oneof=viewer editor admin"`
+}
+
+// WorkspaceKey is an API key issued to a member of a workspace, acting with
+// the current role of the member. Only the digest of the key is stored; the
+// key itself is only returned when created.
+type WorkspaceKey struct {
+	ID        int64     `json:"id"`
+	Workspace string    `json:"-"`
+	Name      string    `json:"name" validate:"required,max=255"`
+	Member    string    `json:"member" validate:"required,max=255"`
+	Role      string    `json:"role,omitempty"`
+	Key       string    `json:"key,omitempty"`
+	Digest    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspaceKeyList struct {
+	Keys []WorkspaceKey `json:"keys"`
+}
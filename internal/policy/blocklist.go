@@ -0,0 +1,122 @@
+package policy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultReloadInterval = 30 * time.Second
+
+// Blocklist rejects destinations on domains listed in a file, one domain per
+// line, blocking its subdomains as well. Blank lines and lines starting with #
+// are skipped. The file is re-read when its modification time changes, checked
+// at most once per reload interval, so it can be updated without a restart.
+type Blocklist struct {
+	path     string
+	interval time.Duration
+
+	mu        sync.RWMutex
+	domains   map[string]bool
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewBlocklist loads the blocklist from path. A zero interval means 30 seconds.
+func NewBlocklist(path string, interval time.Duration) (*Blocklist, error) {
+	if interval == 0 {
+		interval = defaultReloadInterval
+	}
+
+	bl := &Blocklist{path: path, interval: interval}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("policy.NewBlocklist: %w", err)
+	}
+	if err := bl.load(info.ModTime()); err != nil {
+		return nil, err
+	}
+	bl.checkedAt = time.Now()
+
+	return bl, nil
+}
+
+func (bl *Blocklist) load(modTime time.Time) error {
+	file, err := os.Open(bl.path)
+	if err != nil {
+		return fmt.Errorf("policy.Blocklist.load: %w", err)
+	}
+	defer file.Close()
+
+	domains := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains[normalizeHost(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("policy.Blocklist.load: %w", err)
+	}
+
+	bl.mu.Lock()
+	bl.domains, bl.modTime = domains, modTime
+	bl.mu.Unlock()
+
+	return nil
+}
+
+// reload re-reads the file if it changed. Failures keep the previous list,
+// so a half-written or deleted file never disables the blocklist.
+func (bl *Blocklist) reload() {
+	bl.mu.Lock()
+	if time.Since(bl.checkedAt) < bl.interval {
+		bl.mu.Unlock()
+		return
+	}
+	bl.checkedAt = time.Now()
+	modTime := bl.modTime
+	bl.mu.Unlock()
+
+	info, err := os.Stat(bl.path)
+	if err != nil {
+		log.Printf("error while reloading blocklist: %v", err)
+		return
+	}
+	if info.ModTime().Equal(modTime) {
+		return
+	}
+	if err := bl.load(info.ModTime()); err != nil {
+		log.Printf("error while reloading blocklist: %v", err)
+	}
+}
+
+func (bl *Blocklist) Check(ctx context.Context, u *url.URL) error {
+	bl.reload()
+
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+
+	// walk up the labels, so listing a domain blocks its subdomains
+	host := normalizeHost(u.Hostname())
+	for host != "" {
+		if bl.domains[host] {
+			return &Violation{Reason: ReasonBlocked}
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+		host = parent
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checkUrl(t *testing.T, checker Checker, rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	assert.NoError(t, err)
+	return checker.Check(context.Background(), u)
+}
+
+func TestBlocklist(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("# phishing\nEvil.example\n\n  scam.example.org  \n"), 0o644))
+
+	bl, err := NewBlocklist(path, time.Nanosecond)
+	assert.NoError(t, err)
+
+	blocked := &Violation{Reason: ReasonBlocked}
+
+	assert.Equal(t, blocked, checkUrl(t, bl, "http://evil.example/login"))
+	assert.Equal(t, blocked, checkUrl(t, bl, "http://login.EVIL.example./"))
+	assert.Equal(t, blocked, checkUrl(t, bl, "https://scam.example.org/"))
+	assert.NoError(t, checkUrl(t, bl, "https://example.org/"))
+	assert.NoError(t, checkUrl(t, bl, "https://notevil.example/"))
+
+	// the file is reloaded once its modification time changes
+	assert.NoError(t, os.WriteFile(path, []byte("example.org\n"), 0o644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	assert.NoError(t, checkUrl(t, bl, "http://evil.example/login"))
+	assert.Equal(t, blocked, checkUrl(t, bl, "https://www.example.org/"))
+
+	// a missing file keeps the last loaded list
+	assert.NoError(t, os.Remove(path))
+	assert.Equal(t, blocked, checkUrl(t, bl, "https://www.example.org/"))
+}
+
+func TestBlocklistReloadInterval(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("evil.example\n"), 0o644))
+
+	bl, err := NewBlocklist(path, time.Hour)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(path, []byte(""), 0o644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	assert.Equal(t, &Violation{Reason: ReasonBlocked}, checkUrl(t, bl, "http://evil.example/"))
+}
+
+func TestNewBlocklistMissingFile(t *testing.T) {
+
+	_, err := NewBlocklist(filepath.Join(t.TempDir(), "missing.txt"), 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package policy
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+)
+
+// Reason codes of rejected destinations.
+const (
+	ReasonScheme         = "scheme_not_allowed"
+	ReasonBlocked        = "blocked_domain"
+	ReasonPrivateAddress = "private_address"
+	ReasonShortener      = "chained_shortener"
+	ReasonSelfReference  = "self_reference"
+)
+
+// Checker decides whether links may point to a destination. It returns a
+// *Violation for rejected destinations; any other error means the check
+// itself failed.
+type Checker interface {
+	Check(ctx context.Context, u *url.URL) error
+}
+
+// Violation is returned by checkers for destinations that are not allowed.
+type Violation struct {
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return "destination is not allowed: " + v.Reason
+}
+
+var defaultSchemes = []string{"http", "https"}
+
+var defaultShorteners = []string{
+	"bit.ly", "bitly.com", "tinyurl.com", "t.co", "goo.gl", "ow.ly", "is.gd", "buff.ly",
+	"rebrand.ly", "cutt.ly", "shorturl.at", "tiny.cc", "rb.gy", "v.gd", "clck.ru",
+}
+
+// Policy is the built-in destination policy. It allows only the configured
+// schemes, rejects IP literals of private, loopback and link-local ranges,
+// links to other shorteners and to the service itself, then consults the
+// additional checkers in order.
+type Policy struct {
+	schemes    map[string]bool
+	ownDomains []string
+	shorteners []string
+	checkers   []Checker
+}
+
+// New builds the policy from cfg. ownHost is the host short links are served
+// from; it is treated as one of cfg.OwnDomains.
+func New(cfg *bootstrap.Policy, ownHost string, checkers ...Checker) *Policy {
+	schemes := cfg.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = defaultSchemes
+	}
+	shorteners := cfg.Shorteners
+	if shorteners == nil {
+		shorteners = defaultShorteners
+	}
+
+	p := &Policy{schemes: make(map[string]bool, len(schemes)), checkers: checkers}
+	for _, scheme := range schemes {
+		p.schemes[strings.ToLower(scheme)] = true
+	}
+	for _, domain := range append([]string{ownHost}, cfg.OwnDomains...) {
+		if domain = normalizeHost(domain); domain != "" {
+			p.ownDomains = append(p.ownDomains, domain)
+		}
+	}
+	for _, domain := range shorteners {
+		if domain = normalizeHost(domain); domain != "" {
+			p.shorteners = append(p.shorteners, domain)
+		}
+	}
+	return p
+}
+
+func (p *Policy) Check(ctx context.Context, u *url.URL) error {
+	if !p.schemes[strings.ToLower(u.Scheme)] {
+		return &Violation{Reason: ReasonScheme}
+	}
+
+	host := normalizeHost(u.Hostname())
+
+	if ip := parseIP(host); ip != nil {
+		if PrivateAddress(ip) {
+			return &Violation{Reason: ReasonPrivateAddress}
+		}
+	} else if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return &Violation{Reason: ReasonPrivateAddress}
+	}
+
+	if matchAny(host, p.ownDomains) {
+		return &Violation{Reason: ReasonSelfReference}
+	}
+	if matchAny(host, p.shorteners) {
+		return &Violation{Reason: ReasonShortener}
+	}
+
+	for _, checker := range p.checkers {
+		if err := checker.Check(ctx, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrivateAddress reports whether ip is in a private, loopback or link-local
+// range, or unspecified.
+func PrivateAddress(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// matchDomain reports whether host is domain or one of its subdomains.
+func matchDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func matchAny(host string, domains []string) bool {
+	for _, domain := range domains {
+		if matchDomain(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIP parses an IP literal, including the legacy IPv4 forms browsers
+// still accept, such as 2130706433, 0x7f.1 or 0177.0.0.1.
+func parseIP(host string) net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip
+	}
+
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+
+	var addr uint64
+	for i, part := range parts {
+		// the last part fills all the remaining bytes of the address
+		bits := 8
+		if i == len(parts)-1 {
+			bits = 8 * (4 - i)
+		}
+		// base 0 would also accept 0b, 0o and underscores, which browsers don't
+		lower := strings.ToLower(part)
+		if strings.Contains(part, "_") || strings.HasPrefix(lower, "0b") || strings.HasPrefix(lower, "0o") {
+			return nil
+		}
+		value, err := strconv.ParseUint(part, 0, bits)
+		if err != nil {
+			return nil
+		}
+		addr = addr<<bits | value
+	}
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr))
+}
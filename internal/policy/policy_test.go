@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/stretchr/testify/assert"
+)
+
+type checkerFunc func(ctx context.Context, u *url.URL) error
+
+func (f checkerFunc) Check(ctx context.Context, u *url.URL) error {
+	return f(ctx, u)
+}
+
+func TestPolicyCheck(t *testing.T) {
+
+	errThreatDB := errors.New("threat db is unavailable")
+
+	threats := checkerFunc(func(ctx context.Context, u *url.URL) error {
+		switch u.Hostname() {
+		case "malware.example":
+			return &Violation{Reason: "malware"}
+		case "unknown.example":
+			return errThreatDB
+		}
+		return nil
+	})
+
+	p := New(&bootstrap.Policy{OwnDomains: []string{"sho.rt"}}, "localhost", threats)
+
+	tests := []struct {
+		Name      string
+		Url       string
+		ExpectErr error
+	}{
+		{Name: "http", Url: "http://example.com/path", ExpectErr: nil},
+		{Name: "https with port", Url: "https://example.com:8443/", ExpectErr: nil},
+		{Name: "uppercase scheme", Url: "HTTPS://example.com/", ExpectErr: nil},
+		{Name: "javascript", Url: "javascript:alert(1)", ExpectErr: &Violation{Reason: ReasonScheme}},
+		{Name: "file", Url: "file:///etc/passwd", ExpectErr: &Violation{Reason: ReasonScheme}},
+		{Name: "data", Url: "data:text/html,<script>", ExpectErr: &Violation{Reason: ReasonScheme}},
+		{Name: "ftp", Url: "ftp://example.com/", ExpectErr: &Violation{Reason: ReasonScheme}},
+		{Name: "public ip", Url: "http://93.184.216.34/", ExpectErr: nil},
+		{Name: "loopback", Url: "http://127.0.0.1:8080/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "private 10/8", Url: "http://10.1.2.3/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "private 192.168/16", Url: "http://192.168.0.1/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "private 172.16/12", Url: "http://172.20.0.1/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "link-local metadata", Url: "http://169.254.169.254/latest", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "unspecified", Url: "http://0.0.0.0/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "ipv6 loopback", Url: "http://[::1]/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "ipv6 unique local", Url: "http://[fd00::1]/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "ipv4-mapped loopback", Url: "http://[::ffff:127.0.0.1]/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "decimal loopback", Url: "http://2130706433/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "hex loopback", Url: "http://0x7f.1/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "octal loopback", Url: "http://0177.0.0.1/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "localhost name", Url: "http://localhost:3000/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "localhost subdomain", Url: "http://app.localhost/", ExpectErr: &Violation{Reason: ReasonPrivateAddress}},
+		{Name: "self reference", Url: "https://sho.rt/Abc_def_gs", ExpectErr: &Violation{Reason: ReasonSelfReference}},
+		{Name: "self reference subdomain", Url: "https://WWW.Sho.rt./Abc_def_gs", ExpectErr: &Violation{Reason: ReasonSelfReference}},
+		{Name: "similar domain is not self", Url: "https://notsho.rt/", ExpectErr: nil},
+		{Name: "chained shortener", Url: "https://bit.ly/abc", ExpectErr: &Violation{Reason: ReasonShortener}},
+		{Name: "chained shortener subdomain", Url: "https://www.tinyurl.com/abc", ExpectErr: &Violation{Reason: ReasonShortener}},
+		{Name: "numeric-looking domain", Url: "http://1.2.3.com/", ExpectErr: nil},
+		{Name: "checker violation", Url: "http://malware.example/", ExpectErr: &Violation{Reason: "malware"}},
+		{Name: "checker failure", Url: "http://unknown.example/", ExpectErr: errThreatDB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			u, err := url.Parse(tt.Url)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.ExpectErr, p.Check(context.Background(), u))
+		})
+	}
+}
+
+func TestPolicyConfig(t *testing.T) {
+
+	p := New(&bootstrap.Policy{AllowedSchemes: []string{"HTTPS", "tg"}, Shorteners: []string{"sho.rt"}}, "")
+
+	for rawUrl, expectErr := range map[string]error{
+		"http://example.com/":   &Violation{Reason: ReasonScheme},
+		"https://example.com/":  nil,
+		"tg://resolve?domain=a": nil,
+		"https://bit.ly/abc":    nil,
+		"https://sho.rt/abc":    &Violation{Reason: ReasonShortener},
+	} {
+		u, err := url.Parse(rawUrl)
+		assert.NoError(t, err)
+		assert.Equal(t, expectErr, p.Check(context.Background(), u), rawUrl)
+	}
+}
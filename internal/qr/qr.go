@@ -0,0 +1,215 @@
+package qr
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// Options describe how a QR code is rendered. Size is the width and height of
+// the image in pixels, Margin the quiet zone around the code in modules.
+type Options struct {
+	Format     string
+	Size       int
+	Level      string
+	Margin     int
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+// ParseColor parses a #rgb or #rrggbb colour, the leading # being optional.
+func ParseColor(value string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid colour %q", value)
+	}
+
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid colour %q", value)
+	}
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, nil
+}
+
+// Generator renders QR codes and keeps the most recently rendered ones in an
+// LRU cache, since the same printed links are requested over and over.
+type Generator struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	cache    map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key  string
+	data []byte
+}
+
+func NewGenerator(capacity int) *Generator {
+	return &Generator{capacity: capacity, order: list.New(), cache: make(map[string]*list.Element)}
+}
+
+func (g *Generator) Generate(content string, opts *Options) ([]byte, error) {
+	key := fmt.Sprintf("%s|%d|%s|%d|%v|%v|%s", opts.Format, opts.Size, opts.Level, opts.Margin,
+		opts.Foreground, opts.Background, content)
+
+	if data, ok := g.get(key); ok {
+		return data, nil
+	}
+
+	data, err := render(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	g.put(key, data)
+	return data, nil
+}
+
+func (g *Generator) get(key string) ([]byte, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	elem, ok := g.cache[key]
+	if !ok {
+		return nil, false
+	}
+	g.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).data, true
+}
+
+func (g *Generator) put(key string, data []byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.capacity <= 0 {
+		return
+	}
+	if elem, ok := g.cache[key]; ok {
+		g.order.MoveToFront(elem)
+		return
+	}
+
+	g.cache[key] = g.order.PushFront(&cacheEntry{key: key, data: data})
+	if g.order.Len() > g.capacity {
+		oldest := g.order.Back()
+		g.order.Remove(oldest)
+		delete(g.cache, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func render(content string, opts *Options) ([]byte, error) {
+	level, ok := levels[opts.Level]
+	if !ok {
+		return nil, fmt.Errorf("unknown error correction level %q", opts.Level)
+	}
+
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+
+	switch opts.Format {
+	case FormatPNG:
+		return renderPNG(bitmap, opts)
+	case FormatSVG:
+		return renderSVG(bitmap, opts), nil
+	}
+	return nil, fmt.Errorf("unknown format %q", opts.Format)
+}
+
+// layout returns the size of a module in pixels and the offset of the first
+// module, centring the code when the size isn't a multiple of the module count.
+func layout(modules int, opts *Options) (int, int, int) {
+	total := modules + 2*opts.Margin
+	size := opts.Size
+	if size < total {
+		size = total
+	}
+	scale := size / total
+	offset := (size-scale*total)/2 + opts.Margin*scale
+	return size, scale, offset
+}
+
+func renderPNG(bitmap [][]bool, opts *Options) ([]byte, error) {
+	size, scale, offset := layout(len(bitmap), opts)
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{opts.Background, opts.Foreground})
+	for y, row := range bitmap {
+		for x, set := range row {
+			if !set {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(offset+x*scale+dx, offset+y*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func renderSVG(bitmap [][]bool, opts *Options) []byte {
+	// SVG scales without loss, so the view box is measured in modules and only
+	// the margin is added around the code
+	total := len(bitmap) + 2*opts.Margin
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, total, total, hexColor(opts.Background))
+	fmt.Fprintf(&buf, `<path fill="%s" d="`, hexColor(opts.Foreground))
+
+	for y, row := range bitmap {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			run := 1
+			for x+run < len(row) && row[x+run] {
+				run++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", x+opts.Margin, y+opts.Margin, run, run)
+			x += run
+		}
+	}
+
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
@@ -0,0 +1,133 @@
+package qr
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	black = color.RGBA{A: 0xff}
+	white = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+func TestParseColor(t *testing.T) {
+
+	tests := []struct {
+		Name      string
+		Value     string
+		Expected  color.RGBA
+		ExpectErr bool
+	}{
+		{Name: "long form", Value: "#1a2b3c", Expected: color.RGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 0xff}},
+		{Name: "without hash", Value: "1A2B3C", Expected: color.RGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 0xff}},
+		{Name: "short form", Value: "#f0a", Expected: color.RGBA{R: 0xff, G: 0x00, B: 0xaa, A: 0xff}},
+		{Name: "wrong length", Value: "#1234", ExpectErr: true},
+		{Name: "not hex", Value: "#zzzzzz", ExpectErr: true},
+		{Name: "empty", Value: "", ExpectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			res, err := ParseColor(tt.Value)
+
+			assert.Equal(t, tt.ExpectErr, err != nil)
+			assert.Equal(t, tt.Expected, res)
+		})
+	}
+}
+
+func TestGeneratePNG(t *testing.T) {
+
+	g := NewGenerator(8)
+	red := color.RGBA{R: 0xff, A: 0xff}
+
+	data, err := g.Generate("http://localhost:8080/Abc_def_gs", &Options{Format: FormatPNG, Size: 300, Level: "M",
+		Margin: 2, Foreground: red, Background: white})
+	assert.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 300, img.Bounds().Dy())
+
+	// a version 3 code is 29 modules wide, so with the margin every module is
+	// 300 / 33 = 9px and the code is centred with 1px of extra padding
+	assert.Equal(t, color.RGBAModel.Convert(white), color.RGBAModel.Convert(img.At(0, 0)))
+	assert.Equal(t, color.RGBAModel.Convert(white), color.RGBAModel.Convert(img.At(18, 18)))
+	assert.Equal(t, color.RGBAModel.Convert(red), color.RGBAModel.Convert(img.At(19, 19)))
+	assert.Equal(t, color.RGBAModel.Convert(red), color.RGBAModel.Convert(img.At(19+7*9-1, 19)))
+	assert.Equal(t, color.RGBAModel.Convert(white), color.RGBAModel.Convert(img.At(19+7*9, 19)))
+}
+
+func TestGenerateSmallSize(t *testing.T) {
+
+	g := NewGenerator(8)
+
+	data, err := g.Generate("http://localhost:8080/Abc_def_gs", &Options{Format: FormatPNG, Size: 10, Level: "L",
+		Margin: 4, Foreground: black, Background: white})
+	assert.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	// the image can't be smaller than one pixel per module
+	assert.Greater(t, img.Bounds().Dx(), 10)
+}
+
+func TestGenerateSVG(t *testing.T) {
+
+	g := NewGenerator(8)
+
+	data, err := g.Generate("http://localhost:8080/Abc_def_gs", &Options{Format: FormatSVG, Size: 256, Level: "H",
+		Margin: 0, Foreground: color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff}, Background: white})
+	assert.NoError(t, err)
+
+	svg := string(data)
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="256" height="256"`))
+	assert.Contains(t, svg, `fill="#ffffff"`)
+	assert.Contains(t, svg, `<path fill="#112233" d="M0 0h7v1h-7z`)
+	assert.True(t, strings.HasSuffix(svg, `"/></svg>`))
+}
+
+func TestGenerateErrors(t *testing.T) {
+
+	g := NewGenerator(8)
+
+	_, err := g.Generate("http://localhost:8080/Abc_def_gs", &Options{Format: "gif", Size: 256, Level: "M"})
+	assert.Error(t, err)
+
+	_, err = g.Generate("http://localhost:8080/Abc_def_gs", &Options{Format: FormatPNG, Size: 256, Level: "X"})
+	assert.Error(t, err)
+}
+
+func TestGenerateCache(t *testing.T) {
+
+	g := NewGenerator(2)
+
+	opts := &Options{Format: FormatSVG, Size: 256, Level: "M", Margin: 4, Foreground: black, Background: white}
+
+	first, err := g.Generate("http://localhost:8080/a", opts)
+	assert.NoError(t, err)
+
+	again, err := g.Generate("http://localhost:8080/a", opts)
+	assert.NoError(t, err)
+	assert.Same(t, &first[0], &again[0])
+
+	_, err = g.Generate("http://localhost:8080/b", opts)
+	assert.NoError(t, err)
+	_, err = g.Generate("http://localhost:8080/c", opts)
+	assert.NoError(t, err)
+
+	// "a" was the least recently used one and has been evicted
+	assert.Equal(t, 2, g.order.Len())
+	_, ok := g.cache["svg|256|M|4|{0 0 0 255}|{255 255 255 255}|http://localhost:8080/a"]
+	assert.False(t, ok)
+	_, ok = g.cache["svg|256|M|4|{0 0 0 255}|{255 255 255 255}|http://localhost:8080/c"]
+	assert.True(t, ok)
+}
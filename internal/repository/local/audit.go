@@ -0,0 +1,92 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+func copyAuditEntry(entry *models.AuditEntry) *models.AuditEntry {
+	res := *entry
+	if entry.Before != nil {
+		res.Before = append(json.RawMessage(nil), entry.Before...)
+	}
+	if entry.After != nil {
+		res.After = append(json.RawMessage(nil), entry.After...)
+	}
+	return &res
+}
+
+// AppendAuditEntry appends entry to the audit log, setting its id and hashes.
+func (ur *UrlRepository) AppendAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	ur.auditMu.Lock()
+	defer ur.auditMu.Unlock()
+
+	var prevHash string
+	if len(ur.audit) > 0 {
+		prevHash = ur.audit[len(ur.audit)-1].Hash
+	}
+
+	entry.ID, entry.Time, entry.PrevHash = int64(len(ur.audit))+1, entry.Time.UTC().Truncate(time.Microsecond), prevHash
+	entry.Hash = audit.Hash(entry, prevHash)
+	ur.audit = append(ur.audit, copyAuditEntry(entry))
+	return nil
+}
+
+func matchAuditFilter(entry *models.AuditEntry, filter *models.AuditFilter) bool {
+	if filter.Actor != "" && entry.Actor != filter.Actor {
+		return false
+	}
+	if filter.Action != "" && entry.Action != filter.Action {
+		return false
+	}
+	if filter.Target != "" && entry.Target != filter.Target {
+		return false
+	}
+	if filter.From != nil && entry.Time.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !entry.Time.Before(*filter.To) {
+		return false
+	}
+	return true
+}
+
+// ListAuditEntries returns up to filter.Limit audit entries matching filter,
+// continuing after filter.Cursor.
+func (ur *UrlRepository) ListAuditEntries(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, error) {
+	ur.auditMu.Lock()
+	defer ur.auditMu.Unlock()
+
+	// entry ids are their positions in the log plus one
+	var res []*models.AuditEntry
+	add := func(entry *models.AuditEntry) bool {
+		if matchAuditFilter(entry, filter) {
+			res = append(res, copyAuditEntry(entry))
+		}
+		return filter.Limit == 0 || len(res) < filter.Limit
+	}
+
+	if filter.Order == "asc" {
+		for i := int(filter.Cursor); i < len(ur.audit); i++ {
+			if !add(ur.audit[i]) {
+				break
+			}
+		}
+		return res, nil
+	}
+
+	start := len(ur.audit) - 1
+	if filter.Cursor > 0 {
+		start = min(start, int(filter.Cursor)-2)
+	}
+	for i := start; i >= 0; i-- {
+		if !add(ur.audit[i]) {
+			break
+		}
+	}
+	return res, nil
+}
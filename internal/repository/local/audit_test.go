@@ -0,0 +1,56 @@
+package local
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	start := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	for i, target := range []string{"Abc_def_ga", "Abc_def_gb", "Abc_def_ga", "Abc_def_gc"} {
+		entry := &models.AuditEntry{Time: start.Add(time.Duration(i) * time.Minute), Actor: "migration",
+			Action: audit.LinkCreate, Target: target, After: []byte(`{"short_url":"` + target + `"}`)}
+		require.NoError(t, urlRepo.AppendAuditEntry(ctx, entry))
+		assert.Equal(t, int64(i+1), entry.ID)
+	}
+
+	entries, err := urlRepo.ListAuditEntries(ctx, &models.AuditFilter{Order: "asc"})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, -1, audit.Verify(entries, ""))
+
+	// the log hands out copies
+	entries[0].After[2] = 'x'
+	entries, err = urlRepo.ListAuditEntries(ctx, &models.AuditFilter{Order: "asc", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, -1, audit.Verify(entries, ""))
+
+	ids := func(filter *models.AuditFilter) []int64 {
+		entries, err := urlRepo.ListAuditEntries(ctx, filter)
+		require.NoError(t, err)
+		var res []int64
+		for _, entry := range entries {
+			res = append(res, entry.ID)
+		}
+		return res
+	}
+
+	from, to := start.Add(time.Minute), start.Add(3*time.Minute)
+	assert.Equal(t, []int64{4, 3, 2, 1}, ids(&models.AuditFilter{}))
+	assert.Equal(t, []int64{3, 1}, ids(&models.AuditFilter{Target: "Abc_def_ga"}))
+	assert.Equal(t, []int64{3, 2}, ids(&models.AuditFilter{From: &from, To: &to}))
+	assert.Equal(t, []int64{2, 1}, ids(&models.AuditFilter{Cursor: 3}))
+	assert.Equal(t, []int64{3}, ids(&models.AuditFilter{Cursor: 4, Limit: 1}))
+	assert.Equal(t, []int64{3, 4}, ids(&models.AuditFilter{Order: "asc", Cursor: 2}))
+	assert.Empty(t, ids(&models.AuditFilter{Actor: "admin"}))
+}
@@ -0,0 +1,86 @@
+package local
+
+import (
+	"context"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+type idempotencyKey struct {
+	scope string
+	key   string
+}
+
+func copyIdempotencyRecord(record *models.IdempotencyRecord) *models.IdempotencyRecord {
+	res := *record
+	if record.Body != nil {
+		res.Body = append([]byte(nil), record.Body...)
+	}
+	return &res
+}
+
+// AcquireIdempotencyKey stores record as the in-progress request with its key,
+// unless another request holds the key, and returns nil. Otherwise it returns
+// the record of the other request.
+func (ur *UrlRepository) AcquireIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord,
+	now time.Time) (*models.IdempotencyRecord, error) {
+	ur.idempotencyMu.Lock()
+	defer ur.idempotencyMu.Unlock()
+
+	key := idempotencyKey{scope: record.Scope, key: record.Key}
+	existing, ok := ur.idempotency[key]
+	if ok && existing.ExpiresAt.After(now) && (existing.Status != 0 || existing.LockedUntil.After(now)) {
+		return copyIdempotencyRecord(existing), nil
+	}
+
+	acquired := copyIdempotencyRecord(record)
+	acquired.Status, acquired.ContentType, acquired.Body = 0, "", nil
+	ur.idempotency[key] = acquired
+	return nil, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request holding the key
+// of record.
+func (ur *UrlRepository) CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error {
+	ur.idempotencyMu.Lock()
+	defer ur.idempotencyMu.Unlock()
+
+	existing, ok := ur.idempotency[idempotencyKey{scope: record.Scope, key: record.Key}]
+	if !ok || existing.RequestHash != record.RequestHash || existing.Status != 0 {
+		return nil
+	}
+	existing.Status, existing.ContentType = record.Status, record.ContentType
+	existing.Body = append([]byte(nil), record.Body...)
+	return nil
+}
+
+// ReleaseIdempotencyKey lets the next request with the key of record, still
+// in progress, take it over, so failed requests can be retried.
+func (ur *UrlRepository) ReleaseIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord,
+	now time.Time) error {
+	ur.idempotencyMu.Lock()
+	defer ur.idempotencyMu.Unlock()
+
+	existing, ok := ur.idempotency[idempotencyKey{scope: record.Scope, key: record.Key}]
+	if ok && existing.RequestHash == record.RequestHash && existing.Status == 0 {
+		existing.LockedUntil = now
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes the records expired at now and returns
+// their number.
+func (ur *UrlRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	ur.idempotencyMu.Lock()
+	defer ur.idempotencyMu.Unlock()
+
+	var deleted int64
+	for key, record := range ur.idempotency {
+		if !record.ExpiresAt.After(now) {
+			delete(ur.idempotency, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,78 @@
+package local
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	record := &models.IdempotencyRecord{Scope: "runner", Key: "key-1", RequestHash: "hash-1",
+		LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)}
+
+	existing, err := urlRepo.AcquireIdempotencyKey(ctx, record, now)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// the first request is in progress
+	existing, err = urlRepo.AcquireIdempotencyKey(ctx, record, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, existing.Status)
+
+	// other scopes have their own keys
+	other := *record
+	other.Scope = "other"
+	existing, err = urlRepo.AcquireIdempotencyKey(ctx, &other, now)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	completed := *record
+	completed.Status, completed.ContentType, completed.Body = 200, "application/json", []byte(`{}`)
+	assert.NoError(t, urlRepo.CompleteIdempotencyKey(ctx, &completed))
+
+	// completed records are kept after the lock times out, until they expire
+	existing, err = urlRepo.AcquireIdempotencyKey(ctx, record, now.Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, &completed, existing)
+
+	existing, err = urlRepo.AcquireIdempotencyKey(ctx, record, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	deleted, err := urlRepo.DeleteExpiredIdempotencyKeys(ctx, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+}
+
+func TestReleaseIdempotencyKey(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	record := &models.IdempotencyRecord{Scope: "runner", Key: "key-1", RequestHash: "hash-1",
+		LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)}
+
+	existing, err := urlRepo.AcquireIdempotencyKey(ctx, record, now)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	assert.NoError(t, urlRepo.ReleaseIdempotencyKey(ctx, record, now))
+
+	existing, err = urlRepo.AcquireIdempotencyKey(ctx, record, now)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// abandoned requests are taken over once their lock times out
+	existing, err = urlRepo.AcquireIdempotencyKey(ctx, record, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+}
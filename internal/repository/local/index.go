@@ -0,0 +1,70 @@
+package local
+
+import "github.com/google/btree"
+
+// indexKey orders links by a numeric value, ties being broken by the short url
+// as in the Postgres listing.
+type indexKey struct {
+	value    int64
+	shortUrl string
+}
+
+func (k indexKey) less(other indexKey) bool {
+	if k.value != other.value {
+		return k.value < other.value
+	}
+	return k.shortUrl < other.shortUrl
+}
+
+// indexDegree is the degree of the index B-trees.
+const indexDegree = 32
+
+// linkIndex keeps keys in a B-tree, so a listing page is located without
+// sorting the whole store and updating a key on every click costs O(log n).
+// The zero value is an empty index.
+type linkIndex struct {
+	tree *btree.BTreeG[indexKey]
+}
+
+func (idx *linkIndex) insert(key indexKey) {
+	if idx.tree == nil {
+		idx.tree = btree.NewG(indexDegree, indexKey.less)
+	}
+	idx.tree.ReplaceOrInsert(key)
+}
+
+func (idx *linkIndex) remove(key indexKey) {
+	if idx.tree != nil {
+		idx.tree.Delete(key)
+	}
+}
+
+// walk calls fn for the keys following after, or for all keys when after is
+// nil, in ascending or descending order until fn returns false.
+func (idx *linkIndex) walk(after *indexKey, desc bool, fn func(indexKey) bool) {
+	if idx.tree == nil {
+		return
+	}
+
+	if after == nil {
+		if desc {
+			idx.tree.Descend(fn)
+		} else {
+			idx.tree.Ascend(fn)
+		}
+		return
+	}
+
+	// the cursor key itself is skipped, whether it is still indexed or not
+	skip := func(key indexKey) bool {
+		if key == *after {
+			return true
+		}
+		return fn(key)
+	}
+	if desc {
+		idx.tree.DescendLessOrEqual(*after, skip)
+	} else {
+		idx.tree.AscendGreaterOrEqual(*after, skip)
+	}
+}
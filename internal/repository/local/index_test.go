@@ -0,0 +1,62 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkIndex(t *testing.T) {
+
+	idx := &linkIndex{}
+	for _, key := range []indexKey{{3, "c"}, {1, "a"}, {2, "b"}, {2, "a"}, {5, "e"}} {
+		idx.insert(key)
+	}
+
+	collect := func(after *indexKey, desc bool, limit int) []indexKey {
+		res := []indexKey{}
+		idx.walk(after, desc, func(key indexKey) bool {
+			res = append(res, key)
+			return len(res) < limit
+		})
+		return res
+	}
+
+	assert.Equal(t, []indexKey{{1, "a"}, {2, "a"}, {2, "b"}, {3, "c"}, {5, "e"}}, collect(nil, false, 10))
+
+	idx.remove(indexKey{2, "b"})
+	idx.remove(indexKey{4, "d"})
+	assert.Equal(t, []indexKey{{1, "a"}, {2, "a"}, {3, "c"}, {5, "e"}}, collect(nil, false, 10))
+
+	tests := []struct {
+		Name     string
+		After    *indexKey
+		Desc     bool
+		Limit    int
+		Expected []indexKey
+	}{
+		{Name: "ascending from start", After: nil, Desc: false, Limit: 2, Expected: []indexKey{{1, "a"}, {2, "a"}}},
+		{Name: "descending from start", After: nil, Desc: true, Limit: 2, Expected: []indexKey{{5, "e"}, {3, "c"}}},
+		{Name: "ascending after existing key", After: &indexKey{2, "a"}, Desc: false, Limit: 10, Expected: []indexKey{{3, "c"}, {5, "e"}}},
+		{Name: "descending after existing key", After: &indexKey{3, "c"}, Desc: true, Limit: 10, Expected: []indexKey{{2, "a"}, {1, "a"}}},
+		{Name: "ascending after removed key", After: &indexKey{2, "b"}, Desc: false, Limit: 10, Expected: []indexKey{{3, "c"}, {5, "e"}}},
+		{Name: "descending after removed key", After: &indexKey{2, "b"}, Desc: true, Limit: 10, Expected: []indexKey{{2, "a"}, {1, "a"}}},
+		{Name: "nothing after last key", After: &indexKey{5, "e"}, Desc: false, Limit: 10, Expected: []indexKey{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, collect(tt.After, tt.Desc, tt.Limit))
+		})
+	}
+}
+
+func TestLinkIndexEmpty(t *testing.T) {
+
+	idx := &linkIndex{}
+	idx.remove(indexKey{1, "a"})
+	idx.walk(nil, false, func(indexKey) bool {
+		t.Fatal("empty index walked a key")
+		return false
+	})
+}
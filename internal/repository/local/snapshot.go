@@ -0,0 +1,99 @@
+package local
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+// SaveSnapshot writes every link with its counters to path as JSON Lines. The
+// links are copied under the read lock and written after it is released; the
+// file is replaced only once completely written.
+func (ur *UrlRepository) SaveSnapshot(path string) error {
+
+	ur.mu.RLock()
+	links := make([]*models.StoredLink, 0, len(ur.store))
+	ur.byCreated.walk(nil, false, func(key indexKey) bool {
+		link := &models.StoredLink{UrlData: *ur.load(ur.store[key.shortUrl])}
+		if variants := ur.clicks[key.shortUrl].VariantClicks; len(variants) > 0 {
+			link.VariantClicks = make(map[int]int64, len(variants))
+			for variant, count := range variants {
+				link.VariantClicks[variant] = count
+			}
+		}
+		links = append(links, link)
+		return true
+	})
+	ur.mu.RUnlock()
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("local.UrlRepository.SaveSnapshot: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, link := range links {
+		if err := encoder.Encode(link); err != nil {
+			return fmt.Errorf("local.UrlRepository.SaveSnapshot: %w", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("local.UrlRepository.SaveSnapshot: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("local.UrlRepository.SaveSnapshot: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("local.UrlRepository.SaveSnapshot: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("local.UrlRepository.SaveSnapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot adds the links of a snapshot written by SaveSnapshot, keeping
+// the links already stored under the same short urls. A missing file is
+// reported with an error satisfying errors.Is(err, fs.ErrNotExist).
+func (ur *UrlRepository) LoadSnapshot(path string) error {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("local.UrlRepository.LoadSnapshot: %w", err)
+	}
+	defer file.Close()
+
+	var links []*models.StoredLink
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		link := &models.StoredLink{}
+		err := decoder.Decode(link)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("local.UrlRepository.LoadSnapshot: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	for _, link := range links {
+		if _, ok := ur.store[link.ShortUrl]; !ok {
+			ur.restore(link)
+		}
+	}
+	return nil
+}
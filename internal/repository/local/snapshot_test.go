@@ -0,0 +1,81 @@
+package local
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "links.jsonl")
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	notAfter := createdAt.Add(time.Hour)
+	clicksLeft := 4
+
+	links := []*models.StoredLink{
+		{UrlData: models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "https://example.com/a", CreatedAt: createdAt,
+			ClicksLeft: &clicksLeft, ActivationWindow: models.ActivationWindow{NotAfter: &notAfter},
+			Rules:        []models.TargetingRule{{OS: "ios", Query: map[string]string{"a": "1"}, TargetUrl: "https://apps.apple.com"}},
+			Owner:        "team",
+			Clicks:       2,
+			LinkMetadata: models.LinkMetadata{Title: "Sale", Tags: []string{"promo"}}}},
+		{UrlData: models.UrlData{ShortUrl: "Abc_def_gb", OriginalUrl: "https://example.com/b", CreatedAt: createdAt.Add(time.Second),
+			Destinations: []models.Destination{{Url: "https://a.example.com", Weight: 1}, {Url: "https://b.example.com", Weight: 3}},
+			Clicks:       9},
+			VariantClicks: map[int]int64{0: 4, 1: 5}},
+	}
+
+	taken, err := urlRepo.RestoreLinks(ctx, links)
+	assert.NoError(t, err)
+	assert.Nil(t, taken)
+
+	assert.NoError(t, urlRepo.SaveSnapshot(path))
+
+	restored := NewUrlRepository()
+	assert.NoError(t, restored.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "https://kept.com"}))
+	assert.NoError(t, restored.LoadSnapshot(path))
+
+	data, err := restored.GetUrlData(ctx, "Abc_def_ga")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://kept.com", data.OriginalUrl)
+
+	data, err = restored.GetUrlData(ctx, "Abc_def_gb")
+	assert.NoError(t, err)
+	assert.Equal(t, &links[1].UrlData, data)
+
+	clicks, err := restored.GetClicks(ctx, "Abc_def_gb")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ClickData{Clicks: 9, VariantClicks: map[int]int64{0: 4, 1: 5}}, clicks)
+
+	loaded := NewUrlRepository()
+	assert.NoError(t, loaded.LoadSnapshot(path))
+
+	data, err = loaded.GetUrlData(ctx, "Abc_def_ga")
+	assert.NoError(t, err)
+	assert.Equal(t, &links[0].UrlData, data)
+
+	listed, err := loaded.ListLinks(ctx, &models.LinkFilter{Sort: "clicks"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Abc_def_gb", listed[0].ShortUrl)
+
+	taken, err = loaded.RestoreLinks(ctx, links)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Abc_def_ga", "Abc_def_gb"}, taken)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.ErrorIs(t, NewUrlRepository().LoadSnapshot(filepath.Join(t.TempDir(), "missing.jsonl")), fs.ErrNotExist)
+}
@@ -0,0 +1,57 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/events"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+// RestoreUrl brings back a link deleted after deletedAfter, once check accepts
+// the usage of its owner in month, if any. A non-empty owner may only restore
+// its own links.
+func (ur *UrlRepository) RestoreUrl(ctx context.Context, shortUrl, owner string, deletedAfter, month time.Time,
+	check func(*models.Usage) error) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	val, ok := ur.store[shortUrl]
+	if !ok || val.DeletedAt == nil || !val.DeletedAt.After(deletedAfter) || (owner != "" && val.Owner != owner) {
+		return &utils.InternalError{Code: http.StatusNotFound, Message: "no restorable link match this shortUrl"}
+	}
+	if val.Owner != "" {
+		if err := check(ur.currentUsage(val.Owner, month)); err != nil {
+			return err
+		}
+	}
+	val.DeletedAt = nil
+	ur.activeLinks[val.Owner]++
+	ur.enqueue(events.Restored(shortUrl, val.Owner))
+	return nil
+}
+
+// PurgeDeletedLinks deletes the tombstones of the links deleted before
+// before, freeing their codes, and returns their number.
+func (ur *UrlRepository) PurgeDeletedLinks(ctx context.Context, before time.Time) (int64, error) {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	var purged int64
+	for shortUrl, val := range ur.store {
+		if val.DeletedAt == nil || val.DeletedAt.After(before) {
+			continue
+		}
+		ur.byCreated.remove(indexKey{value: val.CreatedAt.UnixNano(), shortUrl: shortUrl})
+		ur.byClicks.remove(indexKey{value: ur.clicks[shortUrl].Clicks, shortUrl: shortUrl})
+		delete(ur.store, shortUrl)
+		delete(ur.clicks, shortUrl)
+		delete(ur.expiryNotified, shortUrl)
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,81 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreUrl(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	require.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_ga",
+		Owner: "team"}))
+	require.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_ga", -1, time.Now()))
+	require.NoError(t, urlRepo.DeleteUrl(ctx, "Abc_def_ga"))
+
+	notRestorable := &utils.InternalError{Code: http.StatusNotFound, Message: "no restorable link match this shortUrl"}
+	hourAgo := time.Now().Add(-time.Hour)
+	month := monthStart(time.Now())
+	accept := func(*models.Usage) error { return nil }
+
+	assert.Equal(t, notRestorable, urlRepo.RestoreUrl(ctx, "Abc_def_ga", "other", hourAgo, month, accept))
+	assert.Equal(t, notRestorable, urlRepo.RestoreUrl(ctx, "Abc_def_ga", "", time.Now().Add(time.Hour), month, accept))
+	assert.Equal(t, notRestorable, urlRepo.RestoreUrl(ctx, "Abc_def_gb", "", hourAgo, month, accept))
+	require.NoError(t, urlRepo.RestoreUrl(ctx, "Abc_def_ga", "team", hourAgo, month, accept))
+
+	// the link comes back with its counters
+	data, err := urlRepo.GetUrlData(ctx, "Abc_def_ga")
+	require.NoError(t, err)
+	assert.Nil(t, data.DeletedAt)
+	assert.Equal(t, int64(1), data.Clicks)
+	links, err := urlRepo.ListLinks(ctx, &models.LinkFilter{})
+	require.NoError(t, err)
+	assert.Len(t, links, 1)
+
+	assert.Equal(t, notRestorable, urlRepo.RestoreUrl(ctx, "Abc_def_ga", "", hourAgo, month, accept))
+}
+
+func TestPurgeDeletedLinks(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	for _, shortUrl := range []string{"Abc_def_ga", "Abc_def_gb"} {
+		require.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: shortUrl}))
+	}
+	require.NoError(t, urlRepo.DeleteUrl(ctx, "Abc_def_ga"))
+
+	// tombstones are saved in snapshots
+	path := filepath.Join(t.TempDir(), "links.jsonl")
+	require.NoError(t, urlRepo.SaveSnapshot(path))
+	loaded := NewUrlRepository()
+	require.NoError(t, loaded.LoadSnapshot(path))
+	_, err := loaded.GetUrlData(ctx, "Abc_def_ga")
+	assert.Equal(t, &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has been deleted"}, err)
+
+	purged, err := urlRepo.PurgeDeletedLinks(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+
+	purged, err = urlRepo.PurgeDeletedLinks(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	// the purged code is free again
+	_, err = urlRepo.GetUrlData(ctx, "Abc_def_ga")
+	assert.Equal(t, &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}, err)
+	require.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru/new", ShortUrl: "Abc_def_ga"}))
+	links, err := urlRepo.ListLinks(ctx, &models.LinkFilter{Sort: "clicks"})
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+}
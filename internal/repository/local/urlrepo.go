@@ -3,24 +3,124 @@ package local
 import (
 	"context"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
+	"time"
+	"unicode"
 
+	"github.com/AlexNov03/UrlShortener/internal/events"
 	"github.com/AlexNov03/UrlShortener/internal/models"
 	"github.com/AlexNov03/UrlShortener/utils"
 )
 
 type UrlRepository struct {
-	mu    sync.RWMutex
-	store map[string]string
+	mu        sync.RWMutex
+	store     map[string]*models.UrlData
+	clicks    map[string]*models.ClickData
+	byCreated linkIndex
+	byClicks  linkIndex
+
+	// links that are not deleted are counted per owner; the monthly usage is
+	// not saved in snapshots
+	activeLinks map[string]int64
+	usage       map[usageKey]*models.Usage
+
+	// webhooks and their outbox are not saved in snapshots
+	webhooks       map[int64]*models.WebhookSubscription
+	outbox         map[int64]*models.WebhookDelivery
+	nextWebhookID  int64
+	nextDeliveryID int64
+	expiryNotified map[string]bool
+
+	// the audit log is not saved in snapshots
+	auditMu sync.Mutex
+	audit   []*models.AuditEntry
+
+	// idempotency keys are not links and are not saved in snapshots
+	idempotencyMu sync.Mutex
+	idempotency   map[idempotencyKey]*models.IdempotencyRecord
+
+	workspaceMu sync.RWMutex
+	ws          workspaceStore
 }
 
 func NewUrlRepository() *UrlRepository {
-	return &UrlRepository{mu: sync.RWMutex{}, store: make(map[string]string)}
+	return &UrlRepository{mu: sync.RWMutex{}, store: make(map[string]*models.UrlData),
+		clicks: make(map[string]*models.ClickData), activeLinks: make(map[string]int64),
+		usage: make(map[usageKey]*models.Usage), webhooks: make(map[int64]*models.WebhookSubscription),
+		outbox: make(map[int64]*models.WebhookDelivery), expiryNotified: make(map[string]bool),
+		idempotency: make(map[idempotencyKey]*models.IdempotencyRecord), ws: newWorkspaceStore()}
+}
+
+// load returns a copy of a stored link with its click count.
+func (ur *UrlRepository) load(val *models.UrlData) *models.UrlData {
+	res := copyUrlData(val)
+	res.Clicks = ur.clicks[val.ShortUrl].Clicks
+	return res
+}
+
+// copyUrlData returns a copy of data that shares no pointers with it, so
+// values handed in and out of the store can't be mutated behind the mutex.
+func copyUrlData(data *models.UrlData) *models.UrlData {
+	res := *data
+	if data.ClicksLeft != nil {
+		left := *data.ClicksLeft
+		res.ClicksLeft = &left
+	}
+	if data.DeletedAt != nil {
+		deletedAt := *data.DeletedAt
+		res.DeletedAt = &deletedAt
+	}
+	res.ActivationWindow = copyWindow(&data.ActivationWindow)
+	res.Rules = copyRules(data.Rules)
+	if data.Destinations != nil {
+		res.Destinations = append([]models.Destination(nil), data.Destinations...)
+	}
+	res.LinkMetadata = copyMetadata(&data.LinkMetadata)
+	return &res
+}
+
+func copyMetadata(metadata *models.LinkMetadata) models.LinkMetadata {
+	res := *metadata
+	if metadata.Tags != nil {
+		res.Tags = append([]string(nil), metadata.Tags...)
+	}
+	return res
+}
+
+func copyRules(rules []models.TargetingRule) []models.TargetingRule {
+	if rules == nil {
+		return nil
+	}
+	res := make([]models.TargetingRule, len(rules))
+	for i, rule := range rules {
+		res[i] = rule
+		if rule.Query != nil {
+			res[i].Query = make(map[string]string, len(rule.Query))
+			for key, value := range rule.Query {
+				res[i].Query[key] = value
+			}
+		}
+	}
+	return res
+}
+
+func copyWindow(window *models.ActivationWindow) models.ActivationWindow {
+	res := *window
+	if window.NotBefore != nil {
+		notBefore := *window.NotBefore
+		res.NotBefore = &notBefore
+	}
+	if window.NotAfter != nil {
+		notAfter := *window.NotAfter
+		res.NotAfter = &notAfter
+	}
+	return res
 }
 
 func (ur *UrlRepository) AddOriginalUrl(ctx context.Context, data *models.UrlData) error {
 
-	origUrl := data.OriginalUrl
 	shortUrl := data.ShortUrl
 
 	ur.mu.Lock()
@@ -29,10 +129,109 @@ func (ur *UrlRepository) AddOriginalUrl(ctx context.Context, data *models.UrlDat
 	if _, ok := ur.store[shortUrl]; ok {
 		return &utils.InternalError{Code: http.StatusConflict, Message: "this shortUrl already exists"}
 	}
-	ur.store[shortUrl] = origUrl
+	ur.insert(data)
+	ur.enqueue(events.Created(data))
 	return nil
 }
 
+// insert stores a copy of data under a free short url, the mutex being held.
+func (ur *UrlRepository) insert(data *models.UrlData) {
+	val := copyUrlData(data)
+	if val.CreatedAt.IsZero() {
+		val.CreatedAt = time.Now()
+	}
+	val.Clicks = 0
+
+	ur.store[val.ShortUrl] = val
+	ur.clicks[val.ShortUrl] = &models.ClickData{VariantClicks: make(map[int]int64)}
+	ur.byCreated.insert(indexKey{value: val.CreatedAt.UnixNano(), shortUrl: val.ShortUrl})
+	ur.byClicks.insert(indexKey{value: 0, shortUrl: val.ShortUrl})
+	if val.DeletedAt == nil {
+		ur.activeLinks[val.Owner]++
+	}
+}
+
+// ImportLinks stores links under their own short urls and returns the skipped
+// ones with the reasons: taken short urls, and links of owners whose usage
+// check rejects. Links of owners count as creations in month, so the links of
+// a batch are checked with the ones before them counted.
+func (ur *UrlRepository) ImportLinks(ctx context.Context, links []*models.UrlData, month time.Time,
+	check func(*models.Usage) error) (map[string]error, error) {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	skipped := make(map[string]error)
+	for _, link := range links {
+		if _, ok := ur.store[link.ShortUrl]; ok {
+			skipped[link.ShortUrl] = &utils.InternalError{Code: http.StatusConflict,
+				Message: "this shortUrl already exists"}
+			continue
+		}
+		if link.Owner != "" {
+			if err := check(ur.currentUsage(link.Owner, month)); err != nil {
+				skipped[link.ShortUrl] = err
+				continue
+			}
+			ur.monthlyUsage(link.Owner, month).Creations++
+		}
+		ur.insert(link)
+		ur.enqueue(events.Created(link))
+	}
+	return skipped, nil
+}
+
+// RestoreLinks stores links with every field and counter under their own
+// short urls, skipping the short urls that are taken, and returns the skipped
+// ones.
+func (ur *UrlRepository) RestoreLinks(ctx context.Context, links []*models.StoredLink) ([]string, error) {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	var taken []string
+	for _, link := range links {
+		if _, ok := ur.store[link.ShortUrl]; ok {
+			taken = append(taken, link.ShortUrl)
+			continue
+		}
+		ur.restore(link)
+	}
+	return taken, nil
+}
+
+// restore inserts a link together with its counters, the mutex being held.
+func (ur *UrlRepository) restore(link *models.StoredLink) {
+	ur.insert(&link.UrlData)
+
+	clicks := ur.clicks[link.ShortUrl]
+	ur.byClicks.remove(indexKey{value: clicks.Clicks, shortUrl: link.ShortUrl})
+	clicks.Clicks = link.Clicks
+	ur.byClicks.insert(indexKey{value: clicks.Clicks, shortUrl: link.ShortUrl})
+	for variant, count := range link.VariantClicks {
+		clicks.VariantClicks[variant] = count
+	}
+	// links restored already expired are not reported
+	if link.NotAfter != nil && !link.NotAfter.After(time.Now()) {
+		ur.expiryNotified[link.ShortUrl] = true
+	}
+}
+
+// ExistingCodes returns the codes that are already used as short urls.
+func (ur *UrlRepository) ExistingCodes(ctx context.Context, codes []string) ([]string, error) {
+
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+
+	var res []string
+	for _, code := range codes {
+		if _, ok := ur.store[code]; ok {
+			res = append(res, code)
+		}
+	}
+	return res, nil
+}
+
 func (ur *UrlRepository) GetOriginalUrl(ctx context.Context, shortUrl string) (string, error) {
 
 	ur.mu.RLock()
@@ -42,5 +241,269 @@ func (ur *UrlRepository) GetOriginalUrl(ctx context.Context, shortUrl string) (s
 	if !ok {
 		return "", &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
 	}
-	return val, nil
+	if val.DeletedAt != nil {
+		return "", &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has been deleted"}
+	}
+	return val.OriginalUrl, nil
+}
+
+func (ur *UrlRepository) GetUrlData(ctx context.Context, shortUrl string) (*models.UrlData, error) {
+
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+
+	val, ok := ur.store[shortUrl]
+	if !ok {
+		return nil, &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+	}
+	if val.DeletedAt != nil {
+		return nil, &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has been deleted"}
+	}
+	return ur.load(val), nil
+}
+
+func (ur *UrlRepository) DecrementClicks(ctx context.Context, shortUrl string) (int, error) {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	val, ok := ur.store[shortUrl]
+	if !ok || val.ClicksLeft == nil || *val.ClicksLeft <= 0 {
+		return 0, &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has reached its click limit"}
+	}
+	*val.ClicksLeft--
+	if *val.ClicksLeft == 0 {
+		ur.enqueue(events.Expired(shortUrl, val.Owner, events.ReasonClickLimit))
+	}
+	return *val.ClicksLeft, nil
+}
+
+func (ur *UrlRepository) UpdateWindow(ctx context.Context, shortUrl string, window *models.ActivationWindow) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	val, ok := ur.store[shortUrl]
+	if !ok {
+		return &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+	}
+	val.ActivationWindow = copyWindow(window)
+	delete(ur.expiryNotified, shortUrl)
+	ur.enqueue(events.WindowUpdated(shortUrl, val.Owner, window))
+	return nil
+}
+
+func (ur *UrlRepository) UpdateRules(ctx context.Context, shortUrl string, rules []models.TargetingRule) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	val, ok := ur.store[shortUrl]
+	if !ok {
+		return &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+	}
+	val.Rules = copyRules(rules)
+	ur.enqueue(events.RulesUpdated(shortUrl, val.Owner, rules))
+	return nil
+}
+
+func (ur *UrlRepository) RecordClick(ctx context.Context, shortUrl string, variant int, month time.Time) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	clicks, ok := ur.clicks[shortUrl]
+	if !ok {
+		return &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+	}
+	ur.byClicks.remove(indexKey{value: clicks.Clicks, shortUrl: shortUrl})
+	clicks.Clicks++
+	ur.byClicks.insert(indexKey{value: clicks.Clicks, shortUrl: shortUrl})
+	if variant >= 0 {
+		clicks.VariantClicks[variant]++
+	}
+	owner := ur.store[shortUrl].Owner
+	if owner != "" {
+		ur.monthlyUsage(owner, month).Resolutions++
+	}
+	ur.enqueue(events.ClicksReached(shortUrl, owner, clicks.Clicks))
+	return nil
+}
+
+func (ur *UrlRepository) GetClicks(ctx context.Context, shortUrl string) (*models.ClickData, error) {
+
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+
+	clicks, ok := ur.clicks[shortUrl]
+	if !ok {
+		return nil, &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+	}
+
+	res := &models.ClickData{Clicks: clicks.Clicks, VariantClicks: make(map[int]int64, len(clicks.VariantClicks))}
+	for variant, count := range clicks.VariantClicks {
+		res.VariantClicks[variant] = count
+	}
+	return res, nil
+}
+
+func (ur *UrlRepository) UpdateMetadata(ctx context.Context, shortUrl string, metadata *models.LinkMetadata) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	val, ok := ur.store[shortUrl]
+	if !ok {
+		return &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+	}
+	val.LinkMetadata = copyMetadata(metadata)
+	ur.enqueue(events.MetadataUpdated(shortUrl, val.Owner, metadata))
+	return nil
+}
+
+// DeleteUrl turns a link into a tombstone, which keeps its code and counters
+// until it is restored or purged. Tombstones stay in the indexes, so they are
+// saved in snapshots, and are skipped by the listing.
+func (ur *UrlRepository) DeleteUrl(ctx context.Context, shortUrl string) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	val, ok := ur.store[shortUrl]
+	if !ok || val.DeletedAt != nil {
+		return &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+	}
+	deletedAt := time.Now().UTC()
+	val.DeletedAt = &deletedAt
+	ur.activeLinks[val.Owner]--
+	ur.enqueue(events.Deleted(shortUrl, val.Owner))
+	return nil
+}
+
+// destinationHost returns the lowercased host of a destination without the
+// brackets of IPv6 addresses, matching the original_host column in Postgres.
+func destinationHost(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// searchWords splits s into lowercased words of letters and digits, the way
+// the Postgres search column is built.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func matchSearch(data *models.UrlData, search string) bool {
+	words := make(map[string]bool)
+	for _, word := range searchWords(data.Title + " " + data.OriginalUrl) {
+		words[word] = true
+	}
+	for _, word := range searchWords(search) {
+		if !words[word] {
+			return false
+		}
+	}
+	return true
+}
+
+func matchTag(data *models.UrlData, tag string) bool {
+	for _, t := range data.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchFilter(data *models.UrlData, filter *models.LinkFilter) bool {
+	if filter.Owner != "" && data.Owner != filter.Owner {
+		return false
+	}
+	if filter.Host != "" || filter.Domain != "" {
+		host := destinationHost(data.OriginalUrl)
+		if filter.Host != "" && host != filter.Host {
+			return false
+		}
+		if filter.Domain != "" && host != filter.Domain && !strings.HasSuffix(host, "."+filter.Domain) {
+			return false
+		}
+	}
+	if filter.Tag != "" && !matchTag(data, filter.Tag) {
+		return false
+	}
+	if filter.Title != "" && !strings.Contains(strings.ToLower(data.Title), strings.ToLower(filter.Title)) {
+		return false
+	}
+	if filter.Search != "" && !matchSearch(data, filter.Search) {
+		return false
+	}
+	if filter.CreatedFrom != nil && data.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && !data.CreatedAt.Before(*filter.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// ListLinks returns up to filter.Limit links matching filter, continuing after
+// filter.After when it is set. The page is read from the index of the
+// requested order, starting at the cursor.
+func (ur *UrlRepository) ListLinks(ctx context.Context, filter *models.LinkFilter) ([]*models.UrlData, error) {
+
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+
+	index := &ur.byCreated
+	if filter.Sort == "clicks" {
+		index = &ur.byClicks
+	}
+
+	var after *indexKey
+	if filter.After != nil {
+		after = &indexKey{value: filter.After.CreatedAt.UnixNano(), shortUrl: filter.After.ShortUrl}
+		if filter.Sort == "clicks" {
+			after.value = filter.After.Clicks
+		}
+	}
+
+	var res []*models.UrlData
+	index.walk(after, filter.Order != "asc", func(key indexKey) bool {
+		val := ur.store[key.shortUrl]
+		if val.DeletedAt == nil && matchFilter(val, filter) {
+			res = append(res, ur.load(val))
+		}
+		return filter.Limit <= 0 || len(res) < filter.Limit
+	})
+	return res, nil
+}
+
+// ExportLinks calls fn for every link matching filter in the order of filter.
+// The matching links are copied under the read lock, which is released before
+// fn is called, so a slow export neither blocks writers nor sees their changes.
+func (ur *UrlRepository) ExportLinks(ctx context.Context, filter *models.LinkFilter,
+	fn func(*models.UrlData) error) error {
+
+	snapshot := *filter
+	snapshot.Limit = 0
+
+	links, err := ur.ListLinks(ctx, &snapshot)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(link); err != nil {
+			return err
+		}
+	}
+	return nil
 }
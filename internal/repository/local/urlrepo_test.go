@@ -0,0 +1,426 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecrementClicks(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	clicksLeft := 2
+	assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{
+		OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gs", ClicksLeft: &clicksLeft}))
+	assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{
+		OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gu"}))
+
+	tests := []struct {
+		Name             string
+		ShortUrl         string
+		ExpectClicksLeft int
+		ExpectErr        error
+	}{
+		{
+			Name:             "first click",
+			ShortUrl:         "Abc_def_gs",
+			ExpectClicksLeft: 1,
+			ExpectErr:        nil,
+		},
+		{
+			Name:             "last click",
+			ShortUrl:         "Abc_def_gs",
+			ExpectClicksLeft: 0,
+			ExpectErr:        nil,
+		},
+		{
+			Name:      "exhausted link",
+			ShortUrl:  "Abc_def_gs",
+			ExpectErr: &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has reached its click limit"},
+		},
+		{
+			Name:      "unlimited link",
+			ShortUrl:  "Abc_def_gu",
+			ExpectErr: &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has reached its click limit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			res, err := urlRepo.DecrementClicks(ctx, tt.ShortUrl)
+
+			assert.Equal(t, tt.ExpectClicksLeft, res)
+			assert.Equal(t, tt.ExpectErr, err)
+		})
+	}
+
+	// the caller's value must not have been shared with the store
+	assert.Equal(t, 2, clicksLeft)
+}
+
+func TestDecrementClicksConcurrent(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	const maxClicks = 5
+	const resolutions = 100
+
+	clicksLeft := maxClicks
+	assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{
+		OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gs", ClicksLeft: &clicksLeft}))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+
+	for i := 0; i < resolutions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := urlRepo.DecrementClicks(ctx, "Abc_def_gs"); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, maxClicks, succeeded)
+
+	data, err := urlRepo.GetUrlData(ctx, "Abc_def_gs")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, *data.ClicksLeft)
+}
+
+func TestUpdateWindow(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gs"}))
+
+	notAfter := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	window := models.ActivationWindow{NotAfter: &notAfter, FallbackUrl: "http://ya.ru/soon"}
+
+	assert.NoError(t, urlRepo.UpdateWindow(ctx, "Abc_def_gs", &window))
+	assert.Equal(t, &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		urlRepo.UpdateWindow(ctx, "Abc_def_gt", &window))
+
+	// mutating the caller's window must not leak into the store
+	notAfter = notAfter.Add(time.Hour)
+
+	data, err := urlRepo.GetUrlData(ctx, "Abc_def_gs")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), *data.NotAfter)
+	assert.Equal(t, "http://ya.ru/soon", data.FallbackUrl)
+}
+
+func TestRecordClick(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gs"}))
+
+	assert.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_gs", -1, time.Now()))
+	assert.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_gs", 1, time.Now()))
+	assert.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_gs", 1, time.Now()))
+	assert.Equal(t, &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		urlRepo.RecordClick(ctx, "Abc_def_gt", -1, time.Now()))
+
+	clicks, err := urlRepo.GetClicks(ctx, "Abc_def_gs")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ClickData{Clicks: 3, VariantClicks: map[int]int64{1: 2}}, clicks)
+
+	// the returned counters are a snapshot
+	clicks.VariantClicks[1] = 100
+	clicks, err = urlRepo.GetClicks(ctx, "Abc_def_gs")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), clicks.VariantClicks[1])
+}
+
+func TestAddOriginalUrlCreatedAt(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	before := time.Now()
+	assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gs"}))
+	assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gt",
+		CreatedAt: createdAt}))
+
+	data, err := urlRepo.GetUrlData(ctx, "Abc_def_gs")
+	assert.NoError(t, err)
+	assert.False(t, data.CreatedAt.Before(before))
+
+	data, err = urlRepo.GetUrlData(ctx, "Abc_def_gt")
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt, data.CreatedAt)
+}
+
+func TestListLinks(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, data := range []models.UrlData{
+		{ShortUrl: "Abc_def_ga", LinkMetadata: models.LinkMetadata{Title: "Spring sale", Tags: []string{"Promo"}}},
+		{ShortUrl: "Abc_def_gb", LinkMetadata: models.LinkMetadata{Title: "Docs", Tags: []string{"internal"}}},
+		{ShortUrl: "Abc_def_gc", LinkMetadata: models.LinkMetadata{Title: "Summer SALE", Tags: []string{"promo", "summer"}}},
+		{ShortUrl: "Abc_def_gd"},
+	} {
+		data.OriginalUrl = "http://ya.ru"
+		data.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &data))
+	}
+
+	codes := func(links []*models.UrlData) []string {
+		res := []string{}
+		for _, link := range links {
+			res = append(res, link.ShortUrl)
+		}
+		return res
+	}
+
+	tests := []struct {
+		Name        string
+		Filter      models.LinkFilter
+		ExpectCodes []string
+	}{
+		{Name: "all links newest first", Filter: models.LinkFilter{}, ExpectCodes: []string{"Abc_def_gd", "Abc_def_gc", "Abc_def_gb", "Abc_def_ga"}},
+		{Name: "limit", Filter: models.LinkFilter{Limit: 2}, ExpectCodes: []string{"Abc_def_gd", "Abc_def_gc"}},
+		{Name: "tag ignores case", Filter: models.LinkFilter{Tag: "PROMO"}, ExpectCodes: []string{"Abc_def_gc", "Abc_def_ga"}},
+		{Name: "title substring ignores case", Filter: models.LinkFilter{Title: "sale"}, ExpectCodes: []string{"Abc_def_gc", "Abc_def_ga"}},
+		{Name: "tag and title", Filter: models.LinkFilter{Tag: "promo", Title: "spring"}, ExpectCodes: []string{"Abc_def_ga"}},
+		{Name: "no matches", Filter: models.LinkFilter{Tag: "pro"}, ExpectCodes: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			links, err := urlRepo.ListLinks(ctx, &tt.Filter)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ExpectCodes, codes(links))
+		})
+	}
+}
+
+func TestUpdateMetadata(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gs",
+		LinkMetadata: models.LinkMetadata{Title: "Old", Tags: []string{"a"}}}))
+
+	metadata := &models.LinkMetadata{Title: "New", Description: "d", Notes: "n", Tags: []string{"b", "c"}}
+	assert.NoError(t, urlRepo.UpdateMetadata(ctx, "Abc_def_gs", metadata))
+
+	// the stored tags don't share memory with the caller
+	metadata.Tags[0] = "x"
+
+	data, err := urlRepo.GetUrlData(ctx, "Abc_def_gs")
+	assert.NoError(t, err)
+	assert.Equal(t, models.LinkMetadata{Title: "New", Description: "d", Notes: "n", Tags: []string{"b", "c"}}, data.LinkMetadata)
+
+	assert.Equal(t, &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		urlRepo.UpdateMetadata(ctx, "Abc_def_gt", metadata))
+}
+
+func TestDeleteUrl(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	for _, shortUrl := range []string{"Abc_def_ga", "Abc_def_gb"} {
+		assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: shortUrl}))
+	}
+	assert.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_ga", -1, time.Now()))
+
+	assert.NoError(t, urlRepo.DeleteUrl(ctx, "Abc_def_ga"))
+
+	gone := &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has been deleted"}
+	_, err := urlRepo.GetUrlData(ctx, "Abc_def_ga")
+	assert.Equal(t, gone, err)
+	_, err = urlRepo.GetOriginalUrl(ctx, "Abc_def_ga")
+	assert.Equal(t, gone, err)
+
+	// the tombstone keeps the code
+	assert.Equal(t, &utils.InternalError{Code: http.StatusConflict, Message: "this shortUrl already exists"},
+		urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_ga"}))
+	taken, err := urlRepo.ExistingCodes(ctx, []string{"Abc_def_ga", "Abc_def_gc"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Abc_def_ga"}, taken)
+
+	// the deleted link is gone from both listing orders
+	for _, sort := range []string{"created_at", "clicks"} {
+		links, err := urlRepo.ListLinks(ctx, &models.LinkFilter{Sort: sort, Order: "desc"})
+		assert.NoError(t, err)
+		assert.Len(t, links, 1)
+		assert.Equal(t, "Abc_def_gb", links[0].ShortUrl)
+	}
+
+	assert.Equal(t, &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		urlRepo.DeleteUrl(ctx, "Abc_def_ga"))
+}
+
+func TestListLinksPages(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, data := range []models.UrlData{
+		{ShortUrl: "Abc_def_ga", OriginalUrl: "https://example.com/spring-sale", Owner: "team"},
+		{ShortUrl: "Abc_def_gb", OriginalUrl: "https://www.example.com/docs", Owner: "team"},
+		{ShortUrl: "Abc_def_gc", OriginalUrl: "https://notexample.com/", Owner: "other",
+			LinkMetadata: models.LinkMetadata{Title: "Spring launch"}},
+		{ShortUrl: "Abc_def_gd", OriginalUrl: "http://[2001:db8::1]:8080/", Owner: "team"},
+	} {
+		data.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &data))
+	}
+	for shortUrl, clicks := range map[string]int{"Abc_def_ga": 2, "Abc_def_gb": 5, "Abc_def_gd": 2} {
+		for i := 0; i < clicks; i++ {
+			assert.NoError(t, urlRepo.RecordClick(ctx, shortUrl, -1, time.Now()))
+		}
+	}
+
+	codes := func(links []*models.UrlData) []string {
+		res := []string{}
+		for _, link := range links {
+			res = append(res, link.ShortUrl)
+		}
+		return res
+	}
+
+	from := base.Add(time.Hour)
+	to := base.Add(3 * time.Hour)
+
+	tests := []struct {
+		Name        string
+		Filter      models.LinkFilter
+		ExpectCodes []string
+	}{
+		{Name: "owner", Filter: models.LinkFilter{Owner: "team"}, ExpectCodes: []string{"Abc_def_gd", "Abc_def_gb", "Abc_def_ga"}},
+		{Name: "exact host", Filter: models.LinkFilter{Host: "example.com"}, ExpectCodes: []string{"Abc_def_ga"}},
+		{Name: "ipv6 host", Filter: models.LinkFilter{Host: "2001:db8::1"}, ExpectCodes: []string{"Abc_def_gd"}},
+		{Name: "domain with subdomains", Filter: models.LinkFilter{Domain: "example.com"}, ExpectCodes: []string{"Abc_def_gb", "Abc_def_ga"}},
+		{Name: "search in destination", Filter: models.LinkFilter{Search: "Spring"}, ExpectCodes: []string{"Abc_def_gc", "Abc_def_ga"}},
+		{Name: "search needs every word", Filter: models.LinkFilter{Search: "spring sale"}, ExpectCodes: []string{"Abc_def_ga"}},
+		{Name: "search matches whole words", Filter: models.LinkFilter{Search: "spr"}, ExpectCodes: []string{}},
+		{Name: "creation range", Filter: models.LinkFilter{CreatedFrom: &from, CreatedTo: &to}, ExpectCodes: []string{"Abc_def_gc", "Abc_def_gb"}},
+		{Name: "oldest first", Filter: models.LinkFilter{Order: "asc", Limit: 2}, ExpectCodes: []string{"Abc_def_ga", "Abc_def_gb"}},
+		{Name: "most clicked first", Filter: models.LinkFilter{Sort: "clicks"}, ExpectCodes: []string{"Abc_def_gb", "Abc_def_gd", "Abc_def_ga", "Abc_def_gc"}},
+		{Name: "least clicked first", Filter: models.LinkFilter{Sort: "clicks", Order: "asc"}, ExpectCodes: []string{"Abc_def_gc", "Abc_def_ga", "Abc_def_gd", "Abc_def_gb"}},
+		{
+			Name: "page after cursor",
+			Filter: models.LinkFilter{Limit: 2,
+				After: &models.LinkCursor{CreatedAt: base.Add(2 * time.Hour), ShortUrl: "Abc_def_gc"}},
+			ExpectCodes: []string{"Abc_def_gb", "Abc_def_ga"},
+		},
+		{
+			Name: "page after clicks cursor",
+			Filter: models.LinkFilter{Sort: "clicks", Owner: "team",
+				After: &models.LinkCursor{Clicks: 2, ShortUrl: "Abc_def_gd"}},
+			ExpectCodes: []string{"Abc_def_ga"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			links, err := urlRepo.ListLinks(ctx, &tt.Filter)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ExpectCodes, codes(links))
+		})
+	}
+
+	links, err := urlRepo.ListLinks(ctx, &models.LinkFilter{Sort: "clicks", Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), links[0].Clicks)
+}
+
+func TestImportLinks(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: "taken", OriginalUrl: "https://example.com/"}))
+
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	skipped, err := urlRepo.ImportLinks(ctx, []*models.UrlData{
+		{ShortUrl: "taken", OriginalUrl: "https://example.com/other"},
+		{ShortUrl: "spring-sale-2020", OriginalUrl: "https://example.com/sale", Owner: "team", CreatedAt: createdAt,
+			LinkMetadata: models.LinkMetadata{Tags: []string{"promo"}}},
+	}, monthStart(time.Now()), func(*models.Usage) error { return nil })
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]error{"taken": &utils.InternalError{Code: http.StatusConflict,
+		Message: "this shortUrl already exists"}}, skipped)
+
+	data, err := urlRepo.GetUrlData(ctx, "taken")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/", data.OriginalUrl)
+
+	data, err = urlRepo.GetUrlData(ctx, "spring-sale-2020")
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt, data.CreatedAt)
+	assert.Equal(t, "team", data.Owner)
+
+	links, err := urlRepo.ListLinks(ctx, &models.LinkFilter{Order: "asc", Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, links, 1)
+	assert.Equal(t, "spring-sale-2020", links[0].ShortUrl)
+
+	codes, err := urlRepo.ExistingCodes(ctx, []string{"free", "taken", "spring-sale-2020"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"taken", "spring-sale-2020"}, codes)
+}
+
+func TestExportLinks(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, shortUrl := range []string{"Abc_def_ga", "Abc_def_gb", "Abc_def_gc"} {
+		assert.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: shortUrl, OriginalUrl: "https://example.com/",
+			Owner: "team", CreatedAt: base.Add(time.Duration(i) * time.Hour)}))
+	}
+
+	var exported []string
+	err := urlRepo.ExportLinks(ctx, &models.LinkFilter{Owner: "team", Order: "asc"}, func(data *models.UrlData) error {
+		exported = append(exported, data.ShortUrl)
+		// writers are not blocked by the export and don't change what it sees
+		return urlRepo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: data.ShortUrl + "_new",
+			OriginalUrl: "https://example.com/", Owner: "team", CreatedAt: base.Add(time.Minute)})
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Abc_def_ga", "Abc_def_gb", "Abc_def_gc"}, exported)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Equal(t, context.Canceled, urlRepo.ExportLinks(cancelled, &models.LinkFilter{},
+		func(*models.UrlData) error { return nil }))
+}
@@ -0,0 +1,76 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/events"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+type usageKey struct {
+	owner string
+	month time.Time
+}
+
+// monthStart returns the first instant of the month of t in UTC.
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// monthlyUsage returns the usage counters of owner in the month of t, the
+// mutex being held.
+func (ur *UrlRepository) monthlyUsage(owner string, t time.Time) *models.Usage {
+	key := usageKey{owner: owner, month: monthStart(t)}
+	usage, ok := ur.usage[key]
+	if !ok {
+		usage = &models.Usage{Owner: owner, Month: key.month}
+		ur.usage[key] = usage
+	}
+	return usage
+}
+
+// currentUsage returns a copy of the usage of owner in month, the mutex being
+// held.
+func (ur *UrlRepository) currentUsage(owner string, month time.Time) *models.Usage {
+	res := &models.Usage{Owner: owner, Month: month, ActiveLinks: ur.activeLinks[owner]}
+	if usage, ok := ur.usage[usageKey{owner: owner, month: monthStart(month)}]; ok {
+		res.Creations = usage.Creations
+		res.Resolutions = usage.Resolutions
+	}
+	return res
+}
+
+// AddOwnedUrl adds a link of an owner, counting its creation in month, once
+// check accepts the usage of the owner. The usage is checked and updated under
+// the same lock as the link is added, so concurrent additions can't all pass a
+// check at the limit.
+func (ur *UrlRepository) AddOwnedUrl(ctx context.Context, data *models.UrlData, month time.Time,
+	check func(*models.Usage) error) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	if err := check(ur.currentUsage(data.Owner, month)); err != nil {
+		return err
+	}
+	if _, ok := ur.store[data.ShortUrl]; ok {
+		return &utils.InternalError{Code: http.StatusConflict, Message: "this shortUrl already exists"}
+	}
+	ur.insert(data)
+	ur.monthlyUsage(data.Owner, month).Creations++
+	ur.enqueue(events.Created(data))
+	return nil
+}
+
+// GetUsage returns the usage of owner in month.
+func (ur *UrlRepository) GetUsage(ctx context.Context, owner string, month time.Time) (*models.Usage, error) {
+
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+
+	return ur.currentUsage(owner, month), nil
+}
@@ -0,0 +1,191 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+	month := monthStart(time.Now())
+	accept := func(*models.Usage) error { return nil }
+
+	for _, shortUrl := range []string{"Abc_def_ga", "Abc_def_gb"} {
+		require.NoError(t, urlRepo.AddOwnedUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: shortUrl,
+			Owner: "team"}, month, accept))
+	}
+	// imported links are counted as created too
+	_, err := urlRepo.ImportLinks(ctx, []*models.UrlData{{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gc",
+		Owner: "team"}}, month, accept)
+	require.NoError(t, err)
+	require.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gd"}))
+
+	require.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_ga", -1, month))
+	require.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_gb", -1, month))
+	require.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_gd", -1, month))
+	require.NoError(t, urlRepo.DeleteUrl(ctx, "Abc_def_ga"))
+
+	usage, err := urlRepo.GetUsage(ctx, "team", month)
+	require.NoError(t, err)
+	assert.Equal(t, &models.Usage{Owner: "team", Month: month, ActiveLinks: 2, Creations: 3, Resolutions: 2}, usage)
+
+	require.NoError(t, urlRepo.RestoreUrl(ctx, "Abc_def_ga", "", time.Now().Add(-time.Hour), month, accept))
+	usage, err = urlRepo.GetUsage(ctx, "team", month)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), usage.ActiveLinks)
+
+	// monthly usage starts over in the next month, and clicks count in the
+	// month they are recorded in
+	require.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_ga", -1, month.AddDate(0, 1, 0)))
+	usage, err = urlRepo.GetUsage(ctx, "team", month.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, &models.Usage{Owner: "team", Month: month.AddDate(0, 1, 0), ActiveLinks: 3, Resolutions: 1}, usage)
+
+	// tombstones loaded from snapshots are not active
+	require.NoError(t, urlRepo.DeleteUrl(ctx, "Abc_def_gb"))
+	path := filepath.Join(t.TempDir(), "links.jsonl")
+	require.NoError(t, urlRepo.SaveSnapshot(path))
+	loaded := NewUrlRepository()
+	require.NoError(t, loaded.LoadSnapshot(path))
+	usage, err = loaded.GetUsage(ctx, "team", month)
+	require.NoError(t, err)
+	assert.Equal(t, &models.Usage{Owner: "team", Month: month, ActiveLinks: 2}, usage)
+}
+
+func TestAddOwnedUrlRejected(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+	month := monthStart(time.Now())
+	quotaErr := utils.NewInternalError(http.StatusForbidden, "quota reached")
+
+	err := urlRepo.AddOwnedUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_ga", Owner: "team"},
+		month, func(*models.Usage) error { return quotaErr })
+	assert.Equal(t, quotaErr, err)
+
+	_, err = urlRepo.GetUrlData(ctx, "Abc_def_ga")
+	assert.Equal(t, &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}, err)
+	usage, err := urlRepo.GetUsage(ctx, "team", month)
+	require.NoError(t, err)
+	assert.Equal(t, &models.Usage{Owner: "team", Month: month}, usage)
+}
+
+func TestAddOwnedUrlConcurrently(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+	month := monthStart(time.Now())
+	quotaErr := utils.NewInternalError(http.StatusForbidden, "quota reached")
+
+	const limit = 10
+	check := func(usage *models.Usage) error {
+		if usage.ActiveLinks >= limit {
+			return quotaErr
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := urlRepo.AddOwnedUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru",
+				ShortUrl: fmt.Sprintf("Abc_def_%02d", i), Owner: "team"}, month, check)
+			if err == nil {
+				mu.Lock()
+				added++
+				mu.Unlock()
+			} else {
+				assert.Equal(t, quotaErr, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, limit, added)
+	usage, err := urlRepo.GetUsage(ctx, "team", month)
+	require.NoError(t, err)
+	assert.Equal(t, &models.Usage{Owner: "team", Month: month, ActiveLinks: limit, Creations: limit}, usage)
+}
+
+func TestImportLinksQuota(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+	month := monthStart(time.Now())
+	quotaErr := utils.NewInternalError(http.StatusForbidden, "quota reached")
+	// at most two active links per owner
+	check := func(usage *models.Usage) error {
+		if usage.ActiveLinks >= 2 {
+			return quotaErr
+		}
+		return nil
+	}
+
+	require.NoError(t, urlRepo.AddOwnedUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_ga",
+		Owner: "team"}, month, check))
+
+	skipped, err := urlRepo.ImportLinks(ctx, []*models.UrlData{
+		{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_ga", Owner: "team"},
+		{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gb", Owner: "team"},
+		{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gc", Owner: "team"},
+		{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_gd", Owner: "other"},
+		{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_ge"},
+	}, month, check)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]error{
+		"Abc_def_ga": &utils.InternalError{Code: http.StatusConflict, Message: "this shortUrl already exists"},
+		"Abc_def_gc": quotaErr,
+	}, skipped)
+
+	usage, err := urlRepo.GetUsage(ctx, "team", month)
+	require.NoError(t, err)
+	assert.Equal(t, &models.Usage{Owner: "team", Month: month, ActiveLinks: 2, Creations: 2}, usage)
+	usage, err = urlRepo.GetUsage(ctx, "other", month)
+	require.NoError(t, err)
+	assert.Equal(t, &models.Usage{Owner: "other", Month: month, ActiveLinks: 1, Creations: 1}, usage)
+}
+
+func TestRestoreUrlQuota(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+	month := monthStart(time.Now())
+	accept := func(*models.Usage) error { return nil }
+	quotaErr := utils.NewInternalError(http.StatusForbidden, "quota reached")
+
+	require.NoError(t, urlRepo.AddOwnedUrl(ctx, &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_def_ga",
+		Owner: "team"}, month, accept))
+	require.NoError(t, urlRepo.DeleteUrl(ctx, "Abc_def_ga"))
+
+	var checked *models.Usage
+	err := urlRepo.RestoreUrl(ctx, "Abc_def_ga", "", time.Now().Add(-time.Hour), month,
+		func(usage *models.Usage) error {
+			checked = usage
+			return quotaErr
+		})
+	assert.Equal(t, quotaErr, err)
+	assert.Equal(t, &models.Usage{Owner: "team", Month: month, Creations: 1}, checked)
+
+	// the link stays deleted
+	_, err = urlRepo.GetUrlData(ctx, "Abc_def_ga")
+	assert.Equal(t, &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has been deleted"}, err)
+	usage, err := urlRepo.GetUsage(ctx, "team", month)
+	require.NoError(t, err)
+	assert.Zero(t, usage.ActiveLinks)
+}
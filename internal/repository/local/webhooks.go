@@ -0,0 +1,231 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/events"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+func copySubscription(subscription *models.WebhookSubscription) *models.WebhookSubscription {
+	res := *subscription
+	res.Events = append([]string(nil), subscription.Events...)
+	if subscription.ClickThresholds != nil {
+		res.ClickThresholds = append([]int64(nil), subscription.ClickThresholds...)
+	}
+	return &res
+}
+
+func copyDelivery(delivery *models.WebhookDelivery) *models.WebhookDelivery {
+	res := *delivery
+	res.Payload = append(json.RawMessage(nil), delivery.Payload...)
+	return &res
+}
+
+// matches reports whether subscription receives event.
+func matches(subscription *models.WebhookSubscription, event *models.WebhookEvent) bool {
+	if !slices.Contains(subscription.Events, event.Type) {
+		return false
+	}
+	if subscription.Owner != "" && subscription.Owner != event.Owner {
+		return false
+	}
+	return event.Type != events.LinkClicksThreshold || slices.Contains(subscription.ClickThresholds, event.Clicks)
+}
+
+// enqueue writes a delivery of every event to the outbox for each
+// subscription it matches, the mutex being held together with the change the
+// events describe.
+func (ur *UrlRepository) enqueue(evts ...*models.WebhookEvent) {
+	for _, event := range evts {
+		// events consist of plain values and always marshal
+		payload, _ := json.Marshal(event)
+		for _, subscription := range ur.webhooks {
+			if !matches(subscription, event) {
+				continue
+			}
+			ur.nextDeliveryID++
+			ur.outbox[ur.nextDeliveryID] = &models.WebhookDelivery{ID: ur.nextDeliveryID, SubscriptionID: subscription.ID,
+				EventID: event.ID, EventType: event.Type, Payload: payload, NextAttemptAt: event.CreatedAt,
+				CreatedAt: event.CreatedAt}
+		}
+	}
+}
+
+// CreateWebhook stores a subscription, setting its id and creation time.
+func (ur *UrlRepository) CreateWebhook(ctx context.Context, subscription *models.WebhookSubscription) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	ur.nextWebhookID++
+	subscription.ID, subscription.CreatedAt = ur.nextWebhookID, time.Now().UTC()
+	ur.webhooks[subscription.ID] = copySubscription(subscription)
+	return nil
+}
+
+// ListWebhooks returns the subscriptions of owner, or all of them when owner
+// is empty, without their secrets.
+func (ur *UrlRepository) ListWebhooks(ctx context.Context, owner string) ([]*models.WebhookSubscription, error) {
+
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+
+	var res []*models.WebhookSubscription
+	for _, subscription := range ur.webhooks {
+		if owner != "" && subscription.Owner != owner {
+			continue
+		}
+		val := copySubscription(subscription)
+		val.Secret = ""
+		res = append(res, val)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+// DeleteWebhook deletes a subscription of owner, or any subscription when
+// owner is empty, together with its pending deliveries.
+func (ur *UrlRepository) DeleteWebhook(ctx context.Context, id int64, owner string) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	subscription, ok := ur.webhooks[id]
+	if !ok || owner != "" && subscription.Owner != owner {
+		return utils.NewInternalError(http.StatusNotFound, "no webhook with this id")
+	}
+	delete(ur.webhooks, id)
+	for deliveryID, delivery := range ur.outbox {
+		if delivery.SubscriptionID == id {
+			delete(ur.outbox, deliveryID)
+		}
+	}
+	return nil
+}
+
+// ListDeadLetters returns up to limit deliveries that ran out of attempts, of
+// the subscriptions of owner or of all subscriptions when owner is empty.
+func (ur *UrlRepository) ListDeadLetters(ctx context.Context, owner string, limit int) ([]*models.WebhookDelivery, error) {
+
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+
+	var res []*models.WebhookDelivery
+	for _, delivery := range ur.outbox {
+		subscription := ur.webhooks[delivery.SubscriptionID]
+		if !delivery.Dead || owner != "" && subscription.Owner != owner {
+			continue
+		}
+		val := copyDelivery(delivery)
+		val.Url = subscription.Url
+		res = append(res, val)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+// RetryDeadLetter schedules a dead letter of the subscriptions of owner, or
+// of any subscription when owner is empty, for delivery at now with a fresh
+// set of attempts.
+func (ur *UrlRepository) RetryDeadLetter(ctx context.Context, id int64, owner string, now time.Time) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	delivery, ok := ur.outbox[id]
+	if !ok || !delivery.Dead || owner != "" && ur.webhooks[delivery.SubscriptionID].Owner != owner {
+		return utils.NewInternalError(http.StatusNotFound, "no dead letter with this id")
+	}
+	delivery.Dead, delivery.Attempts, delivery.NextAttemptAt = false, 0, now
+	return nil
+}
+
+// ClaimWebhookDeliveries returns up to limit deliveries due at now, which
+// are not returned again before lockedUntil.
+func (ur *UrlRepository) ClaimWebhookDeliveries(ctx context.Context, now, lockedUntil time.Time,
+	limit int) ([]*models.WebhookDelivery, error) {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	var due []*models.WebhookDelivery
+	for _, delivery := range ur.outbox {
+		if !delivery.Dead && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	res := make([]*models.WebhookDelivery, 0, len(due))
+	for _, delivery := range due {
+		delivery.NextAttemptAt = lockedUntil
+		val := copyDelivery(delivery)
+		subscription := ur.webhooks[delivery.SubscriptionID]
+		val.Url, val.Secret = subscription.Url, subscription.Secret
+		res = append(res, val)
+	}
+	return res, nil
+}
+
+// CompleteWebhookDelivery removes a delivered event from the outbox.
+func (ur *UrlRepository) CompleteWebhookDelivery(ctx context.Context, id int64) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	delete(ur.outbox, id)
+	return nil
+}
+
+// FailWebhookDelivery stores the attempts, next attempt, error and dead
+// letter flag of a failed delivery.
+func (ur *UrlRepository) FailWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	val, ok := ur.outbox[delivery.ID]
+	if !ok {
+		return nil
+	}
+	val.Attempts, val.NextAttemptAt, val.LastError, val.Dead = delivery.Attempts, delivery.NextAttemptAt,
+		delivery.LastError, delivery.Dead
+	return nil
+}
+
+// EnqueueExpiredLinks writes link.expired events of the links whose
+// activation window ended by now, once per window, and returns their number.
+func (ur *UrlRepository) EnqueueExpiredLinks(ctx context.Context, now time.Time) (int, error) {
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	expired := 0
+	for shortUrl, val := range ur.store {
+		if ur.expiryNotified[shortUrl] || val.DeletedAt != nil || val.NotAfter == nil || val.NotAfter.After(now) {
+			continue
+		}
+		ur.expiryNotified[shortUrl] = true
+		ur.enqueue(events.Expired(shortUrl, val.Owner, events.ReasonNotAfter))
+		expired++
+	}
+	return expired, nil
+}
@@ -0,0 +1,162 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookOutbox(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	all := &models.WebhookSubscription{Url: "https://example.com/all", Secret: "secret-all",
+		Events: []string{"link.created", "link.deleted", "link.clicks_threshold"}, ClickThresholds: []int64{2}}
+	team := &models.WebhookSubscription{Url: "https://example.com/team", Secret: "secret-team",
+		Events: []string{"link.created", "link.expired"}, Owner: "team"}
+	require.NoError(t, urlRepo.CreateWebhook(ctx, all))
+	require.NoError(t, urlRepo.CreateWebhook(ctx, team))
+
+	clicksLeft := 1
+	require.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "http://ya.ru",
+		Owner: "team", ClicksLeft: &clicksLeft}))
+	require.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: "Abc_def_gb", OriginalUrl: "http://ya.ru"}))
+	_, err := urlRepo.DecrementClicks(ctx, "Abc_def_ga")
+	require.NoError(t, err)
+	require.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_gb", -1, time.Now()))
+	require.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_gb", -1, time.Now()))
+	require.NoError(t, urlRepo.RecordClick(ctx, "Abc_def_gb", -1, time.Now()))
+	require.NoError(t, urlRepo.DeleteUrl(ctx, "Abc_def_gb"))
+
+	now := time.Now().Add(time.Second)
+	deliveries, err := urlRepo.ClaimWebhookDeliveries(ctx, now, now.Add(time.Minute), 100)
+	require.NoError(t, err)
+
+	type sent struct {
+		Url    string
+		Secret string
+		Type   string
+		Code   string
+	}
+	var got []sent
+	for _, delivery := range deliveries {
+		event := &models.WebhookEvent{}
+		require.NoError(t, json.Unmarshal(delivery.Payload, event))
+		assert.Equal(t, delivery.EventID, event.ID)
+		got = append(got, sent{Url: delivery.Url, Secret: delivery.Secret, Type: event.Type, Code: event.Code})
+	}
+	assert.ElementsMatch(t, []sent{
+		{Url: "https://example.com/all", Secret: "secret-all", Type: "link.created", Code: "Abc_def_ga"},
+		{Url: "https://example.com/team", Secret: "secret-team", Type: "link.created", Code: "Abc_def_ga"},
+		{Url: "https://example.com/all", Secret: "secret-all", Type: "link.created", Code: "Abc_def_gb"},
+		{Url: "https://example.com/team", Secret: "secret-team", Type: "link.expired", Code: "Abc_def_ga"},
+		{Url: "https://example.com/all", Secret: "secret-all", Type: "link.clicks_threshold", Code: "Abc_def_gb"},
+		{Url: "https://example.com/all", Secret: "secret-all", Type: "link.deleted", Code: "Abc_def_gb"},
+	}, got)
+
+	// claimed deliveries are locked
+	again, err := urlRepo.ClaimWebhookDeliveries(ctx, now, now.Add(time.Minute), 100)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	// deleting a subscription drops its deliveries
+	require.NoError(t, urlRepo.DeleteWebhook(ctx, all.ID, ""))
+	again, err = urlRepo.ClaimWebhookDeliveries(ctx, now.Add(time.Hour), now.Add(time.Hour), 100)
+	require.NoError(t, err)
+	assert.Len(t, again, 2)
+}
+
+func TestWebhooksOwner(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	subscription := &models.WebhookSubscription{Url: "https://example.com/hook", Secret: "secret",
+		Events: []string{"link.created"}, Owner: "team"}
+	require.NoError(t, urlRepo.CreateWebhook(ctx, subscription))
+
+	list, err := urlRepo.ListWebhooks(ctx, "team")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Empty(t, list[0].Secret)
+
+	list, err = urlRepo.ListWebhooks(ctx, "other")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	err = urlRepo.DeleteWebhook(ctx, subscription.ID, "other")
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no webhook with this id"), err)
+	assert.NoError(t, urlRepo.DeleteWebhook(ctx, subscription.ID, "team"))
+}
+
+func TestDeadLetters(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	require.NoError(t, urlRepo.CreateWebhook(ctx, &models.WebhookSubscription{Url: "https://example.com/hook",
+		Secret: "secret", Events: []string{"link.created"}, Owner: "team"}))
+	require.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "http://ya.ru",
+		Owner: "team"}))
+
+	now := time.Now().Add(time.Second)
+	deliveries, err := urlRepo.ClaimWebhookDeliveries(ctx, now, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	delivery := deliveries[0]
+	delivery.Attempts, delivery.LastError, delivery.Dead = 5, "status 500", true
+	require.NoError(t, urlRepo.FailWebhookDelivery(ctx, delivery))
+
+	dead, err := urlRepo.ListDeadLetters(ctx, "team", 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "status 500", dead[0].LastError)
+	assert.Equal(t, "https://example.com/hook", dead[0].Url)
+	assert.Empty(t, dead[0].Secret)
+
+	err = urlRepo.RetryDeadLetter(ctx, delivery.ID, "other", now)
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no dead letter with this id"), err)
+	require.NoError(t, urlRepo.RetryDeadLetter(ctx, delivery.ID, "team", now))
+
+	deliveries, err = urlRepo.ClaimWebhookDeliveries(ctx, now, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, 0, deliveries[0].Attempts)
+}
+
+func TestEnqueueExpiredLinks(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	require.NoError(t, urlRepo.CreateWebhook(ctx, &models.WebhookSubscription{Url: "https://example.com/hook",
+		Secret: "secret", Events: []string{"link.expired"}}))
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	notAfter := now.Add(-time.Minute)
+	require.NoError(t, urlRepo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "http://ya.ru",
+		ActivationWindow: models.ActivationWindow{NotAfter: &notAfter}}))
+
+	expired, err := urlRepo.EnqueueExpiredLinks(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	// a link expires once per window
+	expired, err = urlRepo.EnqueueExpiredLinks(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, expired)
+
+	require.NoError(t, urlRepo.UpdateWindow(ctx, "Abc_def_ga", &models.ActivationWindow{NotAfter: &now}))
+	expired, err = urlRepo.EnqueueExpiredLinks(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+}
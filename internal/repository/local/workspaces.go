@@ -0,0 +1,216 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+type memberKey struct {
+	workspace string
+	name      string
+}
+
+// workspaceStore holds the workspaces with their members and keys, which are
+// not links and are not saved in snapshots.
+type workspaceStore struct {
+	workspaces map[string]*models.Workspace
+	members    map[memberKey]*models.Member
+	keys       map[int64]*models.WorkspaceKey
+	digests    map[string]int64
+	nextKeyID  int64
+}
+
+func newWorkspaceStore() workspaceStore {
+	return workspaceStore{workspaces: make(map[string]*models.Workspace), members: make(map[memberKey]*models.Member),
+		keys: make(map[int64]*models.WorkspaceKey), digests: make(map[string]int64)}
+}
+
+// withRole returns a copy of key with the role of its member, the mutex being
+// held.
+func (ws *workspaceStore) withRole(key *models.WorkspaceKey) *models.WorkspaceKey {
+	res := *key
+	res.Digest = ""
+	res.Role = ws.members[memberKey{workspace: key.Workspace, name: key.Member}].Role
+	return &res
+}
+
+func (ur *UrlRepository) CreateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+
+	ur.workspaceMu.Lock()
+	defer ur.workspaceMu.Unlock()
+
+	if _, ok := ur.ws.workspaces[workspace.Slug]; ok {
+		return utils.NewInternalError(http.StatusConflict, "this workspace already exists")
+	}
+	workspace.CreatedAt = time.Now()
+	val := *workspace
+	ur.ws.workspaces[workspace.Slug] = &val
+	return nil
+}
+
+func (ur *UrlRepository) GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error) {
+
+	ur.workspaceMu.RLock()
+	defer ur.workspaceMu.RUnlock()
+
+	workspace, ok := ur.ws.workspaces[slug]
+	if !ok {
+		return nil, utils.NewInternalError(http.StatusNotFound, "no workspace match this slug")
+	}
+	res := *workspace
+	return &res, nil
+}
+
+func (ur *UrlRepository) ListWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+
+	ur.workspaceMu.RLock()
+	defer ur.workspaceMu.RUnlock()
+
+	res := make([]*models.Workspace, 0, len(ur.ws.workspaces))
+	for _, workspace := range ur.ws.workspaces {
+		val := *workspace
+		res = append(res, &val)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Slug < res[j].Slug })
+	return res, nil
+}
+
+func (ur *UrlRepository) AddMember(ctx context.Context, member *models.Member) error {
+
+	ur.workspaceMu.Lock()
+	defer ur.workspaceMu.Unlock()
+
+	if _, ok := ur.ws.workspaces[member.Workspace]; !ok {
+		return utils.NewInternalError(http.StatusNotFound, "no workspace match this slug")
+	}
+	key := memberKey{workspace: member.Workspace, name: member.Name}
+	if _, ok := ur.ws.members[key]; ok {
+		return utils.NewInternalError(http.StatusConflict, "this member already exists")
+	}
+	member.CreatedAt = time.Now()
+	val := *member
+	ur.ws.members[key] = &val
+	return nil
+}
+
+func (ur *UrlRepository) ListMembers(ctx context.Context, workspace string) ([]*models.Member, error) {
+
+	ur.workspaceMu.RLock()
+	defer ur.workspaceMu.RUnlock()
+
+	var res []*models.Member
+	for key, member := range ur.ws.members {
+		if key.workspace == workspace {
+			val := *member
+			res = append(res, &val)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// UpdateMemberRole changes the role of a member, which applies to the keys
+// issued to it from their next request.
+func (ur *UrlRepository) UpdateMemberRole(ctx context.Context, workspace, name, role string) error {
+
+	ur.workspaceMu.Lock()
+	defer ur.workspaceMu.Unlock()
+
+	member, ok := ur.ws.members[memberKey{workspace: workspace, name: name}]
+	if !ok {
+		return utils.NewInternalError(http.StatusNotFound, "no member match this name")
+	}
+	member.Role = role
+	return nil
+}
+
+// RemoveMember removes a member from a workspace together with its keys.
+func (ur *UrlRepository) RemoveMember(ctx context.Context, workspace, name string) error {
+
+	ur.workspaceMu.Lock()
+	defer ur.workspaceMu.Unlock()
+
+	key := memberKey{workspace: workspace, name: name}
+	if _, ok := ur.ws.members[key]; !ok {
+		return utils.NewInternalError(http.StatusNotFound, "no member match this name")
+	}
+	delete(ur.ws.members, key)
+	for id, workspaceKey := range ur.ws.keys {
+		if workspaceKey.Workspace == workspace && workspaceKey.Member == name {
+			delete(ur.ws.digests, workspaceKey.Digest)
+			delete(ur.ws.keys, id)
+		}
+	}
+	return nil
+}
+
+// CreateWorkspaceKey stores the digest of a key issued to a member.
+func (ur *UrlRepository) CreateWorkspaceKey(ctx context.Context, key *models.WorkspaceKey) error {
+
+	ur.workspaceMu.Lock()
+	defer ur.workspaceMu.Unlock()
+
+	if _, ok := ur.ws.members[memberKey{workspace: key.Workspace, name: key.Member}]; !ok {
+		return utils.NewInternalError(http.StatusNotFound, "no member match this name")
+	}
+	for _, other := range ur.ws.keys {
+		if other.Workspace == key.Workspace && other.Name == key.Name {
+			return utils.NewInternalError(http.StatusConflict, "this api key name already exists")
+		}
+	}
+	ur.ws.nextKeyID++
+	key.ID, key.CreatedAt = ur.ws.nextKeyID, time.Now()
+	val := *key
+	val.Key, val.Role = "", ""
+	ur.ws.keys[val.ID] = &val
+	ur.ws.digests[val.Digest] = val.ID
+	return nil
+}
+
+func (ur *UrlRepository) ListWorkspaceKeys(ctx context.Context, workspace string) ([]*models.WorkspaceKey, error) {
+
+	ur.workspaceMu.RLock()
+	defer ur.workspaceMu.RUnlock()
+
+	var res []*models.WorkspaceKey
+	for _, key := range ur.ws.keys {
+		if key.Workspace == workspace {
+			res = append(res, ur.ws.withRole(key))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func (ur *UrlRepository) DeleteWorkspaceKey(ctx context.Context, workspace string, id int64) error {
+
+	ur.workspaceMu.Lock()
+	defer ur.workspaceMu.Unlock()
+
+	key, ok := ur.ws.keys[id]
+	if !ok || key.Workspace != workspace {
+		return utils.NewInternalError(http.StatusNotFound, "no api key with this id")
+	}
+	delete(ur.ws.digests, key.Digest)
+	delete(ur.ws.keys, id)
+	return nil
+}
+
+// FindWorkspaceKey returns the key with the given digest along with the
+// current role of its member.
+func (ur *UrlRepository) FindWorkspaceKey(ctx context.Context, digest string) (*models.WorkspaceKey, error) {
+
+	ur.workspaceMu.RLock()
+	defer ur.workspaceMu.RUnlock()
+
+	id, ok := ur.ws.digests[digest]
+	if !ok {
+		return nil, utils.NewInternalError(http.StatusNotFound, "no api key match this digest")
+	}
+	return ur.ws.withRole(ur.ws.keys[id]), nil
+}
@@ -0,0 +1,115 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaces(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	require.NoError(t, urlRepo.CreateWorkspace(ctx, &models.Workspace{Slug: "team", Name: "Team"}))
+	require.NoError(t, urlRepo.CreateWorkspace(ctx, &models.Workspace{Slug: "acme"}))
+	err := urlRepo.CreateWorkspace(ctx, &models.Workspace{Slug: "team"})
+	assert.Equal(t, utils.NewInternalError(http.StatusConflict, "this workspace already exists"), err)
+
+	workspace, err := urlRepo.GetWorkspace(ctx, "team")
+	require.NoError(t, err)
+	assert.Equal(t, "Team", workspace.Name)
+	assert.False(t, workspace.CreatedAt.IsZero())
+
+	_, err = urlRepo.GetWorkspace(ctx, "other")
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no workspace match this slug"), err)
+
+	workspaces, err := urlRepo.ListWorkspaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, workspaces, 2)
+	assert.Equal(t, "acme", workspaces[0].Slug)
+	assert.Equal(t, "team", workspaces[1].Slug)
+}
+
+func TestMembers(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	require.NoError(t, urlRepo.CreateWorkspace(ctx, &models.Workspace{Slug: "team"}))
+	require.NoError(t, urlRepo.AddMember(ctx, &models.Member{Workspace: "team", Name: "bob", Role: "viewer"}))
+	require.NoError(t, urlRepo.AddMember(ctx, &models.Member{Workspace: "team", Name: "alice", Role: "admin"}))
+
+	err := urlRepo.AddMember(ctx, &models.Member{Workspace: "team", Name: "bob", Role: "editor"})
+	assert.Equal(t, utils.NewInternalError(http.StatusConflict, "this member already exists"), err)
+	err = urlRepo.AddMember(ctx, &models.Member{Workspace: "other", Name: "bob", Role: "editor"})
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no workspace match this slug"), err)
+
+	require.NoError(t, urlRepo.UpdateMemberRole(ctx, "team", "bob", "editor"))
+	err = urlRepo.UpdateMemberRole(ctx, "team", "carol", "editor")
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no member match this name"), err)
+
+	members, err := urlRepo.ListMembers(ctx, "team")
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, []string{"alice", "bob"}, []string{members[0].Name, members[1].Name})
+	assert.Equal(t, "editor", members[1].Role)
+
+	require.NoError(t, urlRepo.RemoveMember(ctx, "team", "bob"))
+	err = urlRepo.RemoveMember(ctx, "team", "bob")
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no member match this name"), err)
+}
+
+func TestWorkspaceKeys(t *testing.T) {
+
+	urlRepo := NewUrlRepository()
+	ctx := context.Background()
+
+	require.NoError(t, urlRepo.CreateWorkspace(ctx, &models.Workspace{Slug: "team"}))
+	require.NoError(t, urlRepo.CreateWorkspace(ctx, &models.Workspace{Slug: "acme"}))
+	require.NoError(t, urlRepo.AddMember(ctx, &models.Member{Workspace: "team", Name: "ci", Role: "editor"}))
+	require.NoError(t, urlRepo.AddMember(ctx, &models.Member{Workspace: "acme", Name: "ci", Role: "viewer"}))
+
+	key := &models.WorkspaceKey{Workspace: "team", Name: "deploy", Member: "ci", Key: "secret", Digest: "digest-1"}
+	require.NoError(t, urlRepo.CreateWorkspaceKey(ctx, key))
+	assert.Equal(t, int64(1), key.ID)
+
+	err := urlRepo.CreateWorkspaceKey(ctx, &models.WorkspaceKey{Workspace: "team", Name: "deploy", Member: "ci",
+		Digest: "digest-2"})
+	assert.Equal(t, utils.NewInternalError(http.StatusConflict, "this api key name already exists"), err)
+	err = urlRepo.CreateWorkspaceKey(ctx, &models.WorkspaceKey{Workspace: "team", Name: "other", Member: "bob",
+		Digest: "digest-2"})
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no member match this name"), err)
+	// key names are unique within a workspace only
+	require.NoError(t, urlRepo.CreateWorkspaceKey(ctx, &models.WorkspaceKey{Workspace: "acme", Name: "deploy",
+		Member: "ci", Digest: "digest-3"}))
+
+	// keys act with the current role of their member
+	require.NoError(t, urlRepo.UpdateMemberRole(ctx, "team", "ci", "admin"))
+	found, err := urlRepo.FindWorkspaceKey(ctx, "digest-1")
+	require.NoError(t, err)
+	assert.Equal(t, &models.WorkspaceKey{ID: 1, Workspace: "team", Name: "deploy", Member: "ci", Role: "admin",
+		CreatedAt: key.CreatedAt}, found)
+
+	keys, err := urlRepo.ListWorkspaceKeys(ctx, "team")
+	require.NoError(t, err)
+	assert.Equal(t, []*models.WorkspaceKey{found}, keys)
+
+	// keys of other workspaces can't be deleted
+	err = urlRepo.DeleteWorkspaceKey(ctx, "acme", 1)
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no api key with this id"), err)
+
+	// removing a member revokes its keys
+	require.NoError(t, urlRepo.RemoveMember(ctx, "team", "ci"))
+	_, err = urlRepo.FindWorkspaceKey(ctx, "digest-1")
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no api key match this digest"), err)
+
+	require.NoError(t, urlRepo.DeleteWorkspaceKey(ctx, "acme", 2))
+	_, err = urlRepo.FindWorkspaceKey(ctx, "digest-3")
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no api key match this digest"), err)
+}
@@ -0,0 +1,150 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+// auditLockKey is the advisory lock serializing appends to the audit log, so
+// every entry is chained to the one before it.
+const auditLockKey = 0x61756469
+
+const auditColumns = `id, time, actor, source_ip, action, target, before_value, after_value, prev_hash, hash`
+
+// AppendAuditEntry appends entry to the audit log, setting its id and hashes.
+func (ur *UrlRepository) AppendAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.AppendAuditEntry: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditLockKey); err != nil {
+		return fmt.Errorf("pg.UrlRepository.AppendAuditEntry: %w", err)
+	}
+
+	var prevHash string
+	err = tx.QueryRowContext(ctx, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&prevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("pg.UrlRepository.AppendAuditEntry: %w", err)
+	}
+
+	// ids are taken under the lock, so they follow the order of the chain
+	var id int64
+	if err := tx.QueryRowContext(ctx, `SELECT nextval('audit_log_id_seq')`).Scan(&id); err != nil {
+		return fmt.Errorf("pg.UrlRepository.AppendAuditEntry: %w", err)
+	}
+
+	entry.ID, entry.Time, entry.PrevHash = id, entry.Time.UTC().Truncate(time.Microsecond), prevHash
+	entry.Hash = audit.Hash(entry, prevHash)
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO audit_log (`+auditColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		entry.ID, entry.Time, entry.Actor, entry.SourceIP, entry.Action, entry.Target, nullRaw(entry.Before),
+		nullRaw(entry.After), entry.PrevHash, entry.Hash)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.AppendAuditEntry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pg.UrlRepository.AppendAuditEntry: %w", err)
+	}
+	return nil
+}
+
+// nullRaw stores absent values as NULL, keeping the others byte for byte, as
+// they are hashed.
+func nullRaw(value []byte) any {
+	if value == nil {
+		return nil
+	}
+	return string(value)
+}
+
+// buildAuditQuery translates filter into a keyset query on the ids.
+func buildAuditQuery(filter *models.AuditFilter) (string, []any) {
+	q := &listQuery{}
+
+	if filter.Actor != "" {
+		q.where(`actor = ` + q.arg(filter.Actor))
+	}
+	if filter.Action != "" {
+		q.where(`action = ` + q.arg(filter.Action))
+	}
+	if filter.Target != "" {
+		q.where(`target = ` + q.arg(filter.Target))
+	}
+	if filter.From != nil {
+		q.where(`time >= ` + q.arg(*filter.From))
+	}
+	if filter.To != nil {
+		q.where(`time < ` + q.arg(*filter.To))
+	}
+
+	direction, comparison := "DESC", "<"
+	if filter.Order == "asc" {
+		direction, comparison = "ASC", ">"
+	}
+	if filter.Cursor > 0 {
+		q.where(`id ` + comparison + ` ` + q.arg(filter.Cursor))
+	}
+
+	query := strings.Builder{}
+	query.WriteString(`SELECT ` + auditColumns + ` FROM audit_log`)
+	if len(q.conditions) > 0 {
+		query.WriteString(` WHERE ` + strings.Join(q.conditions, ` AND `))
+	}
+	query.WriteString(` ORDER BY id ` + direction)
+	if filter.Limit > 0 {
+		query.WriteString(` LIMIT ` + q.arg(filter.Limit))
+	}
+	return query.String(), q.args
+}
+
+// ListAuditEntries returns up to filter.Limit audit entries matching filter,
+// continuing after filter.Cursor.
+func (ur *UrlRepository) ListAuditEntries(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	query, args := buildAuditQuery(filter)
+
+	rows, err := ur.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListAuditEntries: %w", err)
+	}
+	defer rows.Close()
+
+	var res []*models.AuditEntry
+	for rows.Next() {
+		entry := &models.AuditEntry{}
+		var before, after sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.Time, &entry.Actor, &entry.SourceIP, &entry.Action, &entry.Target,
+			&before, &after, &entry.PrevHash, &entry.Hash); err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.ListAuditEntries: %w", err)
+		}
+		if before.Valid {
+			entry.Before = []byte(before.String)
+		}
+		if after.Valid {
+			entry.After = []byte(after.String)
+		}
+		entry.Time = entry.Time.UTC()
+		res = append(res, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListAuditEntries: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,126 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAuditEntry(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	tests := []struct {
+		Name     string
+		PrevHash string
+	}{
+		{Name: "first entry"},
+		{Name: "chained entry", PrevHash: "0123456789abcdef"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			entry := &models.AuditEntry{Time: time.Date(2026, time.October, 19, 12, 0, 0, 1500, time.UTC),
+				Actor: "migration", SourceIP: "192.0.2.1", Action: audit.LinkDelete, Target: "Abc_def_gs",
+				Before: []byte(`{"short_url":"Abc_def_gs"}`)}
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).WithArgs(auditLockKey).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			prev := mock.ExpectQuery(regexp.QuoteMeta(`SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`))
+			if tt.PrevHash == "" {
+				prev.WillReturnError(sql.ErrNoRows)
+			} else {
+				prev.WillReturnRows(mock.NewRows([]string{"hash"}).AddRow(tt.PrevHash))
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT nextval('audit_log_id_seq')`)).
+				WillReturnRows(mock.NewRows([]string{"nextval"}).AddRow(5))
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO audit_log (`+auditColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`)).
+				WithArgs(5, entry.Time.Truncate(time.Microsecond), "migration", "192.0.2.1", audit.LinkDelete, "Abc_def_gs",
+					`{"short_url":"Abc_def_gs"}`, nil, tt.PrevHash, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(5, 1))
+			mock.ExpectCommit()
+
+			err := urlRepo.AppendAuditEntry(context.Background(), entry)
+
+			assert.NoError(t, err)
+			assert.Equal(t, int64(5), entry.ID)
+			assert.Equal(t, tt.PrevHash, entry.PrevHash)
+			assert.Equal(t, audit.Hash(entry, tt.PrevHash), entry.Hash)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBuildAuditQuery(t *testing.T) {
+
+	from := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name          string
+		Filter        *models.AuditFilter
+		ExpectedQuery string
+		ExpectedArgs  []any
+	}{
+		{Name: "no filters", Filter: &models.AuditFilter{Limit: 100},
+			ExpectedQuery: `SELECT ` + auditColumns + ` FROM audit_log ORDER BY id DESC LIMIT $1`,
+			ExpectedArgs:  []any{100}},
+		{Name: "filters and cursor", Filter: &models.AuditFilter{Actor: "migration", Target: "Abc_def_gs", From: &from,
+			Cursor: 40, Limit: 10},
+			ExpectedQuery: `SELECT ` + auditColumns + ` FROM audit_log WHERE actor = $1 AND target = $2 AND time >= $3 ` +
+				`AND id < $4 ORDER BY id DESC LIMIT $5`,
+			ExpectedArgs: []any{"migration", "Abc_def_gs", from, int64(40), 10}},
+		{Name: "ascending", Filter: &models.AuditFilter{Action: audit.LinkCreate, Order: "asc", Cursor: 40},
+			ExpectedQuery: `SELECT ` + auditColumns + ` FROM audit_log WHERE action = $1 AND id > $2 ORDER BY id ASC`,
+			ExpectedArgs:  []any{audit.LinkCreate, int64(40)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			query, args := buildAuditQuery(tt.Filter)
+			assert.Equal(t, tt.ExpectedQuery, query)
+			assert.Equal(t, tt.ExpectedArgs, args)
+		})
+	}
+}
+
+func TestListAuditEntries(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	at := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT ` + auditColumns + ` FROM audit_log ORDER BY id DESC LIMIT $1`)).
+		WithArgs(2).
+		WillReturnRows(mock.NewRows([]string{"id", "time", "actor", "source_ip", "action", "target", "before_value",
+			"after_value", "prev_hash", "hash"}).
+			AddRow(2, at, "migration", "", audit.LinkDelete, "Abc_def_gs", `{"short_url":"Abc_def_gs"}`, nil, "h1", "h2").
+			AddRow(1, at, "migration", "192.0.2.1", audit.LinkCreate, "Abc_def_gs", nil, `{}`, "", "h1"))
+
+	entries, err := urlRepo.ListAuditEntries(context.Background(), &models.AuditFilter{Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AuditEntry{
+		{ID: 2, Time: at, Actor: "migration", Action: audit.LinkDelete, Target: "Abc_def_gs",
+			Before: []byte(`{"short_url":"Abc_def_gs"}`), PrevHash: "h1", Hash: "h2"},
+		{ID: 1, Time: at, Actor: "migration", SourceIP: "192.0.2.1", Action: audit.LinkCreate, Target: "Abc_def_gs",
+			After: []byte(`{}`), Hash: "h1"},
+	}, entries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,43 @@
+package pg
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/lib/pq"
+)
+
+// Postgres error codes of constraint violations.
+const (
+	codeStringTooLong       = "22001"
+	codeNotNullViolation    = "23502"
+	codeForeignKeyViolation = "23503"
+	codeUniqueViolation     = "23505"
+	codeCheckViolation      = "23514"
+)
+
+// mapError converts constraint violations reported by Postgres into errors
+// with a proper status, so they don't surface as opaque 500s. Other errors
+// are wrapped with op.
+func mapError(op string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case codeUniqueViolation:
+			return utils.NewInternalError(http.StatusConflict, "this shortUrl already exists")
+		case codeForeignKeyViolation:
+			return utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")
+		case codeStringTooLong:
+			return &utils.InternalError{Code: http.StatusUnprocessableEntity,
+				Message: "value is too long to be stored", Reason: "value_too_long"}
+		case codeNotNullViolation:
+			return utils.NewInternalError(http.StatusBadRequest, "required value is missing")
+		case codeCheckViolation:
+			return &utils.InternalError{Code: http.StatusUnprocessableEntity,
+				Message: "value violates a storage constraint", Reason: "constraint_violation"}
+		}
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
@@ -0,0 +1,72 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+// exportFetchSize is the number of rows fetched from the export cursor at once.
+const exportFetchSize = 1000
+
+// ExportLinks calls fn for every link matching filter in the order of filter.
+// The links are read from a server-side cursor of a read-only repeatable read
+// transaction, so the export sees one snapshot however long fn takes, while
+// only exportFetchSize rows are held in memory.
+func (ur *UrlRepository) ExportLinks(ctx context.Context, filter *models.LinkFilter,
+	fn func(*models.UrlData) error) error {
+
+	tx, err := ur.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.ExportLinks: %w", err)
+	}
+	defer tx.Rollback()
+
+	query, args := buildListQuery(filter)
+
+	_, err = tx.ExecContext(ctx, `DECLARE export_url NO SCROLL CURSOR FOR `+query, args...)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.ExportLinks: %w", err)
+	}
+
+	for {
+		links, err := fetchLinks(ctx, tx, `FETCH `+strconv.Itoa(exportFetchSize)+` FROM export_url`)
+		if err != nil {
+			return fmt.Errorf("pg.UrlRepository.ExportLinks: %w", err)
+		}
+		for _, link := range links {
+			if err := fn(link); err != nil {
+				return err
+			}
+		}
+		if len(links) < exportFetchSize {
+			break
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pg.UrlRepository.ExportLinks: %w", err)
+	}
+	return nil
+}
+
+func fetchLinks(ctx context.Context, tx *sql.Tx, query string) ([]*models.UrlData, error) {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*models.UrlData
+	for rows.Next() {
+		data, err := scanUrlData(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, data)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,101 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportLinks(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	const declareQuery = `DECLARE export_url NO SCROLL CURSOR FOR SELECT .* FROM url WHERE deleted_at IS NULL AND owner = \$1 ` +
+		`ORDER BY created_at ASC, short_url ASC$`
+	const fetchQuery = `FETCH 1000 FROM export_url`
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	filter := &models.LinkFilter{Owner: "team", Sort: "created_at", Order: "asc"}
+
+	row := func(rows *sqlmock.Rows, shortUrl string) *sqlmock.Rows {
+		return rows.AddRow(shortUrl, "http://ya.ru", nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false,
+			createdAt, "", "", "", "team", int64(0), nil, []byte(`{}`))
+	}
+
+	tests := []struct {
+		Name        string
+		Setup       func(m sqlmock.Sqlmock)
+		Fn          func(*models.UrlData) error
+		ExpectCount int
+		ExpectErr   error
+	}{
+		{
+			Name: "successful export over several fetches",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec(declareQuery).WithArgs("team").WillReturnResult(sqlmock.NewResult(0, 0))
+				full := m.NewRows(urlDataColumns)
+				for i := 0; i < exportFetchSize; i++ {
+					row(full, fmt.Sprintf("code%d", i))
+				}
+				m.ExpectQuery(fetchQuery).WillReturnRows(full)
+				m.ExpectQuery(fetchQuery).WillReturnRows(row(m.NewRows(urlDataColumns), "last"))
+				m.ExpectCommit()
+			},
+			Fn:          func(*models.UrlData) error { return nil },
+			ExpectCount: exportFetchSize + 1,
+			ExpectErr:   nil,
+		},
+		{
+			Name: "failed fetch",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec(declareQuery).WithArgs("team").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectQuery(fetchQuery).WillReturnError(fmt.Errorf("some bd error"))
+				m.ExpectRollback()
+			},
+			Fn:          func(*models.UrlData) error { return nil },
+			ExpectCount: 0,
+			ExpectErr:   fmt.Errorf("pg.UrlRepository.ExportLinks: %w", fmt.Errorf("some bd error")),
+		},
+		{
+			Name: "failed consumer",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec(declareQuery).WithArgs("team").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectQuery(fetchQuery).WillReturnRows(row(m.NewRows(urlDataColumns), "first"))
+				m.ExpectRollback()
+			},
+			Fn:          func(*models.UrlData) error { return errors.New("broken pipe") },
+			ExpectCount: 1,
+			ExpectErr:   errors.New("broken pipe"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+
+			count := 0
+			err := urlRepo.ExportLinks(context.Background(), filter, func(data *models.UrlData) error {
+				count++
+				return tt.Fn(data)
+			})
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.Equal(t, tt.ExpectCount, count)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
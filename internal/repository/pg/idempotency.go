@@ -0,0 +1,111 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+// acquireIdempotencyKeyQuery inserts an in-progress record, taking over an
+// existing one only when it expired or its request was abandoned.
+const acquireIdempotencyKeyQuery = `INSERT INTO idempotency_keys ` +
+	`(scope, idempotency_key, request_hash, status, content_type, body, locked_until, expires_at) ` +
+	`VALUES ($1, $2, $3, 0, '', NULL, $4, $5) ` +
+	`ON CONFLICT (scope, idempotency_key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status = 0, ` +
+	`content_type = '', body = NULL, locked_until = EXCLUDED.locked_until, expires_at = EXCLUDED.expires_at ` +
+	`WHERE idempotency_keys.expires_at <= $6 OR (idempotency_keys.status = 0 AND idempotency_keys.locked_until <= $6)`
+
+// AcquireIdempotencyKey stores record as the in-progress request with its key,
+// unless another request holds the key, and returns nil. Otherwise it returns
+// the record of the other request.
+func (ur *UrlRepository) AcquireIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord,
+	now time.Time) (*models.IdempotencyRecord, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	// the existing record may be purged between the two statements, then the
+	// key is free again
+	for attempt := 0; ; attempt++ {
+		res, err := ur.DB.ExecContext(ctx, acquireIdempotencyKeyQuery, record.Scope, record.Key, record.RequestHash,
+			record.LockedUntil, record.ExpiresAt, now)
+		if err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.AcquireIdempotencyKey: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.AcquireIdempotencyKey: %w", err)
+		}
+		if affected > 0 {
+			return nil, nil
+		}
+
+		existing := &models.IdempotencyRecord{Scope: record.Scope, Key: record.Key}
+		err = ur.DB.QueryRowContext(ctx, `SELECT request_hash, status, content_type, body, locked_until, expires_at `+
+			`FROM idempotency_keys WHERE scope=$1 AND idempotency_key=$2`, record.Scope, record.Key).Scan(
+			&existing.RequestHash, &existing.Status, &existing.ContentType, &existing.Body, &existing.LockedUntil,
+			&existing.ExpiresAt)
+		if errors.Is(err, sql.ErrNoRows) && attempt == 0 {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.AcquireIdempotencyKey: %w", err)
+		}
+		return existing, nil
+	}
+}
+
+// CompleteIdempotencyKey stores the response of the request holding the key
+// of record.
+func (ur *UrlRepository) CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	_, err := ur.DB.ExecContext(ctx, `UPDATE idempotency_keys SET status=$4, content_type=$5, body=$6 `+
+		`WHERE scope=$1 AND idempotency_key=$2 AND request_hash=$3 AND status=0`,
+		record.Scope, record.Key, record.RequestHash, record.Status, record.ContentType, record.Body)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.CompleteIdempotencyKey: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey lets the next request with the key of record, still
+// in progress, take it over, so failed requests can be retried.
+func (ur *UrlRepository) ReleaseIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord,
+	now time.Time) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	_, err := ur.DB.ExecContext(ctx, `UPDATE idempotency_keys SET locked_until=$4 `+
+		`WHERE scope=$1 AND idempotency_key=$2 AND request_hash=$3 AND status=0`,
+		record.Scope, record.Key, record.RequestHash, now)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.ReleaseIdempotencyKey: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes the records expired at now and returns
+// their number.
+func (ur *UrlRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	res, err := ur.DB.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.DeleteExpiredIdempotencyKeys: %w", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.DeleteExpiredIdempotencyKeys: %w", err)
+	}
+	return deleted, nil
+}
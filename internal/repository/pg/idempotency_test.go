@@ -0,0 +1,117 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireIdempotencyKey(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	record := &models.IdempotencyRecord{Scope: "runner", Key: "key-1", RequestHash: "hash-1",
+		LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)}
+
+	acquireQuery := regexp.QuoteMeta(acquireIdempotencyKeyQuery)
+	const selectQuery = `SELECT request_hash, status, content_type, body, locked_until, expires_at FROM idempotency_keys ` +
+		`WHERE scope=\$1 AND idempotency_key=\$2`
+	columns := []string{"request_hash", "status", "content_type", "body", "locked_until", "expires_at"}
+
+	tests := []struct {
+		Name           string
+		Setup          func(m sqlmock.Sqlmock)
+		ExpectExisting *models.IdempotencyRecord
+	}{
+		{
+			Name: "key acquired",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectExec(acquireQuery).WithArgs("runner", "key-1", "hash-1", now.Add(time.Minute), now.Add(time.Hour), now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			Name: "key held by a completed request",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectExec(acquireQuery).WithArgs("runner", "key-1", "hash-1", now.Add(time.Minute), now.Add(time.Hour), now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectQuery(selectQuery).WithArgs("runner", "key-1").WillReturnRows(sqlmock.NewRows(columns).
+					AddRow("hash-1", 200, "application/json", []byte(`{}`), now, now.Add(time.Hour)))
+			},
+			ExpectExisting: &models.IdempotencyRecord{Scope: "runner", Key: "key-1", RequestHash: "hash-1", Status: 200,
+				ContentType: "application/json", Body: []byte(`{}`), LockedUntil: now, ExpiresAt: now.Add(time.Hour)},
+		},
+		{
+			Name: "key purged meanwhile",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectExec(acquireQuery).WithArgs("runner", "key-1", "hash-1", now.Add(time.Minute), now.Add(time.Hour), now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectQuery(selectQuery).WithArgs("runner", "key-1").WillReturnError(sql.ErrNoRows)
+				m.ExpectExec(acquireQuery).WithArgs("runner", "key-1", "hash-1", now.Add(time.Minute), now.Add(time.Hour), now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			existing, err := urlRepo.AcquireIdempotencyKey(context.Background(), record, now)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ExpectExisting, existing)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCompleteIdempotencyKey(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	mock.ExpectExec(`UPDATE idempotency_keys SET status=\$4, content_type=\$5, body=\$6 `+
+		`WHERE scope=\$1 AND idempotency_key=\$2 AND request_hash=\$3 AND status=0`).
+		WithArgs("runner", "key-1", "hash-1", 200, "application/json", []byte(`{}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = urlRepo.CompleteIdempotencyKey(context.Background(), &models.IdempotencyRecord{Scope: "runner", Key: "key-1",
+		RequestHash: "hash-1", Status: 200, ContentType: "application/json", Body: []byte(`{}`)})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`DELETE FROM idempotency_keys WHERE expires_at <= \$1`).WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := urlRepo.DeleteExpiredIdempotencyKeys(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,289 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/events"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/lib/pq"
+)
+
+// importColumns are the columns of the temporary table batches are copied to.
+var importColumns = []string{"short_url", "original_url", "owner", "created_at", "title", "description", "notes", "tags"}
+
+// importQuery moves the copied batch into url and url_tags in one statement,
+// skipping taken short urls, and returns the inserted ones.
+const importQuery = `WITH inserted AS (` +
+	`INSERT INTO url (short_url, original_url, owner, created_at, title, description, notes) ` +
+	`SELECT short_url, original_url, owner, COALESCE(created_at, now()), title, description, notes FROM import_url ` +
+	`ON CONFLICT (short_url) DO NOTHING RETURNING short_url), ` +
+	`tags AS (INSERT INTO url_tags (short_url, tag, position) ` +
+	`SELECT i.short_url, t.tag, t.position FROM import_url i JOIN inserted USING (short_url), ` +
+	`unnest(i.tags) WITH ORDINALITY AS t(tag, position)) ` +
+	`SELECT short_url FROM inserted`
+
+// copyBatch streams the rows returned by prepare to a temporary table created
+// by createQuery with COPY and runs query, which moves them to their tables
+// and returns the short urls it inserted, which are then passed to
+// onInserted, if not nil. Everything happens in one transaction, which
+// prepare may use to lock and check rows; op prefixes the errors.
+func (ur *UrlRepository) copyBatch(ctx context.Context, op, createQuery, table string, columns []string,
+	prepare func(tx *sql.Tx) ([][]any, error), query string,
+	onInserted func(tx *sql.Tx, inserted map[string]bool) error) (map[string]bool, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	rows, err := prepare(tx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, createQuery); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			stmt.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return nil, mapError(op, err)
+	}
+	if err := stmt.Close(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, mapError(op, err)
+	}
+
+	inserted := make(map[string]bool, len(rows))
+	for result.Next() {
+		var shortUrl string
+		if err := result.Scan(&shortUrl); err != nil {
+			result.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		inserted[shortUrl] = true
+	}
+	result.Close()
+	if err := result.Err(); err != nil {
+		return nil, mapError(op, err)
+	}
+
+	if onInserted != nil {
+		if err := onInserted(tx, inserted); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return inserted, nil
+}
+
+// ImportLinks stores links under their own short urls and returns the skipped
+// ones with the reasons: taken short urls, and links of owners whose usage
+// check rejects. The additions of the links of the owners of the batch are
+// serialized with AddOwnedUrl, and the links of an owner are checked with the
+// ones before them counted. The admitted links are streamed to a temporary
+// table with COPY and inserted from there with one statement, followed by
+// the creations counted in month and the link.created events of the inserted
+// links.
+func (ur *UrlRepository) ImportLinks(ctx context.Context, links []*models.UrlData, month time.Time,
+	check func(*models.Usage) error) (map[string]error, error) {
+
+	skipped := make(map[string]error)
+	prepare := func(tx *sql.Tx) ([][]any, error) {
+		admitted, err := admitLinks(ctx, tx, links, month, check, skipped)
+		if err != nil {
+			return nil, err
+		}
+
+		rows := make([][]any, 0, len(admitted))
+		for _, link := range admitted {
+			var createdAt sql.NullTime
+			if !link.CreatedAt.IsZero() {
+				createdAt = sql.NullTime{Time: link.CreatedAt, Valid: true}
+			}
+			tags, err := pq.Array(link.Tags).Value()
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, []any{link.ShortUrl, link.OriginalUrl, link.Owner, createdAt, link.Title,
+				link.Description, link.Notes, tags})
+		}
+		return rows, nil
+	}
+
+	inserted, err := ur.copyBatch(ctx, "pg.UrlRepository.ImportLinks", `CREATE TEMP TABLE IF NOT EXISTS import_url `+
+		`(short_url TEXT, original_url TEXT, owner TEXT, created_at TIMESTAMPTZ, title TEXT, description TEXT, notes TEXT, `+
+		`tags TEXT[]) ON COMMIT DELETE ROWS`, "import_url", importColumns, prepare, importQuery,
+		func(tx *sql.Tx, inserted map[string]bool) error {
+			var created []*models.WebhookEvent
+			creations := make(map[string]int64)
+			for _, link := range links {
+				if inserted[link.ShortUrl] {
+					created = append(created, events.Created(link))
+					if link.Owner != "" {
+						creations[link.Owner]++
+					}
+				}
+			}
+			if err := countCreations(ctx, tx, creations, month); err != nil {
+				return err
+			}
+			return enqueueEvents(ctx, tx, created...)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		if _, ok := skipped[link.ShortUrl]; !ok && !inserted[link.ShortUrl] {
+			skipped[link.ShortUrl] = utils.NewInternalError(http.StatusConflict, "this shortUrl already exists")
+		}
+	}
+	return skipped, nil
+}
+
+// admitLinks returns the links of a batch that may be inserted, adding the
+// others to skipped with the error of check. The usage of each owner is
+// checked under its advisory lock, held until tx ends, with the links of the
+// owner admitted before counted; links with taken short urls are left to the
+// insert, which skips them without counting them.
+func admitLinks(ctx context.Context, tx *sql.Tx, links []*models.UrlData, month time.Time,
+	check func(*models.Usage) error, skipped map[string]error) ([]*models.UrlData, error) {
+
+	var owners, codes []string
+	for _, link := range links {
+		if link.Owner == "" {
+			continue
+		}
+		if !slices.Contains(owners, link.Owner) {
+			owners = append(owners, link.Owner)
+		}
+		codes = append(codes, link.ShortUrl)
+	}
+	if len(owners) == 0 {
+		return links, nil
+	}
+	// locks are taken in the same order by concurrent imports
+	slices.Sort(owners)
+
+	usages := make(map[string]*models.Usage, len(owners))
+	for _, owner := range owners {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, usageLockKey,
+			owner); err != nil {
+			return nil, err
+		}
+		usage, err := queryUsage(ctx, tx, owner, month)
+		if err != nil {
+			return nil, err
+		}
+		usages[owner] = usage
+	}
+
+	taken := make(map[string]bool)
+	rows, err := tx.QueryContext(ctx, `SELECT short_url FROM url WHERE short_url = ANY($1)`, pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		taken[code] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	admitted := make([]*models.UrlData, 0, len(links))
+	for _, link := range links {
+		if link.Owner != "" && !taken[link.ShortUrl] {
+			usage := usages[link.Owner]
+			if err := check(usage); err != nil {
+				skipped[link.ShortUrl] = err
+				continue
+			}
+			usage.ActiveLinks++
+			usage.Creations++
+		}
+		admitted = append(admitted, link)
+	}
+	return admitted, nil
+}
+
+// countCreations adds the numbers of links created by owners to their usage
+// in month.
+func countCreations(ctx context.Context, tx *sql.Tx, creations map[string]int64, month time.Time) error {
+	owners := make([]string, 0, len(creations))
+	for owner := range creations {
+		owners = append(owners, owner)
+	}
+	slices.Sort(owners)
+
+	for _, owner := range owners {
+		_, err := tx.ExecContext(ctx, `INSERT INTO owner_usage (owner, month, creations) VALUES ($1, $2, $3) `+
+			`ON CONFLICT (owner, month) DO UPDATE SET creations = owner_usage.creations + $3`,
+			owner, month, creations[owner])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExistingCodes returns the codes that are already used as short urls.
+func (ur *UrlRepository) ExistingCodes(ctx context.Context, codes []string) ([]string, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := ur.DB.QueryContext(ctx, `SELECT short_url FROM url WHERE short_url = ANY($1)`, pq.Array(codes))
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ExistingCodes: %w", err)
+	}
+	defer rows.Close()
+
+	var res []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.ExistingCodes: %w", err)
+		}
+		res = append(res, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ExistingCodes: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,144 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportLinks(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	const createQuery = `CREATE TEMP TABLE IF NOT EXISTS import_url`
+	const lockQuery = `SELECT pg_advisory_xact_lock\(\$1, hashtext\(\$2\)\)`
+	const takenQuery = `SELECT short_url FROM url WHERE short_url = ANY\(\$1\)`
+	copyQuery := regexp.QuoteMeta(`COPY "import_url" ("short_url", "original_url", "owner", "created_at", "title", ` +
+		`"description", "notes", "tags") FROM STDIN`)
+	creationsQuery := regexp.QuoteMeta(`INSERT INTO owner_usage (owner, month, creations) VALUES ($1, $2, $3) ` +
+		`ON CONFLICT (owner, month) DO UPDATE SET creations = owner_usage.creations + $3`)
+
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	quotaErr := utils.NewInternalError(http.StatusForbidden, "quota reached")
+	accept := func(*models.Usage) error { return nil }
+
+	links := []*models.UrlData{
+		{ShortUrl: "abc", OriginalUrl: "https://example.com/a", Owner: "team", CreatedAt: createdAt,
+			LinkMetadata: models.LinkMetadata{Title: "Sale", Tags: []string{"promo", "spring sale"}}},
+		{ShortUrl: "def", OriginalUrl: "https://example.com/b"},
+	}
+
+	expectUsage := func(m sqlmock.Sqlmock, active int64) {
+		m.ExpectExec(lockQuery).WithArgs(usageLockKey, "team").WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectQuery(usageQuery).WithArgs("team", month).WillReturnRows(
+			m.NewRows([]string{"active", "creations", "resolutions"}).AddRow(active, 5, 120))
+		m.ExpectQuery(takenQuery).WithArgs(`{"abc"}`).WillReturnRows(m.NewRows([]string{"short_url"}))
+	}
+
+	tests := []struct {
+		Name          string
+		Check         func(*models.Usage) error
+		Setup         func(m sqlmock.Sqlmock)
+		ExpectSkipped map[string]error
+		ExpectErr     error
+	}{
+		{
+			Name:  "successful import with a taken code",
+			Check: accept,
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				expectUsage(m, 3)
+				m.ExpectExec(createQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				prep := m.ExpectPrepare(copyQuery)
+				prep.ExpectExec().WithArgs("abc", "https://example.com/a", "team", createdAt, "Sale", "", "",
+					`{"promo","spring sale"}`).WillReturnResult(sqlmock.NewResult(0, 0))
+				prep.ExpectExec().WithArgs("def", "https://example.com/b", "", nil, "", "", "", nil).WillReturnResult(
+					sqlmock.NewResult(0, 0))
+				prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+				m.ExpectQuery(regexp.QuoteMeta(importQuery)).WillReturnRows(m.NewRows([]string{"short_url"}).AddRow("abc"))
+				m.ExpectExec(creationsQuery).WithArgs("team", month, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEnqueue(m, "link.created", "team", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectSkipped: map[string]error{"def": &utils.InternalError{Code: http.StatusConflict,
+				Message: "this shortUrl already exists"}},
+		},
+		{
+			Name: "link over the quota of its owner",
+			Check: func(usage *models.Usage) error {
+				if usage.ActiveLinks >= 10 {
+					return quotaErr
+				}
+				return nil
+			},
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				expectUsage(m, 10)
+				m.ExpectExec(createQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				prep := m.ExpectPrepare(copyQuery)
+				prep.ExpectExec().WithArgs("def", "https://example.com/b", "", nil, "", "", "", nil).WillReturnResult(
+					sqlmock.NewResult(0, 0))
+				prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectQuery(regexp.QuoteMeta(importQuery)).WillReturnRows(m.NewRows([]string{"short_url"}).AddRow("def"))
+				expectEnqueue(m, "link.created", "", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectSkipped: map[string]error{"abc": quotaErr},
+		},
+		{
+			Name:  "failed copy",
+			Check: accept,
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				expectUsage(m, 3)
+				m.ExpectExec(createQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				prep := m.ExpectPrepare(copyQuery)
+				prep.ExpectExec().WillReturnError(fmt.Errorf("some bd error"))
+				m.ExpectRollback()
+			},
+			ExpectErr: fmt.Errorf("pg.UrlRepository.ImportLinks: %w", fmt.Errorf("some bd error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			skipped, err := urlRepo.ImportLinks(context.Background(), links, month, tt.Check)
+
+			assert.Equal(t, tt.ExpectSkipped, skipped)
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExistingCodes(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	mock.ExpectQuery(`SELECT short_url FROM url WHERE short_url = ANY\(\$1\)`).WithArgs(`{"abc","def"}`).WillReturnRows(
+		mock.NewRows([]string{"short_url"}).AddRow("def"))
+
+	codes, err := urlRepo.ExistingCodes(context.Background(), []string{"abc", "def"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"def"}, codes)
+}
@@ -0,0 +1,138 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+// sortColumns maps the listing orders to the columns they are indexed on.
+var sortColumns = map[string]string{"created_at": "created_at", "clicks": "clicks"}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// listQuery accumulates the conditions of the listing query together with
+// their arguments.
+type listQuery struct {
+	conditions []string
+	args       []any
+}
+
+// arg adds an argument to the query and returns its placeholder.
+func (q *listQuery) arg(value any) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *listQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+// buildListQuery translates filter into a keyset query. Every filter is backed
+// by an index: the destination host is matched on original_host and, for
+// subdomains, on its reversed form, the search on the search tsvector.
+func buildListQuery(filter *models.LinkFilter) (string, []any) {
+	q := &listQuery{}
+
+	// the tombstones of deleted links are never listed
+	q.where(`deleted_at IS NULL`)
+
+	if filter.Owner != "" {
+		q.where(`owner = ` + q.arg(filter.Owner))
+	}
+	if filter.Host != "" {
+		q.where(`original_host = ` + q.arg(filter.Host))
+	}
+	if filter.Domain != "" {
+		q.where(`(original_host = ` + q.arg(filter.Domain) + ` OR reverse(original_host) LIKE ` +
+			q.arg(escapeLike(reverse(filter.Domain))+".%") + `)`)
+	}
+	if filter.Tag != "" {
+		q.where(`EXISTS (SELECT 1 FROM url_tags WHERE url_tags.short_url = url.short_url AND lower(tag) = lower(` +
+			q.arg(filter.Tag) + `))`)
+	}
+	if filter.Title != "" {
+		q.where(`title ILIKE '%' || ` + q.arg(escapeLike(filter.Title)) + ` || '%'`)
+	}
+	if filter.Search != "" {
+		q.where(`search @@ plainto_tsquery('simple', regexp_replace(` + q.arg(filter.Search) + `, '[^[:alnum:]]+', ' ', 'g'))`)
+	}
+	if filter.CreatedFrom != nil {
+		q.where(`created_at >= ` + q.arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		q.where(`created_at < ` + q.arg(*filter.CreatedTo))
+	}
+
+	column, ok := sortColumns[filter.Sort]
+	if !ok {
+		column = sortColumns["created_at"]
+	}
+	direction, comparison := "DESC", "<"
+	if filter.Order == "asc" {
+		direction, comparison = "ASC", ">"
+	}
+
+	if after := filter.After; after != nil {
+		var value any = after.CreatedAt
+		if column == "clicks" {
+			value = after.Clicks
+		}
+		q.where(`(` + column + `, short_url) ` + comparison + ` (` + q.arg(value) + `, ` + q.arg(after.ShortUrl) + `)`)
+	}
+
+	query := strings.Builder{}
+	query.WriteString(`SELECT ` + urlColumns + ` FROM url`)
+	if len(q.conditions) > 0 {
+		query.WriteString(` WHERE ` + strings.Join(q.conditions, ` AND `))
+	}
+	query.WriteString(` ORDER BY ` + column + ` ` + direction + `, short_url ` + direction)
+	if filter.Limit > 0 {
+		query.WriteString(` LIMIT ` + q.arg(filter.Limit))
+	}
+	return query.String(), q.args
+}
+
+// ListLinks returns up to filter.Limit links matching filter, continuing after
+// filter.After when it is set.
+func (ur *UrlRepository) ListLinks(ctx context.Context, filter *models.LinkFilter) ([]*models.UrlData, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	query, args := buildListQuery(filter)
+
+	rows, err := ur.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListLinks: %w", err)
+	}
+	defer rows.Close()
+
+	var res []*models.UrlData
+	for rows.Next() {
+		data, err := scanUrlData(rows)
+		if err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.ListLinks: %w", err)
+		}
+		res = append(res, data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListLinks: %w", err)
+	}
+	return res, nil
+}
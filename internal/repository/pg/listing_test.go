@@ -0,0 +1,150 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildListQuery(t *testing.T) {
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	const selectUrl = `SELECT ` + urlColumns + ` FROM url WHERE deleted_at IS NULL`
+
+	tests := []struct {
+		Name         string
+		Filter       models.LinkFilter
+		ExpectQuery  string
+		ExpectedArgs []any
+	}{
+		{
+			Name:         "no filters",
+			Filter:       models.LinkFilter{},
+			ExpectQuery:  selectUrl + ` ORDER BY created_at DESC, short_url DESC`,
+			ExpectedArgs: nil,
+		},
+		{
+			Name:   "owner, tag and title",
+			Filter: models.LinkFilter{Owner: "team", Tag: "Promo", Title: "50%_off", Limit: 11},
+			ExpectQuery: selectUrl + ` AND owner = $1 AND EXISTS (SELECT 1 FROM url_tags WHERE url_tags.short_url = url.short_url ` +
+				`AND lower(tag) = lower($2)) AND title ILIKE '%' || $3 || '%' ORDER BY created_at DESC, short_url DESC LIMIT $4`,
+			ExpectedArgs: []any{"team", "Promo", `50\%\_off`, 11},
+		},
+		{
+			Name:   "host and domain",
+			Filter: models.LinkFilter{Host: "www.example.com", Domain: "my_site.com"},
+			ExpectQuery: selectUrl + ` AND original_host = $1 AND (original_host = $2 OR reverse(original_host) LIKE $3) ` +
+				`ORDER BY created_at DESC, short_url DESC`,
+			ExpectedArgs: []any{"www.example.com", "my_site.com", `moc.etis\_ym.%`},
+		},
+		{
+			Name:   "search and creation range",
+			Filter: models.LinkFilter{Search: "spring sale", CreatedFrom: &from, CreatedTo: &to},
+			ExpectQuery: selectUrl + ` AND search @@ plainto_tsquery('simple', regexp_replace($1, '[^[:alnum:]]+', ' ', 'g')) ` +
+				`AND created_at >= $2 AND created_at < $3 ORDER BY created_at DESC, short_url DESC`,
+			ExpectedArgs: []any{"spring sale", from, to},
+		},
+		{
+			Name: "page after cursor by creation time",
+			Filter: models.LinkFilter{Sort: "created_at", Order: "desc", Limit: 3,
+				After: &models.LinkCursor{CreatedAt: from, Clicks: 5, ShortUrl: "Abc_efg_ag"}},
+			ExpectQuery: selectUrl + ` AND (created_at, short_url) < ($1, $2) ` +
+				`ORDER BY created_at DESC, short_url DESC LIMIT $3`,
+			ExpectedArgs: []any{from, "Abc_efg_ag", 3},
+		},
+		{
+			Name: "page after cursor by clicks ascending",
+			Filter: models.LinkFilter{Tag: "promo", Sort: "clicks", Order: "asc",
+				After: &models.LinkCursor{CreatedAt: from, Clicks: 5, ShortUrl: "Abc_efg_ag"}},
+			ExpectQuery: selectUrl + ` AND EXISTS (SELECT 1 FROM url_tags WHERE url_tags.short_url = url.short_url ` +
+				`AND lower(tag) = lower($1)) AND (clicks, short_url) > ($2, $3) ORDER BY clicks ASC, short_url ASC`,
+			ExpectedArgs: []any{"promo", int64(5), "Abc_efg_ag"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			query, args := buildListQuery(&tt.Filter)
+
+			assert.Equal(t, tt.ExpectQuery, query)
+			assert.Equal(t, tt.ExpectedArgs, args)
+		})
+	}
+}
+
+func TestListLinks(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	const listQuery = `SELECT .* FROM url WHERE deleted_at IS NULL AND owner = \$1 ORDER BY created_at DESC, short_url DESC LIMIT \$2`
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name       string
+		Filter     models.LinkFilter
+		Setup      func(m sqlmock.Sqlmock)
+		ExpectData []*models.UrlData
+		ExpectErr  error
+	}{
+		{
+			Name:   "successful listing by owner",
+			Filter: models.LinkFilter{Owner: "team", Limit: 10},
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows(urlDataColumns).AddRow(
+					"Abc_efg_ag", "http://ya.ru", nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false, createdAt,
+					"Sale", "", "", "team", int64(7), nil, []byte(`{promo}`)).AddRow(
+					"Abc_efg_af", "http://ya.ru/b", nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false, createdAt,
+					"", "", "", "team", int64(0), nil, []byte(`{}`))
+				m.ExpectQuery(listQuery).WithArgs("team", 10).WillReturnRows(rows)
+			},
+			ExpectData: []*models.UrlData{
+				{ShortUrl: "Abc_efg_ag", OriginalUrl: "http://ya.ru", CreatedAt: createdAt, Owner: "team", Clicks: 7,
+					LinkMetadata: models.LinkMetadata{Title: "Sale", Tags: []string{"promo"}}},
+				{ShortUrl: "Abc_efg_af", OriginalUrl: "http://ya.ru/b", CreatedAt: createdAt, Owner: "team"},
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name:   "successful listing without matches",
+			Filter: models.LinkFilter{Owner: "nobody", Limit: 10},
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(listQuery).WithArgs("nobody", 10).WillReturnRows(m.NewRows(urlDataColumns))
+			},
+			ExpectData: nil,
+			ExpectErr:  nil,
+		},
+		{
+			Name:   "failed listing",
+			Filter: models.LinkFilter{Owner: "team", Limit: 10},
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(listQuery).WithArgs("team", 10).WillReturnError(fmt.Errorf("some bd error"))
+			},
+			ExpectData: nil,
+			ExpectErr:  fmt.Errorf("pg.UrlRepository.ListLinks: %w", fmt.Errorf("some bd error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			data, err := urlRepo.ListLinks(context.Background(), &tt.Filter)
+
+			assert.Equal(t, tt.ExpectData, data)
+			assert.Equal(t, tt.ExpectErr, err)
+		})
+	}
+}
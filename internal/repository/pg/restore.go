@@ -0,0 +1,106 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/lib/pq"
+)
+
+// restoreColumns are the columns of the temporary table restored links are
+// copied to, variant_clicks being a JSON object of the clicks per destination.
+var restoreColumns = []string{
+	"short_url", "original_url", "clicks_left", "not_before", "not_after", "fallback_url", "rules", "destinations",
+	"utm_source", "utm_medium", "utm_campaign", "pass_query", "interstitial", "created_at", "title", "description",
+	"notes", "owner", "clicks", "tags", "variant_clicks",
+}
+
+const restoreTableQuery = `CREATE TEMP TABLE IF NOT EXISTS restore_url (short_url TEXT, original_url TEXT, ` +
+	`clicks_left INTEGER, not_before TIMESTAMPTZ, not_after TIMESTAMPTZ, fallback_url TEXT, rules JSONB, ` +
+	`destinations JSONB, utm_source TEXT, utm_medium TEXT, utm_campaign TEXT, pass_query BOOLEAN, ` +
+	`interstitial BOOLEAN, created_at TIMESTAMPTZ, title TEXT, description TEXT, notes TEXT, owner TEXT, ` +
+	`clicks BIGINT, tags TEXT[], variant_clicks JSONB) ON COMMIT DELETE ROWS`
+
+// restoreQuery moves the copied batch into url, url_tags and variant_clicks
+// in one statement, skipping taken short urls, and returns the inserted ones.
+const restoreQuery = `WITH inserted AS (` +
+	`INSERT INTO url (short_url, original_url, clicks_left, not_before, not_after, fallback_url, rules, destinations, ` +
+	`utm_source, utm_medium, utm_campaign, pass_query, interstitial, created_at, title, description, notes, owner, clicks, ` +
+	`expiry_notified) ` +
+	`SELECT short_url, original_url, clicks_left, not_before, not_after, fallback_url, rules, destinations, ` +
+	`utm_source, utm_medium, utm_campaign, pass_query, interstitial, created_at, title, description, notes, owner, clicks, ` +
+	`COALESCE(not_after <= now(), FALSE) ` +
+	`FROM restore_url ON CONFLICT (short_url) DO NOTHING RETURNING short_url), ` +
+	`tags AS (INSERT INTO url_tags (short_url, tag, position) ` +
+	`SELECT i.short_url, t.tag, t.position FROM restore_url i JOIN inserted USING (short_url), ` +
+	`unnest(i.tags) WITH ORDINALITY AS t(tag, position)), ` +
+	`variants AS (INSERT INTO variant_clicks (short_url, variant, clicks) ` +
+	`SELECT i.short_url, v.key::integer, v.value::bigint FROM restore_url i JOIN inserted USING (short_url), ` +
+	`jsonb_each_text(i.variant_clicks) AS v(key, value)) ` +
+	`SELECT short_url FROM inserted`
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// RestoreLinks stores links with every field and counter under their own
+// short urls, skipping the short urls that are taken, and returns the skipped
+// ones. Like imports, the batch goes through a temporary table filled with
+// COPY.
+func (ur *UrlRepository) RestoreLinks(ctx context.Context, links []*models.StoredLink) ([]string, error) {
+
+	rows := make([][]any, 0, len(links))
+	for _, link := range links {
+		var clicksLeft sql.NullInt64
+		if link.ClicksLeft != nil {
+			clicksLeft = sql.NullInt64{Int64: int64(*link.ClicksLeft), Valid: true}
+		}
+		rules, err := marshalList(link.Rules)
+		if err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.RestoreLinks: %w", err)
+		}
+		destinations, err := marshalList(link.Destinations)
+		if err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.RestoreLinks: %w", err)
+		}
+		tags, err := pq.Array(link.Tags).Value()
+		if err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.RestoreLinks: %w", err)
+		}
+		variantClicks := link.VariantClicks
+		if variantClicks == nil {
+			variantClicks = map[int]int64{}
+		}
+		variants, err := json.Marshal(variantClicks)
+		if err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.RestoreLinks: %w", err)
+		}
+
+		// COPY sends byte slices as bytea, so the JSONB columns are given as text
+		rows = append(rows, []any{link.ShortUrl, link.OriginalUrl, clicksLeft, nullTime(link.NotBefore),
+			nullTime(link.NotAfter), link.FallbackUrl, string(rules), string(destinations), link.UtmSource,
+			link.UtmMedium, link.UtmCampaign, link.PassQuery, link.Interstitial, link.CreatedAt, link.Title,
+			link.Description, link.Notes, link.Owner, link.Clicks, tags, string(variants)})
+	}
+
+	inserted, err := ur.copyBatch(ctx, "pg.UrlRepository.RestoreLinks", restoreTableQuery, "restore_url",
+		restoreColumns, func(*sql.Tx) ([][]any, error) { return rows, nil }, restoreQuery, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var taken []string
+	for _, link := range links {
+		if !inserted[link.ShortUrl] {
+			taken = append(taken, link.ShortUrl)
+		}
+	}
+	return taken, nil
+}
@@ -0,0 +1,95 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreLinks(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	copyQuery := regexp.QuoteMeta(`COPY "restore_url" ("short_url", "original_url", "clicks_left", "not_before", ` +
+		`"not_after", "fallback_url", "rules", "destinations", "utm_source", "utm_medium", "utm_campaign", "pass_query", ` +
+		`"interstitial", "created_at", "title", "description", "notes", "owner", "clicks", "tags", "variant_clicks") FROM STDIN`)
+
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	notAfter := createdAt.Add(time.Hour)
+	clicksLeft := 2
+
+	links := []*models.StoredLink{
+		{UrlData: models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "https://example.com/a", ClicksLeft: &clicksLeft,
+			ActivationWindow: models.ActivationWindow{NotAfter: &notAfter},
+			Destinations:     []models.Destination{{Url: "https://a.example.com", Weight: 1}},
+			QueryOptions:     models.QueryOptions{UtmSource: "mail"},
+			Owner:            "team", CreatedAt: createdAt, Clicks: 7,
+			LinkMetadata: models.LinkMetadata{Tags: []string{"promo"}}},
+			VariantClicks: map[int]int64{0: 7}},
+		{UrlData: models.UrlData{ShortUrl: "Abc_def_gb", OriginalUrl: "https://example.com/b", CreatedAt: createdAt}},
+	}
+
+	tests := []struct {
+		Name        string
+		Setup       func(m sqlmock.Sqlmock)
+		ExpectTaken []string
+		ExpectErr   error
+	}{
+		{
+			Name: "successful restore with a taken code",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec(`CREATE TEMP TABLE IF NOT EXISTS restore_url`).WillReturnResult(sqlmock.NewResult(0, 0))
+				prep := m.ExpectPrepare(copyQuery)
+				prep.ExpectExec().WithArgs("Abc_def_ga", "https://example.com/a", int64(2), nil, notAfter, "",
+					"[]", `[{"url":"https://a.example.com","weight":1}]`, "mail", "", "", false, false, createdAt, "", "",
+					"", "team", int64(7), `{"promo"}`, `{"0":7}`).WillReturnResult(sqlmock.NewResult(0, 0))
+				prep.ExpectExec().WithArgs("Abc_def_gb", "https://example.com/b", nil, nil, nil, "", "[]", "[]", "", "", "",
+					false, false, createdAt, "", "", "", "", int64(0), nil, "{}").WillReturnResult(sqlmock.NewResult(0, 0))
+				prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+				m.ExpectQuery(regexp.QuoteMeta(restoreQuery)).WillReturnRows(
+					m.NewRows([]string{"short_url"}).AddRow("Abc_def_ga"))
+				m.ExpectCommit()
+			},
+			ExpectTaken: []string{"Abc_def_gb"},
+			ExpectErr:   nil,
+		},
+		{
+			Name: "failed insert",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec(`CREATE TEMP TABLE IF NOT EXISTS restore_url`).WillReturnResult(sqlmock.NewResult(0, 0))
+				prep := m.ExpectPrepare(copyQuery)
+				prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+				prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+				prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+				m.ExpectQuery(regexp.QuoteMeta(restoreQuery)).WillReturnError(fmt.Errorf("some bd error"))
+				m.ExpectRollback()
+			},
+			ExpectTaken: nil,
+			ExpectErr:   fmt.Errorf("pg.UrlRepository.RestoreLinks: %w", fmt.Errorf("some bd error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			taken, err := urlRepo.RestoreLinks(context.Background(), links)
+
+			assert.Equal(t, tt.ExpectTaken, taken)
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/events"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+// RestoreUrl brings back a link deleted after deletedAfter, once check accepts
+// the usage of its owner in month, if any. A non-empty owner may only restore
+// its own links. Restores are serialized with the additions of the links of
+// the owner, so they can't all pass a check at the limit either.
+func (ur *UrlRepository) RestoreUrl(ctx context.Context, shortUrl, owner string, deletedAfter, month time.Time,
+	check func(*models.Usage) error) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.RestoreUrl: %w", err)
+	}
+	defer tx.Rollback()
+
+	notFound := utils.NewInternalError(http.StatusNotFound, "no restorable link match this shortUrl")
+
+	var linkOwner string
+	err = tx.QueryRowContext(ctx, `SELECT owner FROM url WHERE short_url=$1 AND deleted_at > $3 `+
+		`AND ($2 = '' OR owner = $2)`, shortUrl, owner, deletedAfter).Scan(&linkOwner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFound
+		}
+		return fmt.Errorf("pg.UrlRepository.RestoreUrl: %w", err)
+	}
+
+	if linkOwner != "" {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, usageLockKey,
+			linkOwner); err != nil {
+			return fmt.Errorf("pg.UrlRepository.RestoreUrl: %w", err)
+		}
+		usage, err := queryUsage(ctx, tx, linkOwner, month)
+		if err != nil {
+			return fmt.Errorf("pg.UrlRepository.RestoreUrl: %w", err)
+		}
+		if err := check(usage); err != nil {
+			return err
+		}
+	}
+
+	// the link may have been restored or purged since it was read
+	res, err := tx.ExecContext(ctx, `UPDATE url SET deleted_at = NULL WHERE short_url=$1 AND deleted_at > $2`,
+		shortUrl, deletedAfter)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.RestoreUrl: %w", err)
+	}
+	restored, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.RestoreUrl: %w", err)
+	}
+	if restored == 0 {
+		return notFound
+	}
+
+	if err := enqueueEvents(ctx, tx, events.Restored(shortUrl, linkOwner)); err != nil {
+		return fmt.Errorf("pg.UrlRepository.RestoreUrl: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pg.UrlRepository.RestoreUrl: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeletedLinks deletes the tombstones of the links deleted before
+// before, freeing their codes, and returns their number.
+func (ur *UrlRepository) PurgeDeletedLinks(ctx context.Context, before time.Time) (int64, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	res, err := ur.DB.ExecContext(ctx, `DELETE FROM url WHERE deleted_at <= $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.PurgeDeletedLinks: %w", err)
+	}
+
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.PurgeDeletedLinks: %w", err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,120 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreUrl(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	const selectQuery = `SELECT owner FROM url WHERE short_url=\$1 AND deleted_at > \$3 AND \(\$2 = '' OR owner = \$2\)`
+	const lockQuery = `SELECT pg_advisory_xact_lock\(\$1, hashtext\(\$2\)\)`
+	const restoreUrlQuery = `UPDATE url SET deleted_at = NULL WHERE short_url=\$1 AND deleted_at > \$2`
+	deletedAfter := time.Date(2026, time.September, 19, 12, 0, 0, 0, time.UTC)
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	quotaErr := utils.NewInternalError(http.StatusForbidden, "quota reached")
+	notRestorable := &utils.InternalError{Code: http.StatusNotFound, Message: "no restorable link match this shortUrl"}
+
+	expectUsage := func(m sqlmock.Sqlmock, active int64) {
+		m.ExpectQuery(selectQuery).WithArgs("Abc_efg_ag", "team", deletedAfter).
+			WillReturnRows(m.NewRows([]string{"owner"}).AddRow("team"))
+		m.ExpectExec(lockQuery).WithArgs(usageLockKey, "team").WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectQuery(usageQuery).WithArgs("team", month).WillReturnRows(
+			m.NewRows([]string{"active", "creations", "resolutions"}).AddRow(active, 5, 120))
+	}
+	check := func(usage *models.Usage) error {
+		if usage.ActiveLinks >= 10 {
+			return quotaErr
+		}
+		return nil
+	}
+
+	tests := []struct {
+		Name      string
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectErr error
+	}{
+		{
+			Name: "successful restore",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				expectUsage(m, 3)
+				m.ExpectExec(restoreUrlQuery).WithArgs("Abc_efg_ag", deletedAfter).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEnqueue(m, "link.restored", "team", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name: "link not deleted, deleted too long ago or of another owner",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(selectQuery).WithArgs("Abc_efg_ag", "team", deletedAfter).WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			ExpectErr: notRestorable,
+		},
+		{
+			Name: "owner over the active links quota",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				expectUsage(m, 10)
+				m.ExpectRollback()
+			},
+			ExpectErr: quotaErr,
+		},
+		{
+			Name: "link restored concurrently",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				expectUsage(m, 3)
+				m.ExpectExec(restoreUrlQuery).WithArgs("Abc_efg_ag", deletedAfter).WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectRollback()
+			},
+			ExpectErr: notRestorable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			err := urlRepo.RestoreUrl(context.Background(), "Abc_efg_ag", "team", deletedAfter, month, check)
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPurgeDeletedLinks(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	before := time.Date(2026, time.September, 19, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`DELETE FROM url WHERE deleted_at <= \$1`).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 4))
+
+	purged, err := urlRepo.PurgeDeletedLinks(context.Background(), before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
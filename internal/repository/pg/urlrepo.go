@@ -3,15 +3,89 @@ package pg
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/AlexNov03/UrlShortener/internal/events"
 	"github.com/AlexNov03/UrlShortener/internal/models"
 	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/lib/pq"
 )
 
+// marshalList encodes a list for a JSONB column, storing an empty array rather
+// than null when there are no elements.
+func marshalList[T any](list []T) ([]byte, error) {
+	if list == nil {
+		list = []T{}
+	}
+	return json.Marshal(list)
+}
+
+// unmarshalList decodes a JSONB array column, returning nil for empty arrays.
+func unmarshalList[T any](data []byte) ([]T, error) {
+	var list []T
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list, nil
+}
+
+// urlColumns are the columns read by scanUrlData, the tags of the link being
+// aggregated in their original order.
+const urlColumns = `short_url, original_url, clicks_left, not_before, not_after, fallback_url, rules, destinations, ` +
+	`utm_source, utm_medium, utm_campaign, pass_query, interstitial, created_at, title, description, notes, owner, clicks, ` +
+	`deleted_at, ARRAY(SELECT tag FROM url_tags WHERE url_tags.short_url = url.short_url ORDER BY position)`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUrlData(row rowScanner) (*models.UrlData, error) {
+	data := &models.UrlData{}
+	var clicksLeft sql.NullInt64
+	var notBefore, notAfter, deletedAt sql.NullTime
+	var rules, destinations []byte
+	var tags []string
+
+	err := row.Scan(&data.ShortUrl, &data.OriginalUrl, &clicksLeft, &notBefore, &notAfter, &data.FallbackUrl, &rules,
+		&destinations, &data.UtmSource, &data.UtmMedium, &data.UtmCampaign, &data.PassQuery, &data.Interstitial,
+		&data.CreatedAt, &data.Title, &data.Description, &data.Notes, &data.Owner, &data.Clicks, &deletedAt,
+		pq.Array(&tags))
+	if err != nil {
+		return nil, err
+	}
+
+	if clicksLeft.Valid {
+		left := int(clicksLeft.Int64)
+		data.ClicksLeft = &left
+	}
+	if notBefore.Valid {
+		data.NotBefore = &notBefore.Time
+	}
+	if notAfter.Valid {
+		data.NotAfter = &notAfter.Time
+	}
+	if deletedAt.Valid {
+		data.DeletedAt = &deletedAt.Time
+	}
+	if data.Rules, err = unmarshalList[models.TargetingRule](rules); err != nil {
+		return nil, err
+	}
+	if data.Destinations, err = unmarshalList[models.Destination](destinations); err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 {
+		data.Tags = tags
+	}
+	return data, nil
+}
+
 type UrlRepository struct {
 	DB *sql.DB
 }
@@ -34,21 +108,71 @@ func (ur *UrlRepository) AddOriginalUrl(ctx context.Context, data *models.UrlDat
 		return fmt.Errorf("pg.UrlRepository.AddOriginalUrl: %w", err)
 	}
 
-	_, err = ur.DB.ExecContext(ctx, `INSERT INTO url (short_url, original_url) VALUES ($1, $2)`, data.ShortUrl, data.OriginalUrl)
+	tx, err := ur.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("pg.UrlRepository.AddOriginalUrl: %w", err)
 	}
+	defer tx.Rollback()
+
+	if err := insertUrl(ctx, tx, data); err != nil {
+		return mapError("pg.UrlRepository.AddOriginalUrl", err)
+	}
+
+	if err := enqueueEvents(ctx, tx, events.Created(data)); err != nil {
+		return fmt.Errorf("pg.UrlRepository.AddOriginalUrl: %w", err)
+	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pg.UrlRepository.AddOriginalUrl: %w", err)
+	}
 	return nil
 }
 
+// insertUrl inserts a new link with its tags.
+func insertUrl(ctx context.Context, tx *sql.Tx, data *models.UrlData) error {
+	rules, err := marshalList(data.Rules)
+	if err != nil {
+		return err
+	}
+
+	destinations, err := marshalList(data.Destinations)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO url `+
+		`(short_url, original_url, clicks_left, not_before, not_after, fallback_url, rules, destinations, `+
+		`utm_source, utm_medium, utm_campaign, pass_query, interstitial, title, description, notes, owner) `+
+		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+		data.ShortUrl, data.OriginalUrl, data.ClicksLeft, data.NotBefore, data.NotAfter, data.FallbackUrl, rules, destinations,
+		data.UtmSource, data.UtmMedium, data.UtmCampaign, data.PassQuery, data.Interstitial,
+		data.Title, data.Description, data.Notes, data.Owner)
+	if err != nil {
+		return err
+	}
+
+	return insertTags(ctx, tx, data.ShortUrl, data.Tags)
+}
+
+func insertTags(ctx context.Context, tx *sql.Tx, shortUrl string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO url_tags (short_url, tag, position) `+
+		`SELECT $1, tag, position FROM unnest($2::text[]) WITH ORDINALITY AS t(tag, position)`,
+		shortUrl, pq.Array(tags))
+	return err
+}
+
 func (ur *UrlRepository) GetOriginalUrl(ctx context.Context, shortUrl string) (string, error) {
 
 	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
 	var originalUrl string
-	err := ur.DB.QueryRowContext(ctx, `SELECT original_url FROM url WHERE short_url=$1`, shortUrl).Scan(&originalUrl)
+	var deleted bool
+	err := ur.DB.QueryRowContext(ctx, `SELECT original_url, deleted_at IS NOT NULL FROM url WHERE short_url=$1`,
+		shortUrl).Scan(&originalUrl, &deleted)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -56,5 +180,294 @@ func (ur *UrlRepository) GetOriginalUrl(ctx context.Context, shortUrl string) (s
 		}
 		return "", fmt.Errorf("pg.UrlRepository.GetOriginalUrl: %w", err)
 	}
+	if deleted {
+		return "", utils.NewInternalError(http.StatusGone, "this shortUrl has been deleted")
+	}
 	return originalUrl, nil
 }
+
+func (ur *UrlRepository) GetUrlData(ctx context.Context, shortUrl string) (*models.UrlData, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	data, err := scanUrlData(ur.DB.QueryRowContext(ctx, `SELECT `+urlColumns+` FROM url WHERE short_url=$1`, shortUrl))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")
+		}
+		return nil, fmt.Errorf("pg.UrlRepository.GetUrlData: %w", err)
+	}
+	if data.DeletedAt != nil {
+		return nil, utils.NewInternalError(http.StatusGone, "this shortUrl has been deleted")
+	}
+	return data, nil
+}
+
+// DecrementClicks consumes one click of a click-limited link. The update is
+// conditional, so concurrent callers can never drive the counter below zero.
+// Consuming the last click expires the link.
+func (ur *UrlRepository) DecrementClicks(ctx context.Context, shortUrl string) (int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.DecrementClicks: %w", err)
+	}
+	defer tx.Rollback()
+
+	var clicksLeft int
+	var owner string
+	err = tx.QueryRowContext(ctx,
+		`UPDATE url SET clicks_left = clicks_left - 1 WHERE short_url=$1 AND clicks_left > 0 RETURNING clicks_left, owner`,
+		shortUrl).Scan(&clicksLeft, &owner)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, utils.NewInternalError(http.StatusGone, "this shortUrl has reached its click limit")
+		}
+		return 0, fmt.Errorf("pg.UrlRepository.DecrementClicks: %w", err)
+	}
+
+	if clicksLeft == 0 {
+		if err := enqueueEvents(ctx, tx, events.Expired(shortUrl, owner, events.ReasonClickLimit)); err != nil {
+			return 0, fmt.Errorf("pg.UrlRepository.DecrementClicks: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.DecrementClicks: %w", err)
+	}
+	return clicksLeft, nil
+}
+
+// UpdateWindow replaces the activation window of a link, which expires
+// again once the new window ends.
+func (ur *UrlRepository) UpdateWindow(ctx context.Context, shortUrl string, window *models.ActivationWindow) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateWindow: %w", err)
+	}
+	defer tx.Rollback()
+
+	var owner string
+	err = tx.QueryRowContext(ctx, `UPDATE url SET not_before=$2, not_after=$3, fallback_url=$4, expiry_notified=FALSE `+
+		`WHERE short_url=$1 RETURNING owner`, shortUrl, window.NotBefore, window.NotAfter, window.FallbackUrl).Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")
+		}
+		return mapError("pg.UrlRepository.UpdateWindow", err)
+	}
+
+	if err := enqueueEvents(ctx, tx, events.WindowUpdated(shortUrl, owner, window)); err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateWindow: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateWindow: %w", err)
+	}
+	return nil
+}
+
+func (ur *UrlRepository) UpdateRules(ctx context.Context, shortUrl string, rules []models.TargetingRule) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	encoded, err := marshalList(rules)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateRules: %w", err)
+	}
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateRules: %w", err)
+	}
+	defer tx.Rollback()
+
+	var owner string
+	err = tx.QueryRowContext(ctx, `UPDATE url SET rules=$2 WHERE short_url=$1 RETURNING owner`, shortUrl, encoded).Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")
+		}
+		return mapError("pg.UrlRepository.UpdateRules", err)
+	}
+
+	if err := enqueueEvents(ctx, tx, events.RulesUpdated(shortUrl, owner, rules)); err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateRules: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateRules: %w", err)
+	}
+	return nil
+}
+
+// RecordClick counts a resolution of shortUrl and, when variant is not
+// negative, of the split destination with that index, along with the monthly
+// resolutions of its owner in month. The new count is reported to the
+// subscriptions using it as a click threshold.
+func (ur *UrlRepository) RecordClick(ctx context.Context, shortUrl string, variant int, month time.Time) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.RecordClick: %w", err)
+	}
+	defer tx.Rollback()
+
+	var clicks int64
+	var owner string
+	err = tx.QueryRowContext(ctx, `UPDATE url SET clicks = clicks + 1 WHERE short_url=$1 RETURNING clicks, owner`,
+		shortUrl).Scan(&clicks, &owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")
+		}
+		return fmt.Errorf("pg.UrlRepository.RecordClick: %w", err)
+	}
+
+	if variant >= 0 {
+		_, err = tx.ExecContext(ctx, `INSERT INTO variant_clicks (short_url, variant, clicks) VALUES ($1, $2, 1) `+
+			`ON CONFLICT (short_url, variant) DO UPDATE SET clicks = variant_clicks.clicks + 1`, shortUrl, variant)
+		if err != nil {
+			return mapError("pg.UrlRepository.RecordClick", err)
+		}
+	}
+
+	if owner != "" {
+		_, err = tx.ExecContext(ctx, `INSERT INTO owner_usage (owner, month, resolutions) `+
+			`VALUES ($1, $2, 1) ON CONFLICT (owner, month) DO UPDATE SET resolutions = owner_usage.resolutions + 1`,
+			owner, month)
+		if err != nil {
+			return fmt.Errorf("pg.UrlRepository.RecordClick: %w", err)
+		}
+	}
+
+	if err := enqueueEvents(ctx, tx, events.ClicksReached(shortUrl, owner, clicks)); err != nil {
+		return fmt.Errorf("pg.UrlRepository.RecordClick: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pg.UrlRepository.RecordClick: %w", err)
+	}
+	return nil
+}
+
+func (ur *UrlRepository) GetClicks(ctx context.Context, shortUrl string) (*models.ClickData, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	data := &models.ClickData{VariantClicks: make(map[int]int64)}
+
+	err := ur.DB.QueryRowContext(ctx, `SELECT clicks FROM url WHERE short_url=$1`, shortUrl).Scan(&data.Clicks)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")
+		}
+		return nil, fmt.Errorf("pg.UrlRepository.GetClicks: %w", err)
+	}
+
+	rows, err := ur.DB.QueryContext(ctx, `SELECT variant, clicks FROM variant_clicks WHERE short_url=$1`, shortUrl)
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.GetClicks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var variant int
+		var clicks int64
+		if err := rows.Scan(&variant, &clicks); err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.GetClicks: %w", err)
+		}
+		data.VariantClicks[variant] = clicks
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.GetClicks: %w", err)
+	}
+
+	return data, nil
+}
+
+func (ur *UrlRepository) UpdateMetadata(ctx context.Context, shortUrl string, metadata *models.LinkMetadata) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateMetadata: %w", err)
+	}
+	defer tx.Rollback()
+
+	var owner string
+	err = tx.QueryRowContext(ctx, `UPDATE url SET title=$2, description=$3, notes=$4 WHERE short_url=$1 RETURNING owner`,
+		shortUrl, metadata.Title, metadata.Description, metadata.Notes).Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")
+		}
+		return mapError("pg.UrlRepository.UpdateMetadata", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM url_tags WHERE short_url=$1`, shortUrl); err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateMetadata: %w", err)
+	}
+	if err := insertTags(ctx, tx, shortUrl, metadata.Tags); err != nil {
+		return mapError("pg.UrlRepository.UpdateMetadata", err)
+	}
+
+	if err := enqueueEvents(ctx, tx, events.MetadataUpdated(shortUrl, owner, metadata)); err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateMetadata: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateMetadata: %w", err)
+	}
+	return nil
+}
+
+// DeleteUrl turns a link into a tombstone, which keeps its code, tags and
+// click counters until it is restored or purged.
+func (ur *UrlRepository) DeleteUrl(ctx context.Context, shortUrl string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.DeleteUrl: %w", err)
+	}
+	defer tx.Rollback()
+
+	var owner string
+	err = tx.QueryRowContext(ctx, `UPDATE url SET deleted_at = now() WHERE short_url=$1 AND deleted_at IS NULL `+
+		`RETURNING owner`, shortUrl).Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")
+		}
+		return fmt.Errorf("pg.UrlRepository.DeleteUrl: %w", err)
+	}
+
+	if err := enqueueEvents(ctx, tx, events.Deleted(shortUrl, owner)); err != nil {
+		return fmt.Errorf("pg.UrlRepository.DeleteUrl: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pg.UrlRepository.DeleteUrl: %w", err)
+	}
+	return nil
+}
@@ -5,11 +5,14 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/AlexNov03/UrlShortener/internal/models"
 	"github.com/AlexNov03/UrlShortener/utils"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -22,6 +25,8 @@ func TestGetOriginalUrl(t *testing.T) {
 
 	urlRepo := NewUrlRepository(db)
 
+	const originalUrlQuery = `SELECT original_url, deleted_at IS NOT NULL FROM url WHERE short_url=\$1`
+
 	tests := []struct {
 		Name          string
 		ShortUrl      string
@@ -33,19 +38,31 @@ func TestGetOriginalUrl(t *testing.T) {
 			Name:     "successful getting origUrl",
 			ShortUrl: "Abc_efg_ag",
 			Setup: func(m sqlmock.Sqlmock) {
-				rows := m.NewRows([]string{"original_url"}).AddRow(
-					"http://ya.ru")
-				m.ExpectQuery(`SELECT original_url FROM url WHERE short_url=\$1`).WithArgs(
+				rows := m.NewRows([]string{"original_url", "deleted"}).AddRow(
+					"http://ya.ru", false)
+				m.ExpectQuery(originalUrlQuery).WithArgs(
 					"Abc_efg_ag").WillReturnRows(rows)
 			},
 			ExpectOrigUrl: "http://ya.ru",
 			ExpectErr:     nil,
 		},
+		{
+			Name:     "failed getting origUrl of deleted link",
+			ShortUrl: "Abc_efg_ah",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows([]string{"original_url", "deleted"}).AddRow(
+					"http://ya.ru", true)
+				m.ExpectQuery(originalUrlQuery).WithArgs(
+					"Abc_efg_ah").WillReturnRows(rows)
+			},
+			ExpectOrigUrl: "",
+			ExpectErr:     &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has been deleted"},
+		},
 		{
 			Name:     "failed getting origUrl",
 			ShortUrl: "Abc_efah_a",
 			Setup: func(m sqlmock.Sqlmock) {
-				m.ExpectQuery(`SELECT original_url FROM url WHERE short_url=\$1`).WithArgs(
+				m.ExpectQuery(originalUrlQuery).WithArgs(
 					"Abc_efah_a").WillReturnError(sql.ErrNoRows)
 			},
 			ExpectOrigUrl: "",
@@ -66,6 +83,13 @@ func TestGetOriginalUrl(t *testing.T) {
 
 }
 
+const insertUrlQuery = `INSERT INTO url \(short_url, original_url, clicks_left, not_before, not_after, fallback_url, rules, destinations, ` +
+	`utm_source, utm_medium, utm_campaign, pass_query, interstitial, title, description, notes, owner\) ` +
+	`VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9, \$10, \$11, \$12, \$13, \$14, \$15, \$16, \$17\)`
+
+const insertTagsQuery = `INSERT INTO url_tags \(short_url, tag, position\) ` +
+	`SELECT \$1, tag, position FROM unnest\(\$2::text\[\]\) WITH ORDINALITY AS t\(tag, position\)`
+
 func TestAddOriginalUrl(t *testing.T) {
 
 	db, mock, err := sqlmock.New()
@@ -91,8 +115,35 @@ func TestAddOriginalUrl(t *testing.T) {
 				m.ExpectQuery(`SELECT 1 FROM url WHERE short_url=\$1`).WithArgs(
 					data.ShortUrl).WillReturnError(sql.ErrNoRows)
 
-				m.ExpectExec(`INSERT INTO url \(short_url, original_url\) VALUES \(\$1, \$2\)`).WithArgs(
-					data.ShortUrl, data.OriginalUrl).WillReturnResult(sqlmock.NewResult(1, 1))
+				m.ExpectBegin()
+				m.ExpectExec(insertUrlQuery).WithArgs(
+					data.ShortUrl, data.OriginalUrl, nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false, "", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				expectEnqueue(m, "link.created", "", 0).WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectCommit()
+
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name: "successful adding origUrl with metadata",
+			UrlData: models.UrlData{
+				ShortUrl:     "Abc_def_gs",
+				OriginalUrl:  "http://ya.ru",
+				LinkMetadata: models.LinkMetadata{Title: "Spring sale", Tags: []string{"promo", "spring sale"}},
+			},
+			Setup: func(m sqlmock.Sqlmock, data models.UrlData) {
+
+				m.ExpectQuery(`SELECT 1 FROM url WHERE short_url=\$1`).WithArgs(
+					data.ShortUrl).WillReturnError(sql.ErrNoRows)
+
+				m.ExpectBegin()
+				m.ExpectExec(insertUrlQuery).WithArgs(
+					data.ShortUrl, data.OriginalUrl, nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false,
+					"Spring sale", "", "", "").WillReturnResult(sqlmock.NewResult(1, 1))
+				m.ExpectExec(insertTagsQuery).WithArgs(
+					data.ShortUrl, `{"promo","spring sale"}`).WillReturnResult(sqlmock.NewResult(0, 2))
+				expectEnqueue(m, "link.created", "", 0).WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectCommit()
 
 			},
 			ExpectErr: nil,
@@ -124,12 +175,55 @@ func TestAddOriginalUrl(t *testing.T) {
 				m.ExpectQuery(`SELECT 1 FROM url WHERE short_url=\$1`).WithArgs(
 					data.ShortUrl).WillReturnError(sql.ErrNoRows)
 
-				m.ExpectExec(`INSERT INTO url \(short_url, original_url\) VALUES \(\$1, \$2\)`).WithArgs(
-					data.ShortUrl, data.OriginalUrl).WillReturnError(fmt.Errorf("some bd error"))
+				m.ExpectBegin()
+				m.ExpectExec(insertUrlQuery).WithArgs(
+					data.ShortUrl, data.OriginalUrl, nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false, "", "", "", "").WillReturnError(fmt.Errorf("some bd error"))
+				m.ExpectRollback()
 
 			},
 			ExpectErr: fmt.Errorf("pg.UrlRepository.AddOriginalUrl: %w", fmt.Errorf("some bd error")),
 		},
+		{
+			Name: "error when origUrl is inserted concurrently",
+			UrlData: models.UrlData{
+				ShortUrl:    "http://localhost:8080/Abc_def_gs",
+				OriginalUrl: "http://ya.ru",
+			},
+			Setup: func(m sqlmock.Sqlmock, data models.UrlData) {
+
+				m.ExpectQuery(`SELECT 1 FROM url WHERE short_url=\$1`).WithArgs(
+					data.ShortUrl).WillReturnError(sql.ErrNoRows)
+
+				m.ExpectBegin()
+				m.ExpectExec(insertUrlQuery).WithArgs(
+					data.ShortUrl, data.OriginalUrl, nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false, "", "", "", "").WillReturnError(
+					&pq.Error{Code: "23505", Constraint: "url_short_url_key"})
+				m.ExpectRollback()
+
+			},
+			ExpectErr: &utils.InternalError{Code: http.StatusConflict, Message: "this shortUrl already exists"},
+		},
+		{
+			Name: "error when origUrl is too long for the column",
+			UrlData: models.UrlData{
+				ShortUrl:    "http://localhost:8080/Abc_def_gs",
+				OriginalUrl: "http://ya.ru",
+			},
+			Setup: func(m sqlmock.Sqlmock, data models.UrlData) {
+
+				m.ExpectQuery(`SELECT 1 FROM url WHERE short_url=\$1`).WithArgs(
+					data.ShortUrl).WillReturnError(sql.ErrNoRows)
+
+				m.ExpectBegin()
+				m.ExpectExec(insertUrlQuery).WithArgs(
+					data.ShortUrl, data.OriginalUrl, nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false, "", "", "", "").WillReturnError(
+					&pq.Error{Code: "22001", Message: "value too long for type character varying(255)"})
+				m.ExpectRollback()
+
+			},
+			ExpectErr: &utils.InternalError{Code: http.StatusUnprocessableEntity, Message: "value is too long to be stored",
+				Reason: "value_too_long"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -144,3 +238,711 @@ func TestAddOriginalUrl(t *testing.T) {
 	}
 
 }
+
+var urlDataColumns = []string{"short_url", "original_url", "clicks_left", "not_before", "not_after", "fallback_url", "rules",
+	"destinations", "utm_source", "utm_medium", "utm_campaign", "pass_query", "interstitial", "created_at", "title", "description",
+	"notes", "owner", "clicks", "deleted_at", "tags"}
+
+const urlDataQuery = `SELECT short_url, original_url, clicks_left, not_before, not_after, fallback_url, rules, destinations, ` +
+	`utm_source, utm_medium, utm_campaign, pass_query, interstitial, created_at, title, description, notes, owner, clicks, ` +
+	`deleted_at, ARRAY\(SELECT tag FROM url_tags WHERE url_tags.short_url = url.short_url ORDER BY position\) FROM url WHERE short_url=\$1`
+
+func TestGetUrlData(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	clicksLeft := 3
+	notBefore := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2025, 2, 27, 15, 32, 56, 0, time.UTC)
+
+	tests := []struct {
+		Name       string
+		ShortUrl   string
+		Setup      func(m sqlmock.Sqlmock)
+		ExpectData *models.UrlData
+		ExpectErr  error
+	}{
+		{
+			Name:     "successful getting unlimited url data",
+			ShortUrl: "Abc_efg_ag",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows(urlDataColumns).AddRow(
+					"Abc_efg_ag", "http://ya.ru", nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false, createdAt, "", "", "", "", int64(0), nil, []byte("{}"))
+				m.ExpectQuery(urlDataQuery).WithArgs(
+					"Abc_efg_ag").WillReturnRows(rows)
+			},
+			ExpectData: &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_efg_ag", CreatedAt: createdAt},
+			ExpectErr:  nil,
+		},
+		{
+			Name:     "successful getting click-limited url data",
+			ShortUrl: "Abc_efg_ah",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows(urlDataColumns).AddRow(
+					"Abc_efg_ah", "http://ya.ru", 3, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false, createdAt, "", "", "", "", int64(0), nil, []byte("{}"))
+				m.ExpectQuery(urlDataQuery).WithArgs(
+					"Abc_efg_ah").WillReturnRows(rows)
+			},
+			ExpectData: &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_efg_ah", CreatedAt: createdAt, ClicksLeft: &clicksLeft},
+			ExpectErr:  nil,
+		},
+		{
+			Name:     "successful getting url data with activation window",
+			ShortUrl: "Abc_efg_ai",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows(urlDataColumns).AddRow(
+					"Abc_efg_ai", "http://ya.ru", nil, notBefore, notAfter, "http://ya.ru/soon", []byte("[]"), []byte("[]"), "", "", "", false, false, createdAt, "", "", "", "", int64(0), nil, []byte("{}"))
+				m.ExpectQuery(urlDataQuery).WithArgs(
+					"Abc_efg_ai").WillReturnRows(rows)
+			},
+			ExpectData: &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_efg_ai", CreatedAt: createdAt,
+				ActivationWindow: models.ActivationWindow{NotBefore: &notBefore, NotAfter: &notAfter, FallbackUrl: "http://ya.ru/soon"}},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "successful getting url data with targeting rules",
+			ShortUrl: "Abc_efg_aj",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows(urlDataColumns).AddRow(
+					"Abc_efg_aj", "http://ya.ru", nil, nil, nil, "", []byte(`[{"os":"ios","target_url":"https://apps.apple.com/app"}]`), []byte("[]"), "", "", "", false, false, createdAt, "", "", "", "", int64(0), nil, []byte("{}"))
+				m.ExpectQuery(urlDataQuery).WithArgs(
+					"Abc_efg_aj").WillReturnRows(rows)
+			},
+			ExpectData: &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_efg_aj", CreatedAt: createdAt,
+				Rules: []models.TargetingRule{{OS: "ios", TargetUrl: "https://apps.apple.com/app"}}},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "successful getting url data with split destinations",
+			ShortUrl: "Abc_efg_ak",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows(urlDataColumns).AddRow(
+					"Abc_efg_ak", "http://ya.ru", nil, nil, nil, "", []byte("[]"),
+					[]byte(`[{"url":"http://ya.ru/a","weight":1},{"url":"http://ya.ru/b","weight":3}]`), "", "", "", false, false, createdAt, "", "", "", "", int64(0), nil, []byte("{}"))
+				m.ExpectQuery(urlDataQuery).WithArgs(
+					"Abc_efg_ak").WillReturnRows(rows)
+			},
+			ExpectData: &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_efg_ak", CreatedAt: createdAt,
+				Destinations: []models.Destination{{Url: "http://ya.ru/a", Weight: 1}, {Url: "http://ya.ru/b", Weight: 3}}},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "successful getting url data with query options",
+			ShortUrl: "Abc_efg_al",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows(urlDataColumns).AddRow(
+					"Abc_efg_al", "http://ya.ru", nil, nil, nil, "", []byte("[]"), []byte("[]"), "newsletter", "email", "spring", true, false, createdAt, "", "", "", "", int64(0), nil, []byte("{}"))
+				m.ExpectQuery(urlDataQuery).WithArgs(
+					"Abc_efg_al").WillReturnRows(rows)
+			},
+			ExpectData: &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_efg_al", CreatedAt: createdAt,
+				QueryOptions: models.QueryOptions{UtmSource: "newsletter", UtmMedium: "email", UtmCampaign: "spring", PassQuery: true}},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "successful getting interstitial url data",
+			ShortUrl: "Abc_efg_am",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows(urlDataColumns).AddRow(
+					"Abc_efg_am", "http://ya.ru", nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, true, createdAt, "", "", "", "", int64(0), nil, []byte("{}"))
+				m.ExpectQuery(urlDataQuery).WithArgs(
+					"Abc_efg_am").WillReturnRows(rows)
+			},
+			ExpectData: &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_efg_am", Interstitial: true, CreatedAt: createdAt},
+			ExpectErr:  nil,
+		},
+		{
+			Name:     "successful getting url data with metadata",
+			ShortUrl: "Abc_efg_an",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows(urlDataColumns).AddRow(
+					"Abc_efg_an", "http://ya.ru", nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false, createdAt,
+					"Spring sale", "Landing of the spring campaign", "ask marketing before removing", "", int64(0), nil, []byte(`{promo,"spring sale"}`))
+				m.ExpectQuery(urlDataQuery).WithArgs(
+					"Abc_efg_an").WillReturnRows(rows)
+			},
+			ExpectData: &models.UrlData{OriginalUrl: "http://ya.ru", ShortUrl: "Abc_efg_an", CreatedAt: createdAt,
+				LinkMetadata: models.LinkMetadata{Title: "Spring sale", Description: "Landing of the spring campaign",
+					Tags: []string{"promo", "spring sale"}, Notes: "ask marketing before removing"}},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "failed getting url data of deleted link",
+			ShortUrl: "Abc_efg_ao",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows(urlDataColumns).AddRow(
+					"Abc_efg_ao", "http://ya.ru", nil, nil, nil, "", []byte("[]"), []byte("[]"), "", "", "", false, false, createdAt, "", "", "", "", int64(0), createdAt, []byte("{}"))
+				m.ExpectQuery(urlDataQuery).WithArgs(
+					"Abc_efg_ao").WillReturnRows(rows)
+			},
+			ExpectData: nil,
+			ExpectErr:  &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has been deleted"},
+		},
+		{
+			Name:     "failed getting url data",
+			ShortUrl: "Abc_efah_a",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(urlDataQuery).WithArgs(
+					"Abc_efah_a").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectData: nil,
+			ExpectErr:  &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			res, err := urlRepo.GetUrlData(context.Background(), tt.ShortUrl)
+
+			assert.Equal(t, tt.ExpectData, res)
+			assert.Equal(t, tt.ExpectErr, err)
+		})
+	}
+}
+
+func TestDecrementClicks(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	const decrementQuery = `UPDATE url SET clicks_left = clicks_left - 1 WHERE short_url=\$1 AND clicks_left > 0 ` +
+		`RETURNING clicks_left, owner`
+
+	tests := []struct {
+		Name             string
+		ShortUrl         string
+		Setup            func(m sqlmock.Sqlmock)
+		ExpectClicksLeft int
+		ExpectErr        error
+	}{
+		{
+			Name:     "successful decrementing clicks",
+			ShortUrl: "Abc_efg_ag",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows([]string{"clicks_left", "owner"}).AddRow(1, "team")
+				m.ExpectBegin()
+				m.ExpectQuery(decrementQuery).WithArgs("Abc_efg_ag").WillReturnRows(rows)
+				m.ExpectCommit()
+			},
+			ExpectClicksLeft: 1,
+			ExpectErr:        nil,
+		},
+		{
+			Name:     "link expires with its last click",
+			ShortUrl: "Abc_efg_ag",
+			Setup: func(m sqlmock.Sqlmock) {
+				rows := m.NewRows([]string{"clicks_left", "owner"}).AddRow(0, "team")
+				m.ExpectBegin()
+				m.ExpectQuery(decrementQuery).WithArgs("Abc_efg_ag").WillReturnRows(rows)
+				expectEnqueue(m, "link.expired", "team", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectClicksLeft: 0,
+			ExpectErr:        nil,
+		},
+		{
+			Name:     "error when clicks are exhausted",
+			ShortUrl: "Abc_efg_ag",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(decrementQuery).WithArgs("Abc_efg_ag").WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			ExpectClicksLeft: 0,
+			ExpectErr:        &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has reached its click limit"},
+		},
+		{
+			Name:     "internal db error test",
+			ShortUrl: "Abc_efg_ag",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(decrementQuery).WithArgs("Abc_efg_ag").WillReturnError(fmt.Errorf("some bd error"))
+				m.ExpectRollback()
+			},
+			ExpectClicksLeft: 0,
+			ExpectErr:        fmt.Errorf("pg.UrlRepository.DecrementClicks: %w", fmt.Errorf("some bd error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			res, err := urlRepo.DecrementClicks(context.Background(), tt.ShortUrl)
+
+			assert.Equal(t, tt.ExpectClicksLeft, res)
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateWindow(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	notAfter := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	const updateQuery = `UPDATE url SET not_before=\$2, not_after=\$3, fallback_url=\$4, expiry_notified=FALSE ` +
+		`WHERE short_url=\$1 RETURNING owner`
+
+	tests := []struct {
+		Name      string
+		ShortUrl  string
+		Window    models.ActivationWindow
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectErr error
+	}{
+		{
+			Name:     "successful updating window",
+			ShortUrl: "Abc_efg_ag",
+			Window:   models.ActivationWindow{NotAfter: &notAfter},
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(updateQuery).WithArgs("Abc_efg_ag", nil, notAfter, "").WillReturnRows(
+					m.NewRows([]string{"owner"}).AddRow(""))
+				expectEnqueue(m, "link.updated", "", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "error when shortUrl does not exist",
+			ShortUrl: "Abc_efg_ah",
+			Window:   models.ActivationWindow{},
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(updateQuery).WithArgs("Abc_efg_ah", nil, nil, "").WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			ExpectErr: &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+		{
+			Name:     "internal db error test",
+			ShortUrl: "Abc_efg_ag",
+			Window:   models.ActivationWindow{},
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(updateQuery).WithArgs("Abc_efg_ag", nil, nil, "").WillReturnError(fmt.Errorf("some bd error"))
+				m.ExpectRollback()
+			},
+			ExpectErr: fmt.Errorf("pg.UrlRepository.UpdateWindow: %w", fmt.Errorf("some bd error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			err := urlRepo.UpdateWindow(context.Background(), tt.ShortUrl, &tt.Window)
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateRules(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	const updateQuery = `UPDATE url SET rules=\$2 WHERE short_url=\$1 RETURNING owner`
+
+	tests := []struct {
+		Name      string
+		ShortUrl  string
+		Rules     []models.TargetingRule
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectErr error
+	}{
+		{
+			Name:     "successful updating rules",
+			ShortUrl: "Abc_efg_ag",
+			Rules:    []models.TargetingRule{{OS: "android", TargetUrl: "https://play.google.com/app"}},
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(updateQuery).WithArgs(
+					"Abc_efg_ag", []byte(`[{"os":"android","target_url":"https://play.google.com/app"}]`)).WillReturnRows(
+					m.NewRows([]string{"owner"}).AddRow("team"))
+				expectEnqueue(m, "link.updated", "team", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "successful clearing rules",
+			ShortUrl: "Abc_efg_ag",
+			Rules:    nil,
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(updateQuery).WithArgs("Abc_efg_ag", []byte(`[]`)).WillReturnRows(
+					m.NewRows([]string{"owner"}).AddRow(""))
+				expectEnqueue(m, "link.updated", "", 0).WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectCommit()
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "error when shortUrl does not exist",
+			ShortUrl: "Abc_efg_ah",
+			Rules:    nil,
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(updateQuery).WithArgs("Abc_efg_ah", []byte(`[]`)).WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			ExpectErr: &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			err := urlRepo.UpdateRules(context.Background(), tt.ShortUrl, tt.Rules)
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateMetadata(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	const updateQuery = `UPDATE url SET title=\$2, description=\$3, notes=\$4 WHERE short_url=\$1 RETURNING owner`
+	const deleteTagsQuery = `DELETE FROM url_tags WHERE short_url=\$1`
+
+	tests := []struct {
+		Name      string
+		ShortUrl  string
+		Metadata  models.LinkMetadata
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectErr error
+	}{
+		{
+			Name:     "successful updating metadata",
+			ShortUrl: "Abc_efg_ag",
+			Metadata: models.LinkMetadata{Title: "Spring sale", Notes: "temporary", Tags: []string{"promo"}},
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(updateQuery).WithArgs("Abc_efg_ag", "Spring sale", "", "temporary").WillReturnRows(
+					m.NewRows([]string{"owner"}).AddRow(""))
+				m.ExpectExec(deleteTagsQuery).WithArgs("Abc_efg_ag").WillReturnResult(sqlmock.NewResult(0, 3))
+				m.ExpectExec(insertTagsQuery).WithArgs("Abc_efg_ag", `{"promo"}`).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEnqueue(m, "link.updated", "", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "successful clearing metadata",
+			ShortUrl: "Abc_efg_ag",
+			Metadata: models.LinkMetadata{},
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(updateQuery).WithArgs("Abc_efg_ag", "", "", "").WillReturnRows(
+					m.NewRows([]string{"owner"}).AddRow(""))
+				m.ExpectExec(deleteTagsQuery).WithArgs("Abc_efg_ag").WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEnqueue(m, "link.updated", "", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "error when shortUrl does not exist",
+			ShortUrl: "Abc_efg_ah",
+			Metadata: models.LinkMetadata{Title: "Spring sale"},
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(updateQuery).WithArgs("Abc_efg_ah", "Spring sale", "", "").WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			ExpectErr: &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			err := urlRepo.UpdateMetadata(context.Background(), tt.ShortUrl, &tt.Metadata)
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteUrl(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	const deleteQuery = `UPDATE url SET deleted_at = now\(\) WHERE short_url=\$1 AND deleted_at IS NULL RETURNING owner`
+
+	tests := []struct {
+		Name      string
+		ShortUrl  string
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectErr error
+	}{
+		{
+			Name:     "successful deleting",
+			ShortUrl: "Abc_efg_ag",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(deleteQuery).WithArgs("Abc_efg_ag").WillReturnRows(m.NewRows([]string{"owner"}).AddRow("team"))
+				expectEnqueue(m, "link.deleted", "team", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name:     "error when shortUrl does not exist",
+			ShortUrl: "Abc_efg_ah",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(deleteQuery).WithArgs("Abc_efg_ah").WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			ExpectErr: &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			err := urlRepo.DeleteUrl(context.Background(), tt.ShortUrl)
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRecordClick(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	const upsertVariantQuery = `INSERT INTO variant_clicks \(short_url, variant, clicks\) VALUES \(\$1, \$2, 1\) ` +
+		`ON CONFLICT \(short_url, variant\) DO UPDATE SET clicks = variant_clicks.clicks \+ 1`
+
+	const clickQuery = `UPDATE url SET clicks = clicks \+ 1 WHERE short_url=\$1 RETURNING clicks, owner`
+
+	resolutionQuery := regexp.QuoteMeta(`INSERT INTO owner_usage (owner, month, resolutions) ` +
+		`VALUES ($1, $2, 1) ON CONFLICT (owner, month) DO UPDATE SET resolutions = owner_usage.resolutions + 1`)
+
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name      string
+		Variant   int
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectErr error
+	}{
+		{
+			Name:    "successful recording click",
+			Variant: -1,
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(clickQuery).WithArgs("Abc_efg_ag").WillReturnRows(
+					m.NewRows([]string{"clicks", "owner"}).AddRow(100, "team"))
+				m.ExpectExec(resolutionQuery).WithArgs("team", month).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEnqueue(m, "link.clicks_threshold", "team", 100).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name:    "successful recording click of link without owner",
+			Variant: -1,
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(clickQuery).WithArgs("Abc_efg_ag").WillReturnRows(
+					m.NewRows([]string{"clicks", "owner"}).AddRow(100, ""))
+				expectEnqueue(m, "link.clicks_threshold", "", 100).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name:    "successful recording variant click",
+			Variant: 1,
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(clickQuery).WithArgs("Abc_efg_ag").WillReturnRows(
+					m.NewRows([]string{"clicks", "owner"}).AddRow(100, "team"))
+				m.ExpectExec(upsertVariantQuery).WithArgs("Abc_efg_ag", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec(resolutionQuery).WithArgs("team", month).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEnqueue(m, "link.clicks_threshold", "team", 100).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name:    "internal db error test",
+			Variant: 1,
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(clickQuery).WithArgs("Abc_efg_ag").WillReturnRows(
+					m.NewRows([]string{"clicks", "owner"}).AddRow(100, "team"))
+				m.ExpectExec(upsertVariantQuery).WithArgs("Abc_efg_ag", 1).WillReturnError(fmt.Errorf("some bd error"))
+				m.ExpectRollback()
+			},
+			ExpectErr: fmt.Errorf("pg.UrlRepository.RecordClick: %w", fmt.Errorf("some bd error")),
+		},
+		{
+			Name:    "error when shortUrl does not exist",
+			Variant: -1,
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery(clickQuery).WithArgs("Abc_efg_ag").WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			ExpectErr: &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			err := urlRepo.RecordClick(context.Background(), "Abc_efg_ag", tt.Variant, month)
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetClicks(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	tests := []struct {
+		Name         string
+		Setup        func(m sqlmock.Sqlmock)
+		ExpectClicks *models.ClickData
+		ExpectErr    error
+	}{
+		{
+			Name: "successful getting clicks",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(`SELECT clicks FROM url WHERE short_url=\$1`).WithArgs("Abc_efg_ag").WillReturnRows(
+					m.NewRows([]string{"clicks"}).AddRow(7))
+				m.ExpectQuery(`SELECT variant, clicks FROM variant_clicks WHERE short_url=\$1`).WithArgs("Abc_efg_ag").WillReturnRows(
+					m.NewRows([]string{"variant", "clicks"}).AddRow(0, 2).AddRow(1, 5))
+			},
+			ExpectClicks: &models.ClickData{Clicks: 7, VariantClicks: map[int]int64{0: 2, 1: 5}},
+			ExpectErr:    nil,
+		},
+		{
+			Name: "failed getting clicks",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(`SELECT clicks FROM url WHERE short_url=\$1`).WithArgs("Abc_efg_ag").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectClicks: nil,
+			ExpectErr:    &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			res, err := urlRepo.GetClicks(context.Background(), "Abc_efg_ag")
+
+			assert.Equal(t, tt.ExpectClicks, res)
+			assert.Equal(t, tt.ExpectErr, err)
+		})
+	}
+}
+
+func TestMapError(t *testing.T) {
+
+	tests := []struct {
+		Name      string
+		Err       error
+		ExpectErr error
+	}{
+		{
+			Name:      "unique violation",
+			Err:       &pq.Error{Code: "23505"},
+			ExpectErr: &utils.InternalError{Code: http.StatusConflict, Message: "this shortUrl already exists"},
+		},
+		{
+			Name:      "foreign key violation",
+			Err:       &pq.Error{Code: "23503"},
+			ExpectErr: &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+		{
+			Name: "string too long",
+			Err:  &pq.Error{Code: "22001"},
+			ExpectErr: &utils.InternalError{Code: http.StatusUnprocessableEntity, Message: "value is too long to be stored",
+				Reason: "value_too_long"},
+		},
+		{
+			Name:      "not null violation",
+			Err:       &pq.Error{Code: "23502"},
+			ExpectErr: &utils.InternalError{Code: http.StatusBadRequest, Message: "required value is missing"},
+		},
+		{
+			Name: "check violation",
+			Err:  fmt.Errorf("wrapped: %w", &pq.Error{Code: "23514"}),
+			ExpectErr: &utils.InternalError{Code: http.StatusUnprocessableEntity, Message: "value violates a storage constraint",
+				Reason: "constraint_violation"},
+		},
+		{
+			Name:      "other postgres error",
+			Err:       &pq.Error{Code: "40001"},
+			ExpectErr: fmt.Errorf("pg.UrlRepository.Op: %w", &pq.Error{Code: "40001"}),
+		},
+		{
+			Name:      "non postgres error",
+			Err:       context.DeadlineExceeded,
+			ExpectErr: fmt.Errorf("pg.UrlRepository.Op: %w", context.DeadlineExceeded),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.ExpectErr, mapError("pg.UrlRepository.Op", tt.Err))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/events"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+// usageLockKey is the first key of the advisory locks serializing the
+// additions of links of an owner, the second one being the hash of the owner.
+const usageLockKey = 0x75736167
+
+// rowQuerier is implemented by both *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// queryUsage returns the usage of owner in month.
+func queryUsage(ctx context.Context, q rowQuerier, owner string, month time.Time) (*models.Usage, error) {
+	usage := &models.Usage{Owner: owner, Month: month}
+	err := q.QueryRowContext(ctx, `SELECT `+
+		`(SELECT count(*) FROM url WHERE owner=$1 AND deleted_at IS NULL), `+
+		`COALESCE((SELECT creations FROM owner_usage WHERE owner=$1 AND month=$2), 0), `+
+		`COALESCE((SELECT resolutions FROM owner_usage WHERE owner=$1 AND month=$2), 0)`,
+		owner, month).Scan(&usage.ActiveLinks, &usage.Creations, &usage.Resolutions)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// AddOwnedUrl adds a link of an owner, counting its creation in month, once
+// check accepts the usage of the owner. The additions of the links of an owner
+// are serialized, so concurrent ones can't all pass a check at the limit.
+func (ur *UrlRepository) AddOwnedUrl(ctx context.Context, data *models.UrlData, month time.Time,
+	check func(*models.Usage) error) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.AddOwnedUrl: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, usageLockKey,
+		data.Owner); err != nil {
+		return fmt.Errorf("pg.UrlRepository.AddOwnedUrl: %w", err)
+	}
+
+	usage, err := queryUsage(ctx, tx, data.Owner, month)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.AddOwnedUrl: %w", err)
+	}
+	if err := check(usage); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM url WHERE short_url=$1`, data.ShortUrl).Scan(new(int))
+	if err == nil {
+		return utils.NewInternalError(http.StatusConflict, "this shortUrl already exists")
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("pg.UrlRepository.AddOwnedUrl: %w", err)
+	}
+
+	if err := insertUrl(ctx, tx, data); err != nil {
+		return mapError("pg.UrlRepository.AddOwnedUrl", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO owner_usage (owner, month, creations) VALUES ($1, $2, 1) `+
+		`ON CONFLICT (owner, month) DO UPDATE SET creations = owner_usage.creations + 1`, data.Owner, month)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.AddOwnedUrl: %w", err)
+	}
+
+	if err := enqueueEvents(ctx, tx, events.Created(data)); err != nil {
+		return fmt.Errorf("pg.UrlRepository.AddOwnedUrl: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pg.UrlRepository.AddOwnedUrl: %w", err)
+	}
+	return nil
+}
+
+// GetUsage returns the usage of owner in month.
+func (ur *UrlRepository) GetUsage(ctx context.Context, owner string, month time.Time) (*models.Usage, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	usage, err := queryUsage(ctx, ur.DB, owner, month)
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.GetUsage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,141 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var usageQuery = regexp.QuoteMeta(`SELECT ` +
+	`(SELECT count(*) FROM url WHERE owner=$1 AND deleted_at IS NULL), ` +
+	`COALESCE((SELECT creations FROM owner_usage WHERE owner=$1 AND month=$2), 0), ` +
+	`COALESCE((SELECT resolutions FROM owner_usage WHERE owner=$1 AND month=$2), 0)`)
+
+func TestAddOwnedUrl(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	data := &models.UrlData{ShortUrl: "Abc_def_gs", OriginalUrl: "http://ya.ru", Owner: "team"}
+	quotaErr := utils.NewInternalError(http.StatusForbidden, "quota reached")
+
+	const lockQuery = `SELECT pg_advisory_xact_lock\(\$1, hashtext\(\$2\)\)`
+	creationQuery := regexp.QuoteMeta(`INSERT INTO owner_usage (owner, month, creations) VALUES ($1, $2, 1) ` +
+		`ON CONFLICT (owner, month) DO UPDATE SET creations = owner_usage.creations + 1`)
+
+	tests := []struct {
+		Name        string
+		Setup       func(m sqlmock.Sqlmock)
+		ExpectUsage *models.Usage
+		ExpectErr   error
+	}{
+		{
+			Name: "successful adding link within quota",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec(lockQuery).WithArgs(usageLockKey, "team").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectQuery(usageQuery).WithArgs("team", month).WillReturnRows(
+					m.NewRows([]string{"active", "creations", "resolutions"}).AddRow(3, 5, 120))
+				m.ExpectQuery(`SELECT 1 FROM url WHERE short_url=\$1`).WithArgs("Abc_def_gs").WillReturnError(sql.ErrNoRows)
+				m.ExpectExec(insertUrlQuery).WithArgs("Abc_def_gs", "http://ya.ru", nil, nil, nil, "", []byte("[]"),
+					[]byte("[]"), "", "", "", false, false, "", "", "", "team").WillReturnResult(sqlmock.NewResult(1, 1))
+				m.ExpectExec(creationQuery).WithArgs("team", month).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectEnqueue(m, "link.created", "team", 0).WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectCommit()
+			},
+			ExpectUsage: &models.Usage{Owner: "team", Month: month, ActiveLinks: 3, Creations: 5, Resolutions: 120},
+		},
+		{
+			Name: "error when check rejects the usage",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec(lockQuery).WithArgs(usageLockKey, "team").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectQuery(usageQuery).WithArgs("team", month).WillReturnRows(
+					m.NewRows([]string{"active", "creations", "resolutions"}).AddRow(100, 5, 120))
+				m.ExpectRollback()
+			},
+			ExpectUsage: &models.Usage{Owner: "team", Month: month, ActiveLinks: 100, Creations: 5, Resolutions: 120},
+			ExpectErr:   quotaErr,
+		},
+		{
+			Name: "error when code is taken",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec(lockQuery).WithArgs(usageLockKey, "team").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectQuery(usageQuery).WithArgs("team", month).WillReturnRows(
+					m.NewRows([]string{"active", "creations", "resolutions"}).AddRow(3, 5, 120))
+				m.ExpectQuery(`SELECT 1 FROM url WHERE short_url=\$1`).WithArgs("Abc_def_gs").WillReturnRows(
+					m.NewRows([]string{"1"}).AddRow(1))
+				m.ExpectRollback()
+			},
+			ExpectUsage: &models.Usage{Owner: "team", Month: month, ActiveLinks: 3, Creations: 5, Resolutions: 120},
+			ExpectErr:   &utils.InternalError{Code: http.StatusConflict, Message: "this shortUrl already exists"},
+		},
+		{
+			Name: "internal db error test",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec(lockQuery).WithArgs(usageLockKey, "team").WillReturnError(fmt.Errorf("some bd error"))
+				m.ExpectRollback()
+			},
+			ExpectErr: fmt.Errorf("pg.UrlRepository.AddOwnedUrl: %w", fmt.Errorf("some bd error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+
+			var checked *models.Usage
+			err := urlRepo.AddOwnedUrl(context.Background(), data, month, func(usage *models.Usage) error {
+				checked = usage
+				if usage.ActiveLinks >= 100 {
+					return quotaErr
+				}
+				return nil
+			})
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.Equal(t, tt.ExpectUsage, checked)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetUsage(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(usageQuery).WithArgs("team", month).WillReturnRows(
+		mock.NewRows([]string{"active", "creations", "resolutions"}).AddRow(3, 0, 0))
+
+	usage, err := urlRepo.GetUsage(context.Background(), "team", month)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Usage{Owner: "team", Month: month, ActiveLinks: 3}, usage)
+
+	mock.ExpectQuery(usageQuery).WithArgs("team", month).WillReturnError(fmt.Errorf("some bd error"))
+
+	_, err = urlRepo.GetUsage(context.Background(), "team", month)
+	assert.Equal(t, fmt.Errorf("pg.UrlRepository.GetUsage: %w", fmt.Errorf("some bd error")), err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,290 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/events"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/lib/pq"
+)
+
+// enqueueEventsQuery writes a delivery of every event to the outbox for each
+// subscription it matches.
+const enqueueEventsQuery = `INSERT INTO webhook_outbox (subscription_id, event_id, event_type, payload) ` +
+	`SELECT s.id, e.event_id, e.event_type, e.payload::jsonb ` +
+	`FROM unnest($1::text[], $2::text[], $3::text[], $4::bigint[], $5::text[]) AS e(event_id, event_type, owner, clicks, payload) ` +
+	`JOIN webhook_subscriptions s ON e.event_type = ANY(s.events) AND (s.owner = '' OR s.owner = e.owner) ` +
+	`AND (e.event_type <> '` + events.LinkClicksThreshold + `' OR e.clicks = ANY(s.click_thresholds))`
+
+// enqueueEvents writes the deliveries of evts in tx, so they are delivered
+// if and only if the change they describe is committed.
+func enqueueEvents(ctx context.Context, tx *sql.Tx, evts ...*models.WebhookEvent) error {
+	if len(evts) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(evts))
+	types := make([]string, 0, len(evts))
+	owners := make([]string, 0, len(evts))
+	clicks := make([]int64, 0, len(evts))
+	payloads := make([]string, 0, len(evts))
+	for _, event := range evts {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, event.ID)
+		types = append(types, event.Type)
+		owners = append(owners, event.Owner)
+		clicks = append(clicks, event.Clicks)
+		payloads = append(payloads, string(payload))
+	}
+
+	_, err := tx.ExecContext(ctx, enqueueEventsQuery, pq.Array(ids), pq.Array(types), pq.Array(owners),
+		pq.Array(clicks), pq.Array(payloads))
+	return err
+}
+
+// CreateWebhook stores a subscription, setting its id and creation time.
+func (ur *UrlRepository) CreateWebhook(ctx context.Context, subscription *models.WebhookSubscription) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	thresholds := subscription.ClickThresholds
+	if thresholds == nil {
+		thresholds = []int64{}
+	}
+
+	err := ur.DB.QueryRowContext(ctx, `INSERT INTO webhook_subscriptions (url, secret, events, click_thresholds, owner) `+
+		`VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`, subscription.Url, subscription.Secret,
+		pq.Array(subscription.Events), pq.Array(thresholds), subscription.Owner).Scan(&subscription.ID, &subscription.CreatedAt)
+	if err != nil {
+		return mapError("pg.UrlRepository.CreateWebhook", err)
+	}
+	return nil
+}
+
+// ListWebhooks returns the subscriptions of owner, or all of them when owner
+// is empty, without their secrets.
+func (ur *UrlRepository) ListWebhooks(ctx context.Context, owner string) ([]*models.WebhookSubscription, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := ur.DB.QueryContext(ctx, `SELECT id, url, events, click_thresholds, owner, created_at `+
+		`FROM webhook_subscriptions WHERE $1 = '' OR owner = $1 ORDER BY id`, owner)
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListWebhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var res []*models.WebhookSubscription
+	for rows.Next() {
+		subscription := &models.WebhookSubscription{}
+		if err := rows.Scan(&subscription.ID, &subscription.Url, pq.Array(&subscription.Events),
+			pq.Array(&subscription.ClickThresholds), &subscription.Owner, &subscription.CreatedAt); err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.ListWebhooks: %w", err)
+		}
+		if len(subscription.ClickThresholds) == 0 {
+			subscription.ClickThresholds = nil
+		}
+		res = append(res, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListWebhooks: %w", err)
+	}
+	return res, nil
+}
+
+// DeleteWebhook deletes a subscription of owner, or any subscription when
+// owner is empty, together with its pending deliveries.
+func (ur *UrlRepository) DeleteWebhook(ctx context.Context, id int64, owner string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := ur.DB.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id=$1 AND ($2 = '' OR owner = $2)`,
+		id, owner)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.DeleteWebhook: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.DeleteWebhook: %w", err)
+	}
+	if affected == 0 {
+		return utils.NewInternalError(http.StatusNotFound, "no webhook with this id")
+	}
+	return nil
+}
+
+// ListDeadLetters returns up to limit deliveries that ran out of attempts, of
+// the subscriptions of owner or of all subscriptions when owner is empty.
+func (ur *UrlRepository) ListDeadLetters(ctx context.Context, owner string, limit int) ([]*models.WebhookDelivery, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := ur.DB.QueryContext(ctx, `SELECT o.id, o.subscription_id, s.url, o.event_id, o.event_type, o.payload, `+
+		`o.attempts, o.next_attempt_at, o.last_error, o.created_at FROM webhook_outbox o `+
+		`JOIN webhook_subscriptions s ON s.id = o.subscription_id `+
+		`WHERE o.dead AND ($1 = '' OR s.owner = $1) ORDER BY o.id LIMIT $2`, owner, limit)
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListDeadLetters: %w", err)
+	}
+	defer rows.Close()
+
+	var res []*models.WebhookDelivery
+	for rows.Next() {
+		delivery := &models.WebhookDelivery{Dead: true}
+		if err := rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.Url, &delivery.EventID, &delivery.EventType,
+			&delivery.Payload, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastError,
+			&delivery.CreatedAt); err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.ListDeadLetters: %w", err)
+		}
+		res = append(res, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListDeadLetters: %w", err)
+	}
+	return res, nil
+}
+
+// RetryDeadLetter schedules a dead letter of the subscriptions of owner, or
+// of any subscription when owner is empty, for delivery at now with a fresh
+// set of attempts.
+func (ur *UrlRepository) RetryDeadLetter(ctx context.Context, id int64, owner string, now time.Time) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := ur.DB.ExecContext(ctx, `UPDATE webhook_outbox o SET dead = FALSE, attempts = 0, next_attempt_at = $3 `+
+		`FROM webhook_subscriptions s WHERE o.id = $1 AND o.dead AND s.id = o.subscription_id `+
+		`AND ($2 = '' OR s.owner = $2)`, id, owner, now)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.RetryDeadLetter: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.RetryDeadLetter: %w", err)
+	}
+	if affected == 0 {
+		return utils.NewInternalError(http.StatusNotFound, "no dead letter with this id")
+	}
+	return nil
+}
+
+// claimQuery locks the due deliveries until $2, so no other worker picks
+// them up meanwhile, and returns them with the url and secret of their
+// subscriptions.
+const claimQuery = `UPDATE webhook_outbox o SET next_attempt_at = $2 FROM webhook_subscriptions s ` +
+	`WHERE s.id = o.subscription_id AND o.id IN (SELECT id FROM webhook_outbox ` +
+	`WHERE NOT dead AND next_attempt_at <= $1 ORDER BY next_attempt_at, id LIMIT $3 FOR UPDATE SKIP LOCKED) ` +
+	`RETURNING o.id, o.subscription_id, s.url, s.secret, o.event_id, o.event_type, o.payload, o.attempts, o.created_at`
+
+// ClaimWebhookDeliveries returns up to limit deliveries due at now, which
+// are not returned again before lockedUntil.
+func (ur *UrlRepository) ClaimWebhookDeliveries(ctx context.Context, now, lockedUntil time.Time,
+	limit int) ([]*models.WebhookDelivery, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := ur.DB.QueryContext(ctx, claimQuery, now, lockedUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ClaimWebhookDeliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var res []*models.WebhookDelivery
+	for rows.Next() {
+		delivery := &models.WebhookDelivery{NextAttemptAt: lockedUntil}
+		if err := rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.Url, &delivery.Secret, &delivery.EventID,
+			&delivery.EventType, &delivery.Payload, &delivery.Attempts, &delivery.CreatedAt); err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.ClaimWebhookDeliveries: %w", err)
+		}
+		res = append(res, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ClaimWebhookDeliveries: %w", err)
+	}
+	return res, nil
+}
+
+// CompleteWebhookDelivery removes a delivered event from the outbox.
+func (ur *UrlRepository) CompleteWebhookDelivery(ctx context.Context, id int64) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	if _, err := ur.DB.ExecContext(ctx, `DELETE FROM webhook_outbox WHERE id=$1`, id); err != nil {
+		return fmt.Errorf("pg.UrlRepository.CompleteWebhookDelivery: %w", err)
+	}
+	return nil
+}
+
+// FailWebhookDelivery stores the attempts, next attempt, error and dead
+// letter flag of a failed delivery.
+func (ur *UrlRepository) FailWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	_, err := ur.DB.ExecContext(ctx, `UPDATE webhook_outbox SET attempts=$2, next_attempt_at=$3, last_error=$4, dead=$5 `+
+		`WHERE id=$1`, delivery.ID, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.Dead)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.FailWebhookDelivery: %w", err)
+	}
+	return nil
+}
+
+// EnqueueExpiredLinks writes link.expired events of the links whose
+// activation window ended by now, once per window, and returns their number.
+func (ur *UrlRepository) EnqueueExpiredLinks(ctx context.Context, now time.Time) (int, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	tx, err := ur.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.EnqueueExpiredLinks: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `UPDATE url SET expiry_notified = TRUE `+
+		`WHERE not_after <= $1 AND NOT expiry_notified AND deleted_at IS NULL RETURNING short_url, owner`, now)
+	if err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.EnqueueExpiredLinks: %w", err)
+	}
+
+	var evts []*models.WebhookEvent
+	for rows.Next() {
+		var shortUrl, owner string
+		if err := rows.Scan(&shortUrl, &owner); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("pg.UrlRepository.EnqueueExpiredLinks: %w", err)
+		}
+		evts = append(evts, events.Expired(shortUrl, owner, events.ReasonNotAfter))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.EnqueueExpiredLinks: %w", err)
+	}
+
+	if err := enqueueEvents(ctx, tx, evts...); err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.EnqueueExpiredLinks: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("pg.UrlRepository.EnqueueExpiredLinks: %w", err)
+	}
+	return len(evts), nil
+}
@@ -0,0 +1,158 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectEnqueue expects the deliveries of one event of type typ about a link
+// of owner to be written to the outbox.
+func expectEnqueue(m sqlmock.Sqlmock, typ, owner string, clicks int64) *sqlmock.ExpectedExec {
+	return m.ExpectExec(regexp.QuoteMeta(enqueueEventsQuery)).WithArgs(sqlmock.AnyArg(), `{"`+typ+`"}`, `{"`+owner+`"}`,
+		fmt.Sprintf("{%d}", clicks), sqlmock.AnyArg())
+}
+
+func TestCreateWebhook(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	createdAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO webhook_subscriptions (url, secret, events, click_thresholds, owner) `+
+		`VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`)).
+		WithArgs("https://example.com/hook", "0123456789abcdef", `{"link.created"}`, `{}`, "team").
+		WillReturnRows(mock.NewRows([]string{"id", "created_at"}).AddRow(7, createdAt))
+
+	subscription := &models.WebhookSubscription{Url: "https://example.com/hook", Secret: "0123456789abcdef",
+		Events: []string{"link.created"}, Owner: "team"}
+	err = urlRepo.CreateWebhook(context.Background(), subscription)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), subscription.ID)
+	assert.Equal(t, createdAt, subscription.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteWebhook(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	const deleteQuery = `DELETE FROM webhook_subscriptions WHERE id=\$1 AND \(\$2 = '' OR owner = \$2\)`
+
+	tests := []struct {
+		Name      string
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectErr error
+	}{
+		{
+			Name: "successful delete",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectExec(deleteQuery).WithArgs(7, "team").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectErr: nil,
+		},
+		{
+			Name: "webhook of another owner",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectExec(deleteQuery).WithArgs(7, "team").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectErr: utils.NewInternalError(http.StatusNotFound, "no webhook with this id"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup(mock)
+			err := urlRepo.DeleteWebhook(context.Background(), 7, "team")
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestClaimWebhookDeliveries(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	lockedUntil := now.Add(time.Minute)
+
+	mock.ExpectQuery(regexp.QuoteMeta(claimQuery)).WithArgs(now, lockedUntil, 10).WillReturnRows(
+		mock.NewRows([]string{"id", "subscription_id", "url", "secret", "event_id", "event_type", "payload", "attempts",
+			"created_at"}).AddRow(3, 7, "https://example.com/hook", "0123456789abcdef", "EVT", "link.deleted",
+			[]byte(`{"id":"EVT"}`), 1, now))
+
+	deliveries, err := urlRepo.ClaimWebhookDeliveries(context.Background(), now, lockedUntil, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.WebhookDelivery{{ID: 3, SubscriptionID: 7, Url: "https://example.com/hook",
+		Secret: "0123456789abcdef", EventID: "EVT", EventType: "link.deleted", Payload: json.RawMessage(`{"id":"EVT"}`),
+		Attempts: 1, NextAttemptAt: lockedUntil, CreatedAt: now}}, deliveries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryDeadLetter(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`UPDATE webhook_outbox o SET dead = FALSE, attempts = 0, next_attempt_at = \$3`).
+		WithArgs(3, "", now).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = urlRepo.RetryDeadLetter(context.Background(), 3, "", now)
+
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no dead letter with this id"), err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnqueueExpiredLinks(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE url SET expiry_notified = TRUE WHERE not_after <= \$1 AND NOT expiry_notified AND deleted_at IS NULL ` +
+		`RETURNING short_url, owner`).WithArgs(now).WillReturnRows(
+		mock.NewRows([]string{"short_url", "owner"}).AddRow("Abc_efg_ag", "team").AddRow("Abc_efg_ah", ""))
+	mock.ExpectExec(regexp.QuoteMeta(enqueueEventsQuery)).WithArgs(sqlmock.AnyArg(),
+		`{"link.expired","link.expired"}`, `{"team",""}`, `{0,0}`, sqlmock.AnyArg()).WillReturnResult(
+		sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	expired, err := urlRepo.EnqueueExpiredLinks(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, expired)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
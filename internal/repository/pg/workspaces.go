@@ -0,0 +1,262 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/lib/pq"
+)
+
+// violates reports whether err is a Postgres error with the given code.
+func violates(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pq.ErrorCode(code)
+}
+
+func (ur *UrlRepository) CreateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := ur.DB.QueryRowContext(ctx, `INSERT INTO workspaces (slug, name) VALUES ($1, $2) RETURNING created_at`,
+		workspace.Slug, workspace.Name).Scan(&workspace.CreatedAt)
+	if violates(err, codeUniqueViolation) {
+		return utils.NewInternalError(http.StatusConflict, "this workspace already exists")
+	}
+	if err != nil {
+		return mapError("pg.UrlRepository.CreateWorkspace", err)
+	}
+	return nil
+}
+
+func (ur *UrlRepository) GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	workspace := &models.Workspace{}
+	err := ur.DB.QueryRowContext(ctx, `SELECT slug, name, created_at FROM workspaces WHERE slug=$1`, slug).Scan(
+		&workspace.Slug, &workspace.Name, &workspace.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewInternalError(http.StatusNotFound, "no workspace match this slug")
+		}
+		return nil, fmt.Errorf("pg.UrlRepository.GetWorkspace: %w", err)
+	}
+	return workspace, nil
+}
+
+func (ur *UrlRepository) ListWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := ur.DB.QueryContext(ctx, `SELECT slug, name, created_at FROM workspaces ORDER BY slug`)
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListWorkspaces: %w", err)
+	}
+	defer rows.Close()
+
+	var res []*models.Workspace
+	for rows.Next() {
+		workspace := &models.Workspace{}
+		if err := rows.Scan(&workspace.Slug, &workspace.Name, &workspace.CreatedAt); err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.ListWorkspaces: %w", err)
+		}
+		res = append(res, workspace)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListWorkspaces: %w", err)
+	}
+	return res, nil
+}
+
+func (ur *UrlRepository) AddMember(ctx context.Context, member *models.Member) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := ur.DB.QueryRowContext(ctx, `INSERT INTO workspace_members (workspace, name, role) VALUES ($1, $2, $3) `+
+		`RETURNING created_at`, member.Workspace, member.Name, member.Role).Scan(&member.CreatedAt)
+	if violates(err, codeUniqueViolation) {
+		return utils.NewInternalError(http.StatusConflict, "this member already exists")
+	}
+	if violates(err, codeForeignKeyViolation) {
+		return utils.NewInternalError(http.StatusNotFound, "no workspace match this slug")
+	}
+	if err != nil {
+		return mapError("pg.UrlRepository.AddMember", err)
+	}
+	return nil
+}
+
+func (ur *UrlRepository) ListMembers(ctx context.Context, workspace string) ([]*models.Member, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := ur.DB.QueryContext(ctx, `SELECT workspace, name, role, created_at FROM workspace_members `+
+		`WHERE workspace=$1 ORDER BY name`, workspace)
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListMembers: %w", err)
+	}
+	defer rows.Close()
+
+	var res []*models.Member
+	for rows.Next() {
+		member := &models.Member{}
+		if err := rows.Scan(&member.Workspace, &member.Name, &member.Role, &member.CreatedAt); err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.ListMembers: %w", err)
+		}
+		res = append(res, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListMembers: %w", err)
+	}
+	return res, nil
+}
+
+// UpdateMemberRole changes the role of a member, which applies to the keys
+// issued to it from their next request.
+func (ur *UrlRepository) UpdateMemberRole(ctx context.Context, workspace, name, role string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := ur.DB.ExecContext(ctx, `UPDATE workspace_members SET role=$3 WHERE workspace=$1 AND name=$2`,
+		workspace, name, role)
+	if err != nil {
+		return mapError("pg.UrlRepository.UpdateMemberRole", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.UpdateMemberRole: %w", err)
+	}
+	if affected == 0 {
+		return utils.NewInternalError(http.StatusNotFound, "no member match this name")
+	}
+	return nil
+}
+
+// RemoveMember removes a member from a workspace together with its keys.
+func (ur *UrlRepository) RemoveMember(ctx context.Context, workspace, name string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := ur.DB.ExecContext(ctx, `DELETE FROM workspace_members WHERE workspace=$1 AND name=$2`, workspace, name)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.RemoveMember: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.RemoveMember: %w", err)
+	}
+	if affected == 0 {
+		return utils.NewInternalError(http.StatusNotFound, "no member match this name")
+	}
+	return nil
+}
+
+// workspaceKeyColumns are the columns of a key with the role of its member.
+const workspaceKeyColumns = `k.id, k.workspace, k.name, k.member, m.role, k.created_at FROM workspace_keys k ` +
+	`JOIN workspace_members m ON m.workspace = k.workspace AND m.name = k.member`
+
+func scanWorkspaceKey(row rowScanner) (*models.WorkspaceKey, error) {
+	key := &models.WorkspaceKey{}
+	if err := row.Scan(&key.ID, &key.Workspace, &key.Name, &key.Member, &key.Role, &key.CreatedAt); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// CreateWorkspaceKey stores the digest of a key issued to a member.
+func (ur *UrlRepository) CreateWorkspaceKey(ctx context.Context, key *models.WorkspaceKey) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	err := ur.DB.QueryRowContext(ctx, `INSERT INTO workspace_keys (workspace, member, name, digest) `+
+		`VALUES ($1, $2, $3, $4) RETURNING id, created_at`, key.Workspace, key.Member, key.Name, key.Digest).Scan(
+		&key.ID, &key.CreatedAt)
+	if violates(err, codeUniqueViolation) {
+		return utils.NewInternalError(http.StatusConflict, "this api key name already exists")
+	}
+	if violates(err, codeForeignKeyViolation) {
+		return utils.NewInternalError(http.StatusNotFound, "no member match this name")
+	}
+	if err != nil {
+		return mapError("pg.UrlRepository.CreateWorkspaceKey", err)
+	}
+	return nil
+}
+
+func (ur *UrlRepository) ListWorkspaceKeys(ctx context.Context, workspace string) ([]*models.WorkspaceKey, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	rows, err := ur.DB.QueryContext(ctx, `SELECT `+workspaceKeyColumns+` WHERE k.workspace=$1 ORDER BY k.id`, workspace)
+	if err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListWorkspaceKeys: %w", err)
+	}
+	defer rows.Close()
+
+	var res []*models.WorkspaceKey
+	for rows.Next() {
+		key, err := scanWorkspaceKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("pg.UrlRepository.ListWorkspaceKeys: %w", err)
+		}
+		res = append(res, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pg.UrlRepository.ListWorkspaceKeys: %w", err)
+	}
+	return res, nil
+}
+
+func (ur *UrlRepository) DeleteWorkspaceKey(ctx context.Context, workspace string, id int64) error {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	res, err := ur.DB.ExecContext(ctx, `DELETE FROM workspace_keys WHERE workspace=$1 AND id=$2`, workspace, id)
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.DeleteWorkspaceKey: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("pg.UrlRepository.DeleteWorkspaceKey: %w", err)
+	}
+	if affected == 0 {
+		return utils.NewInternalError(http.StatusNotFound, "no api key with this id")
+	}
+	return nil
+}
+
+// FindWorkspaceKey returns the key with the given digest along with the
+// current role of its member.
+func (ur *UrlRepository) FindWorkspaceKey(ctx context.Context, digest string) (*models.WorkspaceKey, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	key, err := scanWorkspaceKey(ur.DB.QueryRowContext(ctx, `SELECT `+workspaceKeyColumns+` WHERE k.digest=$1`, digest))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewInternalError(http.StatusNotFound, "no api key match this digest")
+		}
+		return nil, fmt.Errorf("pg.UrlRepository.FindWorkspaceKey: %w", err)
+	}
+	return key, nil
+}
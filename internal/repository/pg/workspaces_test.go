@@ -0,0 +1,274 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWorkspace(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	createdAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO workspaces (slug, name) VALUES ($1, $2) RETURNING created_at`)
+
+	tests := []struct {
+		Name      string
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectErr error
+	}{
+		{
+			Name: "successful creation",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(insertQuery).WithArgs("team", "Team").WillReturnRows(
+					m.NewRows([]string{"created_at"}).AddRow(createdAt))
+			},
+		},
+		{
+			Name: "error when slug is taken",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(insertQuery).WithArgs("team", "Team").WillReturnError(&pq.Error{Code: "23505"})
+			},
+			ExpectErr: utils.NewInternalError(http.StatusConflict, "this workspace already exists"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.Setup(mock)
+
+			workspace := &models.Workspace{Slug: "team", Name: "Team"}
+			err := urlRepo.CreateWorkspace(context.Background(), workspace)
+
+			assert.Equal(t, tt.ExpectErr, err)
+			if tt.ExpectErr == nil {
+				assert.Equal(t, createdAt, workspace.CreatedAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAddMember(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	createdAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO workspace_members (workspace, name, role) VALUES ($1, $2, $3) ` +
+		`RETURNING created_at`)
+
+	tests := []struct {
+		Name      string
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectErr error
+	}{
+		{
+			Name: "successful adding",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(insertQuery).WithArgs("team", "alice", "editor").WillReturnRows(
+					m.NewRows([]string{"created_at"}).AddRow(createdAt))
+			},
+		},
+		{
+			Name: "error when member exists",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(insertQuery).WithArgs("team", "alice", "editor").WillReturnError(&pq.Error{Code: "23505"})
+			},
+			ExpectErr: utils.NewInternalError(http.StatusConflict, "this member already exists"),
+		},
+		{
+			Name: "error when workspace is missing",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(insertQuery).WithArgs("team", "alice", "editor").WillReturnError(&pq.Error{Code: "23503"})
+			},
+			ExpectErr: utils.NewInternalError(http.StatusNotFound, "no workspace match this slug"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.Setup(mock)
+
+			err := urlRepo.AddMember(context.Background(), &models.Member{Workspace: "team", Name: "alice",
+				Role: "editor"})
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateMemberRole(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	updateQuery := regexp.QuoteMeta(`UPDATE workspace_members SET role=$3 WHERE workspace=$1 AND name=$2`)
+
+	mock.ExpectExec(updateQuery).WithArgs("team", "alice", "admin").WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, urlRepo.UpdateMemberRole(context.Background(), "team", "alice", "admin"))
+
+	mock.ExpectExec(updateQuery).WithArgs("team", "bob", "admin").WillReturnResult(sqlmock.NewResult(0, 0))
+	err = urlRepo.UpdateMemberRole(context.Background(), "team", "bob", "admin")
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no member match this name"), err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateWorkspaceKey(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	createdAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO workspace_keys (workspace, member, name, digest) ` +
+		`VALUES ($1, $2, $3, $4) RETURNING id, created_at`)
+
+	tests := []struct {
+		Name      string
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectErr error
+	}{
+		{
+			Name: "successful creation",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(insertQuery).WithArgs("team", "ci", "deploy", "digest").WillReturnRows(
+					m.NewRows([]string{"id", "created_at"}).AddRow(3, createdAt))
+			},
+		},
+		{
+			Name: "error when name is taken",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(insertQuery).WithArgs("team", "ci", "deploy", "digest").WillReturnError(
+					&pq.Error{Code: "23505"})
+			},
+			ExpectErr: utils.NewInternalError(http.StatusConflict, "this api key name already exists"),
+		},
+		{
+			Name: "error when member is missing",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(insertQuery).WithArgs("team", "ci", "deploy", "digest").WillReturnError(
+					&pq.Error{Code: "23503"})
+			},
+			ExpectErr: utils.NewInternalError(http.StatusNotFound, "no member match this name"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.Setup(mock)
+
+			key := &models.WorkspaceKey{Workspace: "team", Name: "deploy", Member: "ci", Digest: "digest"}
+			err := urlRepo.CreateWorkspaceKey(context.Background(), key)
+
+			assert.Equal(t, tt.ExpectErr, err)
+			if tt.ExpectErr == nil {
+				assert.Equal(t, int64(3), key.ID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFindWorkspaceKey(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	createdAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	findQuery := regexp.QuoteMeta(`SELECT k.id, k.workspace, k.name, k.member, m.role, k.created_at ` +
+		`FROM workspace_keys k JOIN workspace_members m ON m.workspace = k.workspace AND m.name = k.member ` +
+		`WHERE k.digest=$1`)
+
+	tests := []struct {
+		Name      string
+		Setup     func(m sqlmock.Sqlmock)
+		ExpectKey *models.WorkspaceKey
+		ExpectErr error
+	}{
+		{
+			Name: "successful lookup",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(findQuery).WithArgs("digest").WillReturnRows(
+					m.NewRows([]string{"id", "workspace", "name", "member", "role", "created_at"}).
+						AddRow(3, "team", "deploy", "ci", "editor", createdAt))
+			},
+			ExpectKey: &models.WorkspaceKey{ID: 3, Workspace: "team", Name: "deploy", Member: "ci", Role: "editor",
+				CreatedAt: createdAt},
+		},
+		{
+			Name: "error when key is unknown",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(findQuery).WithArgs("digest").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectErr: utils.NewInternalError(http.StatusNotFound, "no api key match this digest"),
+		},
+		{
+			Name: "error from db",
+			Setup: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(findQuery).WithArgs("digest").WillReturnError(fmt.Errorf("some db error"))
+			},
+			ExpectErr: fmt.Errorf("pg.UrlRepository.FindWorkspaceKey: %w", fmt.Errorf("some db error")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.Setup(mock)
+
+			key, err := urlRepo.FindWorkspaceKey(context.Background(), "digest")
+
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.Equal(t, tt.ExpectKey, key)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteWorkspaceKey(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	urlRepo := NewUrlRepository(db)
+
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM workspace_keys WHERE workspace=$1 AND id=$2`)
+
+	mock.ExpectExec(deleteQuery).WithArgs("team", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, urlRepo.DeleteWorkspaceKey(context.Background(), "team", 3))
+
+	mock.ExpectExec(deleteQuery).WithArgs("acme", 3).WillReturnResult(sqlmock.NewResult(0, 0))
+	err = urlRepo.DeleteWorkspaceKey(context.Background(), "acme", 3)
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no api key with this id"), err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
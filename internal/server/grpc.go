@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net"
+
+	urlshortenerv1 "github.com/AlexNov03/UrlShortener/api/urlshortener/v1"
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/grpcdelivery"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// GrpcServer serves the gRPC API together with the health and reflection
+// services.
+type GrpcServer struct {
+	server  *grpc.Server
+	health  *health.Server
+	cfg     *bootstrap.Config
+	service *grpcdelivery.UrlService
+	auth    *auth.Authenticator
+}
+
+// grpcRoles are the roles required by the gRPC methods, the others being
+// served without authentication.
+var grpcRoles = map[string]string{
+	urlshortenerv1.UrlShortener_Shorten_FullMethodName:      auth.RoleEditor,
+	urlshortenerv1.UrlShortener_BatchShorten_FullMethodName: auth.RoleEditor,
+	urlshortenerv1.UrlShortener_Get_FullMethodName:          auth.RoleViewer,
+	urlshortenerv1.UrlShortener_List_FullMethodName:         auth.RoleViewer,
+	urlshortenerv1.UrlShortener_Delete_FullMethodName:       auth.RoleEditor,
+}
+
+// NewGrpcServer creates the gRPC server of the API, authenticating calls with
+// the configured API keys and the keys of workspaces in keys, if not nil.
+func NewGrpcServer(cfg *bootstrap.Config, service *grpcdelivery.UrlService, keys auth.KeyStore) *GrpcServer {
+	return &GrpcServer{cfg: cfg, service: service, auth: auth.NewAuthenticator(cfg.Auth.ApiKeys, keys)}
+}
+
+func (s *GrpcServer) Init() {
+	s.server = grpc.NewServer(grpc.ChainUnaryInterceptor(audit.UnaryInterceptor(s.auth),
+		s.auth.UnaryInterceptor(grpcRoles)))
+	s.health = health.NewServer()
+
+	urlshortenerv1.RegisterUrlShortenerServer(s.server, s.service)
+	healthpb.RegisterHealthServer(s.server, s.health)
+	reflection.Register(s.server)
+
+	s.health.SetServingStatus(urlshortenerv1.UrlShortener_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+}
+
+// Serve serves calls accepted on lis until the server is stopped.
+func (s *GrpcServer) Serve(lis net.Listener) error {
+	if err := s.server.Serve(lis); err != nil {
+		return fmt.Errorf("error while serving grpc: %v ", err)
+	}
+	return nil
+}
+
+func (s *GrpcServer) Run() error {
+	addr := fmt.Sprintf("%s:%d", "0.0.0.0", s.cfg.Grpc.Port)
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error while starting grpc server: %v ", err)
+	}
+	log.Printf("starting grpc server, listening on addr %s", addr)
+	return s.Serve(lis)
+}
+
+// Stop reports the services as not serving and waits for the pending calls.
+func (s *GrpcServer) Stop() {
+	s.health.Shutdown()
+	s.server.GracefulStop()
+	log.Printf("grpc server stopped successfully")
+}
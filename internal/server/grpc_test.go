@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	urlshortenerv1 "github.com/AlexNov03/UrlShortener/api/urlshortener/v1"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/delivery/mocks"
+	"github.com/AlexNov03/UrlShortener/internal/grpcdelivery"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGrpcServer(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	cfg := &bootstrap.Config{}
+	cfg.Auth.ApiKeys = []bootstrap.ApiKey{
+		{Name: "ci", Key: "secret-1", Owner: "team"},
+		{Name: "dashboard", Key: "secret-2", Owner: "team", Role: auth.RoleViewer},
+	}
+
+	s := NewGrpcServer(cfg, grpcdelivery.NewUrlService(mockedUc, validator.New(validator.WithRequiredStructEnabled())),
+		nil)
+	s.Init()
+
+	lis := bufconn.Listen(1024 * 1024)
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(lis)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+
+	t.Run("health", func(t *testing.T) {
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx,
+			&healthpb.HealthCheckRequest{Service: "urlshortener.v1.UrlShortener"})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	})
+
+	t.Run("reflection", func(t *testing.T) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}))
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		require.NoError(t, stream.CloseSend())
+
+		var services []string
+		for _, service := range resp.GetListServicesResponse().GetService() {
+			services = append(services, service.GetName())
+		}
+		assert.Contains(t, services, "urlshortener.v1.UrlShortener")
+		assert.Contains(t, services, "grpc.health.v1.Health")
+	})
+
+	t.Run("delete requires api key", func(t *testing.T) {
+		client := urlshortenerv1.NewUrlShortenerClient(conn)
+
+		_, err := client.Delete(ctx, &urlshortenerv1.DeleteRequest{Code: "Abc_def_qA"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		mockedUc.EXPECT().DeleteLink(gomock.Any(), "Abc_def_qA", "team").Return(nil)
+		_, err = client.Delete(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret-1"),
+			&urlshortenerv1.DeleteRequest{Code: "Abc_def_qA"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("delete requires editor role", func(t *testing.T) {
+		client := urlshortenerv1.NewUrlShortenerClient(conn)
+
+		_, err := client.Delete(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret-2"),
+			&urlshortenerv1.DeleteRequest{Code: "Abc_def_qA"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("resolve is public", func(t *testing.T) {
+		client := urlshortenerv1.NewUrlShortenerClient(conn)
+
+		mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), "Abc_def_qA", gomock.Any()).Return(
+			&models.Redirect{Url: "https://example.com"}, nil)
+		resp, err := client.Resolve(ctx, &urlshortenerv1.ResolveRequest{Code: "Abc_def_qA"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com", resp.GetUrl())
+	})
+
+	s.Stop()
+	assert.NoError(t, <-served)
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/delivery"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// specSchemaTypes maps every schema of the spec to the type encoded or decoded
+// with it.
+var specSchemaTypes = map[string]reflect.Type{
+	"ActivationWindow":    reflect.TypeFor[models.ActivationWindow](),
+	"QueryOptions":        reflect.TypeFor[models.QueryOptions](),
+	"TargetingRule":       reflect.TypeFor[models.TargetingRule](),
+	"Destination":         reflect.TypeFor[models.Destination](),
+	"LinkMetadata":        reflect.TypeFor[models.LinkMetadata](),
+	"QrOptions":           reflect.TypeFor[models.QrOptions](),
+	"OrigUrlData":         reflect.TypeFor[models.OrigUrlData](),
+	"ShortUrlData":        reflect.TypeFor[models.ShortUrlData](),
+	"RulesData":           reflect.TypeFor[models.RulesData](),
+	"LinkData":            reflect.TypeFor[models.LinkData](),
+	"LinkList":            reflect.TypeFor[models.LinkList](),
+	"VariantStats":        reflect.TypeFor[models.VariantStats](),
+	"LinkStats":           reflect.TypeFor[models.LinkStats](),
+	"ImportRecord":        reflect.TypeFor[models.ImportRecord](),
+	"ImportError":         reflect.TypeFor[models.ImportError](),
+	"ImportReport":        reflect.TypeFor[models.ImportReport](),
+	"ExportRecord":        reflect.TypeFor[models.ExportRecord](),
+	"Problem":             reflect.TypeFor[utils.Problem](),
+	"FieldError":          reflect.TypeFor[utils.FieldError](),
+	"WebhookSubscription": reflect.TypeFor[models.WebhookSubscription](),
+	"WebhookList":         reflect.TypeFor[models.WebhookList](),
+	"WebhookEvent":        reflect.TypeFor[models.WebhookEvent](),
+	"LinkChanges":         reflect.TypeFor[models.LinkChanges](),
+	"WebhookDelivery":     reflect.TypeFor[models.WebhookDelivery](),
+	"DeadLetterList":      reflect.TypeFor[models.DeadLetterList](),
+	"AuditEntry":          reflect.TypeFor[models.AuditEntry](),
+	"AuditList":           reflect.TypeFor[models.AuditList](),
+	"AuditVerification":   reflect.TypeFor[models.AuditVerification](),
+	"Quota":               reflect.TypeFor[models.Quota](),
+	"UsageReport":         reflect.TypeFor[models.UsageReport](),
+	"Workspace":           reflect.TypeFor[models.Workspace](),
+	"WorkspaceList":       reflect.TypeFor[models.WorkspaceList](),
+	"Member":              reflect.TypeFor[models.Member](),
+	"MemberList":          reflect.TypeFor[models.MemberList](),
+	"RoleData":            reflect.TypeFor[models.RoleData](),
+	"WorkspaceKey":        reflect.TypeFor[models.WorkspaceKey](),
+	"WorkspaceKeyList":    reflect.TypeFor[models.WorkspaceKeyList](),
+}
+
+type specSchema struct {
+	Required   []string                   `json:"required"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+type specOperation struct {
+	Parameters []struct {
+		Name string `json:"name"`
+		In   string `json:"in"`
+	} `json:"parameters"`
+}
+
+type spec struct {
+	OpenApi    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]specSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// jsonFields returns the JSON names of the fields of t, fields of embedded
+// structs included, and the names of the fields validated as required.
+func jsonFields(t reflect.Type) (fields []string, required []string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if field.Anonymous && tag == "" {
+			embedded, embeddedRequired := jsonFields(field.Type)
+			fields = append(fields, embedded...)
+			required = append(required, embeddedRequired...)
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+
+		// rules after dive apply to the elements
+		rules, _, _ := strings.Cut(field.Tag.Get("validate"), "dive")
+		if slices.Contains(strings.Split(rules, ","), "required") {
+			required = append(required, name)
+		}
+	}
+	return fields, required
+}
+
+func readSpec(t *testing.T) *spec {
+	res := &spec{}
+	require.NoError(t, json.Unmarshal(openApiSpec, res))
+	return res
+}
+
+func TestOpenApiRoutes(t *testing.T) {
+
+	s := NewServer(&bootstrap.Config{}, delivery.NewUrlDelivery(nil, nil), nil, nil)
+	s.Init()
+
+	var routes []string
+	err := s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		// subrouters match prefixes of any method
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			routes = append(routes, strings.ToLower(method)+" "+path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	var specRoutes []string
+	pathParam := regexp.MustCompile(`\{([^}]+)\}`)
+	for path, item := range readSpec(t).Paths {
+		var shared specOperation
+		if raw, ok := item["parameters"]; ok {
+			require.NoError(t, json.Unmarshal(raw, &shared.Parameters))
+		}
+
+		for method, raw := range item {
+			if method == "parameters" {
+				continue
+			}
+			specRoutes = append(specRoutes, method+" "+path)
+
+			var operation specOperation
+			require.NoError(t, json.Unmarshal(raw, &operation))
+			var params []string
+			for _, param := range append(shared.Parameters, operation.Parameters...) {
+				if param.In == "path" {
+					params = append(params, param.Name)
+				}
+			}
+			for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+				assert.Contains(t, params, match[1], "path parameter of %s %s", method, path)
+			}
+		}
+	}
+
+	// routes differing only in their query matchers share an operation
+	slices.Sort(routes)
+	routes = slices.Compact(routes)
+	sort.Strings(specRoutes)
+	assert.Equal(t, routes, specRoutes)
+}
+
+func TestOpenApiSchemas(t *testing.T) {
+
+	schemas := readSpec(t).Components.Schemas
+
+	for name := range schemas {
+		assert.Contains(t, specSchemaTypes, name, "schema %s is not mapped to a type", name)
+	}
+
+	for name, typ := range specSchemaTypes {
+		t.Run(name, func(t *testing.T) {
+			schema, ok := schemas[name]
+			require.True(t, ok, "schema is missing from the spec")
+
+			fields, required := jsonFields(typ)
+
+			var properties []string
+			for property := range schema.Properties {
+				properties = append(properties, property)
+			}
+			sort.Strings(fields)
+			sort.Strings(properties)
+			assert.Equal(t, fields, properties)
+
+			sort.Strings(required)
+			specRequired := slices.Clone(schema.Required)
+			sort.Strings(specRequired)
+			assert.Equal(t, required, specRequired)
+		})
+	}
+}
+
+func TestServeOpenApiSpec(t *testing.T) {
+
+	s := NewServer(&bootstrap.Config{}, delivery.NewUrlDelivery(nil, nil), nil, nil)
+	s.Init()
+
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "3.0.3", readSpec(t).OpenApi)
+	assert.JSONEq(t, string(openApiSpec), w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	// the page loads nothing from outside the server
+	assert.NotContains(t, w.Body.String(), "https://")
+
+	for path, contentType := range map[string]string{
+		"/api/docs/docs.js":              "text/javascript; charset=utf-8",
+		"/api/docs/swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+		"/api/docs/swagger-ui.css":       "text/css; charset=utf-8",
+	} {
+		w = httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, contentType, w.Header().Get("Content-Type"), path)
+	}
+
+	w = httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs/docs.js", nil))
+	assert.Contains(t, w.Body.String(), `url: "/openapi.json"`)
+
+	w = httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs/missing.js", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
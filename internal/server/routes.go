@@ -1,14 +1,163 @@
 package server
 
 import (
+	"crypto/rand"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/utils"
 	"github.com/gorilla/mux"
 )
 
+// apiPrefix is the prefix of the current version of the management API.
+const apiPrefix = "/api/v1"
+
+// The unversioned routes are deprecated since legacyDeprecation and will be
+// removed at legacySunset.
+var (
+	legacyDeprecation = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunset      = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
 func (s *Server) InitRoutes() {
 	router := mux.NewRouter()
-	router.HandleFunc("/shorten", s.delivery.ShortenUrl).Methods(http.MethodPost)
+	router.HandleFunc("/openapi.json", serveOpenApiSpec).Methods(http.MethodGet)
+	router.HandleFunc("/api/docs", serveDocs).Methods(http.MethodGet)
+	router.HandleFunc("/api/docs/{file}", serveDocsAsset).Methods(http.MethodGet)
+
+	api := router.PathPrefix(apiPrefix).Subrouter()
+	api.Handle("/links", s.protect(auth.RoleEditor, s.shortenHandler().ServeHTTP)).Methods(http.MethodPost)
+	s.initApiRoutes(api)
+	s.initWebhookRoutes(api)
+	s.initAuditRoutes(api)
+	s.initWorkspaceRoutes(api)
+
+	legacy := router.NewRoute().Subrouter()
+	legacy.Use(deprecated)
+	legacy.Handle("/shorten", s.protect(auth.RoleEditor, s.shortenHandler().ServeHTTP)).Methods(http.MethodPost)
+	s.initApiRoutes(legacy.PathPrefix("/api").Subrouter())
+
+	router.HandleFunc("/{shortened_url}+", s.delivery.GetPreview).Methods(http.MethodGet)
+	router.HandleFunc("/{shortened_url}", s.delivery.GetPreview).Methods(http.MethodGet).Queries("preview", "1")
 	router.HandleFunc("/{shortened_url}", s.delivery.GetOriginalUrl).Methods(http.MethodGet)
-	s.server.Handler = router
+	s.router = router
+	s.server.Handler = requestID(audit.Middleware(s.auth)(router))
+}
+
+// protect requires a valid API key with role in its workspace for handler.
+func (s *Server) protect(role string, handler http.HandlerFunc) http.Handler {
+	return s.auth.Middleware(auth.Require(role)(handler))
+}
+
+// shortenHandler returns the handler of shorten requests, deduplicated by
+// their idempotency keys when the server has a store for them.
+func (s *Server) shortenHandler() http.Handler {
+	if s.idempotency == nil {
+		return http.HandlerFunc(s.delivery.ShortenUrl)
+	}
+	return s.idempotency.Handler(http.HandlerFunc(s.delivery.ShortenUrl))
+}
+
+// initApiRoutes registers the management endpoints shared by the current and
+// the legacy API.
+func (s *Server) initApiRoutes(router *mux.Router) {
+	router.Handle("/links", s.protect(auth.RoleViewer, s.delivery.ListLinks)).Methods(http.MethodGet)
+	router.Handle("/import", s.protect(auth.RoleEditor, s.delivery.Import)).Methods(http.MethodPost)
+	router.Handle("/export", s.protect(auth.RoleViewer, s.delivery.Export)).Methods(http.MethodGet)
+	router.Handle("/links/{shortened_url}", s.protect(auth.RoleViewer, s.delivery.GetLink)).Methods(http.MethodGet)
+	router.Handle("/links/{shortened_url}", s.protect(auth.RoleEditor, s.delivery.DeleteLink)).Methods(http.MethodDelete)
+	router.Handle("/links/{shortened_url}/restore", s.protect(auth.RoleEditor, s.delivery.RestoreLink)).
+		Methods(http.MethodPost)
+	router.Handle("/links/{shortened_url}/window", s.protect(auth.RoleEditor, s.delivery.UpdateWindow)).
+		Methods(http.MethodPut)
+	router.Handle("/links/{shortened_url}/rules", s.protect(auth.RoleEditor, s.delivery.UpdateRules)).
+		Methods(http.MethodPut)
+	router.Handle("/links/{shortened_url}/metadata", s.protect(auth.RoleEditor, s.delivery.UpdateMetadata)).
+		Methods(http.MethodPut)
+	router.Handle("/links/{shortened_url}/stats", s.protect(auth.RoleViewer, s.delivery.GetStats)).Methods(http.MethodGet)
+	router.Handle("/links/{shortened_url}/qr", s.protect(auth.RoleViewer, s.delivery.GetQrCode)).Methods(http.MethodGet)
+	router.Handle("/usage", s.protect(auth.RoleViewer, s.delivery.GetUsage)).Methods(http.MethodGet)
+}
+
+// initWebhookRoutes registers the webhook endpoints, which only exist in the
+// versioned API.
+func (s *Server) initWebhookRoutes(router *mux.Router) {
+	router.Handle("/webhooks", s.protect(auth.RoleAdmin, s.delivery.ListWebhooks)).Methods(http.MethodGet)
+	router.Handle("/webhooks", s.protect(auth.RoleAdmin, s.delivery.CreateWebhook)).Methods(http.MethodPost)
+	router.Handle("/webhooks/dead-letters", s.protect(auth.RoleAdmin, s.delivery.ListDeadLetters)).Methods(http.MethodGet)
+	router.Handle("/webhooks/dead-letters/{id}/retry", s.protect(auth.RoleAdmin, s.delivery.RetryDeadLetter)).
+		Methods(http.MethodPost)
+	router.Handle("/webhooks/{id}", s.protect(auth.RoleAdmin, s.delivery.DeleteWebhook)).Methods(http.MethodDelete)
+}
+
+// initAuditRoutes registers the audit log endpoints, which only exist in the
+// versioned API.
+func (s *Server) initAuditRoutes(router *mux.Router) {
+	router.Handle("/audit", s.protect(auth.RoleAdmin, s.delivery.ListAudit)).Methods(http.MethodGet)
+	router.Handle("/audit/verify", s.protect(auth.RoleAdmin, s.delivery.VerifyAudit)).Methods(http.MethodGet)
+}
+
+// initWorkspaceRoutes registers the endpoints managing workspaces with their
+// members and keys, which only exist in the versioned API.
+func (s *Server) initWorkspaceRoutes(router *mux.Router) {
+	router.Handle("/workspaces", s.protect(auth.RoleViewer, s.delivery.ListWorkspaces)).Methods(http.MethodGet)
+	router.Handle("/workspaces", s.protect(auth.RoleAdmin, s.delivery.CreateWorkspace)).Methods(http.MethodPost)
+	router.Handle("/workspaces/{slug}", s.protect(auth.RoleViewer, s.delivery.GetWorkspace)).Methods(http.MethodGet)
+	router.Handle("/workspaces/{slug}/members", s.protect(auth.RoleAdmin, s.delivery.ListMembers)).Methods(http.MethodGet)
+	router.Handle("/workspaces/{slug}/members", s.protect(auth.RoleAdmin, s.delivery.AddMember)).Methods(http.MethodPost)
+	router.Handle("/workspaces/{slug}/members/{name}", s.protect(auth.RoleAdmin, s.delivery.UpdateMemberRole)).
+		Methods(http.MethodPut)
+	router.Handle("/workspaces/{slug}/members/{name}", s.protect(auth.RoleAdmin, s.delivery.RemoveMember)).
+		Methods(http.MethodDelete)
+	router.Handle("/workspaces/{slug}/keys", s.protect(auth.RoleAdmin, s.delivery.ListWorkspaceKeys)).
+		Methods(http.MethodGet)
+	router.Handle("/workspaces/{slug}/keys", s.protect(auth.RoleAdmin, s.delivery.CreateWorkspaceKey)).
+		Methods(http.MethodPost)
+	router.Handle("/workspaces/{slug}/keys/{id}", s.protect(auth.RoleAdmin, s.delivery.DeleteWorkspaceKey)).
+		Methods(http.MethodDelete)
+}
+
+// deprecated marks the responses of the legacy routes with the Deprecation
+// (RFC 9745) and Sunset (RFC 8594) headers, pointing to the documentation of
+// the versioned API.
+func deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(legacyDeprecation.Unix(), 10))
+		w.Header().Set("Sunset", legacySunset.Format(http.TimeFormat))
+		w.Header().Set("Link", `</api/docs>; rel="deprecation"; type="text/html"`)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// maxRequestIDLength bounds the request ids taken over from clients.
+const maxRequestIDLength = 128
+
+// validRequestID reports whether a request id sent by a client can be echoed
+// back and logged as is.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// requestID sets the X-Request-ID header of the response to the id sent by
+// the client, or to a new random one, before the handlers run.
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(utils.RequestIDHeader)
+		if !validRequestID(id) {
+			id = rand.Text()
+		}
+		w.Header().Set(utils.RequestIDHeader, id)
+		next.ServeHTTP(w, r)
+	})
 }
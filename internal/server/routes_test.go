@@ -0,0 +1,297 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/delivery"
+	"github.com/AlexNov03/UrlShortener/internal/delivery/mocks"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// keyStore is an auth.KeyStore of principals by key digest.
+type keyStore map[string]*auth.Principal
+
+func (ks keyStore) LookupApiKey(ctx context.Context, digest string) (*auth.Principal, error) {
+	return ks[digest], nil
+}
+
+func TestRoutes(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+
+	cfg := &bootstrap.Config{}
+	cfg.Auth.ApiKeys = []bootstrap.ApiKey{
+		{Name: "ops", Key: "secret-ops"},
+		{Name: "dashboard", Key: "secret-viewer", Owner: "team", Role: auth.RoleViewer},
+	}
+	keys := keyStore{auth.Digest("secret-editor"): {KeyName: "team/ci", Owner: "team", Member: "ci",
+		Role: auth.RoleEditor}}
+
+	s := NewServer(cfg, delivery.NewUrlDelivery(mockedUc, utils.NewValidator()), nil, keys)
+	s.Init()
+
+	tests := []struct {
+		Name               string
+		Method             string
+		Path               string
+		Body               string
+		ApiKey             string
+		Setup              func()
+		ExpectedStatus     int
+		ExpectedDeprecated bool
+	}{
+		{
+			Name:   "shorten",
+			Method: http.MethodPost,
+			Path:   "/api/v1/links",
+			Body:   `{"original_url":"https://example.com"}`,
+			ApiKey: "secret-editor",
+			Setup: func() {
+				mockedUc.EXPECT().ShortenUrl(gomock.Any(), &models.OrigUrlData{OriginalUrl: "https://example.com"}).Return(
+					&models.ShortUrlData{ShortUrl: "http://localhost:8080/Abc_def_qA"}, nil)
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:   "legacy shorten",
+			Method: http.MethodPost,
+			Path:   "/shorten",
+			Body:   `{"original_url":"https://example.com"}`,
+			ApiKey: "secret-ops",
+			Setup: func() {
+				mockedUc.EXPECT().ShortenUrl(gomock.Any(), &models.OrigUrlData{OriginalUrl: "https://example.com"}).Return(
+					&models.ShortUrlData{ShortUrl: "http://localhost:8080/Abc_def_qA"}, nil)
+			},
+			ExpectedStatus:     http.StatusOK,
+			ExpectedDeprecated: true,
+		},
+		{
+			Name:   "get link",
+			Method: http.MethodGet,
+			Path:   "/api/v1/links/Abc_def_qA",
+			ApiKey: "secret-viewer",
+			Setup: func() {
+				mockedUc.EXPECT().GetLink(gomock.Any(), "Abc_def_qA").Return(&models.LinkData{}, nil)
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:   "legacy get link",
+			Method: http.MethodGet,
+			Path:   "/api/links/Abc_def_qA",
+			ApiKey: "secret-viewer",
+			Setup: func() {
+				mockedUc.EXPECT().GetLink(gomock.Any(), "Abc_def_qA").Return(&models.LinkData{}, nil)
+			},
+			ExpectedStatus:     http.StatusOK,
+			ExpectedDeprecated: true,
+		},
+		{
+			Name:           "shorten requires api key",
+			Method:         http.MethodPost,
+			Path:           "/api/v1/links",
+			Body:           `{"original_url":"https://example.com"}`,
+			Setup:          func() {},
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "shorten requires editor role",
+			Method:         http.MethodPost,
+			Path:           "/api/v1/links",
+			Body:           `{"original_url":"https://example.com"}`,
+			ApiKey:         "secret-viewer",
+			Setup:          func() {},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "list links requires api key",
+			Method:         http.MethodGet,
+			Path:           "/api/v1/links",
+			Setup:          func() {},
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "delete requires api key",
+			Method:         http.MethodDelete,
+			Path:           "/api/v1/links/Abc_def_qA",
+			Setup:          func() {},
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "update rules requires editor role",
+			Method:         http.MethodPut,
+			Path:           "/api/v1/links/Abc_def_qA/rules",
+			Body:           `{"rules":[]}`,
+			ApiKey:         "secret-viewer",
+			Setup:          func() {},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:   "stats",
+			Method: http.MethodGet,
+			Path:   "/api/v1/links/Abc_def_qA/stats",
+			ApiKey: "secret-editor",
+			Setup: func() {
+				mockedUc.EXPECT().GetStats(gomock.Any(), "Abc_def_qA").Return(&models.LinkStats{}, nil)
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "webhooks require admin role",
+			Method:         http.MethodGet,
+			Path:           "/api/v1/webhooks",
+			ApiKey:         "secret-editor",
+			Setup:          func() {},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "audit requires admin role",
+			Method:         http.MethodGet,
+			Path:           "/api/v1/audit",
+			ApiKey:         "secret-viewer",
+			Setup:          func() {},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "audit verification requires admin role",
+			Method:         http.MethodGet,
+			Path:           "/api/v1/audit/verify",
+			ApiKey:         "secret-viewer",
+			Setup:          func() {},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:   "audit",
+			Method: http.MethodGet,
+			Path:   "/api/v1/audit",
+			ApiKey: "secret-ops",
+			Setup: func() {
+				mockedUc.EXPECT().ListAudit(gomock.Any(), gomock.Any()).Return(&models.AuditList{}, nil)
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "members require admin role",
+			Method:         http.MethodGet,
+			Path:           "/api/v1/workspaces/team/members",
+			ApiKey:         "secret-editor",
+			Setup:          func() {},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:   "members",
+			Method: http.MethodGet,
+			Path:   "/api/v1/workspaces/team/members",
+			ApiKey: "secret-ops",
+			Setup: func() {
+				mockedUc.EXPECT().ListMembers(gomock.Any(), "team").Return(&models.MemberList{}, nil)
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:   "workspaces",
+			Method: http.MethodGet,
+			Path:   "/api/v1/workspaces",
+			ApiKey: "secret-viewer",
+			Setup: func() {
+				mockedUc.EXPECT().ListWorkspaces(gomock.Any()).Return(&models.WorkspaceList{}, nil)
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:   "resolve",
+			Method: http.MethodGet,
+			Path:   "/Abc_def_qA",
+			Setup: func() {
+				mockedUc.EXPECT().GetOriginalUrl(gomock.Any(), "Abc_def_qA", gomock.Any()).Return(
+					&models.Redirect{Url: "https://example.com"}, nil)
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.Setup()
+
+			r := httptest.NewRequest(tt.Method, tt.Path, strings.NewReader(tt.Body))
+			if tt.ApiKey != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.ApiKey)
+			}
+			w := httptest.NewRecorder()
+
+			s.server.Handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			if tt.ExpectedDeprecated {
+				assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+				assert.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+			} else {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+				assert.Empty(t, w.Header().Get("Sunset"))
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockedUc := mocks.NewMockUrlUsecase(ctrl)
+	mockedUc.EXPECT().GetLink(gomock.Any(), "Abc_def_qA").Return(nil,
+		utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")).Times(3)
+
+	cfg := &bootstrap.Config{}
+	cfg.Auth.ApiKeys = []bootstrap.ApiKey{{Name: "ops", Key: "secret-ops"}}
+
+	s := NewServer(cfg, delivery.NewUrlDelivery(mockedUc, utils.NewValidator()), nil, nil)
+	s.Init()
+
+	tests := []struct {
+		Name       string
+		RequestID  string
+		ExpectEcho bool
+	}{
+		{Name: "client id", RequestID: "trace-1.a_b", ExpectEcho: true},
+		{Name: "missing id", RequestID: ""},
+		{Name: "invalid id", RequestID: "bad id\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/links/Abc_def_qA", nil)
+			r.Header.Set("Authorization", "Bearer secret-ops")
+			if tt.RequestID != "" {
+				r.Header.Set(utils.RequestIDHeader, tt.RequestID)
+			}
+			w := httptest.NewRecorder()
+
+			s.server.Handler.ServeHTTP(w, r)
+
+			id := w.Header().Get(utils.RequestIDHeader)
+			if tt.ExpectEcho {
+				assert.Equal(t, tt.RequestID, id)
+			} else {
+				assert.True(t, validRequestID(id))
+				assert.NotEqual(t, tt.RequestID, id)
+			}
+			assert.Contains(t, w.Body.String(), `"request_id":"`+id+`"`)
+		})
+	}
+}
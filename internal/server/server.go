@@ -1,24 +1,46 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
 
+	"github.com/AlexNov03/UrlShortener/internal/auth"
 	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
 	"github.com/AlexNov03/UrlShortener/internal/delivery"
+	"github.com/AlexNov03/UrlShortener/internal/idempotency"
+	"github.com/gorilla/mux"
 )
 
 type Server struct {
-	server   *http.Server
-	cfg      *bootstrap.Config
-	handler  http.Handler
-	delivery *delivery.UrlDelivery
+	server      *http.Server
+	cfg         *bootstrap.Config
+	handler     http.Handler
+	router      *mux.Router
+	delivery    *delivery.UrlDelivery
+	auth        *auth.Authenticator
+	idempotency *idempotency.Middleware
 }
 
-func NewServer(cfg *bootstrap.Config, delivery *delivery.UrlDelivery) *Server {
-	return &Server{cfg: cfg, delivery: delivery}
+// NewServer creates the HTTP server of the API. Shorten requests with an
+// Idempotency-Key header are deduplicated with store, if not nil. Requests are
+// authenticated with the configured API keys and the keys of workspaces in
+// keys, if not nil.
+func NewServer(cfg *bootstrap.Config, delivery *delivery.UrlDelivery, store idempotency.Store,
+	keys auth.KeyStore) *Server {
+	res := &Server{cfg: cfg, delivery: delivery, auth: auth.NewAuthenticator(cfg.Auth.ApiKeys, keys)}
+	if store != nil {
+		res.idempotency = idempotency.NewMiddleware(store, cfg.Idempotency.TTL)
+	}
+	return res
+}
+
+// Idempotency returns the idempotency middleware of the shorten requests, or
+// nil.
+func (s *Server) Idempotency() *idempotency.Middleware {
+	return s.idempotency
 }
 
 func (s *Server) Init() {
@@ -32,7 +54,7 @@ func (s *Server) Init() {
 
 func (s *Server) Run() error {
 	log.Printf("starting server, listening on addr %s", s.server.Addr)
-	if err := s.server.ListenAndServe(); err != nil {
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("error while starting server: %v ", err)
 	}
 	return nil
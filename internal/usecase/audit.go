@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+const defaultAuditLimit = 100
+
+// auditVerifyBatchSize is the number of entries read at once when verifying
+// the audit log.
+const auditVerifyBatchSize = 1000
+
+// rawValue marshals a value recorded in the audit log, nil being absent.
+func rawValue(value any) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	res, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return res
+}
+
+// record appends an entry of action on target to the audit log, with the
+// source of the request in ctx. The change is made by then, so failing to
+// record it is only logged.
+func (uc *UrlUsecase) record(ctx context.Context, action, target string, before, after any) {
+	source := audit.SourceFromContext(ctx)
+	entry := &models.AuditEntry{Time: uc.now(), Actor: source.Actor, SourceIP: source.IP, Action: action,
+		Target: target, Before: rawValue(before), After: rawValue(after)}
+
+	if err := uc.Repo.AppendAuditEntry(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("error while recording %s of %s in the audit log: %v", action, target, err)
+	}
+}
+
+// errAuditForbidden rejects the keys of a workspace, as the audit log
+// records the changes of every workspace.
+func errAuditForbidden() error {
+	return utils.NewInternalError(http.StatusForbidden, "the audit log needs an unrestricted API key")
+}
+
+// ListAudit returns a page of the audit entries matching filter, newest
+// first unless another order is requested. The page ends with a cursor for
+// the next one while more entries match. Only unrestricted keys may read the
+// audit log.
+func (uc *UrlUsecase) ListAudit(ctx context.Context, filter *models.AuditFilter) (*models.AuditList, error) {
+
+	if boundWorkspace(ctx) != "" {
+		return nil, errAuditForbidden()
+	}
+
+	query := *filter
+	query.Actor = strings.TrimSpace(query.Actor)
+	query.Action = strings.TrimSpace(query.Action)
+	query.Target = strings.TrimSpace(query.Target)
+	if query.Limit == 0 {
+		query.Limit = defaultAuditLimit
+	}
+
+	// one entry more than requested tells whether there is a next page
+	limit := query.Limit
+	query.Limit++
+
+	entries, err := uc.Repo.ListAuditEntries(ctx, &query)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.AuditList{}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		res.NextCursor = entries[limit-1].ID
+	}
+
+	res.Entries = make([]models.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		res.Entries = append(res.Entries, *entry)
+	}
+	return res, nil
+}
+
+// VerifyAudit checks the hash chain of the whole audit log, oldest entry
+// first, stopping at the first entry that does not match it. Only
+// unrestricted keys may verify the audit log.
+func (uc *UrlUsecase) VerifyAudit(ctx context.Context) (*models.AuditVerification, error) {
+
+	if boundWorkspace(ctx) != "" {
+		return nil, errAuditForbidden()
+	}
+
+	res := &models.AuditVerification{Valid: true}
+	filter := &models.AuditFilter{Order: "asc", Limit: auditVerifyBatchSize}
+	var prevHash string
+
+	for {
+		entries, err := uc.Repo.ListAuditEntries(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if broken := audit.Verify(entries, prevHash); broken >= 0 {
+			res.Valid, res.BrokenAt = false, entries[broken].ID
+			res.Checked += broken
+			return res, nil
+		}
+		res.Checked += len(entries)
+
+		if len(entries) < filter.Limit {
+			return res, nil
+		}
+		last := entries[len(entries)-1]
+		filter.Cursor, prevHash = last.ID, last.Hash
+	}
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/repository/local"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ignoreAudit lets the tests not about the audit log record any entries.
+func ignoreAudit(mockRepo *mocks.MockUrlRepository) {
+	mockRepo.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).AnyTimes()
+}
+
+func TestRecordUpdate(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	ctx := audit.WithSource(context.Background(), &audit.Source{Actor: audit.Anonymous, IP: "203.0.113.7"})
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{KeyName: "ci"})
+
+	mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{ShortUrl: "Abc_def_gs",
+		LinkMetadata: models.LinkMetadata{Title: "Old"}}, nil)
+	mockRepo.EXPECT().UpdateMetadata(ctx, "Abc_def_gs", &models.LinkMetadata{Title: "New"}).Return(nil)
+	mockRepo.EXPECT().AppendAuditEntry(gomock.Any(), &models.AuditEntry{Time: now, Actor: "ci", SourceIP: "203.0.113.7",
+		Action: audit.LinkUpdateMetadata, Target: "Abc_def_gs", Before: []byte(`{"title":"Old"}`),
+		After: []byte(`{"title":"New"}`)}).Return(nil)
+
+	assert.NoError(t, uc.UpdateMetadata(ctx, "Abc_def_gs", &models.LinkMetadata{Title: "New"}))
+}
+
+func TestListAudit(t *testing.T) {
+
+	repo := local.NewUrlRepository()
+	uc := NewUrlUsecase(repo, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+	ctx := context.Background()
+
+	for _, target := range []string{"a", "b", "c"} {
+		uc.record(ctx, audit.LinkDelete, target, nil, nil)
+	}
+
+	res, err := uc.ListAudit(ctx, &models.AuditFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 2)
+	assert.Equal(t, "c", res.Entries[0].Target)
+	assert.Equal(t, audit.Anonymous, res.Entries[0].Actor)
+	assert.Equal(t, int64(2), res.NextCursor)
+
+	res, err = uc.ListAudit(ctx, &models.AuditFilter{Limit: 2, Cursor: res.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 1)
+	assert.Equal(t, "a", res.Entries[0].Target)
+	assert.Zero(t, res.NextCursor)
+
+	res, err = uc.ListAudit(ctx, &models.AuditFilter{Target: " b "})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 1)
+	assert.Equal(t, int64(2), res.Entries[0].ID)
+}
+
+func TestAuditInWorkspace(t *testing.T) {
+
+	uc := NewUrlUsecase(local.NewUrlRepository(), rand.New(rand.NewSource(64)), &bootstrap.Config{})
+	forbidden := utils.NewInternalError(http.StatusForbidden, "the audit log needs an unrestricted API key")
+
+	// the log records the changes of every workspace, whatever the role of
+	// the key of one
+	for _, role := range []string{auth.RoleViewer, auth.RoleAdmin} {
+		ctx := auth.WithPrincipal(context.Background(), &auth.Principal{KeyName: "team/ci", Owner: "team",
+			Member: "ci", Role: role})
+
+		_, err := uc.ListAudit(ctx, &models.AuditFilter{})
+		assert.Equal(t, forbidden, err)
+		_, err = uc.VerifyAudit(ctx)
+		assert.Equal(t, forbidden, err)
+	}
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{KeyName: "ops"})
+	_, err := uc.ListAudit(ctx, &models.AuditFilter{})
+	assert.NoError(t, err)
+	_, err = uc.VerifyAudit(ctx)
+	assert.NoError(t, err)
+}
+
+func TestVerifyAudit(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+	ctx := context.Background()
+
+	first := &models.AuditEntry{ID: 1, Actor: "ci", Action: audit.LinkDelete, Target: "a"}
+	first.Hash = audit.Hash(first, "")
+	second := &models.AuditEntry{ID: 2, Actor: "ci", Action: audit.LinkDelete, Target: "b", PrevHash: first.Hash}
+	second.Hash = audit.Hash(second, first.Hash)
+
+	mockRepo.EXPECT().ListAuditEntries(ctx, &models.AuditFilter{Order: "asc", Limit: auditVerifyBatchSize}).Return(
+		[]*models.AuditEntry{first, second}, nil)
+	res, err := uc.VerifyAudit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &models.AuditVerification{Valid: true, Checked: 2}, res)
+
+	// an entry edited after it was appended breaks the chain
+	tampered := *second
+	tampered.Target = "c"
+	mockRepo.EXPECT().ListAuditEntries(ctx, &models.AuditFilter{Order: "asc", Limit: auditVerifyBatchSize}).Return(
+		[]*models.AuditEntry{first, &tampered}, nil)
+	res, err = uc.VerifyAudit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &models.AuditVerification{Valid: false, Checked: 1, BrokenAt: 2}, res)
+}
@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"golang.org/x/net/idna"
+)
+
+var defaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid", "yclid", "mc_cid", "mc_eid", "_openstat",
+}
+
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// idnaProfile converts hosts to punycode as browsers do, without rejecting
+// underscores and other characters allowed in real world host names.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+
+// canonicalizeUrl returns the normal form of rawUrl: lowercased scheme and
+// host, punycode host, no default port, resolved dot segments and minimal
+// uppercase percent-encoding. Depending on cfg the query parameters are also
+// sorted and stripped of tracking parameters.
+func canonicalizeUrl(rawUrl string, cfg *bootstrap.Canonicalization) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", utils.NewInternalError(http.StatusBadRequest, "destination url does not fits the url format")
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+
+	// only hierarchical urls with an authority have a host and a path to normalize
+	if u.Opaque != "" || u.Host == "" {
+		u.Scheme = scheme
+		return u.String(), nil
+	}
+
+	host, err := canonicalHost(u.Hostname())
+	if err != nil {
+		return "", utils.NewInternalError(http.StatusBadRequest, "destination url has incorrect host")
+	}
+	if port := u.Port(); port != "" && port != defaultPorts[scheme] {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+	}
+
+	path := removeDotSegments(normalizeEscapes(u.EscapedPath()))
+	if path == "" {
+		path = "/"
+	}
+
+	res := strings.Builder{}
+	res.WriteString(scheme)
+	res.WriteString("://")
+	if u.User != nil {
+		res.WriteString(u.User.String())
+		res.WriteByte('@')
+	}
+	res.WriteString(host)
+	res.WriteString(path)
+	if query := canonicalQuery(u.RawQuery, cfg); query != "" {
+		res.WriteByte('?')
+		res.WriteString(query)
+	}
+	if fragment := normalizeEscapes(u.EscapedFragment()); fragment != "" {
+		res.WriteByte('#')
+		res.WriteString(fragment)
+	}
+	return res.String(), nil
+}
+
+func canonicalHost(host string) (string, error) {
+	if strings.Contains(host, ":") {
+		return "[" + strings.ToLower(host) + "]", nil
+	}
+
+	host = strings.TrimSuffix(host, ".")
+	ascii, err := idnaProfile.ToASCII(host)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(ascii), nil
+}
+
+func canonicalQuery(rawQuery string, cfg *bootstrap.Canonicalization) string {
+	tracking := cfg.TrackingParams
+	if tracking == nil {
+		tracking = defaultTrackingParams
+	}
+
+	type param struct {
+		key  string
+		pair string
+	}
+
+	var params []param
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		pair = normalizeEscapes(pair)
+
+		key, _, _ := strings.Cut(pair, "=")
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			key = decoded
+		}
+		if cfg.StripTracking && isTrackingParam(key, tracking) {
+			continue
+		}
+		params = append(params, param{key: key, pair: pair})
+	}
+
+	if cfg.SortQuery {
+		sort.SliceStable(params, func(i, j int) bool { return params[i].key < params[j].key })
+	}
+
+	pairs := make([]string, 0, len(params))
+	for _, p := range params {
+		pairs = append(pairs, p.pair)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// isTrackingParam matches key against names, where a trailing * matches any
+// suffix.
+func isTrackingParam(key string, names []string) bool {
+	key = strings.ToLower(key)
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if prefix, ok := strings.CutSuffix(name, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// normalizeEscapes decodes percent-encoded unreserved characters and
+// uppercases the hex digits of the remaining escapes (RFC 3986, 6.2.2).
+// Malformed escapes are kept as is.
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	res := strings.Builder{}
+	res.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) {
+			res.WriteByte(s[i])
+			continue
+		}
+		hi, ok1 := unhex(s[i+1])
+		lo, ok2 := unhex(s[i+2])
+		if !ok1 || !ok2 {
+			res.WriteByte(s[i])
+			continue
+		}
+		if c := hi<<4 | lo; isUnreserved(c) {
+			res.WriteByte(c)
+		} else {
+			res.WriteByte('%')
+			res.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		}
+		i += 2
+	}
+	return res.String()
+}
+
+// removeDotSegments resolves . and .. segments of an absolute path as
+// described in RFC 3986, 5.2.4. Unlike path.Clean it keeps empty segments and
+// trailing slashes, which are significant to servers.
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	res := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch segment {
+		case ".":
+			if last {
+				res = append(res, "")
+			}
+		case "..":
+			// the first element is the empty root segment, it's never removed
+			if len(res) > 1 {
+				res = res[:len(res)-1]
+			}
+			if last {
+				res = append(res, "")
+			}
+		default:
+			res = append(res, segment)
+		}
+	}
+	return strings.Join(res, "/")
+}
+
+func (uc *UrlUsecase) canonicalUrl(rawUrl string) (string, error) {
+	if rawUrl == "" {
+		return "", nil
+	}
+	return canonicalizeUrl(rawUrl, &uc.cfg.Canonicalization)
+}
+
+// canonicalRules returns a copy of rules with canonical target urls.
+func (uc *UrlUsecase) canonicalRules(rules []models.TargetingRule) ([]models.TargetingRule, error) {
+	if rules == nil {
+		return nil, nil
+	}
+	res := make([]models.TargetingRule, len(rules))
+	for i, rule := range rules {
+		target, err := uc.canonicalUrl(rule.TargetUrl)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = rule
+		res[i].TargetUrl = target
+	}
+	return res, nil
+}
+
+// canonicalDestinations returns a copy of destinations with canonical urls.
+func (uc *UrlUsecase) canonicalDestinations(destinations []models.Destination) ([]models.Destination, error) {
+	if destinations == nil {
+		return nil, nil
+	}
+	res := make([]models.Destination, len(destinations))
+	for i, destination := range destinations {
+		target, err := uc.canonicalUrl(destination.Url)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = models.Destination{Url: target, Weight: destination.Weight}
+	}
+	return res, nil
+}
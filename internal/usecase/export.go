@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+// Export streams every link matching opts.Filter to w, oldest first, and
+// returns the number of links written. Filter errors are returned before
+// anything is written; a failure afterwards leaves w with a truncated export.
+func (uc *UrlUsecase) Export(ctx context.Context, w io.Writer, opts *models.ExportOptions) (int, error) {
+
+	filter, err := normalizeFilter(&opts.Filter)
+	if err != nil {
+		return 0, err
+	}
+	// keys of a workspace only export its links
+	if workspace := boundWorkspace(ctx); workspace != "" {
+		filter.Owner = workspace
+	}
+	filter.Sort = sortCreatedAt
+	filter.Order = "asc"
+	filter.Cursor = ""
+	filter.After = nil
+	filter.Limit = 0
+
+	var compressor *gzip.Writer
+	if opts.Gzip {
+		compressor = gzip.NewWriter(w)
+		w = compressor
+	}
+
+	writer, err := newExportWriter(w, opts.Format)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = uc.Repo.ExportLinks(ctx, filter, func(link *models.UrlData) error {
+		if err := writer.write(exportRecord(link)); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if err := writer.flush(); err != nil {
+		return count, err
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	uc := NewUrlUsecase(mockRepo, rnd, &bootstrap.Config{})
+	ctx := context.Background()
+
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clicksLeft := 3
+
+	links := []*models.UrlData{
+		{ShortUrl: "abc", OriginalUrl: "https://example.com/a?x=1&y=2", Owner: "team", CreatedAt: createdAt, Clicks: 7,
+			LinkMetadata: models.LinkMetadata{Title: "Sale, spring", Tags: []string{"promo", "spring"}}},
+		{ShortUrl: "def", OriginalUrl: "https://example.com/b", CreatedAt: createdAt.Add(time.Hour), ClicksLeft: &clicksLeft,
+			ActivationWindow: models.ActivationWindow{NotAfter: &notAfter},
+			QueryOptions:     models.QueryOptions{UtmSource: "mail", PassQuery: true},
+			Rules:            []models.TargetingRule{{OS: "ios", TargetUrl: "https://apps.apple.com"}}},
+	}
+
+	expectedFilter := &models.LinkFilter{Tag: "promo", Host: "example.com", Sort: "created_at", Order: "asc"}
+
+	exportLinks := func(ctx context.Context, filter *models.LinkFilter, fn func(*models.UrlData) error) error {
+		for _, link := range links {
+			if err := fn(link); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		Name           string
+		Opts           models.ExportOptions
+		SetUp          func()
+		ExpectedOutput string
+		ExpectedCount  int
+		ExpectedErr    error
+	}{
+		{
+			Name: "Test for csv export",
+			Opts: models.ExportOptions{Format: "csv",
+				Filter: models.LinkFilter{Tag: " promo ", Host: "Example.COM", Sort: "clicks", Limit: 5}},
+			SetUp: func() {
+				mockRepo.EXPECT().ExportLinks(ctx, expectedFilter, gomock.Any()).DoAndReturn(exportLinks)
+			},
+			ExpectedOutput: "code,url,owner,created_at,clicks,clicks_left,not_before,not_after,fallback_url,utm_source," +
+				"utm_medium,utm_campaign,pass_query,interstitial,rules,destinations,title,description,tags,notes\n" +
+				"abc,https://example.com/a?x=1&y=2,team,2024-05-01T10:00:00Z,7,,,,,,,,false,false,,,\"Sale, spring\",," +
+				"promo;spring,\n" +
+				"def,https://example.com/b,,2024-05-01T11:00:00Z,0,3,,2025-01-01T00:00:00Z,,mail,,,true,false," +
+				"\"[{\"\"os\"\":\"\"ios\"\",\"\"target_url\"\":\"\"https://apps.apple.com\"\"}]\",,,,,\n",
+			ExpectedCount: 2,
+			ExpectedErr:   nil,
+		},
+		{
+			Name: "Test for jsonl export",
+			Opts: models.ExportOptions{Format: "jsonl", Filter: models.LinkFilter{Tag: "promo", Host: "example.com"}},
+			SetUp: func() {
+				mockRepo.EXPECT().ExportLinks(ctx, expectedFilter, gomock.Any()).DoAndReturn(exportLinks)
+			},
+			ExpectedOutput: `{"code":"abc","url":"https://example.com/a?x=1&y=2","owner":"team",` +
+				`"created_at":"2024-05-01T10:00:00Z","clicks":7,"title":"Sale, spring","tags":["promo","spring"]}` + "\n" +
+				`{"code":"def","url":"https://example.com/b","created_at":"2024-05-01T11:00:00Z","clicks":0,` +
+				`"clicks_left":3,"not_after":"2025-01-01T00:00:00Z","utm_source":"mail","pass_query":true,` +
+				`"rules":[{"os":"ios","target_url":"https://apps.apple.com"}]}` + "\n",
+			ExpectedCount: 2,
+			ExpectedErr:   nil,
+		},
+		{
+			Name:           "Test for empty range",
+			Opts:           models.ExportOptions{Format: "csv", Filter: models.LinkFilter{CreatedFrom: &createdAt, CreatedTo: &createdAt}},
+			SetUp:          func() {},
+			ExpectedOutput: "",
+			ExpectedCount:  0,
+			ExpectedErr:    &utils.InternalError{Code: http.StatusBadRequest, Message: "created_to must be later than created_from"},
+		},
+		{
+			Name:           "Test for unknown format",
+			Opts:           models.ExportOptions{Format: "xml"},
+			SetUp:          func() {},
+			ExpectedOutput: "",
+			ExpectedCount:  0,
+			ExpectedErr:    &utils.InternalError{Code: http.StatusBadRequest, Message: "export format must be csv or jsonl"},
+		},
+		{
+			Name: "Test for failed export",
+			Opts: models.ExportOptions{Format: "jsonl"},
+			SetUp: func() {
+				mockRepo.EXPECT().ExportLinks(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, filter *models.LinkFilter, fn func(*models.UrlData) error) error {
+						assert.NoError(t, fn(links[0]))
+						return errors.New("connection reset")
+					})
+			},
+			ExpectedOutput: `{"code":"abc","url":"https://example.com/a?x=1&y=2","owner":"team",` +
+				`"created_at":"2024-05-01T10:00:00Z","clicks":7,"title":"Sale, spring","tags":["promo","spring"]}` + "\n",
+			ExpectedCount: 1,
+			ExpectedErr:   errors.New("connection reset"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp()
+
+			output := &bytes.Buffer{}
+			count, err := uc.Export(ctx, output, &tt.Opts)
+
+			assert.Equal(t, tt.ExpectedErr, err)
+			assert.Equal(t, tt.ExpectedCount, count)
+			assert.Equal(t, tt.ExpectedOutput, output.String())
+		})
+	}
+}
+
+func TestExportGzip(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+	ctx := context.Background()
+
+	mockRepo.EXPECT().ExportLinks(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, filter *models.LinkFilter, fn func(*models.UrlData) error) error {
+			return fn(&models.UrlData{ShortUrl: "abc", OriginalUrl: "https://example.com/",
+				CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)})
+		})
+
+	output := &bytes.Buffer{}
+	count, err := uc.Export(ctx, output, &models.ExportOptions{Format: "jsonl", Gzip: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	reader, err := gzip.NewReader(output)
+	assert.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"code":"abc","url":"https://example.com/","created_at":"2024-05-01T10:00:00Z","clicks":0}`+"\n",
+		string(data))
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+// exportColumns are the columns of a CSV export. Tags are separated by
+// semicolons as in imports, rules and destinations are JSON arrays.
+var exportColumns = []string{
+	"code", "url", "owner", "created_at", "clicks", "clicks_left", "not_before", "not_after", "fallback_url",
+	"utm_source", "utm_medium", "utm_campaign", "pass_query", "interstitial", "rules", "destinations",
+	"title", "description", "tags", "notes",
+}
+
+// exportWriter writes the records of an export one by one. flush must be
+// called once every record has been written.
+type exportWriter interface {
+	write(record *models.ExportRecord) error
+	flush() error
+}
+
+func newExportWriter(w io.Writer, format string) (exportWriter, error) {
+	switch format {
+	case importFormatCsv:
+		return newCsvExportWriter(w)
+	case importFormatJsonl:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		return &jsonlExportWriter{encoder: encoder}, nil
+	}
+	return nil, utils.NewInternalError(http.StatusBadRequest, "export format must be csv or jsonl")
+}
+
+func exportRecord(data *models.UrlData) *models.ExportRecord {
+	return &models.ExportRecord{
+		Code:             data.ShortUrl,
+		Url:              data.OriginalUrl,
+		Owner:            data.Owner,
+		CreatedAt:        data.CreatedAt.UTC(),
+		Clicks:           data.Clicks,
+		ClicksLeft:       data.ClicksLeft,
+		ActivationWindow: data.ActivationWindow,
+		QueryOptions:     data.QueryOptions,
+		Rules:            data.Rules,
+		Destinations:     data.Destinations,
+		Interstitial:     data.Interstitial,
+		LinkMetadata:     data.LinkMetadata,
+	}
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func newCsvExportWriter(w io.Writer) (*csvExportWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	return &csvExportWriter{writer: writer}, nil
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// formatExportList encodes rules or destinations as a JSON array, leaving the
+// column empty when there are none.
+func formatExportList[T any](list []T) (string, error) {
+	if len(list) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (cw *csvExportWriter) write(record *models.ExportRecord) error {
+	clicksLeft := ""
+	if record.ClicksLeft != nil {
+		clicksLeft = strconv.Itoa(*record.ClicksLeft)
+	}
+	rules, err := formatExportList(record.Rules)
+	if err != nil {
+		return err
+	}
+	destinations, err := formatExportList(record.Destinations)
+	if err != nil {
+		return err
+	}
+
+	return cw.writer.Write([]string{
+		record.Code,
+		record.Url,
+		record.Owner,
+		formatExportTime(&record.CreatedAt),
+		strconv.FormatInt(record.Clicks, 10),
+		clicksLeft,
+		formatExportTime(record.NotBefore),
+		formatExportTime(record.NotAfter),
+		record.FallbackUrl,
+		record.UtmSource,
+		record.UtmMedium,
+		record.UtmCampaign,
+		strconv.FormatBool(record.PassQuery),
+		strconv.FormatBool(record.Interstitial),
+		rules,
+		destinations,
+		record.Title,
+		record.Description,
+		strings.Join(record.Tags, ";"),
+		record.Notes,
+	})
+}
+
+func (cw *csvExportWriter) flush() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+type jsonlExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (jw *jsonlExportWriter) write(record *models.ExportRecord) error {
+	return jw.encoder.Encode(record)
+}
+
+func (jw *jsonlExportWriter) flush() error {
+	return nil
+}
@@ -0,0 +1,291 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/go-playground/validator/v10"
+)
+
+const defaultImportBatchSize = 1000
+
+// Reasons of import errors, besides the reasons of rejected destinations.
+const (
+	importReasonInvalid   = "invalid"
+	importReasonConflict  = "conflict"
+	importReasonDuplicate = "duplicate"
+)
+
+var importValidator = validator.New(validator.WithRequiredStructEnabled())
+
+// validImportCode reports whether code can be served as a short link: it
+// must not contain characters with a meaning in urls or routes, like / and the
+// + of preview pages.
+func validImportCode(code string) bool {
+	for _, c := range code {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// importLink validates a record like ShortenUrl validates requests and
+// returns the link to store.
+func (uc *UrlUsecase) importLink(ctx context.Context, record *models.ImportRecord, opts *models.ImportOptions) (*models.UrlData, error) {
+	if err := importValidator.Struct(record); err != nil {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "incorrect fields in row")
+	}
+
+	if !validImportCode(record.Code) {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "code may only contain letters, digits, _ and -")
+	}
+	if reservedCode(record.Code) {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "code is reserved for the api")
+	}
+
+	if _, err := url.ParseRequestURI(record.Url); err != nil {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "original url does not fits the url format")
+	}
+
+	originalUrl, err := uc.canonicalUrl(record.Url)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.screenDestinations(ctx, originalUrl); err != nil {
+		return nil, err
+	}
+
+	owner := opts.Owner
+	if owner == "" {
+		owner = strings.TrimSpace(record.Owner)
+	}
+
+	res := &models.UrlData{ShortUrl: record.Code, OriginalUrl: originalUrl, Owner: owner,
+		LinkMetadata: normalizeMetadata(&record.LinkMetadata)}
+	if record.CreatedAt != nil {
+		res.CreatedAt = *record.CreatedAt
+	}
+	return res, nil
+}
+
+// importer collects valid rows into batches stored with one repository call.
+type importer struct {
+	uc      *UrlUsecase
+	opts    *models.ImportOptions
+	onError func(*models.ImportError)
+	report  *models.ImportReport
+
+	batchSize int
+	links     []*models.UrlData
+	lines     []int
+	codes     map[string]int
+	line      int
+
+	// month is the month creations are counted in, and usage the usage of
+	// the owners of the links checked on a dry run, as if they were stored
+	month time.Time
+	usage map[string]*models.Usage
+}
+
+func (imp *importer) reject(line int, code, reason, message string) {
+	switch reason {
+	case importReasonConflict, importReasonDuplicate:
+		imp.report.Conflicts++
+	case ReasonActiveLinksQuota, ReasonMonthlyCreationsQuota, ReasonMonthlyResolutionsQuota:
+		imp.report.OverQuota++
+	default:
+		imp.report.Invalid++
+	}
+	if imp.onError != nil {
+		imp.onError(&models.ImportError{Line: line, Code: code, Reason: reason, Message: message})
+	}
+}
+
+func (imp *importer) rejectInvalid(line int, code string, err *utils.InternalError) {
+	reason := err.Reason
+	if reason == "" {
+		reason = importReasonInvalid
+	}
+	imp.reject(line, code, reason, err.Message)
+}
+
+func (imp *importer) add(ctx context.Context, line int, link *models.UrlData) error {
+	if first, ok := imp.codes[link.ShortUrl]; ok {
+		imp.reject(line, link.ShortUrl, importReasonDuplicate, fmt.Sprintf("code repeats line %d", first))
+		return nil
+	}
+	imp.codes[link.ShortUrl] = line
+	imp.links = append(imp.links, link)
+	imp.lines = append(imp.lines, line)
+
+	if len(imp.links) < imp.batchSize {
+		return nil
+	}
+	return imp.flush(ctx)
+}
+
+// flush stores the batch within the quotas of the owners of its links, or
+// only checks it on a dry run.
+func (imp *importer) flush(ctx context.Context) error {
+	if len(imp.links) > 0 {
+		var skipped map[string]error
+		var err error
+		if imp.opts.DryRun {
+			skipped, err = imp.check(ctx)
+		} else {
+			skipped, err = imp.uc.Repo.ImportLinks(ctx, imp.links, imp.month, imp.uc.creationCheck)
+		}
+		if err != nil {
+			return err
+		}
+
+		for i, link := range imp.links {
+			err, ok := skipped[link.ShortUrl]
+			if !ok {
+				imp.report.Imported++
+				continue
+			}
+			var interr *utils.InternalError
+			if !errors.As(err, &interr) {
+				return err
+			}
+			if interr.Code == http.StatusConflict {
+				imp.reject(imp.lines[i], link.ShortUrl, importReasonConflict, interr.Message)
+				continue
+			}
+			imp.rejectInvalid(imp.lines[i], link.ShortUrl, interr)
+		}
+
+		imp.links, imp.lines = imp.links[:0], imp.lines[:0]
+		imp.codes = make(map[string]int, imp.batchSize)
+	}
+	imp.report.LastLine = imp.line
+	return nil
+}
+
+// check returns the links of the batch ImportLinks would skip, with the
+// reasons, without storing anything: those with taken codes and those over
+// the quotas of their owners, counting the links checked before as created.
+func (imp *importer) check(ctx context.Context) (map[string]error, error) {
+	codes := make([]string, len(imp.links))
+	for i, link := range imp.links {
+		codes[i] = link.ShortUrl
+	}
+	taken, err := imp.uc.Repo.ExistingCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := make(map[string]error, len(taken))
+	for _, code := range taken {
+		skipped[code] = utils.NewInternalError(http.StatusConflict, "this shortUrl already exists")
+	}
+
+	for _, link := range imp.links {
+		if _, ok := skipped[link.ShortUrl]; ok || link.Owner == "" {
+			continue
+		}
+		usage, ok := imp.usage[link.Owner]
+		if !ok {
+			usage, err = imp.uc.Repo.GetUsage(ctx, link.Owner, imp.month)
+			if err != nil {
+				return nil, err
+			}
+			imp.usage[link.Owner] = usage
+		}
+		if err := imp.uc.creationCheck(usage); err != nil {
+			skipped[link.ShortUrl] = err
+			continue
+		}
+		usage.ActiveLinks++
+		usage.Creations++
+	}
+	return skipped, nil
+}
+
+// Import stores the links read from r in opts.Format, keeping their codes.
+// Invalid rows, rows whose code is taken and rows over the quota of their
+// owner are passed to onError and skipped; imported links of owners count as
+// creations in their usage. Codes repeated within a batch are always
+// reported; on a dry run, codes repeated across batches are not detected, as
+// nothing is stored. An error is returned when reading or storing fails; the
+// report then tells up to which line the import got.
+func (uc *UrlUsecase) Import(ctx context.Context, r io.Reader, opts *models.ImportOptions,
+	onError func(*models.ImportError)) (*models.ImportReport, error) {
+
+	if err := authorize(ctx, opts.Owner, auth.RoleEditor, errNoWorkspace); err != nil {
+		return nil, err
+	}
+
+	reader, err := newImportReader(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := uc.cfg.Import.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+
+	imp := &importer{uc: uc, opts: opts, onError: onError, report: &models.ImportReport{DryRun: opts.DryRun},
+		batchSize: batchSize, codes: make(map[string]int, batchSize), month: usageMonth(uc.now()),
+		usage: make(map[string]*models.Usage)}
+
+	// imports are recorded once they end, even if stopped by an error
+	defer func() {
+		if !opts.DryRun && imp.report.Imported > 0 {
+			uc.record(ctx, audit.LinksImport, opts.Format, nil, imp.report)
+		}
+	}()
+
+	for {
+		record, line, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+
+		var interr *utils.InternalError
+		if err != nil && !errors.As(err, &interr) {
+			return imp.report, err
+		}
+		imp.line = line
+		if line < opts.StartLine {
+			continue
+		}
+		imp.report.Processed++
+
+		if interr != nil {
+			imp.rejectInvalid(line, "", interr)
+			continue
+		}
+
+		link, err := uc.importLink(ctx, record, opts)
+		if errors.As(err, &interr) {
+			imp.rejectInvalid(line, record.Code, interr)
+			continue
+		}
+		if err != nil {
+			return imp.report, err
+		}
+
+		if err := imp.add(ctx, line, link); err != nil {
+			return imp.report, err
+		}
+	}
+
+	if err := imp.flush(ctx); err != nil {
+		return imp.report, err
+	}
+	return imp.report, nil
+}
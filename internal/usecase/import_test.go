@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidImportCode(t *testing.T) {
+	assert.True(t, validImportCode("Abc_def-09"))
+	assert.False(t, validImportCode("abc+"))
+	assert.False(t, validImportCode("a/b"))
+	assert.False(t, validImportCode("код"))
+}
+
+func TestImport(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Import.BatchSize = 2
+
+	uc := NewUrlUsecase(mockRepo, rnd, cfg)
+	uc.now = func() time.Time { return time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	input := "code,url,owner,created_at,tags\n" +
+		"abc,HTTPS://Example.com/a,team,2024-05-01T10:00:00Z,promo;Promo\n" +
+		"def,https://example.com/b,,,\n" +
+		"ghi,http://127.0.0.1/admin,,,\n" +
+		"abc,https://example.com/c,,,\n" +
+		"j+k,https://example.com/d,,,\n" +
+		"lmn,not a url,,,\n" +
+		"opq,https://example.com/e,,,\n"
+
+	tests := []struct {
+		Name           string
+		Opts           models.ImportOptions
+		SetUp          func()
+		ExpectedReport *models.ImportReport
+		ExpectedErrors []models.ImportError
+		ExpectedErr    error
+	}{
+		{
+			Name: "Test for successful import",
+			Opts: models.ImportOptions{Format: "csv"},
+			SetUp: func() {
+				gomock.InOrder(
+					mockRepo.EXPECT().ImportLinks(ctx, []*models.UrlData{
+						{ShortUrl: "abc", OriginalUrl: "https://example.com/a", Owner: "team", CreatedAt: createdAt,
+							LinkMetadata: models.LinkMetadata{Tags: []string{"promo"}}},
+						{ShortUrl: "def", OriginalUrl: "https://example.com/b"},
+					}, month, gomock.Any()).Return(map[string]error{
+						"def": utils.NewInternalError(http.StatusConflict, "this shortUrl already exists")}, nil),
+					mockRepo.EXPECT().ImportLinks(ctx, []*models.UrlData{
+						{ShortUrl: "abc", OriginalUrl: "https://example.com/c"},
+						{ShortUrl: "opq", OriginalUrl: "https://example.com/e"},
+					}, month, gomock.Any()).Return(map[string]error{
+						"abc": utils.NewInternalError(http.StatusConflict, "this shortUrl already exists")}, nil),
+				)
+			},
+			ExpectedReport: &models.ImportReport{Processed: 7, Imported: 2, Conflicts: 2, Invalid: 3, LastLine: 8},
+			ExpectedErrors: []models.ImportError{
+				{Line: 3, Code: "def", Reason: "conflict", Message: "this shortUrl already exists"},
+				{Line: 4, Code: "ghi", Reason: "private_address", Message: "destination url is not allowed"},
+				{Line: 6, Code: "j+k", Reason: "invalid", Message: "code may only contain letters, digits, _ and -"},
+				{Line: 7, Code: "lmn", Reason: "invalid", Message: "original url does not fits the url format"},
+				{Line: 5, Code: "abc", Reason: "conflict", Message: "this shortUrl already exists"},
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "Test for dry run resumed from a line with an owner",
+			Opts: models.ImportOptions{Format: "csv", DryRun: true, StartLine: 5, Owner: "migration"},
+			SetUp: func() {
+				mockRepo.EXPECT().ExistingCodes(ctx, []string{"abc", "opq"}).Return(nil, nil)
+				mockRepo.EXPECT().GetUsage(ctx, "migration", month).Return(
+					&models.Usage{Owner: "migration", Month: month}, nil)
+			},
+			ExpectedReport: &models.ImportReport{DryRun: true, Processed: 4, Imported: 2, Invalid: 2, LastLine: 8},
+			ExpectedErrors: []models.ImportError{
+				{Line: 6, Code: "j+k", Reason: "invalid", Message: "code may only contain letters, digits, _ and -"},
+				{Line: 7, Code: "lmn", Reason: "invalid", Message: "original url does not fits the url format"},
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "Test for failed import",
+			Opts: models.ImportOptions{Format: "csv"},
+			SetUp: func() {
+				gomock.InOrder(
+					mockRepo.EXPECT().ImportLinks(ctx, gomock.Any(), month, gomock.Any()).Return(nil, nil),
+					mockRepo.EXPECT().ImportLinks(ctx, gomock.Any(), month, gomock.Any()).Return(nil,
+						errors.New("some bd error")),
+				)
+			},
+			ExpectedReport: &models.ImportReport{Processed: 7, Imported: 2, Invalid: 3, LastLine: 3},
+			ExpectedErrors: []models.ImportError{
+				{Line: 4, Code: "ghi", Reason: "private_address", Message: "destination url is not allowed"},
+				{Line: 6, Code: "j+k", Reason: "invalid", Message: "code may only contain letters, digits, _ and -"},
+				{Line: 7, Code: "lmn", Reason: "invalid", Message: "original url does not fits the url format"},
+			},
+			ExpectedErr: errors.New("some bd error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp()
+
+			var importErrors []models.ImportError
+			report, err := uc.Import(ctx, strings.NewReader(input), &tt.Opts, func(importError *models.ImportError) {
+				importErrors = append(importErrors, *importError)
+			})
+
+			assert.Equal(t, tt.ExpectedReport, report)
+			assert.Equal(t, tt.ExpectedErrors, importErrors)
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+}
+
+func TestImportDuplicateInBatch(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+	ctx := context.Background()
+
+	input := `{"code":"abc","url":"https://example.com/a"}` + "\n" + `{"code":"abc","url":"https://example.com/b"}` + "\n"
+
+	mockRepo.EXPECT().ImportLinks(ctx, []*models.UrlData{{ShortUrl: "abc", OriginalUrl: "https://example.com/a"}},
+		gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	var importErrors []models.ImportError
+	report, err := uc.Import(ctx, strings.NewReader(input), &models.ImportOptions{Format: "jsonl"},
+		func(importError *models.ImportError) {
+			importErrors = append(importErrors, *importError)
+		})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ImportReport{Processed: 2, Imported: 1, Conflicts: 1, LastLine: 2}, report)
+	assert.Equal(t, []models.ImportError{{Line: 2, Code: "abc", Reason: "duplicate", Message: "code repeats line 1"}},
+		importErrors)
+}
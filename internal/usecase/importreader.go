@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+const (
+	importFormatCsv   = "csv"
+	importFormatJsonl = "jsonl"
+)
+
+// importColumns are the columns a CSV import may have, code and url being
+// required. Tags are separated by semicolons.
+var importColumns = map[string]bool{
+	"code": true, "url": true, "owner": true, "created_at": true, "title": true, "description": true, "tags": true, "notes": true,
+}
+
+// importReader reads the records of an import one by one. Rows that can't be
+// parsed are returned as *utils.InternalError with their line, reading then
+// continuing with the next row; any other error ends the import.
+type importReader interface {
+	next() (*models.ImportRecord, int, error)
+}
+
+func newImportReader(r io.Reader, format string) (importReader, error) {
+	switch format {
+	case importFormatCsv:
+		return newCsvImportReader(r)
+	case importFormatJsonl:
+		return &jsonlImportReader{reader: bufio.NewReader(r)}, nil
+	}
+	return nil, utils.NewInternalError(http.StatusBadRequest, "import format must be csv or jsonl")
+}
+
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCsvImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "csv import must start with a header")
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "incorrect csv header")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(header))
+	for i, column := range header {
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\uFEFF")
+		}
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !importColumns[column] || seen[column] {
+			return nil, utils.NewInternalError(http.StatusBadRequest, fmt.Sprintf("unexpected csv column %q", column))
+		}
+		seen[column] = true
+		header[i] = column
+	}
+	if !seen["code"] || !seen["url"] {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "csv import must have code and url columns")
+	}
+
+	return &csvImportReader{reader: reader, columns: header}, nil
+}
+
+func (cr *csvImportReader) next() (*models.ImportRecord, int, error) {
+	record, err := cr.reader.Read()
+	if err == io.EOF {
+		return nil, 0, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) && !errors.Is(err, csv.ErrFieldCount) {
+		return nil, parseErr.StartLine, utils.NewInternalError(http.StatusBadRequest, "incorrect csv row")
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	line, _ := cr.reader.FieldPos(0)
+	if len(record) != len(cr.columns) {
+		return nil, line, utils.NewInternalError(http.StatusBadRequest,
+			fmt.Sprintf("row has %d fields instead of %d", len(record), len(cr.columns)))
+	}
+
+	res := &models.ImportRecord{}
+	for i, value := range record {
+		switch cr.columns[i] {
+		case "code":
+			res.Code = value
+		case "url":
+			res.Url = value
+		case "owner":
+			res.Owner = value
+		case "created_at":
+			if value == "" {
+				continue
+			}
+			createdAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, line, utils.NewInternalError(http.StatusBadRequest, "created_at must be an RFC 3339 timestamp")
+			}
+			res.CreatedAt = &createdAt
+		case "title":
+			res.Title = value
+		case "description":
+			res.Description = value
+		case "tags":
+			if value != "" {
+				res.Tags = strings.Split(value, ";")
+			}
+		case "notes":
+			res.Notes = value
+		}
+	}
+	return res, line, nil
+}
+
+// jsonlImportReader reads one JSON object per line, skipping blank lines.
+type jsonlImportReader struct {
+	reader *bufio.Reader
+	line   int
+}
+
+func (jr *jsonlImportReader) next() (*models.ImportRecord, int, error) {
+	for {
+		data, err := jr.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		if len(data) == 0 && err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		jr.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+
+		res := &models.ImportRecord{}
+		if err := decoder.Decode(res); err != nil || decoder.More() {
+			return nil, jr.line, utils.NewInternalError(http.StatusBadRequest, "incorrect json row")
+		}
+		return res, jr.line, nil
+	}
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// importRow is what an import reader returned for one row.
+type importRow struct {
+	Record *models.ImportRecord
+	Line   int
+	Err    error
+}
+
+func readAll(t *testing.T, reader importReader) []importRow {
+	var res []importRow
+	for {
+		record, line, err := reader.next()
+		if err == io.EOF {
+			return res
+		}
+		res = append(res, importRow{Record: record, Line: line, Err: err})
+		if len(res) > 100 {
+			t.Fatal("reader does not stop")
+		}
+	}
+}
+
+func TestCsvImportReader(t *testing.T) {
+
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	input := "\uFEFFcode,URL,title,tags,created_at\n" +
+		"abc,https://example.com/a,Spring sale,promo;spring,2024-05-01T10:00:00Z\n" +
+		"def,https://example.com/b,,,\n" +
+		"ghi,https://example.com/c\n" +
+		"jkl,https://example.com/d,,,yesterday\n" +
+		"\"mno\",\"https://example.com/e\nsecond line\",,,\n" +
+		"pq\"r,https://example.com/f,,,\n" +
+		"stu,https://example.com/g,,,\n"
+
+	reader, err := newImportReader(strings.NewReader(input), "csv")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []importRow{
+		{Record: &models.ImportRecord{Code: "abc", Url: "https://example.com/a", CreatedAt: &createdAt,
+			LinkMetadata: models.LinkMetadata{Title: "Spring sale", Tags: []string{"promo", "spring"}}}, Line: 2},
+		{Record: &models.ImportRecord{Code: "def", Url: "https://example.com/b"}, Line: 3},
+		{Line: 4, Err: &utils.InternalError{Code: http.StatusBadRequest, Message: "row has 2 fields instead of 5"}},
+		{Line: 5, Err: &utils.InternalError{Code: http.StatusBadRequest, Message: "created_at must be an RFC 3339 timestamp"}},
+		{Record: &models.ImportRecord{Code: "mno", Url: "https://example.com/e\nsecond line"}, Line: 6},
+		{Line: 8, Err: &utils.InternalError{Code: http.StatusBadRequest, Message: "incorrect csv row"}},
+		{Record: &models.ImportRecord{Code: "stu", Url: "https://example.com/g"}, Line: 9},
+	}, readAll(t, reader))
+}
+
+func TestCsvImportReaderHeader(t *testing.T) {
+
+	tests := []struct {
+		Name        string
+		Input       string
+		ExpectedErr error
+	}{
+		{Name: "empty input", Input: "", ExpectedErr: &utils.InternalError{Code: http.StatusBadRequest,
+			Message: "csv import must start with a header"}},
+		{Name: "missing url", Input: "code,title\n", ExpectedErr: &utils.InternalError{Code: http.StatusBadRequest,
+			Message: "csv import must have code and url columns"}},
+		{Name: "unknown column", Input: "code,url,clicks\n", ExpectedErr: &utils.InternalError{Code: http.StatusBadRequest,
+			Message: `unexpected csv column "clicks"`}},
+		{Name: "repeated column", Input: "code,url,code\n", ExpectedErr: &utils.InternalError{Code: http.StatusBadRequest,
+			Message: `unexpected csv column "code"`}},
+		{Name: "valid header", Input: "code,url\n", ExpectedErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			_, err := newCsvImportReader(strings.NewReader(tt.Input))
+
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+
+	_, err := newImportReader(strings.NewReader(""), "xml")
+	assert.Equal(t, &utils.InternalError{Code: http.StatusBadRequest, Message: "import format must be csv or jsonl"}, err)
+}
+
+func TestJsonlImportReader(t *testing.T) {
+
+	input := `{"code":"abc","url":"https://example.com/a","tags":["promo"],"owner":"team"}` + "\n" +
+		"\n" +
+		`{"code":"def","url":"https://example.com/b"` + "\n" +
+		`{"code":"ghi","url":"https://example.com/c","clicks":5}` + "\n" +
+		`{"code":"jkl","url":"https://example.com/d"} {"code":"x"}` + "\n" +
+		`  {"code":"mno","url":"https://example.com/e"}  `
+
+	reader, err := newImportReader(strings.NewReader(input), "jsonl")
+	assert.NoError(t, err)
+
+	invalid := &utils.InternalError{Code: http.StatusBadRequest, Message: "incorrect json row"}
+
+	assert.Equal(t, []importRow{
+		{Record: &models.ImportRecord{Code: "abc", Url: "https://example.com/a", Owner: "team",
+			LinkMetadata: models.LinkMetadata{Tags: []string{"promo"}}}, Line: 1},
+		{Line: 3, Err: invalid},
+		{Line: 4, Err: invalid},
+		{Line: 5, Err: invalid},
+		{Record: &models.ImportRecord{Code: "mno", Url: "https://example.com/e"}, Line: 6},
+	}, readAll(t, reader))
+}
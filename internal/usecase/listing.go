@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+const defaultListLimit = 100
+
+const (
+	sortCreatedAt = "created_at"
+	orderDesc     = "desc"
+)
+
+// encodeCursor makes an opaque token of cursor for the next_cursor field.
+func encodeCursor(cursor *models.LinkCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a token made by encodeCursor, rejecting cursors issued
+// for another order than the one requested.
+func decodeCursor(token, sort, order string) (*models.LinkCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "incorrect cursor")
+	}
+
+	cursor := &models.LinkCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.ShortUrl == "" {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "incorrect cursor")
+	}
+	if cursor.Sort != sort || cursor.Order != order {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "cursor was issued for another order")
+	}
+	return cursor, nil
+}
+
+// hostFilter brings a host given in the listing filter to the form hosts are
+// stored in: lowercased punycode without IPv6 brackets.
+func hostFilter(host string) (string, error) {
+	host = strings.Trim(strings.TrimSpace(host), "[]")
+	if host == "" {
+		return "", nil
+	}
+
+	host, err := canonicalHost(host)
+	if err != nil {
+		return "", utils.NewInternalError(http.StatusBadRequest, "incorrect host in filter")
+	}
+	return strings.Trim(host, "[]"), nil
+}
+
+// normalizeFilter trims the filter values and brings the hosts to their stored
+// form, rejecting empty creation ranges.
+func normalizeFilter(filter *models.LinkFilter) (*models.LinkFilter, error) {
+	query := *filter
+	query.Owner = strings.TrimSpace(query.Owner)
+	query.Tag = strings.TrimSpace(query.Tag)
+	query.Title = strings.TrimSpace(query.Title)
+	query.Search = strings.TrimSpace(query.Search)
+
+	var err error
+	if query.Host, err = hostFilter(query.Host); err != nil {
+		return nil, err
+	}
+	if query.Domain, err = hostFilter(query.Domain); err != nil {
+		return nil, err
+	}
+
+	if query.CreatedFrom != nil && query.CreatedTo != nil && !query.CreatedTo.After(*query.CreatedFrom) {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "created_to must be later than created_from")
+	}
+	return &query, nil
+}
+
+// ListLinks returns a page of the links matching filter, newest first unless
+// another order is requested. The page ends with a cursor for the next one
+// while more links match.
+func (uc *UrlUsecase) ListLinks(ctx context.Context, filter *models.LinkFilter) (*models.LinkList, error) {
+
+	query, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	// keys of a workspace only see its links
+	if workspace := boundWorkspace(ctx); workspace != "" {
+		query.Owner = workspace
+	}
+	if query.Sort == "" {
+		query.Sort = sortCreatedAt
+	}
+	if query.Order == "" {
+		query.Order = orderDesc
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+
+	if query.Cursor != "" {
+		if query.After, err = decodeCursor(query.Cursor, query.Sort, query.Order); err != nil {
+			return nil, err
+		}
+	}
+
+	// one link more than requested tells whether there is a next page
+	limit := query.Limit
+	query.Limit++
+
+	links, err := uc.Repo.ListLinks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.LinkList{}
+	if len(links) > limit {
+		links = links[:limit]
+		last := links[limit-1]
+		res.NextCursor = encodeCursor(&models.LinkCursor{Sort: query.Sort, Order: query.Order,
+			CreatedAt: last.CreatedAt, Clicks: last.Clicks, ShortUrl: last.ShortUrl})
+	}
+
+	res.Links = make([]models.LinkData, 0, len(links))
+	for _, link := range links {
+		res.Links = append(res.Links, *uc.linkData(link))
+	}
+	return res, nil
+}
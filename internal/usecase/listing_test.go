@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCursor(t *testing.T) {
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cursor := &models.LinkCursor{Sort: "clicks", Order: "asc", CreatedAt: createdAt, Clicks: 3, ShortUrl: "Abc_def_gs"}
+
+	decoded, err := decodeCursor(encodeCursor(cursor), "clicks", "asc")
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	_, err = decodeCursor(encodeCursor(cursor), "created_at", "asc")
+	assert.Equal(t, &utils.InternalError{Code: http.StatusBadRequest, Message: "cursor was issued for another order"}, err)
+
+	_, err = decodeCursor("not a cursor", "clicks", "asc")
+	assert.Equal(t, &utils.InternalError{Code: http.StatusBadRequest, Message: "incorrect cursor"}, err)
+
+	_, err = decodeCursor(encodeCursor(&models.LinkCursor{Sort: "clicks", Order: "asc"}), "clicks", "asc")
+	assert.Equal(t, &utils.InternalError{Code: http.StatusBadRequest, Message: "incorrect cursor"}, err)
+}
+
+func TestHostFilter(t *testing.T) {
+
+	tests := []struct {
+		Name      string
+		Host      string
+		Expected  string
+		ExpectErr bool
+	}{
+		{Name: "empty", Host: " ", Expected: ""},
+		{Name: "lowercased", Host: " WWW.Example.com ", Expected: "www.example.com"},
+		{Name: "punycode", Host: "пример.рф", Expected: "xn--e1afmkfd.xn--p1ai"},
+		{Name: "ipv6 without brackets", Host: "[2001:DB8::1]", Expected: "2001:db8::1"},
+		{Name: "invalid", Host: "xn--a.com", ExpectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			host, err := hostFilter(tt.Host)
+
+			assert.Equal(t, tt.Expected, host)
+			assert.Equal(t, tt.ExpectErr, err != nil)
+		})
+	}
+}
+
+func TestListLinks(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+
+	uc := NewUrlUsecase(mockRepo, rnd, cfg)
+	ctx := context.Background()
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	earlier := createdAt.Add(-time.Hour)
+
+	nextCursor := encodeCursor(&models.LinkCursor{Sort: "clicks", Order: "desc", CreatedAt: createdAt, Clicks: 9,
+		ShortUrl: "Abc_def_gs"})
+
+	tests := []struct {
+		Name          string
+		Filter        models.LinkFilter
+		SetUp         func()
+		ExpectedLinks *models.LinkList
+		ExpectedErr   error
+	}{
+		{
+			Name:   "Test for successful listing with defaults",
+			Filter: models.LinkFilter{Tag: " promo ", Title: " sale", Owner: "team ", Host: "Example.COM"},
+			SetUp: func() {
+				mockRepo.EXPECT().ListLinks(ctx, &models.LinkFilter{Tag: "promo", Title: "sale", Owner: "team",
+					Host: "example.com", Sort: "created_at", Order: "desc", Limit: 101}).Return(
+					[]*models.UrlData{{ShortUrl: "Abc_def_gs", OriginalUrl: "http://example.com", CreatedAt: createdAt,
+						Owner: "team", Clicks: 4, LinkMetadata: models.LinkMetadata{Title: "Spring sale", Tags: []string{"promo"}}}}, nil)
+			},
+			ExpectedLinks: &models.LinkList{Links: []models.LinkData{{ShortUrl: "http://localhost:8080/Abc_def_gs",
+				OriginalUrl: "http://example.com", CreatedAt: createdAt, Owner: "team", Clicks: 4,
+				LinkMetadata: models.LinkMetadata{Title: "Spring sale", Tags: []string{"promo"}}}}},
+			ExpectedErr: nil,
+		},
+		{
+			Name:   "Test for successful listing with next page",
+			Filter: models.LinkFilter{Sort: "clicks", Limit: 1},
+			SetUp: func() {
+				mockRepo.EXPECT().ListLinks(ctx, &models.LinkFilter{Sort: "clicks", Order: "desc", Limit: 2}).Return(
+					[]*models.UrlData{
+						{ShortUrl: "Abc_def_gs", OriginalUrl: "http://example.com", CreatedAt: createdAt, Clicks: 9},
+						{ShortUrl: "Abc_def_gt", OriginalUrl: "http://example.com", CreatedAt: earlier, Clicks: 2},
+					}, nil)
+			},
+			ExpectedLinks: &models.LinkList{Links: []models.LinkData{{ShortUrl: "http://localhost:8080/Abc_def_gs",
+				OriginalUrl: "http://example.com", CreatedAt: createdAt, Clicks: 9}}, NextCursor: nextCursor},
+			ExpectedErr: nil,
+		},
+		{
+			Name:   "Test for successful listing of the next page",
+			Filter: models.LinkFilter{Sort: "clicks", Limit: 1, Cursor: nextCursor},
+			SetUp: func() {
+				mockRepo.EXPECT().ListLinks(ctx, &models.LinkFilter{Sort: "clicks", Order: "desc", Limit: 2, Cursor: nextCursor,
+					After: &models.LinkCursor{Sort: "clicks", Order: "desc", CreatedAt: createdAt, Clicks: 9, ShortUrl: "Abc_def_gs"}}).Return(
+					[]*models.UrlData{{ShortUrl: "Abc_def_gt", OriginalUrl: "http://example.com", CreatedAt: earlier, Clicks: 2}}, nil)
+			},
+			ExpectedLinks: &models.LinkList{Links: []models.LinkData{{ShortUrl: "http://localhost:8080/Abc_def_gt",
+				OriginalUrl: "http://example.com", CreatedAt: earlier, Clicks: 2}}},
+			ExpectedErr: nil,
+		},
+		{
+			Name:          "Test for cursor of another order",
+			Filter:        models.LinkFilter{Order: "asc", Cursor: nextCursor},
+			SetUp:         func() {},
+			ExpectedLinks: nil,
+			ExpectedErr:   &utils.InternalError{Code: http.StatusBadRequest, Message: "cursor was issued for another order"},
+		},
+		{
+			Name:          "Test for empty creation range",
+			Filter:        models.LinkFilter{CreatedFrom: &createdAt, CreatedTo: &earlier},
+			SetUp:         func() {},
+			ExpectedLinks: nil,
+			ExpectedErr:   &utils.InternalError{Code: http.StatusBadRequest, Message: "created_to must be later than created_from"},
+		},
+		{
+			Name:   "Test for successful empty listing",
+			Filter: models.LinkFilter{Limit: 5, Order: "asc"},
+			SetUp: func() {
+				mockRepo.EXPECT().ListLinks(ctx, &models.LinkFilter{Sort: "created_at", Order: "asc", Limit: 6}).Return(nil, nil)
+			},
+			ExpectedLinks: &models.LinkList{Links: []models.LinkData{}},
+			ExpectedErr:   nil,
+		},
+		{
+			Name:   "Test for failed listing",
+			Filter: models.LinkFilter{},
+			SetUp: func() {
+				mockRepo.EXPECT().ListLinks(ctx, &models.LinkFilter{Sort: "created_at", Order: "desc", Limit: 101}).Return(
+					nil, context.DeadlineExceeded)
+			},
+			ExpectedLinks: nil,
+			ExpectedErr:   context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp()
+
+			links, err := uc.ListLinks(ctx, &tt.Filter)
+
+			assert.Equal(t, tt.ExpectedLinks, links)
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+}
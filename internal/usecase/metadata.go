@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+// normalizeMetadata returns a copy of metadata with trimmed tags, dropping
+// empty ones and case-insensitive duplicates while keeping the order.
+func normalizeMetadata(metadata *models.LinkMetadata) models.LinkMetadata {
+	res := *metadata
+	res.Tags = nil
+
+	seen := make(map[string]bool, len(metadata.Tags))
+	for _, tag := range metadata.Tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		res.Tags = append(res.Tags, tag)
+	}
+	return res
+}
+
+func (uc *UrlUsecase) linkData(data *models.UrlData) *models.LinkData {
+	return &models.LinkData{
+		ShortUrl:         uc.publicUrl(data.ShortUrl),
+		OriginalUrl:      data.OriginalUrl,
+		ClicksLeft:       data.ClicksLeft,
+		ActivationWindow: data.ActivationWindow,
+		QueryOptions:     data.QueryOptions,
+		Rules:            data.Rules,
+		Destinations:     data.Destinations,
+		Interstitial:     data.Interstitial,
+		Owner:            data.Owner,
+		CreatedAt:        data.CreatedAt,
+		Clicks:           data.Clicks,
+		LinkMetadata:     data.LinkMetadata,
+	}
+}
+
+func (uc *UrlUsecase) UpdateMetadata(ctx context.Context, shortUrl string, metadata *models.LinkMetadata) error {
+
+	normalized := normalizeMetadata(metadata)
+
+	data, err := uc.getLink(ctx, shortUrl, auth.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.Repo.UpdateMetadata(ctx, shortUrl, &normalized); err != nil {
+		return err
+	}
+	uc.record(ctx, audit.LinkUpdateMetadata, shortUrl, data.LinkMetadata, normalized)
+	return nil
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeMetadata(t *testing.T) {
+
+	tests := []struct {
+		Name     string
+		Tags     []string
+		Expected []string
+	}{
+		{Name: "no tags", Tags: nil, Expected: nil},
+		{Name: "tags are trimmed", Tags: []string{" promo ", "spring sale"}, Expected: []string{"promo", "spring sale"}},
+		{Name: "empty tags are dropped", Tags: []string{"", "  "}, Expected: nil},
+		{Name: "duplicates ignore case", Tags: []string{"Promo", "b", "promo", "PROMO "}, Expected: []string{"Promo", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			metadata := models.LinkMetadata{Title: "t", Tags: tt.Tags}
+			res := normalizeMetadata(&metadata)
+
+			assert.Equal(t, models.LinkMetadata{Title: "t", Tags: tt.Expected}, res)
+		})
+	}
+}
+
+func TestShortenUrlWithMetadata(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	uc := NewUrlUsecase(mockRepo, rnd, &bootstrap.Config{})
+	ctx := context.Background()
+
+	suffix := uc.generateShortUrl()
+	uc.rnd.Seed(64)
+
+	mockRepo.EXPECT().GetOriginalUrl(ctx, suffix).Return("", &utils.InternalError{
+		Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+	mockRepo.EXPECT().AddOwnedUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru/", ShortUrl: suffix, Owner: "team",
+		LinkMetadata: models.LinkMetadata{Title: "Spring sale", Description: "d", Notes: "n",
+			Tags: []string{"promo", "spring"}}}, gomock.Any(), gomock.Any()).Return(nil)
+
+	_, err := uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http://example.ru", Owner: " team",
+		LinkMetadata: models.LinkMetadata{Title: "Spring sale", Description: "d", Notes: "n",
+			Tags: []string{"promo", " spring", "Promo"}}})
+	assert.NoError(t, err)
+}
+
+func TestUpdateMetadata(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	uc := NewUrlUsecase(mockRepo, rnd, &bootstrap.Config{})
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{ShortUrl: "Abc_def_gs"}, nil)
+	mockRepo.EXPECT().UpdateMetadata(ctx, "Abc_def_gs", &models.LinkMetadata{Title: "t", Tags: []string{"a"}}).Return(nil)
+	assert.NoError(t, uc.UpdateMetadata(ctx, "Abc_def_gs", &models.LinkMetadata{Title: "t", Tags: []string{"a", "A"}}))
+
+	mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gt").Return(nil,
+		&utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+	assert.Equal(t, &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		uc.UpdateMetadata(ctx, "Abc_def_gt", &models.LinkMetadata{}))
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+const defaultMigrationBatchSize = 500
+
+// maxReportedMigrationCodes caps the codes listed in a migration check.
+const maxReportedMigrationCodes = 10
+
+// Migrator copies the links of one repository to another together with their
+// counters. Links already present in the target are left untouched, so an
+// interrupted migration is resumed by running it again.
+type Migrator struct {
+	source    UrlRepository
+	target    UrlRepository
+	batchSize int
+}
+
+func NewMigrator(source, target UrlRepository, batchSize int) *Migrator {
+	if batchSize <= 0 {
+		batchSize = defaultMigrationBatchSize
+	}
+	return &Migrator{source: source, target: target, batchSize: batchSize}
+}
+
+// migrationFilter walks every link, oldest first.
+func migrationFilter() *models.LinkFilter {
+	return &models.LinkFilter{Sort: sortCreatedAt, Order: "asc"}
+}
+
+// storedLink adds the per destination counters to a link read from repo.
+// Only split links have them, so other links cost no extra query.
+func storedLink(ctx context.Context, repo UrlRepository, link *models.UrlData) (*models.StoredLink, error) {
+	res := &models.StoredLink{UrlData: *link}
+	if len(link.Destinations) == 0 {
+		return res, nil
+	}
+
+	clicks, err := repo.GetClicks(ctx, link.ShortUrl)
+	if err != nil {
+		return nil, err
+	}
+	res.VariantClicks = clicks.VariantClicks
+	return res, nil
+}
+
+// Migrate copies every link of the source to the target in batches, calling
+// onProgress after each of them, and returns the final counts.
+func (m *Migrator) Migrate(ctx context.Context, onProgress func(*models.MigrationProgress)) (*models.MigrationProgress, error) {
+
+	progress := &models.MigrationProgress{}
+	batch := make([]*models.StoredLink, 0, m.batchSize)
+
+	flush := func() error {
+		skipped, err := m.target.RestoreLinks(ctx, batch)
+		if err != nil {
+			return err
+		}
+		progress.Processed += len(batch)
+		progress.Skipped += len(skipped)
+		progress.Copied += len(batch) - len(skipped)
+		batch = batch[:0]
+		onProgress(progress)
+		return nil
+	}
+
+	err := m.source.ExportLinks(ctx, migrationFilter(), func(link *models.UrlData) error {
+		stored, err := storedLink(ctx, m.source, link)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, stored)
+		if len(batch) < m.batchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return progress, err
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return progress, err
+		}
+	}
+	return progress, nil
+}
+
+// truncateTime brings t to the microsecond precision of Postgres.
+func truncateTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	res := t.UTC().Truncate(time.Microsecond)
+	return &res
+}
+
+// linkChecksum hashes every field and counter of a link in a form that both
+// repositories store identically: times in UTC with microsecond precision
+// and no zero counters of destinations.
+func linkChecksum(link *models.StoredLink) [sha256.Size]byte {
+	record := exportRecord(&link.UrlData)
+	record.CreatedAt = *truncateTime(&record.CreatedAt)
+	record.NotBefore = truncateTime(record.NotBefore)
+	record.NotAfter = truncateTime(record.NotAfter)
+
+	variants := make(map[int]int64, len(link.VariantClicks))
+	for variant, clicks := range link.VariantClicks {
+		if clicks != 0 {
+			variants[variant] = clicks
+		}
+	}
+
+	data, _ := json.Marshal(struct {
+		Record        *models.ExportRecord
+		VariantClicks map[int]int64
+	}{record, variants})
+	return sha256.Sum256(data)
+}
+
+func xorChecksum(sum *[sha256.Size]byte, link [sha256.Size]byte) {
+	for i := range sum {
+		sum[i] ^= link[i]
+	}
+}
+
+// firstCodes returns up to maxReportedMigrationCodes of codes in order.
+func firstCodes(codes []string) []string {
+	sort.Strings(codes)
+	if len(codes) > maxReportedMigrationCodes {
+		codes = codes[:maxReportedMigrationCodes]
+	}
+	return codes
+}
+
+// Verify compares every link of the source with the link stored under the
+// same short url in the target. The checksums combine the hashes of the
+// links regardless of their order; links present only in the target are
+// ignored.
+func (m *Migrator) Verify(ctx context.Context) (*models.MigrationCheck, error) {
+
+	check := &models.MigrationCheck{}
+	sums := make(map[string][sha256.Size]byte)
+	var sourceSum, targetSum [sha256.Size]byte
+
+	err := m.source.ExportLinks(ctx, migrationFilter(), func(link *models.UrlData) error {
+		stored, err := storedLink(ctx, m.source, link)
+		if err != nil {
+			return err
+		}
+		sum := linkChecksum(stored)
+		sums[link.ShortUrl] = sum
+		xorChecksum(&sourceSum, sum)
+		check.SourceLinks++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatched []string
+	err = m.target.ExportLinks(ctx, migrationFilter(), func(link *models.UrlData) error {
+		expected, ok := sums[link.ShortUrl]
+		if !ok {
+			return nil
+		}
+		delete(sums, link.ShortUrl)
+
+		stored, err := storedLink(ctx, m.target, link)
+		if err != nil {
+			return err
+		}
+		sum := linkChecksum(stored)
+		xorChecksum(&targetSum, sum)
+		check.TargetLinks++
+		if sum != expected {
+			mismatched = append(mismatched, link.ShortUrl)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]string, 0, len(sums))
+	for code := range sums {
+		missing = append(missing, code)
+	}
+
+	check.Missing = len(missing)
+	check.Mismatched = len(mismatched)
+	check.MissingCodes = firstCodes(missing)
+	check.MismatchedCodes = firstCodes(mismatched)
+	check.SourceChecksum = hex.EncodeToString(sourceSum[:])
+	check.TargetChecksum = hex.EncodeToString(targetSum[:])
+	return check, nil
+}
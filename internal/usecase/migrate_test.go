@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// exportOf makes an ExportLinks implementation walking links.
+func exportOf(links ...*models.UrlData) func(context.Context, *models.LinkFilter, func(*models.UrlData) error) error {
+	return func(ctx context.Context, filter *models.LinkFilter, fn func(*models.UrlData) error) error {
+		for _, link := range links {
+			if err := fn(link); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestMigrate(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	source := mocks.NewMockUrlRepository(ctrl)
+	target := mocks.NewMockUrlRepository(ctrl)
+
+	ctx := context.Background()
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	destinations := []models.Destination{{Url: "https://a.example.com", Weight: 1}, {Url: "https://b.example.com", Weight: 1}}
+
+	first := &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "https://example.com/a", CreatedAt: createdAt, Clicks: 3}
+	split := &models.UrlData{ShortUrl: "Abc_def_gb", OriginalUrl: "https://example.com/b", CreatedAt: createdAt,
+		Destinations: destinations, Clicks: 5}
+	last := &models.UrlData{ShortUrl: "Abc_def_gc", OriginalUrl: "https://example.com/c", CreatedAt: createdAt}
+
+	filter := &models.LinkFilter{Sort: "created_at", Order: "asc"}
+
+	tests := []struct {
+		Name             string
+		SetUp            func()
+		ExpectedProgress []models.MigrationProgress
+		ExpectedResult   *models.MigrationProgress
+		ExpectedErr      error
+	}{
+		{
+			Name: "Test for successful migration",
+			SetUp: func() {
+				source.EXPECT().ExportLinks(ctx, filter, gomock.Any()).DoAndReturn(exportOf(first, split, last))
+				source.EXPECT().GetClicks(ctx, "Abc_def_gb").Return(
+					&models.ClickData{Clicks: 5, VariantClicks: map[int]int64{0: 2, 1: 3}}, nil)
+				gomock.InOrder(
+					target.EXPECT().RestoreLinks(ctx, []*models.StoredLink{{UrlData: *first},
+						{UrlData: *split, VariantClicks: map[int]int64{0: 2, 1: 3}}}).Return([]string{"Abc_def_ga"}, nil),
+					target.EXPECT().RestoreLinks(ctx, []*models.StoredLink{{UrlData: *last}}).Return(nil, nil),
+				)
+			},
+			ExpectedProgress: []models.MigrationProgress{
+				{Processed: 2, Copied: 1, Skipped: 1},
+				{Processed: 3, Copied: 2, Skipped: 1},
+			},
+			ExpectedResult: &models.MigrationProgress{Processed: 3, Copied: 2, Skipped: 1},
+			ExpectedErr:    nil,
+		},
+		{
+			Name: "Test for failed restore",
+			SetUp: func() {
+				source.EXPECT().ExportLinks(ctx, filter, gomock.Any()).DoAndReturn(exportOf(first, last))
+				target.EXPECT().RestoreLinks(ctx, gomock.Any()).Return(nil, errors.New("connection reset"))
+			},
+			ExpectedProgress: nil,
+			ExpectedResult:   &models.MigrationProgress{},
+			ExpectedErr:      errors.New("connection reset"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp()
+
+			var reported []models.MigrationProgress
+			result, err := NewMigrator(source, target, 2).Migrate(ctx, func(progress *models.MigrationProgress) {
+				reported = append(reported, *progress)
+			})
+
+			assert.Equal(t, tt.ExpectedErr, err)
+			assert.Equal(t, tt.ExpectedResult, result)
+			assert.Equal(t, tt.ExpectedProgress, reported)
+		})
+	}
+}
+
+func TestVerifyMigration(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	source := mocks.NewMockUrlRepository(ctrl)
+	target := mocks.NewMockUrlRepository(ctrl)
+
+	ctx := context.Background()
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 123456789, time.FixedZone("MSK", 3*60*60))
+	stored := createdAt.UTC().Truncate(time.Microsecond)
+
+	same := &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "https://example.com/a", CreatedAt: createdAt, Clicks: 3,
+		LinkMetadata: models.LinkMetadata{Tags: []string{}}}
+	sameStored := &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "https://example.com/a", CreatedAt: stored, Clicks: 3}
+	changed := &models.UrlData{ShortUrl: "Abc_def_gb", OriginalUrl: "https://example.com/b", CreatedAt: createdAt, Clicks: 9}
+	changedStored := &models.UrlData{ShortUrl: "Abc_def_gb", OriginalUrl: "https://example.com/b", CreatedAt: stored, Clicks: 7}
+	missing := &models.UrlData{ShortUrl: "Abc_def_gc", OriginalUrl: "https://example.com/c", CreatedAt: createdAt}
+	targetOnly := &models.UrlData{ShortUrl: "Abc_def_gd", OriginalUrl: "https://example.com/d", CreatedAt: stored}
+
+	source.EXPECT().ExportLinks(ctx, gomock.Any(), gomock.Any()).DoAndReturn(exportOf(same, changed, missing))
+	target.EXPECT().ExportLinks(ctx, gomock.Any(), gomock.Any()).DoAndReturn(exportOf(sameStored, targetOnly, changedStored))
+
+	check, err := NewMigrator(source, target, 0).Verify(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, check.SourceLinks)
+	assert.Equal(t, 2, check.TargetLinks)
+	assert.Equal(t, 1, check.Missing)
+	assert.Equal(t, []string{"Abc_def_gc"}, check.MissingCodes)
+	assert.Equal(t, 1, check.Mismatched)
+	assert.Equal(t, []string{"Abc_def_gb"}, check.MismatchedCodes)
+	assert.NotEqual(t, check.SourceChecksum, check.TargetChecksum)
+
+	source.EXPECT().ExportLinks(ctx, gomock.Any(), gomock.Any()).DoAndReturn(exportOf(same))
+	target.EXPECT().ExportLinks(ctx, gomock.Any(), gomock.Any()).DoAndReturn(exportOf(targetOnly, sameStored))
+
+	check, err = NewMigrator(source, target, 0).Verify(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, check.Missing+check.Mismatched)
+	assert.Equal(t, check.SourceChecksum, check.TargetChecksum)
+}
+
+func TestLinkChecksum(t *testing.T) {
+
+	link := &models.StoredLink{UrlData: models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "https://example.com/",
+		Destinations: []models.Destination{{Url: "https://a.example.com", Weight: 1}}}, VariantClicks: map[int]int64{0: 2, 1: 0}}
+
+	assert.Equal(t, linkChecksum(link), linkChecksum(&models.StoredLink{UrlData: link.UrlData,
+		VariantClicks: map[int]int64{0: 2}}))
+	assert.NotEqual(t, linkChecksum(link), linkChecksum(&models.StoredLink{UrlData: link.UrlData,
+		VariantClicks: map[int]int64{0: 3}}))
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/AlexNov03/UrlShortener/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,20 @@ func (m *MockUrlRepository) EXPECT() *MockUrlRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddMember mocks base method.
+func (m *MockUrlRepository) AddMember(ctx context.Context, member *models.Member) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockUrlRepositoryMockRecorder) AddMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockUrlRepository)(nil).AddMember), ctx, member)
+}
+
 // AddOriginalUrl mocks base method.
 func (m *MockUrlRepository) AddOriginalUrl(ctx context.Context, data *models.UrlData) error {
 	m.ctrl.T.Helper()
@@ -49,6 +64,192 @@ func (mr *MockUrlRepositoryMockRecorder) AddOriginalUrl(ctx, data interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOriginalUrl", reflect.TypeOf((*MockUrlRepository)(nil).AddOriginalUrl), ctx, data)
 }
 
+// AddOwnedUrl mocks base method.
+func (m *MockUrlRepository) AddOwnedUrl(ctx context.Context, data *models.UrlData, month time.Time, check func(*models.Usage) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOwnedUrl", ctx, data, month, check)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddOwnedUrl indicates an expected call of AddOwnedUrl.
+func (mr *MockUrlRepositoryMockRecorder) AddOwnedUrl(ctx, data, month, check interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOwnedUrl", reflect.TypeOf((*MockUrlRepository)(nil).AddOwnedUrl), ctx, data, month, check)
+}
+
+// AppendAuditEntry mocks base method.
+func (m *MockUrlRepository) AppendAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendAuditEntry", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendAuditEntry indicates an expected call of AppendAuditEntry.
+func (mr *MockUrlRepositoryMockRecorder) AppendAuditEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAuditEntry", reflect.TypeOf((*MockUrlRepository)(nil).AppendAuditEntry), ctx, entry)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockUrlRepository) CreateWebhook(ctx context.Context, subscription *models.WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockUrlRepositoryMockRecorder) CreateWebhook(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockUrlRepository)(nil).CreateWebhook), ctx, subscription)
+}
+
+// CreateWorkspace mocks base method.
+func (m *MockUrlRepository) CreateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspace", ctx, workspace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWorkspace indicates an expected call of CreateWorkspace.
+func (mr *MockUrlRepositoryMockRecorder) CreateWorkspace(ctx, workspace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockUrlRepository)(nil).CreateWorkspace), ctx, workspace)
+}
+
+// CreateWorkspaceKey mocks base method.
+func (m *MockUrlRepository) CreateWorkspaceKey(ctx context.Context, key *models.WorkspaceKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspaceKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWorkspaceKey indicates an expected call of CreateWorkspaceKey.
+func (mr *MockUrlRepositoryMockRecorder) CreateWorkspaceKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspaceKey", reflect.TypeOf((*MockUrlRepository)(nil).CreateWorkspaceKey), ctx, key)
+}
+
+// DecrementClicks mocks base method.
+func (m *MockUrlRepository) DecrementClicks(ctx context.Context, shortUrl string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecrementClicks", ctx, shortUrl)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecrementClicks indicates an expected call of DecrementClicks.
+func (mr *MockUrlRepositoryMockRecorder) DecrementClicks(ctx, shortUrl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementClicks", reflect.TypeOf((*MockUrlRepository)(nil).DecrementClicks), ctx, shortUrl)
+}
+
+// DeleteUrl mocks base method.
+func (m *MockUrlRepository) DeleteUrl(ctx context.Context, shortUrl string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUrl", ctx, shortUrl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUrl indicates an expected call of DeleteUrl.
+func (mr *MockUrlRepositoryMockRecorder) DeleteUrl(ctx, shortUrl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUrl", reflect.TypeOf((*MockUrlRepository)(nil).DeleteUrl), ctx, shortUrl)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockUrlRepository) DeleteWebhook(ctx context.Context, id int64, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockUrlRepositoryMockRecorder) DeleteWebhook(ctx, id, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockUrlRepository)(nil).DeleteWebhook), ctx, id, owner)
+}
+
+// DeleteWorkspaceKey mocks base method.
+func (m *MockUrlRepository) DeleteWorkspaceKey(ctx context.Context, workspace string, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspaceKey", ctx, workspace, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspaceKey indicates an expected call of DeleteWorkspaceKey.
+func (mr *MockUrlRepositoryMockRecorder) DeleteWorkspaceKey(ctx, workspace, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspaceKey", reflect.TypeOf((*MockUrlRepository)(nil).DeleteWorkspaceKey), ctx, workspace, id)
+}
+
+// ExistingCodes mocks base method.
+func (m *MockUrlRepository) ExistingCodes(ctx context.Context, codes []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExistingCodes", ctx, codes)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistingCodes indicates an expected call of ExistingCodes.
+func (mr *MockUrlRepositoryMockRecorder) ExistingCodes(ctx, codes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistingCodes", reflect.TypeOf((*MockUrlRepository)(nil).ExistingCodes), ctx, codes)
+}
+
+// ExportLinks mocks base method.
+func (m *MockUrlRepository) ExportLinks(ctx context.Context, filter *models.LinkFilter, fn func(*models.UrlData) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportLinks", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportLinks indicates an expected call of ExportLinks.
+func (mr *MockUrlRepositoryMockRecorder) ExportLinks(ctx, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportLinks", reflect.TypeOf((*MockUrlRepository)(nil).ExportLinks), ctx, filter, fn)
+}
+
+// FindWorkspaceKey mocks base method.
+func (m *MockUrlRepository) FindWorkspaceKey(ctx context.Context, digest string) (*models.WorkspaceKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWorkspaceKey", ctx, digest)
+	ret0, _ := ret[0].(*models.WorkspaceKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWorkspaceKey indicates an expected call of FindWorkspaceKey.
+func (mr *MockUrlRepositoryMockRecorder) FindWorkspaceKey(ctx, digest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWorkspaceKey", reflect.TypeOf((*MockUrlRepository)(nil).FindWorkspaceKey), ctx, digest)
+}
+
+// GetClicks mocks base method.
+func (m *MockUrlRepository) GetClicks(ctx context.Context, shortUrl string) (*models.ClickData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClicks", ctx, shortUrl)
+	ret0, _ := ret[0].(*models.ClickData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClicks indicates an expected call of GetClicks.
+func (mr *MockUrlRepositoryMockRecorder) GetClicks(ctx, shortUrl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClicks", reflect.TypeOf((*MockUrlRepository)(nil).GetClicks), ctx, shortUrl)
+}
+
 // GetOriginalUrl mocks base method.
 func (m *MockUrlRepository) GetOriginalUrl(ctx context.Context, shortUrl string) (string, error) {
 	m.ctrl.T.Helper()
@@ -63,3 +264,310 @@ func (mr *MockUrlRepositoryMockRecorder) GetOriginalUrl(ctx, shortUrl interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOriginalUrl", reflect.TypeOf((*MockUrlRepository)(nil).GetOriginalUrl), ctx, shortUrl)
 }
+
+// GetUrlData mocks base method.
+func (m *MockUrlRepository) GetUrlData(ctx context.Context, shortUrl string) (*models.UrlData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUrlData", ctx, shortUrl)
+	ret0, _ := ret[0].(*models.UrlData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUrlData indicates an expected call of GetUrlData.
+func (mr *MockUrlRepositoryMockRecorder) GetUrlData(ctx, shortUrl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUrlData", reflect.TypeOf((*MockUrlRepository)(nil).GetUrlData), ctx, shortUrl)
+}
+
+// GetUsage mocks base method.
+func (m *MockUrlRepository) GetUsage(ctx context.Context, owner string, month time.Time) (*models.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", ctx, owner, month)
+	ret0, _ := ret[0].(*models.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockUrlRepositoryMockRecorder) GetUsage(ctx, owner, month interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockUrlRepository)(nil).GetUsage), ctx, owner, month)
+}
+
+// GetWorkspace mocks base method.
+func (m *MockUrlRepository) GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspace", ctx, slug)
+	ret0, _ := ret[0].(*models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspace indicates an expected call of GetWorkspace.
+func (mr *MockUrlRepositoryMockRecorder) GetWorkspace(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspace", reflect.TypeOf((*MockUrlRepository)(nil).GetWorkspace), ctx, slug)
+}
+
+// ImportLinks mocks base method.
+func (m *MockUrlRepository) ImportLinks(ctx context.Context, links []*models.UrlData, month time.Time, check func(*models.Usage) error) (map[string]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportLinks", ctx, links, month, check)
+	ret0, _ := ret[0].(map[string]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportLinks indicates an expected call of ImportLinks.
+func (mr *MockUrlRepositoryMockRecorder) ImportLinks(ctx, links, month, check interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportLinks", reflect.TypeOf((*MockUrlRepository)(nil).ImportLinks), ctx, links, month, check)
+}
+
+// ListAuditEntries mocks base method.
+func (m *MockUrlRepository) ListAuditEntries(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEntries", ctx, filter)
+	ret0, _ := ret[0].([]*models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEntries indicates an expected call of ListAuditEntries.
+func (mr *MockUrlRepositoryMockRecorder) ListAuditEntries(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEntries", reflect.TypeOf((*MockUrlRepository)(nil).ListAuditEntries), ctx, filter)
+}
+
+// ListDeadLetters mocks base method.
+func (m *MockUrlRepository) ListDeadLetters(ctx context.Context, owner string, limit int) ([]*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeadLetters", ctx, owner, limit)
+	ret0, _ := ret[0].([]*models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeadLetters indicates an expected call of ListDeadLetters.
+func (mr *MockUrlRepositoryMockRecorder) ListDeadLetters(ctx, owner, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeadLetters", reflect.TypeOf((*MockUrlRepository)(nil).ListDeadLetters), ctx, owner, limit)
+}
+
+// ListLinks mocks base method.
+func (m *MockUrlRepository) ListLinks(ctx context.Context, filter *models.LinkFilter) ([]*models.UrlData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLinks", ctx, filter)
+	ret0, _ := ret[0].([]*models.UrlData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLinks indicates an expected call of ListLinks.
+func (mr *MockUrlRepositoryMockRecorder) ListLinks(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLinks", reflect.TypeOf((*MockUrlRepository)(nil).ListLinks), ctx, filter)
+}
+
+// ListMembers mocks base method.
+func (m *MockUrlRepository) ListMembers(ctx context.Context, workspace string) ([]*models.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, workspace)
+	ret0, _ := ret[0].([]*models.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockUrlRepositoryMockRecorder) ListMembers(ctx, workspace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockUrlRepository)(nil).ListMembers), ctx, workspace)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockUrlRepository) ListWebhooks(ctx context.Context, owner string) ([]*models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, owner)
+	ret0, _ := ret[0].([]*models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockUrlRepositoryMockRecorder) ListWebhooks(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockUrlRepository)(nil).ListWebhooks), ctx, owner)
+}
+
+// ListWorkspaceKeys mocks base method.
+func (m *MockUrlRepository) ListWorkspaceKeys(ctx context.Context, workspace string) ([]*models.WorkspaceKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkspaceKeys", ctx, workspace)
+	ret0, _ := ret[0].([]*models.WorkspaceKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkspaceKeys indicates an expected call of ListWorkspaceKeys.
+func (mr *MockUrlRepositoryMockRecorder) ListWorkspaceKeys(ctx, workspace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkspaceKeys", reflect.TypeOf((*MockUrlRepository)(nil).ListWorkspaceKeys), ctx, workspace)
+}
+
+// ListWorkspaces mocks base method.
+func (m *MockUrlRepository) ListWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkspaces", ctx)
+	ret0, _ := ret[0].([]*models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkspaces indicates an expected call of ListWorkspaces.
+func (mr *MockUrlRepositoryMockRecorder) ListWorkspaces(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkspaces", reflect.TypeOf((*MockUrlRepository)(nil).ListWorkspaces), ctx)
+}
+
+// PurgeDeletedLinks mocks base method.
+func (m *MockUrlRepository) PurgeDeletedLinks(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedLinks", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedLinks indicates an expected call of PurgeDeletedLinks.
+func (mr *MockUrlRepositoryMockRecorder) PurgeDeletedLinks(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedLinks", reflect.TypeOf((*MockUrlRepository)(nil).PurgeDeletedLinks), ctx, before)
+}
+
+// RecordClick mocks base method.
+func (m *MockUrlRepository) RecordClick(ctx context.Context, shortUrl string, variant int, month time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordClick", ctx, shortUrl, variant, month)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordClick indicates an expected call of RecordClick.
+func (mr *MockUrlRepositoryMockRecorder) RecordClick(ctx, shortUrl, variant, month interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordClick", reflect.TypeOf((*MockUrlRepository)(nil).RecordClick), ctx, shortUrl, variant, month)
+}
+
+// RemoveMember mocks base method.
+func (m *MockUrlRepository) RemoveMember(ctx context.Context, workspace, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, workspace, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockUrlRepositoryMockRecorder) RemoveMember(ctx, workspace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockUrlRepository)(nil).RemoveMember), ctx, workspace, name)
+}
+
+// RestoreLinks mocks base method.
+func (m *MockUrlRepository) RestoreLinks(ctx context.Context, links []*models.StoredLink) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreLinks", ctx, links)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreLinks indicates an expected call of RestoreLinks.
+func (mr *MockUrlRepositoryMockRecorder) RestoreLinks(ctx, links interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreLinks", reflect.TypeOf((*MockUrlRepository)(nil).RestoreLinks), ctx, links)
+}
+
+// RestoreUrl mocks base method.
+func (m *MockUrlRepository) RestoreUrl(ctx context.Context, shortUrl, owner string, deletedAfter, month time.Time, check func(*models.Usage) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUrl", ctx, shortUrl, owner, deletedAfter, month, check)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreUrl indicates an expected call of RestoreUrl.
+func (mr *MockUrlRepositoryMockRecorder) RestoreUrl(ctx, shortUrl, owner, deletedAfter, month, check interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUrl", reflect.TypeOf((*MockUrlRepository)(nil).RestoreUrl), ctx, shortUrl, owner, deletedAfter, month, check)
+}
+
+// RetryDeadLetter mocks base method.
+func (m *MockUrlRepository) RetryDeadLetter(ctx context.Context, id int64, owner string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryDeadLetter", ctx, id, owner, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryDeadLetter indicates an expected call of RetryDeadLetter.
+func (mr *MockUrlRepositoryMockRecorder) RetryDeadLetter(ctx, id, owner, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryDeadLetter", reflect.TypeOf((*MockUrlRepository)(nil).RetryDeadLetter), ctx, id, owner, now)
+}
+
+// UpdateMemberRole mocks base method.
+func (m *MockUrlRepository) UpdateMemberRole(ctx context.Context, workspace, name, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMemberRole", ctx, workspace, name, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMemberRole indicates an expected call of UpdateMemberRole.
+func (mr *MockUrlRepositoryMockRecorder) UpdateMemberRole(ctx, workspace, name, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRole", reflect.TypeOf((*MockUrlRepository)(nil).UpdateMemberRole), ctx, workspace, name, role)
+}
+
+// UpdateMetadata mocks base method.
+func (m *MockUrlRepository) UpdateMetadata(ctx context.Context, shortUrl string, metadata *models.LinkMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMetadata", ctx, shortUrl, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMetadata indicates an expected call of UpdateMetadata.
+func (mr *MockUrlRepositoryMockRecorder) UpdateMetadata(ctx, shortUrl, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadata", reflect.TypeOf((*MockUrlRepository)(nil).UpdateMetadata), ctx, shortUrl, metadata)
+}
+
+// UpdateRules mocks base method.
+func (m *MockUrlRepository) UpdateRules(ctx context.Context, shortUrl string, rules []models.TargetingRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRules", ctx, shortUrl, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRules indicates an expected call of UpdateRules.
+func (mr *MockUrlRepositoryMockRecorder) UpdateRules(ctx, shortUrl, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRules", reflect.TypeOf((*MockUrlRepository)(nil).UpdateRules), ctx, shortUrl, rules)
+}
+
+// UpdateWindow mocks base method.
+func (m *MockUrlRepository) UpdateWindow(ctx context.Context, shortUrl string, window *models.ActivationWindow) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWindow", ctx, shortUrl, window)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWindow indicates an expected call of UpdateWindow.
+func (mr *MockUrlRepositoryMockRecorder) UpdateWindow(ctx, shortUrl, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWindow", reflect.TypeOf((*MockUrlRepository)(nil).UpdateWindow), ctx, shortUrl, window)
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image/color"
+	"net/http"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/qr"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+const defaultQrCacheSize = 256
+
+var qrContentTypes = map[string]string{
+	qr.FormatPNG: "image/png",
+	qr.FormatSVG: "image/svg+xml",
+}
+
+// qrOptions fills in the defaults of opts and converts them for the renderer.
+func qrOptions(opts *models.QrOptions) (*qr.Options, error) {
+	res := &qr.Options{Format: qr.FormatPNG, Size: 256, Level: "M", Margin: 4}
+
+	if opts.Format != "" {
+		res.Format = opts.Format
+	}
+	if opts.Size != 0 {
+		res.Size = opts.Size
+	}
+	if opts.Level != "" {
+		res.Level = opts.Level
+	}
+	if opts.Margin != nil {
+		res.Margin = *opts.Margin
+	}
+
+	var err error
+	res.Foreground, res.Background = color.RGBA{A: 0xff}, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+	if opts.Foreground != "" {
+		if res.Foreground, err = qr.ParseColor(opts.Foreground); err != nil {
+			return nil, utils.NewInternalError(http.StatusBadRequest, "incorrect qr foreground colour")
+		}
+	}
+	if opts.Background != "" {
+		if res.Background, err = qr.ParseColor(opts.Background); err != nil {
+			return nil, utils.NewInternalError(http.StatusBadRequest, "incorrect qr background colour")
+		}
+	}
+
+	return res, nil
+}
+
+func (uc *UrlUsecase) renderQrCode(shortUrl string, opts *qr.Options) (*models.QrCode, error) {
+	data, err := uc.qr.Generate(uc.publicUrl(shortUrl), opts)
+	if err != nil {
+		return nil, fmt.Errorf("usecase.UrlUsecase.renderQrCode: %w", err)
+	}
+	return &models.QrCode{ContentType: qrContentTypes[opts.Format], Data: data}, nil
+}
+
+func (uc *UrlUsecase) GetQrCode(ctx context.Context, shortUrl string, opts *models.QrOptions) (*models.QrCode, error) {
+
+	options, err := qrOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.getLink(ctx, shortUrl, auth.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return uc.renderQrCode(shortUrl, options)
+}
+
+func dataUri(code *models.QrCode) string {
+	return fmt.Sprintf("data:%s;base64,%s", code.ContentType, base64.StdEncoding.EncodeToString(code.Data))
+}
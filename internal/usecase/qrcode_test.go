@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image/png"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetQrCode(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+
+	uc := NewUrlUsecase(mockRepo, rnd, cfg)
+	ctx := context.Background()
+
+	margin := 0
+
+	tests := []struct {
+		Name                string
+		Options             models.QrOptions
+		SetUp               func()
+		ExpectedContentType string
+		ExpectedErr         error
+	}{
+		{
+			Name:    "Test for default png",
+			Options: models.QrOptions{},
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{OriginalUrl: "http://example.ru",
+					ShortUrl: "Abc_def_gs"}, nil)
+			},
+			ExpectedContentType: "image/png",
+			ExpectedErr:         nil,
+		},
+		{
+			Name:    "Test for svg",
+			Options: models.QrOptions{Format: "svg", Level: "H", Margin: &margin, Foreground: "123", Background: "#fafafa"},
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{OriginalUrl: "http://example.ru",
+					ShortUrl: "Abc_def_gs"}, nil)
+			},
+			ExpectedContentType: "image/svg+xml",
+			ExpectedErr:         nil,
+		},
+		{
+			Name:    "Test for unknown link",
+			Options: models.QrOptions{},
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(nil,
+					&utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+			},
+			ExpectedErr: &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+		{
+			Name:        "Test for incorrect foreground",
+			Options:     models.QrOptions{Foreground: "black"},
+			SetUp:       func() {},
+			ExpectedErr: &utils.InternalError{Code: http.StatusBadRequest, Message: "incorrect qr foreground colour"},
+		},
+		{
+			Name:        "Test for incorrect background",
+			Options:     models.QrOptions{Background: "#ggg"},
+			SetUp:       func() {},
+			ExpectedErr: &utils.InternalError{Code: http.StatusBadRequest, Message: "incorrect qr background colour"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp()
+
+			code, err := uc.GetQrCode(ctx, "Abc_def_gs", &tt.Options)
+
+			assert.Equal(t, tt.ExpectedErr, err)
+			if tt.ExpectedErr == nil {
+				assert.Equal(t, tt.ExpectedContentType, code.ContentType)
+				assert.NotEmpty(t, code.Data)
+			}
+		})
+	}
+}
+
+func TestShortenUrlWithQrCode(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+
+	uc := NewUrlUsecase(mockRepo, rnd, cfg)
+	ctx := context.Background()
+
+	suffix := uc.generateShortUrl()
+	uc.rnd.Seed(64)
+
+	mockRepo.EXPECT().GetOriginalUrl(ctx, suffix).Return("", &utils.InternalError{
+		Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+	mockRepo.EXPECT().AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru/", ShortUrl: suffix}).Return(nil)
+
+	res, err := uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http://example.ru", Qr: &models.QrOptions{Size: 128}})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/"+suffix, res.ShortUrl)
+
+	encoded, ok := strings.CutPrefix(res.QrCode, "data:image/png;base64,")
+	assert.True(t, ok)
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	assert.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 128, img.Bounds().Dx())
+
+	// incorrect options are rejected before the link is created
+	_, err = uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http://example.ru", Qr: &models.QrOptions{Foreground: "x"}})
+	assert.Equal(t, &utils.InternalError{Code: http.StatusBadRequest, Message: "incorrect qr foreground colour"}, err)
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"net/url"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+// applyQueryOptions appends the link's UTM parameters and, when enabled, the
+// visitor's query string to target. The existing query and fragment of target
+// are kept byte for byte, and parameters already present in it are never
+// overridden; passed through parameters take precedence over UTM defaults.
+func applyQueryOptions(target string, options *models.QueryOptions, visitorQuery url.Values) string {
+	added := url.Values{}
+
+	if options.PassQuery {
+		for key, values := range visitorQuery {
+			added[key] = append([]string(nil), values...)
+		}
+	}
+
+	utm := []struct {
+		key   string
+		value string
+	}{
+		{"utm_source", options.UtmSource},
+		{"utm_medium", options.UtmMedium},
+		{"utm_campaign", options.UtmCampaign},
+	}
+	for _, param := range utm {
+		if param.value != "" && !added.Has(param.key) {
+			added.Set(param.key, param.value)
+		}
+	}
+
+	if len(added) == 0 {
+		return target
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+
+	// ParseQuery still returns every well-formed pair when some are malformed
+	existing, _ := url.ParseQuery(u.RawQuery)
+	for key := range existing {
+		delete(added, key)
+	}
+
+	encoded := added.Encode()
+	if encoded == "" {
+		return target
+	}
+
+	switch {
+	case u.RawQuery == "":
+		u.RawQuery = encoded
+	case u.RawQuery[len(u.RawQuery)-1] == '&':
+		u.RawQuery += encoded
+	default:
+		u.RawQuery += "&" + encoded
+	}
+	u.ForceQuery = false
+
+	return u.String()
+}
@@ -0,0 +1,16 @@
+package usecase
+
+import "strings"
+
+// reservedCodes are the top-level path segments routed by the server
+// instead of being resolved as short links. They can't be generated or
+// imported as codes in any case.
+var reservedCodes = map[string]bool{
+	"api":          true,
+	"shorten":      true,
+	"openapi.json": true,
+}
+
+func reservedCode(code string) bool {
+	return reservedCodes[strings.ToLower(code)]
+}
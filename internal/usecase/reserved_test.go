@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservedCode(t *testing.T) {
+
+	for code, expected := range map[string]bool{
+		"api":        true,
+		"API":        true,
+		"Shorten":    true,
+		"apis":       false,
+		"Abc_def_gs": false,
+	} {
+		assert.Equal(t, expected, reservedCode(code), code)
+	}
+}
+
+func TestImportReservedCode(t *testing.T) {
+
+	uc := NewUrlUsecase(nil, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+
+	_, err := uc.importLink(context.Background(), &models.ImportRecord{Code: "Api", Url: "https://example.com"},
+		&models.ImportOptions{Format: "csv"})
+
+	assert.Equal(t, utils.NewInternalError(http.StatusBadRequest, "code is reserved for the api"), err)
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+const (
+	osIOS     = "ios"
+	osAndroid = "android"
+	osWindows = "windows"
+	osMacOS   = "macos"
+	osLinux   = "linux"
+
+	deviceMobile  = "mobile"
+	deviceTablet  = "tablet"
+	deviceDesktop = "desktop"
+)
+
+// classifyUserAgent extracts the OS and device class from a User-Agent header.
+// Unknown agents get empty values, so they only match rules without OS/device
+// conditions.
+func classifyUserAgent(userAgent string) (string, string) {
+	switch {
+	case strings.Contains(userAgent, "iPad"):
+		return osIOS, deviceTablet
+	case strings.Contains(userAgent, "iPhone") || strings.Contains(userAgent, "iPod"):
+		return osIOS, deviceMobile
+	case strings.Contains(userAgent, "Android"):
+		if strings.Contains(userAgent, "Mobile") {
+			return osAndroid, deviceMobile
+		}
+		return osAndroid, deviceTablet
+	case strings.Contains(userAgent, "Windows Phone"):
+		return osWindows, deviceMobile
+	case strings.Contains(userAgent, "Windows"):
+		return osWindows, deviceDesktop
+	case strings.Contains(userAgent, "Macintosh") || strings.Contains(userAgent, "Mac OS X"):
+		return osMacOS, deviceDesktop
+	case strings.Contains(userAgent, "Linux") || strings.Contains(userAgent, "X11"):
+		return osLinux, deviceDesktop
+	}
+	return "", ""
+}
+
+// parseAcceptLanguage returns the lowercased language tags of an
+// Accept-Language header ordered by preference, skipping q=0 entries.
+func parseAcceptLanguage(header string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		languages = append(languages, language{tag: tag, q: q})
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].q > languages[j].q })
+
+	res := make([]string, 0, len(languages))
+	for _, l := range languages {
+		res = append(res, l.tag)
+	}
+	return res
+}
+
+func matchLanguage(rule string, accepted []string) bool {
+	rule = strings.ToLower(rule)
+	for _, tag := range accepted {
+		if tag == rule || strings.HasPrefix(tag, rule+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRule(rule *models.TargetingRule, os, device string, languages []string, visitor *models.VisitorData) bool {
+	if rule.OS != "" && rule.OS != os {
+		return false
+	}
+	if rule.Device != "" && rule.Device != device {
+		return false
+	}
+	if rule.Language != "" && !matchLanguage(rule.Language, languages) {
+		return false
+	}
+	for key, value := range rule.Query {
+		if !visitor.Query.Has(key) {
+			return false
+		}
+		if value != "" && visitor.Query.Get(key) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluateRules returns the target of the first rule matching the visitor,
+// or an empty string when none does.
+func evaluateRules(rules []models.TargetingRule, visitor *models.VisitorData) string {
+	if len(rules) == 0 || visitor == nil {
+		return ""
+	}
+
+	os, device := classifyUserAgent(visitor.UserAgent)
+	languages := parseAcceptLanguage(visitor.AcceptLanguage)
+
+	for i := range rules {
+		if matchRule(&rules[i], os, device, languages, visitor) {
+			return rules[i].TargetUrl
+		}
+	}
+	return ""
+}
+
+func validateRules(rules []models.TargetingRule) error {
+	for _, rule := range rules {
+		if rule.OS == "" && rule.Device == "" && rule.Language == "" && len(rule.Query) == 0 {
+			return utils.NewInternalError(http.StatusBadRequest, "targeting rule must have at least one condition")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	uaIPhone        = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	uaIPad          = "Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	uaAndroidPhone  = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Mobile Safari/537.36"
+	uaAndroidTablet = "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Safari/537.36"
+	uaWindows       = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Safari/537.36"
+	uaMac           = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15"
+	uaLinux         = "Mozilla/5.0 (X11; Linux x86_64; rv:124.0) Gecko/20100101 Firefox/124.0"
+	uaCurl          = "curl/8.5.0"
+)
+
+func TestClassifyUserAgent(t *testing.T) {
+
+	tests := []struct {
+		Name           string
+		UserAgent      string
+		ExpectedOS     string
+		ExpectedDevice string
+	}{
+		{Name: "iPhone", UserAgent: uaIPhone, ExpectedOS: "ios", ExpectedDevice: "mobile"},
+		{Name: "iPad", UserAgent: uaIPad, ExpectedOS: "ios", ExpectedDevice: "tablet"},
+		{Name: "Android phone", UserAgent: uaAndroidPhone, ExpectedOS: "android", ExpectedDevice: "mobile"},
+		{Name: "Android tablet", UserAgent: uaAndroidTablet, ExpectedOS: "android", ExpectedDevice: "tablet"},
+		{Name: "Windows", UserAgent: uaWindows, ExpectedOS: "windows", ExpectedDevice: "desktop"},
+		{Name: "macOS", UserAgent: uaMac, ExpectedOS: "macos", ExpectedDevice: "desktop"},
+		{Name: "Linux", UserAgent: uaLinux, ExpectedOS: "linux", ExpectedDevice: "desktop"},
+		{Name: "unknown agent", UserAgent: uaCurl, ExpectedOS: "", ExpectedDevice: ""},
+		{Name: "empty agent", UserAgent: "", ExpectedOS: "", ExpectedDevice: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			os, device := classifyUserAgent(tt.UserAgent)
+
+			assert.Equal(t, tt.ExpectedOS, os)
+			assert.Equal(t, tt.ExpectedDevice, device)
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+
+	tests := []struct {
+		Name     string
+		Header   string
+		Expected []string
+	}{
+		{Name: "empty header", Header: "", Expected: []string{}},
+		{Name: "single language", Header: "ru", Expected: []string{"ru"}},
+		{Name: "ordered by quality", Header: "en;q=0.5, ru-RU, de;q=0.8", Expected: []string{"ru-ru", "de", "en"}},
+		{Name: "zero quality skipped", Header: "fr;q=0, en", Expected: []string{"en"}},
+		{Name: "wildcard and garbage skipped", Header: "*, en;q=abc, de", Expected: []string{"de"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, parseAcceptLanguage(tt.Header))
+		})
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+
+	appStore := "https://apps.apple.com/app/id1"
+	playStore := "https://play.google.com/store/apps/details?id=app"
+	website := "https://example.com/desktop"
+	russian := "https://example.ru"
+	campaign := "https://example.com/campaign"
+
+	appRules := []models.TargetingRule{
+		{OS: "ios", TargetUrl: appStore},
+		{OS: "android", TargetUrl: playStore},
+		{Device: "desktop", TargetUrl: website},
+	}
+
+	tests := []struct {
+		Name     string
+		Rules    []models.TargetingRule
+		Visitor  *models.VisitorData
+		Expected string
+	}{
+		{
+			Name:     "no rules",
+			Rules:    nil,
+			Visitor:  &models.VisitorData{UserAgent: uaIPhone},
+			Expected: "",
+		},
+		{
+			Name:     "no visitor",
+			Rules:    appRules,
+			Visitor:  nil,
+			Expected: "",
+		},
+		{
+			Name:     "iPhone goes to App Store",
+			Rules:    appRules,
+			Visitor:  &models.VisitorData{UserAgent: uaIPhone},
+			Expected: appStore,
+		},
+		{
+			Name:     "iPad goes to App Store",
+			Rules:    appRules,
+			Visitor:  &models.VisitorData{UserAgent: uaIPad},
+			Expected: appStore,
+		},
+		{
+			Name:     "Android goes to Play",
+			Rules:    appRules,
+			Visitor:  &models.VisitorData{UserAgent: uaAndroidPhone},
+			Expected: playStore,
+		},
+		{
+			Name:     "desktop goes to website",
+			Rules:    appRules,
+			Visitor:  &models.VisitorData{UserAgent: uaWindows},
+			Expected: website,
+		},
+		{
+			Name:     "unknown agent falls back",
+			Rules:    appRules,
+			Visitor:  &models.VisitorData{UserAgent: uaCurl},
+			Expected: "",
+		},
+		{
+			Name:     "first matching rule wins",
+			Rules:    []models.TargetingRule{{Device: "mobile", TargetUrl: website}, {OS: "ios", TargetUrl: appStore}},
+			Visitor:  &models.VisitorData{UserAgent: uaIPhone},
+			Expected: website,
+		},
+		{
+			Name:     "all conditions must match",
+			Rules:    []models.TargetingRule{{OS: "ios", Device: "tablet", TargetUrl: appStore}},
+			Visitor:  &models.VisitorData{UserAgent: uaIPhone},
+			Expected: "",
+		},
+		{
+			Name:     "language matches primary subtag",
+			Rules:    []models.TargetingRule{{Language: "ru", TargetUrl: russian}},
+			Visitor:  &models.VisitorData{AcceptLanguage: "ru-RU,ru;q=0.9,en;q=0.8"},
+			Expected: russian,
+		},
+		{
+			Name:     "language matches less preferred language",
+			Rules:    []models.TargetingRule{{Language: "RU", TargetUrl: russian}},
+			Visitor:  &models.VisitorData{AcceptLanguage: "en-US, ru;q=0.3"},
+			Expected: russian,
+		},
+		{
+			Name:     "language does not match by prefix only",
+			Rules:    []models.TargetingRule{{Language: "r", TargetUrl: russian}},
+			Visitor:  &models.VisitorData{AcceptLanguage: "ru"},
+			Expected: "",
+		},
+		{
+			Name:     "language with zero quality does not match",
+			Rules:    []models.TargetingRule{{Language: "ru", TargetUrl: russian}},
+			Visitor:  &models.VisitorData{AcceptLanguage: "en, ru;q=0"},
+			Expected: "",
+		},
+		{
+			Name:     "query value matches",
+			Rules:    []models.TargetingRule{{Query: map[string]string{"utm_source": "tv"}, TargetUrl: campaign}},
+			Visitor:  &models.VisitorData{Query: url.Values{"utm_source": {"tv"}}},
+			Expected: campaign,
+		},
+		{
+			Name:     "query value differs",
+			Rules:    []models.TargetingRule{{Query: map[string]string{"utm_source": "tv"}, TargetUrl: campaign}},
+			Visitor:  &models.VisitorData{Query: url.Values{"utm_source": {"radio"}}},
+			Expected: "",
+		},
+		{
+			Name:     "query presence matches",
+			Rules:    []models.TargetingRule{{Query: map[string]string{"promo": ""}, TargetUrl: campaign}},
+			Visitor:  &models.VisitorData{Query: url.Values{"promo": {""}}},
+			Expected: campaign,
+		},
+		{
+			Name:     "query parameter missing",
+			Rules:    []models.TargetingRule{{Query: map[string]string{"promo": ""}, TargetUrl: campaign}},
+			Visitor:  &models.VisitorData{},
+			Expected: "",
+		},
+		{
+			Name: "combined conditions",
+			Rules: []models.TargetingRule{
+				{OS: "android", Language: "ru", Query: map[string]string{"promo": "spring"}, TargetUrl: campaign},
+				{OS: "android", TargetUrl: playStore},
+			},
+			Visitor: &models.VisitorData{UserAgent: uaAndroidTablet, AcceptLanguage: "ru",
+				Query: url.Values{"promo": {"spring"}}},
+			Expected: campaign,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, evaluateRules(tt.Rules, tt.Visitor))
+		})
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+
+	assert.NoError(t, validateRules(nil))
+	assert.NoError(t, validateRules([]models.TargetingRule{{OS: "ios", TargetUrl: "https://apps.apple.com"}}))
+	assert.Equal(t, &utils.InternalError{Code: http.StatusBadRequest, Message: "targeting rule must have at least one condition"},
+		validateRules([]models.TargetingRule{{TargetUrl: "https://example.com"}}))
+}
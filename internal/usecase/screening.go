@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/AlexNov03/UrlShortener/internal/policy"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+const (
+	defaultMaxUrlLength = 8192
+	reasonUrlTooLong    = "url_too_long"
+)
+
+// screenDestinations checks every non-empty url against the maximum length
+// and the destination policy. Rejected urls result in 422 with a reason code.
+func (uc *UrlUsecase) screenDestinations(ctx context.Context, urls ...string) error {
+	maxLength := uc.cfg.Links.MaxUrlLength
+	if maxLength == 0 {
+		maxLength = defaultMaxUrlLength
+	}
+
+	for _, rawUrl := range urls {
+		if rawUrl == "" {
+			continue
+		}
+
+		if len(rawUrl) > maxLength {
+			return &utils.InternalError{Code: http.StatusUnprocessableEntity,
+				Message: fmt.Sprintf("destination url is longer than %d characters", maxLength), Reason: reasonUrlTooLong}
+		}
+
+		u, err := url.Parse(rawUrl)
+		if err != nil {
+			return utils.NewInternalError(http.StatusBadRequest, "destination url does not fits the url format")
+		}
+
+		if err := uc.checkPolicy(ctx, u, "destination url is not allowed"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPolicy checks u against the destination policy, rejecting it with 422,
+// message and the reason code of the violation.
+func (uc *UrlUsecase) checkPolicy(ctx context.Context, u *url.URL, message string) error {
+	err := uc.policy.Check(ctx, u)
+
+	var violation *policy.Violation
+	if errors.As(err, &violation) {
+		return &utils.InternalError{Code: http.StatusUnprocessableEntity, Message: message, Reason: violation.Reason}
+	}
+	return err
+}
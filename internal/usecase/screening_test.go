@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/policy"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type threatList map[string]error
+
+func (tl threatList) Check(ctx context.Context, u *url.URL) error {
+	return tl[u.Hostname()]
+}
+
+func rejected(reason string) error {
+	return &utils.InternalError{Code: http.StatusUnprocessableEntity, Message: "destination url is not allowed", Reason: reason}
+}
+
+func TestShortenUrlScreening(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "sho.rt", 8080
+
+	errThreatDB := errors.New("threat db is unavailable")
+	threats := threatList{"malware.example": &policy.Violation{Reason: "malware"}, "unknown.example": errThreatDB}
+
+	// no repository calls are expected: rejected links are never stored
+	uc := NewUrlUsecase(mockRepo, rnd, cfg, threats)
+	ctx := context.Background()
+
+	tests := []struct {
+		Name        string
+		Data        models.OrigUrlData
+		ExpectedErr error
+	}{
+		{
+			Name:        "Test for javascript url",
+			Data:        models.OrigUrlData{OriginalUrl: "javascript:alert(1)"},
+			ExpectedErr: rejected(policy.ReasonScheme),
+		},
+		{
+			Name:        "Test for file url",
+			Data:        models.OrigUrlData{OriginalUrl: "file:///etc/passwd"},
+			ExpectedErr: rejected(policy.ReasonScheme),
+		},
+		{
+			Name:        "Test for private address",
+			Data:        models.OrigUrlData{OriginalUrl: "http://192.168.1.1/admin"},
+			ExpectedErr: rejected(policy.ReasonPrivateAddress),
+		},
+		{
+			Name:        "Test for self reference",
+			Data:        models.OrigUrlData{OriginalUrl: "http://sho.rt:8080/Abc_def_gs"},
+			ExpectedErr: rejected(policy.ReasonSelfReference),
+		},
+		{
+			Name: "Test for private fallback url",
+			Data: models.OrigUrlData{OriginalUrl: "http://example.ru",
+				ActivationWindow: models.ActivationWindow{FallbackUrl: "http://127.0.0.1/"}},
+			ExpectedErr: rejected(policy.ReasonPrivateAddress),
+		},
+		{
+			Name: "Test for chained shortener in rule target",
+			Data: models.OrigUrlData{OriginalUrl: "http://example.ru",
+				Rules: []models.TargetingRule{{OS: "ios", TargetUrl: "https://bit.ly/app"}}},
+			ExpectedErr: rejected(policy.ReasonShortener),
+		},
+		{
+			Name: "Test for split destination from threat list",
+			Data: models.OrigUrlData{OriginalUrl: "http://example.ru", Destinations: []models.Destination{
+				{Url: "http://example.ru/a", Weight: 1}, {Url: "http://malware.example/b", Weight: 1}}},
+			ExpectedErr: rejected("malware"),
+		},
+		{
+			Name:        "Test for failed threat list check",
+			Data:        models.OrigUrlData{OriginalUrl: "http://unknown.example"},
+			ExpectedErr: errThreatDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			res, err := uc.ShortenUrl(ctx, &tt.Data)
+
+			assert.Nil(t, res)
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+}
+
+func TestUpdateScreening(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	uc := NewUrlUsecase(mockRepo, rnd, &bootstrap.Config{})
+	ctx := context.Background()
+
+	err := uc.UpdateWindow(ctx, "Abc_def_gs", &models.ActivationWindow{FallbackUrl: "javascript:alert(1)"})
+	assert.Equal(t, rejected(policy.ReasonScheme), err)
+
+	err = uc.UpdateRules(ctx, "Abc_def_gs", []models.TargetingRule{
+		{OS: "ios", TargetUrl: "https://apps.apple.com/app"}, {OS: "android", TargetUrl: "http://10.0.0.1/"}})
+	assert.Equal(t, rejected(policy.ReasonPrivateAddress), err)
+}
+
+func TestScreeningUrlLength(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	ctx := context.Background()
+
+	long := "https://bucket.s3.amazonaws.com/object?X-Amz-Signature=" + strings.Repeat("a", 8192)
+
+	uc := NewUrlUsecase(mockRepo, rnd, &bootstrap.Config{})
+
+	assert.NoError(t, uc.screenDestinations(ctx, long[:8192]))
+	assert.Equal(t, &utils.InternalError{Code: http.StatusUnprocessableEntity,
+		Message: "destination url is longer than 8192 characters", Reason: "url_too_long"},
+		uc.screenDestinations(ctx, long[:8193]))
+
+	cfg := &bootstrap.Config{}
+	cfg.Links.MaxUrlLength = 64
+
+	uc = NewUrlUsecase(mockRepo, rnd, cfg)
+
+	_, err := uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http://example.ru",
+		Destinations: []models.Destination{{Url: "http://example.ru/a", Weight: 1}, {Url: long, Weight: 1}}})
+	assert.Equal(t, &utils.InternalError{Code: http.StatusUnprocessableEntity,
+		Message: "destination url is longer than 64 characters", Reason: "url_too_long"}, err)
+
+	err = uc.UpdateWindow(ctx, "Abc_def_gs", &models.ActivationWindow{FallbackUrl: long})
+	assert.Equal(t, &utils.InternalError{Code: http.StatusUnprocessableEntity,
+		Message: "destination url is longer than 64 characters", Reason: "url_too_long"}, err)
+}
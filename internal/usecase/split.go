@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/url"
+
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+// maxDestinationWeight bounds the weights of split destinations, so their
+// total can't overflow when a destination is picked.
+const maxDestinationWeight = 10000
+
+func validateDestinations(destinations []models.Destination) error {
+	total := 0
+	for _, destination := range destinations {
+		if destination.Weight < 1 || destination.Weight > maxDestinationWeight {
+			return utils.NewInternalError(http.StatusBadRequest,
+				fmt.Sprintf("destination weight must be between 1 and %d", maxDestinationWeight))
+		}
+		if total > math.MaxInt-destination.Weight {
+			return utils.NewInternalError(http.StatusBadRequest, "destination weights are too large")
+		}
+		total += destination.Weight
+		if _, err := url.ParseRequestURI(destination.Url); err != nil {
+			return utils.NewInternalError(http.StatusBadRequest, "destination url does not fits the url format")
+		}
+	}
+	return nil
+}
+
+// pickDestination returns the index of the split destination to serve. Known
+// visitors are hashed together with the link, so they keep getting the same
+// variant; anonymous ones are assigned by weighted random.
+func (uc *UrlUsecase) pickDestination(shortUrl string, destinations []models.Destination, visitorID string) int {
+	total := 0
+	for _, destination := range destinations {
+		total += destination.Weight
+	}
+
+	var point int
+	if visitorID != "" {
+		hash := fnv.New64a()
+		hash.Write([]byte(shortUrl))
+		hash.Write([]byte{0})
+		hash.Write([]byte(visitorID))
+		point = int(hash.Sum64() % uint64(total))
+	} else {
+		uc.rndMu.Lock()
+		point = uc.rnd.Intn(total)
+		uc.rndMu.Unlock()
+	}
+
+	for i, destination := range destinations {
+		if point < destination.Weight {
+			return i
+		}
+		point -= destination.Weight
+	}
+	return len(destinations) - 1
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickDestinationSeeded(t *testing.T) {
+
+	destinations := []models.Destination{{Url: "http://example.ru/a", Weight: 1}, {Url: "http://example.ru/b", Weight: 3}}
+
+	pick := func() []int {
+		uc := NewUrlUsecase(nil, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+		res := make([]int, 20)
+		for i := range res {
+			res[i] = uc.pickDestination("Abc_def_gs", destinations, "")
+		}
+		return res
+	}
+
+	// the same seed must produce the same sequence of assignments
+	assert.Equal(t, pick(), pick())
+}
+
+func TestPickDestinationWeights(t *testing.T) {
+
+	uc := NewUrlUsecase(nil, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+
+	destinations := []models.Destination{{Url: "http://example.ru/a", Weight: 1}, {Url: "http://example.ru/b", Weight: 3}}
+
+	const picks = 10000
+	counts := make([]int, len(destinations))
+	for i := 0; i < picks; i++ {
+		counts[uc.pickDestination("Abc_def_gs", destinations, "")]++
+	}
+	assert.InDelta(t, picks/4, counts[0], picks/50)
+	assert.InDelta(t, picks*3/4, counts[1], picks/50)
+
+	counts = make([]int, len(destinations))
+	for i := 0; i < picks; i++ {
+		counts[uc.pickDestination("Abc_def_gs", destinations, fmt.Sprintf("visitor-%d", i))]++
+	}
+	assert.InDelta(t, picks/4, counts[0], picks/50)
+	assert.InDelta(t, picks*3/4, counts[1], picks/50)
+}
+
+func TestPickDestinationSticky(t *testing.T) {
+
+	uc := NewUrlUsecase(nil, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+
+	destinations := []models.Destination{{Url: "http://example.ru/a", Weight: 1}, {Url: "http://example.ru/b", Weight: 1}}
+
+	for i := 0; i < 100; i++ {
+		visitorID := fmt.Sprintf("visitor-%d", i)
+		first := uc.pickDestination("Abc_def_gs", destinations, visitorID)
+		for j := 0; j < 5; j++ {
+			assert.Equal(t, first, uc.pickDestination("Abc_def_gs", destinations, visitorID))
+		}
+	}
+}
+
+func TestValidateDestinations(t *testing.T) {
+
+	assert.NoError(t, validateDestinations(nil))
+	assert.NoError(t, validateDestinations([]models.Destination{{Url: "http://example.ru/a", Weight: 1}}))
+	badWeight := &utils.InternalError{Code: http.StatusBadRequest, Message: "destination weight must be between 1 and 10000"}
+	assert.Equal(t, badWeight, validateDestinations([]models.Destination{{Url: "http://example.ru/a", Weight: 0}}))
+	// weights adding up past the largest int would panic every resolution
+	assert.Equal(t, badWeight, validateDestinations([]models.Destination{{Url: "http://example.ru/a", Weight: math.MaxInt},
+		{Url: "http://example.ru/b", Weight: math.MaxInt}, {Url: "http://example.ru/c", Weight: 2}}))
+	assert.NoError(t, validateDestinations([]models.Destination{{Url: "http://example.ru/a", Weight: 10000},
+		{Url: "http://example.ru/b", Weight: 10000}}))
+	assert.Equal(t, &utils.InternalError{Code: http.StatusBadRequest, Message: "destination url does not fits the url format"},
+		validateDestinations([]models.Destination{{Url: "example", Weight: 1}}))
+}
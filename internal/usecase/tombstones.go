@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+)
+
+const defaultDeletedRetention = 30 * 24 * time.Hour
+
+func (uc *UrlUsecase) deletedRetention() time.Duration {
+	if uc.cfg.Links.DeletedRetention > 0 {
+		return uc.cfg.Links.DeletedRetention
+	}
+	return defaultDeletedRetention
+}
+
+// RestoreLink brings back a link deleted within the retention period, within
+// the active links quota of its owner. A non-empty owner may only restore its
+// own links.
+func (uc *UrlUsecase) RestoreLink(ctx context.Context, shortUrl string, owner string) (*models.LinkData, error) {
+
+	if err := authorize(ctx, owner, auth.RoleEditor, errNoLink); err != nil {
+		return nil, err
+	}
+
+	now := uc.now()
+	err := uc.Repo.RestoreUrl(ctx, shortUrl, owner, now.Add(-uc.deletedRetention()), usageMonth(now), uc.restoreCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := uc.Repo.GetUrlData(ctx, shortUrl)
+	if err != nil {
+		uc.record(ctx, audit.LinkRestore, shortUrl, nil, nil)
+		return nil, err
+	}
+
+	res := uc.linkData(data)
+	uc.record(ctx, audit.LinkRestore, shortUrl, nil, res)
+	return res, nil
+}
+
+// PurgeDeletedLinks deletes the links deleted longer than the retention
+// period ago every interval until ctx is done.
+func (uc *UrlUsecase) PurgeDeletedLinks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := uc.Repo.PurgeDeletedLinks(ctx, uc.now().Add(-uc.deletedRetention()))
+			if err != nil {
+				log.Printf("error while purging deleted links: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("%d deleted links purged", purged)
+			}
+		}
+	}
+}
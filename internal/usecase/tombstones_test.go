@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortenUrlSkipsDeletedCodes(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), cfg)
+	ctx := context.Background()
+
+	first := uc.generateShortUrl()
+	second := uc.generateShortUrl()
+	uc.rnd.Seed(64)
+
+	// the first code belongs to a deleted link
+	gomock.InOrder(
+		mockRepo.EXPECT().GetOriginalUrl(ctx, first).Return("",
+			&utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has been deleted"}),
+		mockRepo.EXPECT().GetOriginalUrl(ctx, second).Return("",
+			&utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}),
+		mockRepo.EXPECT().AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru/", ShortUrl: second}).Return(nil),
+	)
+
+	res, err := uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http://example.ru"})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ShortUrlData{ShortUrl: "http://localhost:8080/" + second}, res)
+}
+
+func TestRestoreLink(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+	cfg.Links.DeletedRetention = 7 * 24 * time.Hour
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), cfg)
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	createdAt := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	deletedAfter := time.Date(2026, time.October, 12, 12, 0, 0, 0, time.UTC)
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().RestoreUrl(ctx, "Abc_def_gs", "team", deletedAfter, month, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{ShortUrl: "Abc_def_gs",
+		OriginalUrl: "http://ya.ru", Owner: "team", CreatedAt: createdAt}, nil)
+	mockRepo.EXPECT().AppendAuditEntry(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, entry *models.AuditEntry) error {
+			assert.Equal(t, audit.LinkRestore, entry.Action)
+			assert.Equal(t, "Abc_def_gs", entry.Target)
+			assert.Nil(t, entry.Before)
+			assert.NotNil(t, entry.After)
+			return nil
+		})
+
+	res, err := uc.RestoreLink(ctx, "Abc_def_gs", "team")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/Abc_def_gs", res.ShortUrl)
+	assert.Equal(t, "http://ya.ru", res.OriginalUrl)
+
+	notRestorable := &utils.InternalError{Code: http.StatusNotFound, Message: "no restorable link match this shortUrl"}
+	mockRepo.EXPECT().RestoreUrl(ctx, "Abc_def_gt", "", deletedAfter, month, gomock.Any()).Return(notRestorable)
+
+	_, err = uc.RestoreLink(ctx, "Abc_def_gt", "")
+	assert.Equal(t, notRestorable, err)
+}
+
+func TestPurgeDeletedLinks(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	// tombstones are kept for 30 days by default
+	mockRepo.EXPECT().PurgeDeletedLinks(gomock.Any(), now.Add(-30*24*time.Hour)).DoAndReturn(
+		func(context.Context, time.Time) (int64, error) {
+			cancel()
+			return 2, nil
+		}).MinTimes(1)
+
+	go func() {
+		uc.PurgeDeletedLinks(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the purge did not run")
+	}
+}
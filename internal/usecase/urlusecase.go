@@ -4,49 +4,213 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"math/rand"
 
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
 	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
 	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/policy"
+	"github.com/AlexNov03/UrlShortener/internal/qr"
 	"github.com/AlexNov03/UrlShortener/utils"
 )
 
 type UrlRepository interface {
 	AddOriginalUrl(ctx context.Context, data *models.UrlData) error
+	AddOwnedUrl(ctx context.Context, data *models.UrlData, month time.Time, check func(*models.Usage) error) error
 	GetOriginalUrl(ctx context.Context, shortUrl string) (string, error)
+	GetUrlData(ctx context.Context, shortUrl string) (*models.UrlData, error)
+	DecrementClicks(ctx context.Context, shortUrl string) (int, error)
+	UpdateWindow(ctx context.Context, shortUrl string, window *models.ActivationWindow) error
+	UpdateRules(ctx context.Context, shortUrl string, rules []models.TargetingRule) error
+	RecordClick(ctx context.Context, shortUrl string, variant int, month time.Time) error
+	GetClicks(ctx context.Context, shortUrl string) (*models.ClickData, error)
+	UpdateMetadata(ctx context.Context, shortUrl string, metadata *models.LinkMetadata) error
+	ListLinks(ctx context.Context, filter *models.LinkFilter) ([]*models.UrlData, error)
+	ImportLinks(ctx context.Context, links []*models.UrlData, month time.Time,
+		check func(*models.Usage) error) (map[string]error, error)
+	ExistingCodes(ctx context.Context, codes []string) ([]string, error)
+	ExportLinks(ctx context.Context, filter *models.LinkFilter, fn func(*models.UrlData) error) error
+	RestoreLinks(ctx context.Context, links []*models.StoredLink) ([]string, error)
+	DeleteUrl(ctx context.Context, shortUrl string) error
+	RestoreUrl(ctx context.Context, shortUrl, owner string, deletedAfter, month time.Time,
+		check func(*models.Usage) error) error
+	PurgeDeletedLinks(ctx context.Context, before time.Time) (int64, error)
+	GetUsage(ctx context.Context, owner string, month time.Time) (*models.Usage, error)
+	AppendAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	ListAuditEntries(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, error)
+	CreateWebhook(ctx context.Context, subscription *models.WebhookSubscription) error
+	ListWebhooks(ctx context.Context, owner string) ([]*models.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id int64, owner string) error
+	ListDeadLetters(ctx context.Context, owner string, limit int) ([]*models.WebhookDelivery, error)
+	RetryDeadLetter(ctx context.Context, id int64, owner string, now time.Time) error
+	CreateWorkspace(ctx context.Context, workspace *models.Workspace) error
+	GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error)
+	ListWorkspaces(ctx context.Context) ([]*models.Workspace, error)
+	AddMember(ctx context.Context, member *models.Member) error
+	ListMembers(ctx context.Context, workspace string) ([]*models.Member, error)
+	UpdateMemberRole(ctx context.Context, workspace, name, role string) error
+	RemoveMember(ctx context.Context, workspace, name string) error
+	CreateWorkspaceKey(ctx context.Context, key *models.WorkspaceKey) error
+	ListWorkspaceKeys(ctx context.Context, workspace string) ([]*models.WorkspaceKey, error)
+	DeleteWorkspaceKey(ctx context.Context, workspace string, id int64) error
+	FindWorkspaceKey(ctx context.Context, digest string) (*models.WorkspaceKey, error)
 }
 
 type UrlUsecase struct {
-	Repo UrlRepository
-	rnd  *rand.Rand
-	cfg  *bootstrap.Config
+	Repo   UrlRepository
+	rnd    *rand.Rand
+	rndMu  sync.Mutex
+	cfg    *bootstrap.Config
+	now    func() time.Time
+	qr     *qr.Generator
+	policy policy.Checker
 }
 
-func NewUrlUsecase(repo UrlRepository, rnd *rand.Rand, cfg *bootstrap.Config) *UrlUsecase {
-	return &UrlUsecase{Repo: repo, rnd: rnd, cfg: cfg}
+// NewUrlUsecase creates the usecase. Destinations are screened by the built-in
+// policy configured in cfg, followed by the given checkers.
+func NewUrlUsecase(repo UrlRepository, rnd *rand.Rand, cfg *bootstrap.Config, checkers ...policy.Checker) *UrlUsecase {
+	qrCacheSize := cfg.Qr.CacheSize
+	if qrCacheSize == 0 {
+		qrCacheSize = defaultQrCacheSize
+	}
+	return &UrlUsecase{Repo: repo, rnd: rnd, cfg: cfg, now: time.Now, qr: qr.NewGenerator(qrCacheSize),
+		policy: policy.New(&cfg.Policy, cfg.Server.Host, checkers...)}
 }
 
 const length = 10
+const defaultInterstitialDelay = 5
 const charSet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_"
 
 func (uc *UrlUsecase) generateShortUrl() string {
-	res := strings.Builder{}
-	for i := 0; i < length; i++ {
-		res.WriteByte(charSet[uc.rnd.Intn(len(charSet))])
+	uc.rndMu.Lock()
+	defer uc.rndMu.Unlock()
+
+	for {
+		res := strings.Builder{}
+		for i := 0; i < length; i++ {
+			res.WriteByte(charSet[uc.rnd.Intn(len(charSet))])
+		}
+		if !reservedCode(res.String()) {
+			return res.String()
+		}
+	}
+}
+
+func (uc *UrlUsecase) publicUrl(shortUrl string) string {
+	return fmt.Sprintf("%s://%s:%d/%s", uc.cfg.Server.Protocol, uc.cfg.Server.Host, uc.cfg.Server.Port, shortUrl)
+}
+
+func validateWindow(window *models.ActivationWindow) error {
+	if window.NotBefore != nil && window.NotAfter != nil && !window.NotAfter.After(*window.NotBefore) {
+		return utils.NewInternalError(http.StatusBadRequest, "not_after must be later than not_before")
+	}
+	return nil
+}
+
+// checkWindow returns the configured error when now is outside the
+// activation window of the link, and nil otherwise.
+func (uc *UrlUsecase) checkWindow(window *models.ActivationWindow) error {
+	now := uc.now()
+
+	if window.NotBefore != nil && now.Before(*window.NotBefore) {
+		status, message := uc.cfg.Links.NotYetActiveStatus, uc.cfg.Links.NotYetActiveMessage
+		if status == 0 {
+			status = http.StatusNotFound
+		}
+		if message == "" {
+			message = "this shortUrl is not active yet"
+		}
+		return utils.NewInternalError(status, message)
+	}
+
+	if window.NotAfter != nil && !now.Before(*window.NotAfter) {
+		status, message := uc.cfg.Links.ExpiredStatus, uc.cfg.Links.ExpiredMessage
+		if status == 0 {
+			status = http.StatusGone
+		}
+		if message == "" {
+			message = "this shortUrl has expired"
+		}
+		return utils.NewInternalError(status, message)
 	}
-	return res.String()
 
+	return nil
 }
 
-func (uc *UrlUsecase) ShortenUrl(ctx context.Context, originalUrl string) (string, error) {
+func (uc *UrlUsecase) ShortenUrl(ctx context.Context, data *models.OrigUrlData) (*models.ShortUrlData, error) {
 
-	_, err := url.ParseRequestURI(originalUrl)
+	_, err := url.ParseRequestURI(data.OriginalUrl)
+	if err != nil {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "original url does not fits the url format")
+	}
+
+	if err := validateWindow(&data.ActivationWindow); err != nil {
+		return nil, err
+	}
+
+	if err := validateRules(data.Rules); err != nil {
+		return nil, err
+	}
+
+	if err := validateDestinations(data.Destinations); err != nil {
+		return nil, err
+	}
+
+	// keys of a workspace create links in it
+	owner := strings.TrimSpace(data.Owner)
+	if workspace := boundWorkspace(ctx); workspace != "" {
+		if owner != "" && owner != workspace {
+			return nil, utils.NewInternalError(http.StatusForbidden,
+				"links can only be created in the workspace of the api key")
+		}
+		owner = workspace
+	}
+	if err := authorize(ctx, owner, auth.RoleEditor, errNoWorkspace); err != nil {
+		return nil, err
+	}
+
+	// links are stored with canonical urls, so equal destinations look equal
+	originalUrl, err := uc.canonicalUrl(data.OriginalUrl)
+	if err != nil {
+		return nil, err
+	}
+	window := data.ActivationWindow
+	if window.FallbackUrl, err = uc.canonicalUrl(window.FallbackUrl); err != nil {
+		return nil, err
+	}
+	rules, err := uc.canonicalRules(data.Rules)
 	if err != nil {
-		return "", utils.NewInternalError(http.StatusBadRequest, "original url does not fits the url format")
+		return nil, err
+	}
+	destinations, err := uc.canonicalDestinations(data.Destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []string{originalUrl, window.FallbackUrl}
+	for _, rule := range rules {
+		targets = append(targets, rule.TargetUrl)
+	}
+	for _, destination := range destinations {
+		targets = append(targets, destination.Url)
+	}
+	if err := uc.screenDestinations(ctx, targets...); err != nil {
+		return nil, err
+	}
+
+	var qrOpts *qr.Options
+	if data.Qr != nil {
+		if qrOpts, err = qrOptions(data.Qr); err != nil {
+			return nil, err
+		}
 	}
 
 	for {
@@ -56,21 +220,239 @@ func (uc *UrlUsecase) ShortenUrl(ctx context.Context, originalUrl string) (strin
 		var interr *utils.InternalError
 
 		if errors.As(err, &interr) && interr.Code == http.StatusNotFound {
-			err = uc.Repo.AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: originalUrl, ShortUrl: shortUrl})
+			urlData := &models.UrlData{OriginalUrl: originalUrl, ShortUrl: shortUrl,
+				ActivationWindow: window, QueryOptions: data.QueryOptions,
+				Rules: rules, Destinations: destinations, Interstitial: data.Interstitial,
+				Owner: owner, LinkMetadata: normalizeMetadata(&data.LinkMetadata)}
+			if data.MaxClicks > 0 {
+				clicksLeft := data.MaxClicks
+				urlData.ClicksLeft = &clicksLeft
+			}
+
+			err = uc.addUrl(ctx, urlData)
+			// another link may have taken the code since the lookup
+			if errors.As(err, &interr) && interr.Code == http.StatusConflict {
+				continue
+			}
 			if err != nil {
-				return "", err
+				return nil, err
+			}
+			uc.record(ctx, audit.LinkCreate, shortUrl, nil, uc.linkData(urlData))
+
+			res := &models.ShortUrlData{ShortUrl: uc.publicUrl(shortUrl)}
+			if qrOpts != nil {
+				code, err := uc.renderQrCode(shortUrl, qrOpts)
+				if err != nil {
+					return nil, err
+				}
+				res.QrCode = dataUri(code)
 			}
-			return fmt.Sprintf("%s://%s:%d/%s", uc.cfg.Server.Protocol, uc.cfg.Server.Host, uc.cfg.Server.Port, shortUrl), nil
+			return res, nil
 		}
 
-		if err != nil {
-			return "", err
+		// deleted links keep their codes until purged
+		if err != nil && !(errors.As(err, &interr) && interr.Code == http.StatusGone) {
+			return nil, err
 		}
 	}
 }
 
-func (uc *UrlUsecase) GetOriginalUrl(ctx context.Context, shortUrl string) (string, error) {
+func (uc *UrlUsecase) GetOriginalUrl(ctx context.Context, shortUrl string, visitor *models.VisitorData) (*models.Redirect, error) {
+
+	data, err := uc.Repo.GetUrlData(ctx, shortUrl)
+	if err != nil {
+		return nil, err
+	}
 
-	return uc.Repo.GetOriginalUrl(ctx, shortUrl)
+	var visitorQuery url.Values
+	if visitor != nil {
+		visitorQuery = visitor.Query
+	}
 
+	if err := uc.checkWindow(&data.ActivationWindow); err != nil {
+		if data.FallbackUrl != "" {
+			return uc.redirect(data, applyQueryOptions(data.FallbackUrl, &data.QueryOptions, visitorQuery)), nil
+		}
+		return nil, err
+	}
+
+	if data.ClicksLeft != nil {
+		if *data.ClicksLeft <= 0 {
+			return nil, utils.NewInternalError(http.StatusGone, "this shortUrl has reached its click limit")
+		}
+		// the repository re-checks the counter atomically, so only as many
+		// concurrent resolutions as there are clicks left get through
+		if _, err := uc.Repo.DecrementClicks(ctx, shortUrl); err != nil {
+			return nil, err
+		}
+	}
+
+	target, variant := data.OriginalUrl, -1
+
+	if ruleTarget := evaluateRules(data.Rules, visitor); ruleTarget != "" {
+		target = ruleTarget
+	} else if len(data.Destinations) > 0 {
+		visitorID := ""
+		if visitor != nil {
+			visitorID = visitor.VisitorID
+		}
+		variant = uc.pickDestination(shortUrl, data.Destinations, visitorID)
+		target = data.Destinations[variant].Url
+	}
+
+	// losing a click in the stats is better than failing the resolution
+	if err := uc.Repo.RecordClick(ctx, shortUrl, variant, usageMonth(uc.now())); err != nil {
+		log.Printf("error while recording click: %v", err)
+	}
+
+	return uc.redirect(data, applyQueryOptions(target, &data.QueryOptions, visitorQuery)), nil
+}
+
+func (uc *UrlUsecase) redirect(data *models.UrlData, target string) *models.Redirect {
+	res := &models.Redirect{Url: target, Interstitial: data.Interstitial}
+	if data.Interstitial {
+		res.Delay = uc.cfg.Links.InterstitialDelay
+		if res.Delay == 0 {
+			res.Delay = defaultInterstitialDelay
+		}
+	}
+	return res
+}
+
+// GetPreview describes the link for its preview page. Unlike GetOriginalUrl
+// it neither counts a click nor consumes one of a limited link.
+func (uc *UrlUsecase) GetPreview(ctx context.Context, shortUrl string) (*models.PreviewData, error) {
+
+	data, err := uc.Repo.GetUrlData(ctx, shortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	clicks, err := uc.Repo.GetClicks(ctx, shortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.PreviewData{
+		ShortUrl:    uc.publicUrl(shortUrl),
+		OriginalUrl: data.OriginalUrl,
+		CreatedAt:   data.CreatedAt,
+		Clicks:      clicks.Clicks,
+	}
+	if u, err := url.Parse(data.OriginalUrl); err == nil {
+		preview.Host = u.Hostname()
+	}
+	return preview, nil
+}
+
+func (uc *UrlUsecase) GetLink(ctx context.Context, shortUrl string) (*models.LinkData, error) {
+
+	data, err := uc.getLink(ctx, shortUrl, auth.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.linkData(data), nil
+}
+
+// DeleteLink deletes a link, which can be restored during the retention
+// period. A non-empty owner may only delete its own links, the links of other
+// owners being reported as not found.
+func (uc *UrlUsecase) DeleteLink(ctx context.Context, shortUrl string, owner string) error {
+
+	data, err := uc.getLink(ctx, shortUrl, auth.RoleEditor)
+	if err != nil {
+		return err
+	}
+	if owner != "" && data.Owner != owner {
+		return errNoLink()
+	}
+
+	if err := uc.Repo.DeleteUrl(ctx, shortUrl); err != nil {
+		return err
+	}
+	uc.record(ctx, audit.LinkDelete, shortUrl, uc.linkData(data), nil)
+	return nil
+}
+
+func (uc *UrlUsecase) UpdateWindow(ctx context.Context, shortUrl string, window *models.ActivationWindow) error {
+
+	if err := validateWindow(window); err != nil {
+		return err
+	}
+
+	fallbackUrl, err := uc.canonicalUrl(window.FallbackUrl)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.screenDestinations(ctx, fallbackUrl); err != nil {
+		return err
+	}
+
+	data, err := uc.getLink(ctx, shortUrl, auth.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	updated := &models.ActivationWindow{NotBefore: window.NotBefore, NotAfter: window.NotAfter, FallbackUrl: fallbackUrl}
+	if err := uc.Repo.UpdateWindow(ctx, shortUrl, updated); err != nil {
+		return err
+	}
+	uc.record(ctx, audit.LinkUpdateWindow, shortUrl, data.ActivationWindow, updated)
+	return nil
+}
+
+func (uc *UrlUsecase) UpdateRules(ctx context.Context, shortUrl string, rules []models.TargetingRule) error {
+
+	if err := validateRules(rules); err != nil {
+		return err
+	}
+
+	rules, err := uc.canonicalRules(rules)
+	if err != nil {
+		return err
+	}
+
+	targets := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		targets = append(targets, rule.TargetUrl)
+	}
+	if err := uc.screenDestinations(ctx, targets...); err != nil {
+		return err
+	}
+
+	data, err := uc.getLink(ctx, shortUrl, auth.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.Repo.UpdateRules(ctx, shortUrl, rules); err != nil {
+		return err
+	}
+	uc.record(ctx, audit.LinkUpdateRules, shortUrl, models.RulesData{Rules: data.Rules}, models.RulesData{Rules: rules})
+	return nil
+}
+
+func (uc *UrlUsecase) GetStats(ctx context.Context, shortUrl string) (*models.LinkStats, error) {
+
+	data, err := uc.getLink(ctx, shortUrl, auth.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	clicks, err := uc.Repo.GetClicks(ctx, shortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.LinkStats{ShortUrl: uc.publicUrl(shortUrl), Clicks: clicks.Clicks}
+	for i, destination := range data.Destinations {
+		stats.Variants = append(stats.Variants, models.VariantStats{
+			Url:    destination.Url,
+			Weight: destination.Weight,
+			Clicks: clicks.VariantClicks[i],
+		})
+	}
+	return stats, nil
 }
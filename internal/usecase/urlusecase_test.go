@@ -2,14 +2,19 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sync"
 	"testing"
+	"time"
 
 	"math/rand"
 
 	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
 	"github.com/AlexNov03/UrlShortener/internal/models"
+	localrepo "github.com/AlexNov03/UrlShortener/internal/repository/local"
 	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
 	"github.com/AlexNov03/UrlShortener/utils"
 	"github.com/stretchr/testify/assert"
@@ -23,6 +28,7 @@ func TestShortenUrl(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
 
 	rnd := rand.New(rand.NewSource(64))
 
@@ -35,12 +41,18 @@ func TestShortenUrl(t *testing.T) {
 	suffix := uc.generateShortUrl()
 	generatedShortUrl := fmt.Sprintf("%s://%s:%d/%s", uc.cfg.Server.Protocol, uc.cfg.Server.Host, uc.cfg.Server.Port, suffix)
 
+	windowStart := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	windowEnd := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
 	tests := []struct {
-		Name           string
-		OriginalUrl    string
-		SetUp          func()
-		ExpectedString string
-		ExpectedErr    error
+		Name         string
+		OriginalUrl  string
+		MaxClicks    int
+		Window       models.ActivationWindow
+		Options      models.QueryOptions
+		SetUp        func()
+		ExpectedData *models.ShortUrlData
+		ExpectedErr  error
 	}{
 		{
 			Name:        "Test for successful returning generated url",
@@ -49,11 +61,26 @@ func TestShortenUrl(t *testing.T) {
 				mockRepo.EXPECT().GetOriginalUrl(ctx, suffix).Return("", &utils.InternalError{
 					Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
 
-				mockRepo.EXPECT().AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru",
+				mockRepo.EXPECT().AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru/",
 					ShortUrl: suffix}).Return(nil)
 			},
-			ExpectedString: generatedShortUrl,
-			ExpectedErr:    nil,
+			ExpectedData: &models.ShortUrlData{ShortUrl: generatedShortUrl},
+			ExpectedErr:  nil,
+		},
+		{
+			Name:        "Test for successful returning generated one-time url",
+			OriginalUrl: "http://example.ru",
+			MaxClicks:   1,
+			SetUp: func() {
+				mockRepo.EXPECT().GetOriginalUrl(ctx, suffix).Return("", &utils.InternalError{
+					Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+
+				clicksLeft := 1
+				mockRepo.EXPECT().AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru/",
+					ShortUrl: suffix, ClicksLeft: &clicksLeft}).Return(nil)
+			},
+			ExpectedData: &models.ShortUrlData{ShortUrl: generatedShortUrl},
+			ExpectedErr:  nil,
 		},
 		{
 			Name:        "Test for failed GetOriginalUrl request to db",
@@ -61,16 +88,39 @@ func TestShortenUrl(t *testing.T) {
 			SetUp: func() {
 				mockRepo.EXPECT().GetOriginalUrl(ctx, suffix).Return("", fmt.Errorf("pg.UrlRepository.GetOriginalUrl:%w", context.DeadlineExceeded))
 			},
-			ExpectedString: "",
-			ExpectedErr:    fmt.Errorf("pg.UrlRepository.GetOriginalUrl:%w", context.DeadlineExceeded),
+			ExpectedData: nil,
+			ExpectedErr:  fmt.Errorf("pg.UrlRepository.GetOriginalUrl:%w", context.DeadlineExceeded),
+		},
+		{
+			Name:        "Test for successful returning generated url with query options",
+			OriginalUrl: "http://example.ru",
+			Options:     models.QueryOptions{UtmSource: "newsletter", PassQuery: true},
+			SetUp: func() {
+				mockRepo.EXPECT().GetOriginalUrl(ctx, suffix).Return("", &utils.InternalError{
+					Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+
+				mockRepo.EXPECT().AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru/", ShortUrl: suffix,
+					QueryOptions: models.QueryOptions{UtmSource: "newsletter", PassQuery: true}}).Return(nil)
+			},
+			ExpectedData: &models.ShortUrlData{ShortUrl: generatedShortUrl},
+			ExpectedErr:  nil,
+		},
+		{
+			Name:        "Test for inverted activation window",
+			OriginalUrl: "http://example.ru",
+			Window:      models.ActivationWindow{NotBefore: &windowEnd, NotAfter: &windowStart},
+			SetUp: func() {
+			},
+			ExpectedData: nil,
+			ExpectedErr:  &utils.InternalError{Code: http.StatusBadRequest, Message: "not_after must be later than not_before"},
 		},
 		{
 			Name:        "Test for bad url format",
 			OriginalUrl: "http/example.ru",
 			SetUp: func() {
 			},
-			ExpectedString: "",
-			ExpectedErr:    &utils.InternalError{Code: http.StatusBadRequest, Message: "original url does not fits the url format"},
+			ExpectedData: nil,
+			ExpectedErr:  &utils.InternalError{Code: http.StatusBadRequest, Message: "original url does not fits the url format"},
 		},
 	}
 
@@ -80,15 +130,52 @@ func TestShortenUrl(t *testing.T) {
 			uc.rnd.Seed(64)
 			tt.SetUp()
 
-			shortUrl, err := uc.ShortenUrl(ctx, tt.OriginalUrl)
+			shortUrlData, err := uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: tt.OriginalUrl, MaxClicks: tt.MaxClicks,
+				ActivationWindow: tt.Window, QueryOptions: tt.Options})
 
-			assert.Equal(t, tt.ExpectedString, shortUrl)
+			assert.Equal(t, tt.ExpectedData, shortUrlData)
 			assert.Equal(t, tt.ExpectedErr, err)
 
 		})
 	}
 }
 
+func TestShortenUrlConflictRetry(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+
+	uc := NewUrlUsecase(mockRepo, rnd, cfg)
+	ctx := context.Background()
+
+	first := uc.generateShortUrl()
+	second := uc.generateShortUrl()
+	uc.rnd.Seed(64)
+
+	notFound := &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+
+	// the first code is taken between the lookup and the insert
+	gomock.InOrder(
+		mockRepo.EXPECT().GetOriginalUrl(ctx, first).Return("", notFound),
+		mockRepo.EXPECT().AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru/", ShortUrl: first}).Return(
+			&utils.InternalError{Code: http.StatusConflict, Message: "this shortUrl already exists"}),
+		mockRepo.EXPECT().GetOriginalUrl(ctx, second).Return("", notFound),
+		mockRepo.EXPECT().AddOriginalUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru/", ShortUrl: second}).Return(nil),
+	)
+
+	res, err := uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http://example.ru"})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ShortUrlData{ShortUrl: "http://localhost:8080/" + second}, res)
+}
+
 func TestGetOriginalUrl(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
@@ -106,29 +193,196 @@ func TestGetOriginalUrl(t *testing.T) {
 	suffix := uc.generateShortUrl()
 
 	originalUrl := "http://example.ru"
+	fallbackUrl := "http://example.ru/soon"
+
+	windowStart := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	windowEnd := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC) }
+	month := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	destinations := []models.Destination{{Url: "http://example.ru/a", Weight: 1}, {Url: "http://example.ru/b", Weight: 1}}
+	stickyVariant := uc.pickDestination(suffix, destinations, "visitor-1")
 
 	tests := []struct {
-		Name           string
-		SetUp          func()
-		ExpectedString string
-		ExpectedErr    error
+		Name         string
+		Visitor      *models.VisitorData
+		SetUp        func()
+		ExpectedData *models.Redirect
+		ExpectedErr  error
 	}{
 		{
 			Name: "Test for successful getting original url",
 			SetUp: func() {
-				mockRepo.EXPECT().GetOriginalUrl(ctx, suffix).Return(originalUrl, nil)
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix}, nil)
+				mockRepo.EXPECT().RecordClick(ctx, suffix, -1, month).Return(nil)
 			},
-			ExpectedString: originalUrl,
-			ExpectedErr:    nil,
+			ExpectedData: &models.Redirect{Url: originalUrl},
+			ExpectedErr:  nil,
 		},
 		{
 			Name: "Test for failed getting original url",
 			SetUp: func() {
-				mockRepo.EXPECT().GetOriginalUrl(ctx, suffix).Return("",
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(nil,
 					&utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
 			},
-			ExpectedString: "",
-			ExpectedErr:    &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+			ExpectedData: nil,
+			ExpectedErr:  &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+		{
+			Name: "Test for getting original url inside activation window",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix,
+						ActivationWindow: models.ActivationWindow{NotBefore: &windowStart, NotAfter: &windowEnd}}, nil)
+				mockRepo.EXPECT().RecordClick(ctx, suffix, -1, month).Return(nil)
+			},
+			ExpectedData: &models.Redirect{Url: originalUrl},
+			ExpectedErr:  nil,
+		},
+		{
+			Name: "Test for getting original url before activation window",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix,
+						ActivationWindow: models.ActivationWindow{NotBefore: &windowEnd}}, nil)
+			},
+			ExpectedData: nil,
+			ExpectedErr:  &utils.InternalError{Code: http.StatusNotFound, Message: "this shortUrl is not active yet"},
+		},
+		{
+			Name: "Test for getting original url after activation window",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix,
+						ActivationWindow: models.ActivationWindow{NotAfter: &windowStart}}, nil)
+			},
+			ExpectedData: nil,
+			ExpectedErr:  &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has expired"},
+		},
+		{
+			Name: "Test for getting fallback url outside activation window",
+			SetUp: func() {
+				clicksLeft := 1
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix, ClicksLeft: &clicksLeft,
+						ActivationWindow: models.ActivationWindow{NotBefore: &windowEnd, FallbackUrl: fallbackUrl}}, nil)
+			},
+			ExpectedData: &models.Redirect{Url: fallbackUrl},
+			ExpectedErr:  nil,
+		},
+		{
+			Name:    "Test for getting targeted url",
+			Visitor: &models.VisitorData{UserAgent: uaIPhone},
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix,
+						Rules: []models.TargetingRule{{OS: "ios", TargetUrl: "https://apps.apple.com/app"}}}, nil)
+				mockRepo.EXPECT().RecordClick(ctx, suffix, -1, month).Return(nil)
+			},
+			ExpectedData: &models.Redirect{Url: "https://apps.apple.com/app"},
+			ExpectedErr:  nil,
+		},
+		{
+			Name:    "Test for getting original url when no rule matches",
+			Visitor: &models.VisitorData{UserAgent: uaWindows},
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix,
+						Rules: []models.TargetingRule{{OS: "ios", TargetUrl: "https://apps.apple.com/app"}}}, nil)
+				mockRepo.EXPECT().RecordClick(ctx, suffix, -1, month).Return(nil)
+			},
+			ExpectedData: &models.Redirect{Url: originalUrl},
+			ExpectedErr:  nil,
+		},
+		{
+			Name: "Test for getting click-limited original url",
+			SetUp: func() {
+				clicksLeft := 2
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix, ClicksLeft: &clicksLeft}, nil)
+				mockRepo.EXPECT().DecrementClicks(ctx, suffix).Return(1, nil)
+				mockRepo.EXPECT().RecordClick(ctx, suffix, -1, month).Return(nil)
+			},
+			ExpectedData: &models.Redirect{Url: originalUrl},
+			ExpectedErr:  nil,
+		},
+		{
+			Name:    "Test for getting sticky split destination",
+			Visitor: &models.VisitorData{VisitorID: "visitor-1"},
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix, Destinations: destinations}, nil)
+				mockRepo.EXPECT().RecordClick(ctx, suffix, stickyVariant, month).Return(nil)
+			},
+			ExpectedData: &models.Redirect{Url: destinations[stickyVariant].Url},
+			ExpectedErr:  nil,
+		},
+		{
+			Name: "Test for getting original url when recording click fails",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix}, nil)
+				mockRepo.EXPECT().RecordClick(ctx, suffix, -1, month).Return(fmt.Errorf("some bd error"))
+			},
+			ExpectedData: &models.Redirect{Url: originalUrl},
+			ExpectedErr:  nil,
+		},
+		{
+			Name:    "Test for getting original url with query options",
+			Visitor: &models.VisitorData{Query: url.Values{"ref": {"x"}}},
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: "http://example.ru/?a=1#top", ShortUrl: suffix,
+						QueryOptions: models.QueryOptions{UtmSource: "newsletter", PassQuery: true}}, nil)
+				mockRepo.EXPECT().RecordClick(ctx, suffix, -1, month).Return(nil)
+			},
+			ExpectedData: &models.Redirect{Url: "http://example.ru/?a=1&ref=x&utm_source=newsletter#top"},
+			ExpectedErr:  nil,
+		},
+		{
+			Name:    "Test for getting fallback url with query options",
+			Visitor: &models.VisitorData{Query: url.Values{"ref": {"x"}}},
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix,
+						ActivationWindow: models.ActivationWindow{NotBefore: &windowEnd, FallbackUrl: fallbackUrl},
+						QueryOptions:     models.QueryOptions{PassQuery: true}}, nil)
+			},
+			ExpectedData: &models.Redirect{Url: fallbackUrl + "?ref=x"},
+			ExpectedErr:  nil,
+		},
+		{
+			Name: "Test for getting interstitial original url",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix, Interstitial: true}, nil)
+				mockRepo.EXPECT().RecordClick(ctx, suffix, -1, month).Return(nil)
+			},
+			ExpectedData: &models.Redirect{Url: originalUrl, Interstitial: true, Delay: 5},
+			ExpectedErr:  nil,
+		},
+		{
+			Name: "Test for getting exhausted original url",
+			SetUp: func() {
+				clicksLeft := 0
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix, ClicksLeft: &clicksLeft}, nil)
+			},
+			ExpectedData: nil,
+			ExpectedErr:  &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has reached its click limit"},
+		},
+		{
+			Name: "Test for losing the race for the last click",
+			SetUp: func() {
+				clicksLeft := 1
+				mockRepo.EXPECT().GetUrlData(ctx, suffix).Return(
+					&models.UrlData{OriginalUrl: originalUrl, ShortUrl: suffix, ClicksLeft: &clicksLeft}, nil)
+				mockRepo.EXPECT().DecrementClicks(ctx, suffix).Return(0,
+					&utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has reached its click limit"})
+			},
+			ExpectedData: nil,
+			ExpectedErr:  &utils.InternalError{Code: http.StatusGone, Message: "this shortUrl has reached its click limit"},
 		},
 	}
 
@@ -137,11 +391,488 @@ func TestGetOriginalUrl(t *testing.T) {
 
 			tt.SetUp()
 
-			originalUrl, err := uc.GetOriginalUrl(ctx, suffix)
+			redirect, err := uc.GetOriginalUrl(ctx, suffix, tt.Visitor)
 
-			assert.Equal(t, tt.ExpectedString, originalUrl)
+			assert.Equal(t, tt.ExpectedData, redirect)
 			assert.Equal(t, tt.ExpectedErr, err)
 
 		})
 	}
 }
+
+func TestGetOriginalUrlOneTimeConcurrent(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+
+	uc := NewUrlUsecase(localrepo.NewUrlRepository(), rnd, cfg)
+	ctx := context.Background()
+
+	suffix := uc.generateShortUrl()
+	uc.rnd.Seed(64)
+
+	_, err := uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http://example.ru", MaxClicks: 1})
+	assert.NoError(t, err)
+
+	const resolutions = 100
+
+	var wg sync.WaitGroup
+	results := make(chan error, resolutions)
+
+	for i := 0; i < resolutions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := uc.GetOriginalUrl(ctx, suffix, &models.VisitorData{})
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		var interr *utils.InternalError
+		assert.True(t, errors.As(err, &interr))
+		assert.Equal(t, http.StatusGone, interr.Code)
+	}
+
+	assert.Equal(t, 1, succeeded)
+}
+
+func TestGetOriginalUrlWindowConfig(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Links.NotYetActiveStatus, cfg.Links.NotYetActiveMessage = http.StatusForbidden, "coming soon"
+	cfg.Links.ExpiredStatus, cfg.Links.ExpiredMessage = http.StatusNotFound, "campaign is over"
+
+	uc := NewUrlUsecase(mockRepo, rnd, cfg)
+	uc.now = func() time.Time { return time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	windowStart := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	windowEnd := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{OriginalUrl: "http://example.ru",
+		ActivationWindow: models.ActivationWindow{NotBefore: &windowEnd}}, nil)
+	mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gt").Return(&models.UrlData{OriginalUrl: "http://example.ru",
+		ActivationWindow: models.ActivationWindow{NotAfter: &windowStart}}, nil)
+
+	_, err := uc.GetOriginalUrl(ctx, "Abc_def_gs", &models.VisitorData{})
+	assert.Equal(t, &utils.InternalError{Code: http.StatusForbidden, Message: "coming soon"}, err)
+
+	_, err = uc.GetOriginalUrl(ctx, "Abc_def_gt", &models.VisitorData{})
+	assert.Equal(t, &utils.InternalError{Code: http.StatusNotFound, Message: "campaign is over"}, err)
+}
+
+func TestGetOriginalUrlInterstitialDelay(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Links.InterstitialDelay = 10
+
+	uc := NewUrlUsecase(mockRepo, rnd, cfg)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{OriginalUrl: "http://example.ru",
+		ShortUrl: "Abc_def_gs", Interstitial: true}, nil)
+	mockRepo.EXPECT().RecordClick(ctx, "Abc_def_gs", -1, gomock.Any()).Return(nil)
+
+	redirect, err := uc.GetOriginalUrl(ctx, "Abc_def_gs", &models.VisitorData{})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Redirect{Url: "http://example.ru", Interstitial: true, Delay: 10}, redirect)
+}
+
+func TestGetPreview(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+
+	uc := NewUrlUsecase(mockRepo, rnd, cfg)
+	ctx := context.Background()
+
+	clicksLeft := 1
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name            string
+		SetUp           func()
+		ExpectedPreview *models.PreviewData
+		ExpectedErr     error
+	}{
+		{
+			Name: "Test for successful getting preview",
+			SetUp: func() {
+				// previewing a one-time link must not consume its click
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{OriginalUrl: "https://www.example.ru:8443/a?b=c",
+					ShortUrl: "Abc_def_gs", ClicksLeft: &clicksLeft, CreatedAt: createdAt}, nil)
+				mockRepo.EXPECT().GetClicks(ctx, "Abc_def_gs").Return(&models.ClickData{Clicks: 42}, nil)
+			},
+			ExpectedPreview: &models.PreviewData{ShortUrl: "http://localhost:8080/Abc_def_gs",
+				OriginalUrl: "https://www.example.ru:8443/a?b=c", Host: "www.example.ru", CreatedAt: createdAt, Clicks: 42},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "Test for failed getting preview",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(nil,
+					&utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+			},
+			ExpectedPreview: nil,
+			ExpectedErr:     &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+		{
+			Name: "Test for failed getting clicks",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{OriginalUrl: "http://example.ru",
+					ShortUrl: "Abc_def_gs"}, nil)
+				mockRepo.EXPECT().GetClicks(ctx, "Abc_def_gs").Return(nil, context.DeadlineExceeded)
+			},
+			ExpectedPreview: nil,
+			ExpectedErr:     context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp()
+
+			preview, err := uc.GetPreview(ctx, "Abc_def_gs")
+
+			assert.Equal(t, tt.ExpectedPreview, preview)
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+}
+
+func TestGetLink(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+
+	uc := NewUrlUsecase(mockRepo, rnd, cfg)
+	ctx := context.Background()
+
+	clicksLeft := 1
+	notAfter := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	window := models.ActivationWindow{NotAfter: &notAfter}
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name         string
+		SetUp        func()
+		ExpectedLink *models.LinkData
+		ExpectedErr  error
+	}{
+		{
+			Name: "Test for successful getting link",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{OriginalUrl: "http://example.ru",
+					ShortUrl: "Abc_def_gs", ClicksLeft: &clicksLeft, ActivationWindow: window, Interstitial: true,
+					CreatedAt: createdAt}, nil)
+			},
+			ExpectedLink: &models.LinkData{ShortUrl: "http://localhost:8080/Abc_def_gs", OriginalUrl: "http://example.ru",
+				ClicksLeft: &clicksLeft, ActivationWindow: window, Interstitial: true, CreatedAt: createdAt},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "Test for failed getting link",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(nil,
+					&utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+			},
+			ExpectedLink: nil,
+			ExpectedErr:  &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp()
+
+			link, err := uc.GetLink(ctx, "Abc_def_gs")
+
+			assert.Equal(t, tt.ExpectedLink, link)
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+}
+
+func TestDeleteLink(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	uc := NewUrlUsecase(mockRepo, rnd, &bootstrap.Config{})
+	ctx := context.Background()
+
+	notFound := &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+
+	tests := []struct {
+		Name        string
+		Owner       string
+		SetUp       func()
+		ExpectedErr error
+	}{
+		{
+			Name: "Test for successful deleting link",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{ShortUrl: "Abc_def_gs",
+					Owner: "team"}, nil)
+				mockRepo.EXPECT().DeleteUrl(ctx, "Abc_def_gs").Return(nil)
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Name:  "Test for successful deleting own link",
+			Owner: "team",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{ShortUrl: "Abc_def_gs",
+					Owner: "team"}, nil)
+				mockRepo.EXPECT().DeleteUrl(ctx, "Abc_def_gs").Return(nil)
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Name:  "Test for failed deleting link of another owner",
+			Owner: "team",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{ShortUrl: "Abc_def_gs",
+					Owner: "other"}, nil)
+			},
+			ExpectedErr: notFound,
+		},
+		{
+			Name: "Test for failed deleting missing link",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(nil, notFound)
+			},
+			ExpectedErr: notFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp()
+
+			err := uc.DeleteLink(ctx, "Abc_def_gs", tt.Owner)
+
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+}
+
+func TestUpdateWindow(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	uc := NewUrlUsecase(mockRepo, rnd, &bootstrap.Config{})
+	ctx := context.Background()
+
+	windowStart := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	windowEnd := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name        string
+		Window      models.ActivationWindow
+		SetUp       func(window *models.ActivationWindow)
+		ExpectedErr error
+	}{
+		{
+			Name:   "Test for successful updating window",
+			Window: models.ActivationWindow{NotBefore: &windowStart, NotAfter: &windowEnd},
+			SetUp: func(window *models.ActivationWindow) {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{ShortUrl: "Abc_def_gs"}, nil)
+				mockRepo.EXPECT().UpdateWindow(ctx, "Abc_def_gs", window).Return(nil)
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Name:   "Test for inverted window",
+			Window: models.ActivationWindow{NotBefore: &windowEnd, NotAfter: &windowStart},
+			SetUp: func(window *models.ActivationWindow) {
+			},
+			ExpectedErr: &utils.InternalError{Code: http.StatusBadRequest, Message: "not_after must be later than not_before"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp(&tt.Window)
+
+			err := uc.UpdateWindow(ctx, "Abc_def_gs", &tt.Window)
+
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+}
+
+func TestUpdateRules(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	uc := NewUrlUsecase(mockRepo, rnd, &bootstrap.Config{})
+	ctx := context.Background()
+
+	tests := []struct {
+		Name        string
+		Rules       []models.TargetingRule
+		SetUp       func(rules []models.TargetingRule)
+		ExpectedErr error
+	}{
+		{
+			Name:  "Test for successful updating rules",
+			Rules: []models.TargetingRule{{OS: "android", TargetUrl: "https://play.google.com/app"}},
+			SetUp: func(rules []models.TargetingRule) {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{ShortUrl: "Abc_def_gs"}, nil)
+				mockRepo.EXPECT().UpdateRules(ctx, "Abc_def_gs", rules).Return(nil)
+			},
+			ExpectedErr: nil,
+		},
+		{
+			Name:  "Test for rule without conditions",
+			Rules: []models.TargetingRule{{TargetUrl: "https://play.google.com/app"}},
+			SetUp: func(rules []models.TargetingRule) {
+			},
+			ExpectedErr: &utils.InternalError{Code: http.StatusBadRequest, Message: "targeting rule must have at least one condition"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp(tt.Rules)
+
+			err := uc.UpdateRules(ctx, "Abc_def_gs", tt.Rules)
+
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+}
+
+func TestGetStats(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	rnd := rand.New(rand.NewSource(64))
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+
+	uc := NewUrlUsecase(mockRepo, rnd, cfg)
+	ctx := context.Background()
+
+	destinations := []models.Destination{{Url: "http://example.ru/a", Weight: 1}, {Url: "http://example.ru/b", Weight: 3}}
+
+	tests := []struct {
+		Name          string
+		SetUp         func()
+		ExpectedStats *models.LinkStats
+		ExpectedErr   error
+	}{
+		{
+			Name: "Test for successful getting split stats",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{OriginalUrl: "http://example.ru",
+					ShortUrl: "Abc_def_gs", Destinations: destinations}, nil)
+				mockRepo.EXPECT().GetClicks(ctx, "Abc_def_gs").Return(&models.ClickData{Clicks: 4,
+					VariantClicks: map[int]int64{1: 4}}, nil)
+			},
+			ExpectedStats: &models.LinkStats{ShortUrl: "http://localhost:8080/Abc_def_gs", Clicks: 4,
+				Variants: []models.VariantStats{
+					{Url: "http://example.ru/a", Weight: 1, Clicks: 0},
+					{Url: "http://example.ru/b", Weight: 3, Clicks: 4},
+				}},
+			ExpectedErr: nil,
+		},
+		{
+			Name: "Test for successful getting plain stats",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{OriginalUrl: "http://example.ru",
+					ShortUrl: "Abc_def_gs"}, nil)
+				mockRepo.EXPECT().GetClicks(ctx, "Abc_def_gs").Return(&models.ClickData{Clicks: 2,
+					VariantClicks: map[int]int64{}}, nil)
+			},
+			ExpectedStats: &models.LinkStats{ShortUrl: "http://localhost:8080/Abc_def_gs", Clicks: 2},
+			ExpectedErr:   nil,
+		},
+		{
+			Name: "Test for failed getting stats",
+			SetUp: func() {
+				mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(nil,
+					&utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"})
+			},
+			ExpectedStats: nil,
+			ExpectedErr:   &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			tt.SetUp()
+
+			stats, err := uc.GetStats(ctx, "Abc_def_gs")
+
+			assert.Equal(t, tt.ExpectedStats, stats)
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+// Reasons of the errors rejecting links over the quota of their owner.
+const (
+	ReasonActiveLinksQuota        = "active_links_quota"
+	ReasonMonthlyCreationsQuota   = "monthly_creations_quota"
+	ReasonMonthlyResolutionsQuota = "monthly_resolutions_quota"
+)
+
+// usageMonth returns the first instant of the calendar month of t in UTC,
+// which monthly usage is counted in.
+func usageMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// quota returns the limits of owner: its own ones if configured, and the
+// default ones otherwise.
+func (uc *UrlUsecase) quota(owner string) models.Quota {
+	limits := uc.cfg.Quotas.Default
+	for _, ownerQuota := range uc.cfg.Quotas.Owners {
+		if ownerQuota.Owner == owner {
+			limits = ownerQuota.QuotaLimits
+			break
+		}
+	}
+	return models.Quota{MaxActiveLinks: limits.MaxActiveLinks, MonthlyCreations: limits.MonthlyCreations,
+		MonthlyResolutions: limits.MonthlyResolutions}
+}
+
+// checkQuota returns a check rejecting a new link of owner when its usage has
+// reached one of the limits of quota. Reaching the number of active links is
+// forbidden until some are deleted, while the monthly limits are lifted in
+// the next month.
+func checkQuota(owner string, quota models.Quota) func(*models.Usage) error {
+	return func(usage *models.Usage) error {
+		if err := checkActiveLinks(owner, quota)(usage); err != nil {
+			return err
+		}
+		if quota.MonthlyCreations > 0 && usage.Creations >= quota.MonthlyCreations {
+			return &utils.InternalError{Code: http.StatusTooManyRequests, Reason: ReasonMonthlyCreationsQuota,
+				Message: fmt.Sprintf("owner %q has reached its quota of %d links created this month",
+					owner, quota.MonthlyCreations)}
+		}
+		if quota.MonthlyResolutions > 0 && usage.Resolutions >= quota.MonthlyResolutions {
+			return &utils.InternalError{Code: http.StatusTooManyRequests, Reason: ReasonMonthlyResolutionsQuota,
+				Message: fmt.Sprintf("owner %q has reached its quota of %d link resolutions this month",
+					owner, quota.MonthlyResolutions)}
+		}
+		return nil
+	}
+}
+
+// checkActiveLinks returns a check rejecting a link of owner becoming active
+// when its usage has reached the number of active links of quota.
+func checkActiveLinks(owner string, quota models.Quota) func(*models.Usage) error {
+	return func(usage *models.Usage) error {
+		if quota.MaxActiveLinks > 0 && usage.ActiveLinks >= quota.MaxActiveLinks {
+			return &utils.InternalError{Code: http.StatusForbidden, Reason: ReasonActiveLinksQuota,
+				Message: fmt.Sprintf("owner %q has reached its quota of %d active links", owner, quota.MaxActiveLinks)}
+		}
+		return nil
+	}
+}
+
+// creationCheck checks a new link against the quota of the owner of usage,
+// for the repository calls adding links of several owners.
+func (uc *UrlUsecase) creationCheck(usage *models.Usage) error {
+	return checkQuota(usage.Owner, uc.quota(usage.Owner))(usage)
+}
+
+// restoreCheck checks a restored link against the active links quota of the
+// owner of usage. Restoring a link is not a creation, so the monthly limits
+// don't apply.
+func (uc *UrlUsecase) restoreCheck(usage *models.Usage) error {
+	return checkActiveLinks(usage.Owner, uc.quota(usage.Owner))(usage)
+}
+
+// addUrl adds a new link. Links with an owner are added within its quota and
+// counted in its usage.
+func (uc *UrlUsecase) addUrl(ctx context.Context, data *models.UrlData) error {
+	if data.Owner == "" {
+		return uc.Repo.AddOriginalUrl(ctx, data)
+	}
+	return uc.Repo.AddOwnedUrl(ctx, data, usageMonth(uc.now()), checkQuota(data.Owner, uc.quota(data.Owner)))
+}
+
+// GetUsage reports the usage of owner in the current month along with its
+// quota.
+func (uc *UrlUsecase) GetUsage(ctx context.Context, owner string) (*models.UsageReport, error) {
+
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "owner is required")
+	}
+	if err := authorize(ctx, owner, auth.RoleViewer, errNoWorkspace); err != nil {
+		return nil, err
+	}
+
+	month := usageMonth(uc.now())
+	usage, err := uc.Repo.GetUsage(ctx, owner, month)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UsageReport{Owner: owner, Month: month.Format("2006-01"), ActiveLinks: usage.ActiveLinks,
+		Creations: usage.Creations, Resolutions: usage.Resolutions, Quota: uc.quota(owner)}, nil
+}
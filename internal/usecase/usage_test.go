@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func quotaConfig() *bootstrap.Config {
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+	cfg.Quotas.Default = bootstrap.QuotaLimits{MaxActiveLinks: 100, MonthlyCreations: 50, MonthlyResolutions: 1000}
+	cfg.Quotas.Owners = []bootstrap.OwnerQuota{{Owner: "big", QuotaLimits: bootstrap.QuotaLimits{MonthlyCreations: 500}}}
+	return cfg
+}
+
+func TestCheckQuota(t *testing.T) {
+
+	quota := models.Quota{MaxActiveLinks: 100, MonthlyCreations: 50, MonthlyResolutions: 1000}
+
+	tests := []struct {
+		Name      string
+		Quota     models.Quota
+		Usage     models.Usage
+		ExpectErr error
+	}{
+		{
+			Name:  "usage within quota",
+			Quota: quota,
+			Usage: models.Usage{ActiveLinks: 99, Creations: 49, Resolutions: 999},
+		},
+		{
+			Name:  "no limits",
+			Usage: models.Usage{ActiveLinks: 1000, Creations: 1000, Resolutions: 100000},
+		},
+		{
+			Name:  "active links reached",
+			Quota: quota,
+			Usage: models.Usage{ActiveLinks: 100, Creations: 50},
+			ExpectErr: &utils.InternalError{Code: http.StatusForbidden, Reason: ReasonActiveLinksQuota,
+				Message: `owner "team" has reached its quota of 100 active links`},
+		},
+		{
+			Name:  "monthly creations reached",
+			Quota: quota,
+			Usage: models.Usage{ActiveLinks: 20, Creations: 50},
+			ExpectErr: &utils.InternalError{Code: http.StatusTooManyRequests, Reason: ReasonMonthlyCreationsQuota,
+				Message: `owner "team" has reached its quota of 50 links created this month`},
+		},
+		{
+			Name:  "monthly resolutions reached",
+			Quota: quota,
+			Usage: models.Usage{ActiveLinks: 20, Creations: 10, Resolutions: 1200},
+			ExpectErr: &utils.InternalError{Code: http.StatusTooManyRequests, Reason: ReasonMonthlyResolutionsQuota,
+				Message: `owner "team" has reached its quota of 1000 link resolutions this month`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.ExpectErr, checkQuota("team", tt.Quota)(&tt.Usage))
+		})
+	}
+}
+
+func TestShortenUrlWithinQuota(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), quotaConfig())
+	uc.now = func() time.Time { return time.Date(2026, time.October, 19, 12, 0, 0, 0, time.FixedZone("MSK", 3*3600)) }
+	ctx := context.Background()
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	code := uc.generateShortUrl()
+	uc.rnd.Seed(64)
+
+	notFound := &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+	mockRepo.EXPECT().GetOriginalUrl(ctx, code).Return("", notFound).Times(2)
+	gomock.InOrder(
+		mockRepo.EXPECT().AddOwnedUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru/", ShortUrl: code,
+			Owner: "team"}, month, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, data *models.UrlData, month time.Time, check func(*models.Usage) error) error {
+				return check(&models.Usage{Owner: "team", Month: month, ActiveLinks: 99, Creations: 10})
+			}),
+		mockRepo.EXPECT().AddOwnedUrl(ctx, &models.UrlData{OriginalUrl: "http://example.ru/", ShortUrl: code,
+			Owner: "team"}, month, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, data *models.UrlData, month time.Time, check func(*models.Usage) error) error {
+				return check(&models.Usage{Owner: "team", Month: month, ActiveLinks: 100, Creations: 10})
+			}),
+	)
+
+	res, err := uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http://example.ru", Owner: "team"})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ShortUrlData{ShortUrl: "http://localhost:8080/" + code}, res)
+
+	uc.rnd.Seed(64)
+	_, err = uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http://example.ru", Owner: "team"})
+	assert.Equal(t, &utils.InternalError{Code: http.StatusForbidden, Reason: ReasonActiveLinksQuota,
+		Message: `owner "team" has reached its quota of 100 active links`}, err)
+}
+
+func TestImportWithinQuota(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), quotaConfig())
+	uc.now = func() time.Time { return time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	input := "code,url,owner\n" +
+		"abc,https://example.com/a,team\n" +
+		"def,https://example.com/b,big\n" +
+		"ghi,https://example.com/c,team\n"
+
+	overQuota := &utils.InternalError{Code: http.StatusTooManyRequests, Reason: ReasonMonthlyCreationsQuota,
+		Message: `owner "team" has reached its quota of 50 links created this month`}
+
+	// the repository checks the links of each owner against its own quota
+	mockRepo.EXPECT().ImportLinks(ctx, gomock.Len(3), month, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, links []*models.UrlData, month time.Time,
+			check func(*models.Usage) error) (map[string]error, error) {
+			skipped := make(map[string]error)
+			for _, link := range links {
+				if err := check(&models.Usage{Owner: link.Owner, Month: month, Creations: 50}); err != nil {
+					skipped[link.ShortUrl] = err
+				}
+			}
+			return skipped, nil
+		})
+
+	var importErrors []models.ImportError
+	report, err := uc.Import(ctx, strings.NewReader(input), &models.ImportOptions{Format: "csv"},
+		func(importError *models.ImportError) {
+			importErrors = append(importErrors, *importError)
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ImportReport{Processed: 3, Imported: 1, OverQuota: 2, LastLine: 4}, report)
+	assert.Equal(t, []models.ImportError{
+		{Line: 2, Code: "abc", Reason: ReasonMonthlyCreationsQuota, Message: overQuota.Message},
+		{Line: 4, Code: "ghi", Reason: ReasonMonthlyCreationsQuota, Message: overQuota.Message},
+	}, importErrors)
+
+	// a dry run counts the links it checks as created
+	mockRepo.EXPECT().ExistingCodes(ctx, []string{"abc", "def", "ghi"}).Return(nil, nil)
+	mockRepo.EXPECT().GetUsage(ctx, "team", month).Return(&models.Usage{Owner: "team", Month: month,
+		ActiveLinks: 99, Creations: 10}, nil)
+	mockRepo.EXPECT().GetUsage(ctx, "big", month).Return(&models.Usage{Owner: "big", Month: month,
+		ActiveLinks: 1000, Creations: 10}, nil)
+
+	importErrors = nil
+	report, err = uc.Import(ctx, strings.NewReader(input), &models.ImportOptions{Format: "csv", DryRun: true},
+		func(importError *models.ImportError) {
+			importErrors = append(importErrors, *importError)
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ImportReport{DryRun: true, Processed: 3, Imported: 2, OverQuota: 1, LastLine: 4}, report)
+	assert.Equal(t, []models.ImportError{{Line: 4, Code: "ghi", Reason: ReasonActiveLinksQuota,
+		Message: `owner "team" has reached its quota of 100 active links`}}, importErrors)
+}
+
+func TestRestoreLinkWithinQuota(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), quotaConfig())
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	restore := func(usage models.Usage) {
+		mockRepo.EXPECT().RestoreUrl(ctx, "Abc_def_gs", "team", gomock.Any(), month, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, shortUrl, owner string, deletedAfter, month time.Time,
+				check func(*models.Usage) error) error {
+				return check(&usage)
+			})
+	}
+
+	// restoring is not a creation, so only the active links count
+	restore(models.Usage{Owner: "team", Month: month, ActiveLinks: 99, Creations: 50})
+	mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(&models.UrlData{ShortUrl: "Abc_def_gs",
+		OriginalUrl: "http://ya.ru", Owner: "team"}, nil)
+	_, err := uc.RestoreLink(ctx, "Abc_def_gs", "team")
+	assert.NoError(t, err)
+
+	restore(models.Usage{Owner: "team", Month: month, ActiveLinks: 100})
+	_, err = uc.RestoreLink(ctx, "Abc_def_gs", "team")
+	assert.Equal(t, &utils.InternalError{Code: http.StatusForbidden, Reason: ReasonActiveLinksQuota,
+		Message: `owner "team" has reached its quota of 100 active links`}, err)
+}
+
+func TestGetUsage(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), quotaConfig())
+	uc.now = func() time.Time { return time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+	month := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().GetUsage(ctx, "team", month).Return(&models.Usage{Owner: "team", Month: month,
+		ActiveLinks: 3, Creations: 5, Resolutions: 120}, nil)
+	res, err := uc.GetUsage(ctx, " team")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.UsageReport{Owner: "team", Month: "2026-10", ActiveLinks: 3, Creations: 5,
+		Resolutions: 120, Quota: models.Quota{MaxActiveLinks: 100, MonthlyCreations: 50, MonthlyResolutions: 1000}}, res)
+
+	// the limits of an owner replace the default ones
+	mockRepo.EXPECT().GetUsage(ctx, "big", month).Return(&models.Usage{Owner: "big", Month: month}, nil)
+	res, err = uc.GetUsage(ctx, "big")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Quota{MonthlyCreations: 500}, res.Quota)
+
+	_, err = uc.GetUsage(ctx, " ")
+	assert.Equal(t, utils.NewInternalError(http.StatusBadRequest, "owner is required"), err)
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+const defaultDeadLetterLimit = 100
+
+// CreateWebhook subscribes the url of subscription to the events of the
+// links of its owner. The url is screened by the destination policy like the
+// destinations of links, so webhooks can't reach the private network of the
+// service. Without a secret, a random one is generated; the secret is only
+// returned here.
+func (uc *UrlUsecase) CreateWebhook(ctx context.Context,
+	subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+
+	if err := authorize(ctx, subscription.Owner, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(strings.TrimSpace(subscription.Url))
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "webhook url must be an absolute http or https url")
+	}
+	if err := uc.checkPolicy(ctx, u, "webhook url is not allowed"); err != nil {
+		return nil, err
+	}
+
+	res := &models.WebhookSubscription{Url: u.String(), Secret: subscription.Secret, Owner: subscription.Owner}
+	if res.Secret == "" {
+		res.Secret = rand.Text()
+	}
+	for _, event := range subscription.Events {
+		if !slices.Contains(res.Events, event) {
+			res.Events = append(res.Events, event)
+		}
+	}
+	for _, threshold := range subscription.ClickThresholds {
+		if !slices.Contains(res.ClickThresholds, threshold) {
+			res.ClickThresholds = append(res.ClickThresholds, threshold)
+		}
+	}
+	slices.Sort(res.ClickThresholds)
+
+	if err := uc.Repo.CreateWebhook(ctx, res); err != nil {
+		return nil, err
+	}
+
+	recorded := *res
+	recorded.Secret = ""
+	uc.record(ctx, audit.WebhookCreate, strconv.FormatInt(res.ID, 10), nil, &recorded)
+	return res, nil
+}
+
+// ListWebhooks returns the subscriptions of owner without their secrets, or
+// every subscription when owner is empty.
+func (uc *UrlUsecase) ListWebhooks(ctx context.Context, owner string) (*models.WebhookList, error) {
+
+	if err := authorize(ctx, owner, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := uc.Repo.ListWebhooks(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.WebhookList{Webhooks: make([]models.WebhookSubscription, 0, len(webhooks))}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+		res.Webhooks = append(res.Webhooks, *webhook)
+	}
+	return res, nil
+}
+
+// DeleteWebhook deletes a subscription with its pending deliveries. A
+// non-empty owner may only delete its own subscriptions.
+func (uc *UrlUsecase) DeleteWebhook(ctx context.Context, id int64, owner string) error {
+
+	if err := authorize(ctx, owner, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return err
+	}
+
+	if err := uc.Repo.DeleteWebhook(ctx, id, owner); err != nil {
+		return err
+	}
+	uc.record(ctx, audit.WebhookDelete, strconv.FormatInt(id, 10), nil, nil)
+	return nil
+}
+
+// ListDeadLetters returns up to limit deliveries that ran out of attempts,
+// 100 if limit is zero, of the subscriptions of owner or of all
+// subscriptions when owner is empty.
+func (uc *UrlUsecase) ListDeadLetters(ctx context.Context, owner string, limit int) (*models.DeadLetterList, error) {
+
+	if err := authorize(ctx, owner, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return nil, err
+	}
+
+	if limit == 0 {
+		limit = defaultDeadLetterLimit
+	}
+
+	deadLetters, err := uc.Repo.ListDeadLetters(ctx, owner, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.DeadLetterList{DeadLetters: make([]models.WebhookDelivery, 0, len(deadLetters))}
+	for _, deadLetter := range deadLetters {
+		res.DeadLetters = append(res.DeadLetters, *deadLetter)
+	}
+	return res, nil
+}
+
+// RetryDeadLetter schedules a dead letter for immediate delivery with a fresh
+// set of attempts. A non-empty owner may only retry the dead letters of its
+// own subscriptions.
+func (uc *UrlUsecase) RetryDeadLetter(ctx context.Context, id int64, owner string) error {
+
+	if err := authorize(ctx, owner, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return err
+	}
+
+	if err := uc.Repo.RetryDeadLetter(ctx, id, owner, uc.now()); err != nil {
+		return err
+	}
+	uc.record(ctx, audit.DeadLetterRetry, strconv.FormatInt(id, 10), nil, nil)
+	return nil
+}
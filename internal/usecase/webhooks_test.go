@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/policy"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhook(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+	ctx := context.Background()
+
+	mockRepo.EXPECT().CreateWebhook(ctx, &models.WebhookSubscription{Url: "https://example.com/hook",
+		Secret: "0123456789abcdef", Events: []string{"link.created", "link.clicks_threshold"},
+		ClickThresholds: []int64{10, 100}, Owner: "team"}).DoAndReturn(
+		func(ctx context.Context, subscription *models.WebhookSubscription) error {
+			subscription.ID = 7
+			return nil
+		})
+
+	res, err := uc.CreateWebhook(ctx, &models.WebhookSubscription{Url: " https://example.com/hook",
+		Secret: "0123456789abcdef", Events: []string{"link.created", "link.clicks_threshold", "link.created"},
+		ClickThresholds: []int64{100, 10, 100}, Owner: "team"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), res.ID)
+	assert.Equal(t, "0123456789abcdef", res.Secret)
+
+	// a secret is generated when none is given
+	mockRepo.EXPECT().CreateWebhook(ctx, gomock.Any()).Return(nil)
+	res, err = uc.CreateWebhook(ctx, &models.WebhookSubscription{Url: "http://example.com/hook",
+		Events: []string{"link.deleted"}})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, len(res.Secret), 16)
+
+	_, err = uc.CreateWebhook(ctx, &models.WebhookSubscription{Url: "ftp://example.com/hook",
+		Events: []string{"link.deleted"}})
+	assert.Equal(t, utils.NewInternalError(http.StatusBadRequest, "webhook url must be an absolute http or https url"), err)
+
+	// webhooks can't target the private network of the service
+	for _, hookUrl := range []string{"http://127.0.0.1:5432/", "http://169.254.169.254/latest/meta-data",
+		"http://localhost:8080/hook", "https://10.0.0.7/hook", "http://[::1]/hook"} {
+		_, err = uc.CreateWebhook(ctx, &models.WebhookSubscription{Url: hookUrl, Events: []string{"link.deleted"}})
+		assert.Equal(t, &utils.InternalError{Code: http.StatusUnprocessableEntity, Message: "webhook url is not allowed",
+			Reason: policy.ReasonPrivateAddress}, err, hookUrl)
+	}
+}
+
+func TestListWebhooks(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+	ctx := context.Background()
+
+	mockRepo.EXPECT().ListWebhooks(ctx, "team").Return([]*models.WebhookSubscription{{ID: 7,
+		Url: "https://example.com/hook", Secret: "0123456789abcdef", Events: []string{"link.created"}, Owner: "team"}}, nil)
+	res, err := uc.ListWebhooks(ctx, "team")
+	require.NoError(t, err)
+	assert.Equal(t, &models.WebhookList{Webhooks: []models.WebhookSubscription{{ID: 7, Url: "https://example.com/hook",
+		Events: []string{"link.created"}, Owner: "team"}}}, res)
+
+	mockRepo.EXPECT().ListWebhooks(ctx, "").Return(nil, nil)
+	res, err = uc.ListWebhooks(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, &models.WebhookList{Webhooks: []models.WebhookSubscription{}}, res)
+}
+
+func TestDeadLetters(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	uc := NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), &bootstrap.Config{})
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	mockRepo.EXPECT().ListDeadLetters(ctx, "team", defaultDeadLetterLimit).Return([]*models.WebhookDelivery{{ID: 3,
+		EventType: "link.deleted", Attempts: 10, LastError: "status 500"}}, nil)
+	res, err := uc.ListDeadLetters(ctx, "team", 0)
+	require.NoError(t, err)
+	assert.Equal(t, &models.DeadLetterList{DeadLetters: []models.WebhookDelivery{{ID: 3, EventType: "link.deleted",
+		Attempts: 10, LastError: "status 500"}}}, res)
+
+	mockRepo.EXPECT().RetryDeadLetter(ctx, int64(3), "team", now).Return(nil)
+	assert.NoError(t, uc.RetryDeadLetter(ctx, 3, "team"))
+}
@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AlexNov03/UrlShortener/internal/audit"
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/utils"
+)
+
+func errNoLink() error {
+	return utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")
+}
+
+func errNoWorkspace() error {
+	return utils.NewInternalError(http.StatusNotFound, "no workspace match this slug")
+}
+
+// authorize checks that the principal of ctx may act with role in
+// workspace. The resources of other workspaces are reported with notFound, so
+// keys can't tell them from missing ones. Calls without a principal come from
+// trusted callers, such as the command line tools, and are always allowed.
+func authorize(ctx context.Context, workspace, role string, notFound func() error) error {
+	principal := auth.FromContext(ctx)
+	if principal == nil || principal.Allows(workspace, role) {
+		return nil
+	}
+	if principal.Owner != workspace {
+		return notFound()
+	}
+	return auth.Forbidden(role)
+}
+
+// boundWorkspace returns the workspace the principal of ctx is scoped to, or
+// an empty string for unrestricted principals and calls without one.
+func boundWorkspace(ctx context.Context) string {
+	if principal := auth.FromContext(ctx); principal != nil {
+		return principal.Owner
+	}
+	return ""
+}
+
+// getLink returns the data of a link the principal of ctx may act on with
+// role.
+func (uc *UrlUsecase) getLink(ctx context.Context, shortUrl, role string) (*models.UrlData, error) {
+	data, err := uc.Repo.GetUrlData(ctx, shortUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, data.Owner, role, errNoLink); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// LookupApiKey returns the principal of the workspace key with the given
+// digest, acting with the current role of its member, or nil for unknown
+// keys. Its name is prefixed with the workspace, so keys of different
+// workspaces are told apart in the audit log.
+func (uc *UrlUsecase) LookupApiKey(ctx context.Context, digest string) (*auth.Principal, error) {
+
+	key, err := uc.Repo.FindWorkspaceKey(ctx, digest)
+	var interr *utils.InternalError
+	if errors.As(err, &interr) && interr.Code == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Principal{KeyName: key.Workspace + "/" + key.Name, Owner: key.Workspace, Member: key.Member,
+		Role: key.Role}, nil
+}
+
+// validSlug reports whether slug can name a workspace in paths and as the
+// owner of links.
+func validSlug(slug string) bool {
+	if slug == "" {
+		return false
+	}
+	for _, c := range slug {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateWorkspace creates a workspace, which only unrestricted keys may do.
+func (uc *UrlUsecase) CreateWorkspace(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error) {
+
+	if boundWorkspace(ctx) != "" {
+		return nil, utils.NewInternalError(http.StatusForbidden, "only unrestricted api keys can create workspaces")
+	}
+
+	res := &models.Workspace{Slug: strings.TrimSpace(workspace.Slug), Name: strings.TrimSpace(workspace.Name)}
+	if !validSlug(res.Slug) {
+		return nil, utils.NewInternalError(http.StatusBadRequest,
+			"slug may only contain lowercase letters, digits, '-' and '_'")
+	}
+	if err := uc.Repo.CreateWorkspace(ctx, res); err != nil {
+		return nil, err
+	}
+	uc.record(ctx, audit.WorkspaceCreate, res.Slug, nil, res)
+	return res, nil
+}
+
+// ListWorkspaces returns every workspace to unrestricted keys, and only their
+// own one to the keys of a workspace.
+func (uc *UrlUsecase) ListWorkspaces(ctx context.Context) (*models.WorkspaceList, error) {
+
+	res := &models.WorkspaceList{Workspaces: []models.Workspace{}}
+
+	if slug := boundWorkspace(ctx); slug != "" {
+		workspace, err := uc.Repo.GetWorkspace(ctx, slug)
+		var interr *utils.InternalError
+		if errors.As(err, &interr) && interr.Code == http.StatusNotFound {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		res.Workspaces = append(res.Workspaces, *workspace)
+		return res, nil
+	}
+
+	workspaces, err := uc.Repo.ListWorkspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, workspace := range workspaces {
+		res.Workspaces = append(res.Workspaces, *workspace)
+	}
+	return res, nil
+}
+
+func (uc *UrlUsecase) GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error) {
+
+	if err := authorize(ctx, slug, auth.RoleViewer, errNoWorkspace); err != nil {
+		return nil, err
+	}
+	return uc.Repo.GetWorkspace(ctx, slug)
+}
+
+// AddMember adds a member to a workspace, which its admins may do.
+func (uc *UrlUsecase) AddMember(ctx context.Context, workspace string, member *models.Member) (*models.Member, error) {
+
+	if err := authorize(ctx, workspace, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return nil, err
+	}
+
+	res := &models.Member{Workspace: workspace, Name: strings.TrimSpace(member.Name), Role: member.Role}
+	if res.Name == "" {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "member name is required")
+	}
+	if !auth.ValidRole(res.Role) {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "role must be viewer, editor or admin")
+	}
+	if err := uc.Repo.AddMember(ctx, res); err != nil {
+		return nil, err
+	}
+	uc.record(ctx, audit.MemberAdd, workspace+"/"+res.Name, nil, res)
+	return res, nil
+}
+
+func (uc *UrlUsecase) ListMembers(ctx context.Context, workspace string) (*models.MemberList, error) {
+
+	if err := authorize(ctx, workspace, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return nil, err
+	}
+	if _, err := uc.Repo.GetWorkspace(ctx, workspace); err != nil {
+		return nil, err
+	}
+
+	members, err := uc.Repo.ListMembers(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.MemberList{Members: make([]models.Member, 0, len(members))}
+	for _, member := range members {
+		res.Members = append(res.Members, *member)
+	}
+	return res, nil
+}
+
+// checkOwnMembership keeps keys from changing the membership they act with,
+// so that a workspace can't lose its last admin by mistake.
+func checkOwnMembership(ctx context.Context, workspace, name string) error {
+	principal := auth.FromContext(ctx)
+	if principal != nil && principal.Owner == workspace && principal.Member != "" && principal.Member == name {
+		return utils.NewInternalError(http.StatusForbidden, "api keys cannot change their own membership")
+	}
+	return nil
+}
+
+// UpdateMemberRole changes the role of a member, which applies to its keys
+// from their next request.
+func (uc *UrlUsecase) UpdateMemberRole(ctx context.Context, workspace, name, role string) error {
+
+	if err := authorize(ctx, workspace, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return err
+	}
+	if err := checkOwnMembership(ctx, workspace, name); err != nil {
+		return err
+	}
+	if !auth.ValidRole(role) {
+		return utils.NewInternalError(http.StatusBadRequest, "role must be viewer, editor or admin")
+	}
+
+	if err := uc.Repo.UpdateMemberRole(ctx, workspace, name, role); err != nil {
+		return err
+	}
+	uc.record(ctx, audit.MemberUpdateRole, workspace+"/"+name, nil, models.RoleData{Role: role})
+	return nil
+}
+
+// RemoveMember removes a member from a workspace, revoking its keys.
+func (uc *UrlUsecase) RemoveMember(ctx context.Context, workspace, name string) error {
+
+	if err := authorize(ctx, workspace, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return err
+	}
+	if err := checkOwnMembership(ctx, workspace, name); err != nil {
+		return err
+	}
+
+	if err := uc.Repo.RemoveMember(ctx, workspace, name); err != nil {
+		return err
+	}
+	uc.record(ctx, audit.MemberRemove, workspace+"/"+name, nil, nil)
+	return nil
+}
+
+// CreateWorkspaceKey issues a random API key to a member of a workspace. Only
+// its digest is stored, the key itself is only returned here.
+func (uc *UrlUsecase) CreateWorkspaceKey(ctx context.Context, workspace string,
+	key *models.WorkspaceKey) (*models.WorkspaceKey, error) {
+
+	if err := authorize(ctx, workspace, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return nil, err
+	}
+
+	res := &models.WorkspaceKey{Workspace: workspace, Name: strings.TrimSpace(key.Name),
+		Member: strings.TrimSpace(key.Member), Key: rand.Text()}
+	if res.Name == "" || res.Member == "" {
+		return nil, utils.NewInternalError(http.StatusBadRequest, "key name and member are required")
+	}
+	res.Digest = auth.Digest(res.Key)
+
+	if err := uc.Repo.CreateWorkspaceKey(ctx, res); err != nil {
+		return nil, err
+	}
+
+	recorded := *res
+	recorded.Key = ""
+	uc.record(ctx, audit.ApiKeyCreate, workspace+"/"+res.Name, nil, &recorded)
+	return res, nil
+}
+
+func (uc *UrlUsecase) ListWorkspaceKeys(ctx context.Context, workspace string) (*models.WorkspaceKeyList, error) {
+
+	if err := authorize(ctx, workspace, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return nil, err
+	}
+	if _, err := uc.Repo.GetWorkspace(ctx, workspace); err != nil {
+		return nil, err
+	}
+
+	keys, err := uc.Repo.ListWorkspaceKeys(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.WorkspaceKeyList{Keys: make([]models.WorkspaceKey, 0, len(keys))}
+	for _, key := range keys {
+		res.Keys = append(res.Keys, *key)
+	}
+	return res, nil
+}
+
+// DeleteWorkspaceKey revokes a key of a workspace.
+func (uc *UrlUsecase) DeleteWorkspaceKey(ctx context.Context, workspace string, id int64) error {
+
+	if err := authorize(ctx, workspace, auth.RoleAdmin, errNoWorkspace); err != nil {
+		return err
+	}
+
+	if err := uc.Repo.DeleteWorkspaceKey(ctx, workspace, id); err != nil {
+		return err
+	}
+	uc.record(ctx, audit.ApiKeyDelete, workspace+"/"+strconv.FormatInt(id, 10), nil, nil)
+	return nil
+}
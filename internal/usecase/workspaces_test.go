@@ -0,0 +1,338 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/auth"
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/usecase/mocks"
+	"github.com/AlexNov03/UrlShortener/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Principals of the authorization tests: keys of the team workspace with
+// each role, a key of another workspace and an unrestricted key.
+var (
+	teamViewer   = &auth.Principal{KeyName: "team/dashboard", Owner: "team", Member: "bob", Role: auth.RoleViewer}
+	teamEditor   = &auth.Principal{KeyName: "team/ci", Owner: "team", Member: "ci", Role: auth.RoleEditor}
+	teamAdmin    = &auth.Principal{KeyName: "team/admin", Owner: "team", Member: "alice", Role: auth.RoleAdmin}
+	otherAdmin   = &auth.Principal{KeyName: "acme/admin", Owner: "acme", Member: "carol", Role: auth.RoleAdmin}
+	unrestricted = &auth.Principal{KeyName: "ops"}
+)
+
+func workspaceUsecase(t *testing.T) (*UrlUsecase, *mocks.MockUrlRepository) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockRepo := mocks.NewMockUrlRepository(ctrl)
+	ignoreAudit(mockRepo)
+
+	cfg := &bootstrap.Config{}
+	cfg.Server.Protocol, cfg.Server.Host, cfg.Server.Port = "http", "localhost", 8080
+	return NewUrlUsecase(mockRepo, rand.New(rand.NewSource(64)), cfg), mockRepo
+}
+
+func TestLinkAuthorization(t *testing.T) {
+
+	noLink := utils.NewInternalError(http.StatusNotFound, "no originalUrl match this shortUrl")
+	needsEditor := utils.NewInternalError(http.StatusForbidden, "the api key needs the editor role")
+
+	tests := []struct {
+		Name           string
+		Principal      *auth.Principal
+		ExpectReadErr  error
+		ExpectWriteErr error
+	}{
+		{Name: "no principal"},
+		{Name: "unrestricted key", Principal: unrestricted},
+		{Name: "viewer", Principal: teamViewer, ExpectWriteErr: needsEditor},
+		{Name: "editor", Principal: teamEditor},
+		{Name: "admin", Principal: teamAdmin},
+		{Name: "other workspace", Principal: otherAdmin, ExpectReadErr: noLink, ExpectWriteErr: noLink},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			uc, mockRepo := workspaceUsecase(t)
+			ctx := context.Background()
+			if tt.Principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.Principal)
+			}
+
+			data := &models.UrlData{OriginalUrl: "http://example.ru/", ShortUrl: "Abc_def_gs", Owner: "team"}
+			mockRepo.EXPECT().GetUrlData(ctx, "Abc_def_gs").Return(data, nil).AnyTimes()
+			mockRepo.EXPECT().GetClicks(ctx, "Abc_def_gs").Return(&models.ClickData{Clicks: 3}, nil).AnyTimes()
+
+			_, err := uc.GetLink(ctx, "Abc_def_gs")
+			assert.Equal(t, tt.ExpectReadErr, err)
+			_, err = uc.GetStats(ctx, "Abc_def_gs")
+			assert.Equal(t, tt.ExpectReadErr, err)
+			_, err = uc.GetQrCode(ctx, "Abc_def_gs", &models.QrOptions{})
+			assert.Equal(t, tt.ExpectReadErr, err)
+
+			if tt.ExpectWriteErr == nil {
+				mockRepo.EXPECT().UpdateRules(ctx, "Abc_def_gs", []models.TargetingRule(nil)).Return(nil)
+				mockRepo.EXPECT().UpdateMetadata(ctx, "Abc_def_gs", gomock.Any()).Return(nil)
+				mockRepo.EXPECT().DeleteUrl(ctx, "Abc_def_gs").Return(nil)
+			}
+			assert.Equal(t, tt.ExpectWriteErr, uc.UpdateRules(ctx, "Abc_def_gs", nil))
+			assert.Equal(t, tt.ExpectWriteErr, uc.UpdateMetadata(ctx, "Abc_def_gs", &models.LinkMetadata{}))
+			assert.Equal(t, tt.ExpectWriteErr, uc.DeleteLink(ctx, "Abc_def_gs", ""))
+		})
+	}
+}
+
+func TestShortenUrlInWorkspace(t *testing.T) {
+
+	notFound := &utils.InternalError{Code: http.StatusNotFound, Message: "no originalUrl match this shortUrl"}
+
+	tests := []struct {
+		Name        string
+		Principal   *auth.Principal
+		Owner       string
+		ExpectOwner string
+		ExpectErr   error
+	}{
+		{Name: "key creates links in its workspace", Principal: teamEditor, ExpectOwner: "team"},
+		{Name: "key names its workspace", Principal: teamAdmin, Owner: "team", ExpectOwner: "team"},
+		{Name: "key names another workspace", Principal: teamEditor, Owner: "acme",
+			ExpectErr: utils.NewInternalError(http.StatusForbidden,
+				"links can only be created in the workspace of the api key")},
+		{Name: "viewer", Principal: teamViewer,
+			ExpectErr: utils.NewInternalError(http.StatusForbidden, "the api key needs the editor role")},
+		{Name: "unrestricted key names any owner", Principal: unrestricted, Owner: "acme", ExpectOwner: "acme"},
+		{Name: "unrestricted key without owner", Principal: unrestricted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			uc, mockRepo := workspaceUsecase(t)
+			uc.now = func() time.Time { return time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC) }
+			ctx := auth.WithPrincipal(context.Background(), tt.Principal)
+
+			code := uc.generateShortUrl()
+			uc.rnd.Seed(64)
+
+			if tt.ExpectErr == nil {
+				mockRepo.EXPECT().GetOriginalUrl(ctx, code).Return("", notFound)
+				data := &models.UrlData{OriginalUrl: "http://example.ru/", ShortUrl: code, Owner: tt.ExpectOwner}
+				if tt.ExpectOwner == "" {
+					mockRepo.EXPECT().AddOriginalUrl(ctx, data).Return(nil)
+				} else {
+					mockRepo.EXPECT().AddOwnedUrl(ctx, data, gomock.Any(), gomock.Any()).Return(nil)
+				}
+			}
+
+			_, err := uc.ShortenUrl(ctx, &models.OrigUrlData{OriginalUrl: "http://example.ru", Owner: tt.Owner})
+			assert.Equal(t, tt.ExpectErr, err)
+		})
+	}
+}
+
+func TestListLinksInWorkspace(t *testing.T) {
+
+	uc, mockRepo := workspaceUsecase(t)
+
+	// keys of a workspace only list its links, whatever owner they filter on
+	ctx := auth.WithPrincipal(context.Background(), teamViewer)
+	mockRepo.EXPECT().ListLinks(ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, filter *models.LinkFilter) ([]*models.UrlData, error) {
+			assert.Equal(t, "team", filter.Owner)
+			return nil, nil
+		})
+	_, err := uc.ListLinks(ctx, &models.LinkFilter{Owner: "acme"})
+	assert.NoError(t, err)
+
+	ctx = auth.WithPrincipal(context.Background(), unrestricted)
+	mockRepo.EXPECT().ListLinks(ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, filter *models.LinkFilter) ([]*models.UrlData, error) {
+			assert.Equal(t, "acme", filter.Owner)
+			return nil, nil
+		})
+	_, err = uc.ListLinks(ctx, &models.LinkFilter{Owner: "acme"})
+	assert.NoError(t, err)
+}
+
+func TestExportInWorkspace(t *testing.T) {
+
+	uc, mockRepo := workspaceUsecase(t)
+
+	// keys of a workspace only export its links, whatever owner they filter on
+	ctx := auth.WithPrincipal(context.Background(), teamViewer)
+	mockRepo.EXPECT().ExportLinks(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, filter *models.LinkFilter, fn func(*models.UrlData) error) error {
+			assert.Equal(t, "team", filter.Owner)
+			return nil
+		})
+	_, err := uc.Export(ctx, io.Discard, &models.ExportOptions{Format: "csv", Filter: models.LinkFilter{Owner: "acme"}})
+	assert.NoError(t, err)
+
+	ctx = auth.WithPrincipal(context.Background(), unrestricted)
+	mockRepo.EXPECT().ExportLinks(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, filter *models.LinkFilter, fn func(*models.UrlData) error) error {
+			assert.Equal(t, "acme", filter.Owner)
+			return nil
+		})
+	_, err = uc.Export(ctx, io.Discard, &models.ExportOptions{Format: "csv", Filter: models.LinkFilter{Owner: "acme"}})
+	assert.NoError(t, err)
+}
+
+func TestManagementAuthorization(t *testing.T) {
+
+	needsAdmin := utils.NewInternalError(http.StatusForbidden, "the api key needs the admin role")
+	noWorkspace := utils.NewInternalError(http.StatusNotFound, "no workspace match this slug")
+
+	tests := []struct {
+		Name      string
+		Principal *auth.Principal
+		ExpectErr error
+	}{
+		{Name: "unrestricted key", Principal: unrestricted},
+		{Name: "admin", Principal: teamAdmin},
+		{Name: "editor", Principal: teamEditor, ExpectErr: needsAdmin},
+		{Name: "viewer", Principal: teamViewer, ExpectErr: needsAdmin},
+		{Name: "admin of other workspace", Principal: otherAdmin, ExpectErr: noWorkspace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			uc, mockRepo := workspaceUsecase(t)
+			ctx := auth.WithPrincipal(context.Background(), tt.Principal)
+
+			if tt.ExpectErr == nil {
+				mockRepo.EXPECT().AddMember(ctx, &models.Member{Workspace: "team", Name: "dave", Role: "viewer"}).
+					Return(nil)
+				mockRepo.EXPECT().GetWorkspace(ctx, "team").Return(&models.Workspace{Slug: "team"}, nil).Times(2)
+				mockRepo.EXPECT().ListMembers(ctx, "team").Return(nil, nil)
+				mockRepo.EXPECT().UpdateMemberRole(ctx, "team", "dave", "editor").Return(nil)
+				mockRepo.EXPECT().RemoveMember(ctx, "team", "dave").Return(nil)
+				mockRepo.EXPECT().CreateWorkspaceKey(ctx, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().ListWorkspaceKeys(ctx, "team").Return(nil, nil)
+				mockRepo.EXPECT().DeleteWorkspaceKey(ctx, "team", int64(3)).Return(nil)
+				mockRepo.EXPECT().ListWebhooks(ctx, "team").Return(nil, nil)
+			}
+
+			_, err := uc.AddMember(ctx, "team", &models.Member{Name: "dave", Role: "viewer"})
+			assert.Equal(t, tt.ExpectErr, err)
+			_, err = uc.ListMembers(ctx, "team")
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.Equal(t, tt.ExpectErr, uc.UpdateMemberRole(ctx, "team", "dave", "editor"))
+			assert.Equal(t, tt.ExpectErr, uc.RemoveMember(ctx, "team", "dave"))
+			_, err = uc.CreateWorkspaceKey(ctx, "team", &models.WorkspaceKey{Name: "deploy", Member: "dave"})
+			assert.Equal(t, tt.ExpectErr, err)
+			_, err = uc.ListWorkspaceKeys(ctx, "team")
+			assert.Equal(t, tt.ExpectErr, err)
+			assert.Equal(t, tt.ExpectErr, uc.DeleteWorkspaceKey(ctx, "team", 3))
+			_, err = uc.ListWebhooks(ctx, "team")
+			assert.Equal(t, tt.ExpectErr, err)
+		})
+	}
+}
+
+func TestOwnMembership(t *testing.T) {
+
+	uc, _ := workspaceUsecase(t)
+	ctx := auth.WithPrincipal(context.Background(), teamAdmin)
+
+	ownMembership := utils.NewInternalError(http.StatusForbidden, "api keys cannot change their own membership")
+	assert.Equal(t, ownMembership, uc.UpdateMemberRole(ctx, "team", "alice", "viewer"))
+	assert.Equal(t, ownMembership, uc.RemoveMember(ctx, "team", "alice"))
+}
+
+func TestCreateWorkspace(t *testing.T) {
+
+	uc, mockRepo := workspaceUsecase(t)
+
+	ctx := auth.WithPrincipal(context.Background(), unrestricted)
+	mockRepo.EXPECT().CreateWorkspace(ctx, &models.Workspace{Slug: "team", Name: "Team"}).Return(nil)
+	res, err := uc.CreateWorkspace(ctx, &models.Workspace{Slug: " team", Name: "Team "})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Workspace{Slug: "team", Name: "Team"}, res)
+
+	_, err = uc.CreateWorkspace(ctx, &models.Workspace{Slug: "Team/1"})
+	assert.Equal(t, utils.NewInternalError(http.StatusBadRequest,
+		"slug may only contain lowercase letters, digits, '-' and '_'"), err)
+
+	// keys of a workspace can't create other ones
+	_, err = uc.CreateWorkspace(auth.WithPrincipal(context.Background(), teamAdmin), &models.Workspace{Slug: "acme"})
+	assert.Equal(t, utils.NewInternalError(http.StatusForbidden, "only unrestricted api keys can create workspaces"),
+		err)
+}
+
+func TestListWorkspaces(t *testing.T) {
+
+	uc, mockRepo := workspaceUsecase(t)
+
+	ctx := auth.WithPrincipal(context.Background(), unrestricted)
+	mockRepo.EXPECT().ListWorkspaces(ctx).Return([]*models.Workspace{{Slug: "acme"}, {Slug: "team"}}, nil)
+	res, err := uc.ListWorkspaces(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.WorkspaceList{Workspaces: []models.Workspace{{Slug: "acme"}, {Slug: "team"}}}, res)
+
+	ctx = auth.WithPrincipal(context.Background(), teamViewer)
+	mockRepo.EXPECT().GetWorkspace(ctx, "team").Return(&models.Workspace{Slug: "team"}, nil)
+	res, err = uc.ListWorkspaces(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.WorkspaceList{Workspaces: []models.Workspace{{Slug: "team"}}}, res)
+
+	_, err = uc.GetWorkspace(ctx, "acme")
+	assert.Equal(t, utils.NewInternalError(http.StatusNotFound, "no workspace match this slug"), err)
+}
+
+func TestCreateWorkspaceKey(t *testing.T) {
+
+	uc, mockRepo := workspaceUsecase(t)
+	ctx := auth.WithPrincipal(context.Background(), teamAdmin)
+
+	var stored *models.WorkspaceKey
+	mockRepo.EXPECT().CreateWorkspaceKey(ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, key *models.WorkspaceKey) error {
+			stored = key
+			key.ID = 3
+			return nil
+		})
+
+	res, err := uc.CreateWorkspaceKey(ctx, "team", &models.WorkspaceKey{Name: "deploy ", Member: "ci"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), res.ID)
+	assert.Equal(t, "team", stored.Workspace)
+	assert.Equal(t, "deploy", stored.Name)
+	assert.NotEmpty(t, res.Key)
+	// only the digest of the key is stored
+	assert.Equal(t, auth.Digest(res.Key), stored.Digest)
+}
+
+func TestLookupApiKey(t *testing.T) {
+
+	uc, mockRepo := workspaceUsecase(t)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().FindWorkspaceKey(ctx, "digest-1").Return(&models.WorkspaceKey{ID: 3, Workspace: "team",
+		Name: "deploy", Member: "ci", Role: "editor"}, nil)
+	principal, err := uc.LookupApiKey(ctx, "digest-1")
+	assert.NoError(t, err)
+	assert.Equal(t, &auth.Principal{KeyName: "team/deploy", Owner: "team", Member: "ci", Role: "editor"}, principal)
+
+	mockRepo.EXPECT().FindWorkspaceKey(ctx, "digest-2").Return(nil,
+		utils.NewInternalError(http.StatusNotFound, "no api key match this digest"))
+	principal, err = uc.LookupApiKey(ctx, "digest-2")
+	assert.NoError(t, err)
+	assert.Nil(t, principal)
+
+	dbErr := errors.New("some db error")
+	mockRepo.EXPECT().FindWorkspaceKey(ctx, "digest-3").Return(nil, dbErr)
+	_, err = uc.LookupApiKey(ctx, "digest-3")
+	assert.Equal(t, dbErr, err)
+}
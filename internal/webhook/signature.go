@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the signature of a webhook payload, formatted as
+// t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<payload>">.
+const SignatureHeader = "X-Webhook-Signature"
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrExpiredSignature = errors.New("webhook signature is too old")
+)
+
+func mac(secret string, timestamp int64, payload []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(h, "%d.", timestamp)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// Sign returns the signature header value of payload sent at t.
+func Sign(secret string, t time.Time, payload []byte) string {
+	timestamp := t.Unix()
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac(secret, timestamp, payload)))
+}
+
+// Verify checks that signature was made with secret for payload no longer
+// than tolerance before now, so receivers can reject forged and replayed
+// webhooks. A zero tolerance skips the age check.
+func Verify(secret, signature string, payload []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp int64
+	var sums [][]byte
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return ErrInvalidSignature
+			}
+			timestamp = t
+		case "v1":
+			sum, err := hex.DecodeString(value)
+			if err != nil {
+				return ErrInvalidSignature
+			}
+			sums = append(sums, sum)
+		}
+	}
+	if timestamp == 0 || len(sums) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := mac(secret, timestamp, payload)
+	valid := false
+	for _, sum := range sums {
+		valid = valid || hmac.Equal(sum, expected)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && now.Sub(time.Unix(timestamp, 0)) > tolerance {
+		return ErrExpiredSignature
+	}
+	return nil
+}
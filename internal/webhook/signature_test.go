@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	payload := []byte(`{"id":"EVT","type":"link.created"}`)
+	signature := Sign("0123456789abcdef", now, payload)
+	_, sum, _ := strings.Cut(signature, ",")
+
+	tests := []struct {
+		Name      string
+		Secret    string
+		Signature string
+		Payload   []byte
+		Now       time.Time
+		ExpectErr error
+	}{
+		{
+			Name:      "valid signature",
+			Secret:    "0123456789abcdef",
+			Signature: signature,
+			Payload:   payload,
+			Now:       now.Add(time.Minute),
+		},
+		{
+			Name:      "valid signature among rotated ones",
+			Secret:    "0123456789abcdef",
+			Signature: Sign("fedcba9876543210", now, payload) + "," + sum,
+			Payload:   payload,
+			Now:       now,
+		},
+		{
+			Name:      "wrong secret",
+			Secret:    "fedcba9876543210",
+			Signature: signature,
+			Payload:   payload,
+			Now:       now,
+			ExpectErr: ErrInvalidSignature,
+		},
+		{
+			Name:      "tampered payload",
+			Secret:    "0123456789abcdef",
+			Signature: signature,
+			Payload:   []byte(`{"id":"EVT","type":"link.deleted"}`),
+			Now:       now,
+			ExpectErr: ErrInvalidSignature,
+		},
+		{
+			Name:      "malformed signature",
+			Secret:    "0123456789abcdef",
+			Signature: "v1=zz",
+			Payload:   payload,
+			Now:       now,
+			ExpectErr: ErrInvalidSignature,
+		},
+		{
+			Name:      "replayed signature",
+			Secret:    "0123456789abcdef",
+			Signature: signature,
+			Payload:   payload,
+			Now:       now.Add(time.Hour),
+			ExpectErr: ErrExpiredSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			err := Verify(tt.Secret, tt.Signature, tt.Payload, 5*time.Minute, tt.Now)
+
+			assert.Equal(t, tt.ExpectErr, err)
+		})
+	}
+}
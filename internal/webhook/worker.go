@@ -0,0 +1,272 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/policy"
+)
+
+// Headers of webhook requests besides the signature.
+const (
+	EventIdHeader    = "X-Webhook-Id"
+	EventTypeHeader  = "X-Webhook-Event"
+	DeliveryIdHeader = "X-Webhook-Delivery"
+)
+
+const (
+	defaultPollInterval        = time.Second
+	defaultMaxAttempts         = 10
+	defaultInitialBackoff      = 10 * time.Second
+	defaultMaxBackoff          = time.Hour
+	defaultTimeout             = 10 * time.Second
+	defaultExpiryCheckInterval = time.Minute
+
+	// batchSize bounds the deliveries claimed and sent concurrently per poll.
+	batchSize = 32
+)
+
+// errAddressNotAllowed is returned when dialing private addresses.
+var errAddressNotAllowed = errors.New("destination address is not allowed")
+
+// Store holds the outbox of the webhook deliveries.
+type Store interface {
+	ClaimWebhookDeliveries(ctx context.Context, now, lockedUntil time.Time, limit int) ([]*models.WebhookDelivery, error)
+	CompleteWebhookDelivery(ctx context.Context, id int64) error
+	FailWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	EnqueueExpiredLinks(ctx context.Context, now time.Time) (int, error)
+}
+
+// Worker sends the deliveries of the outbox to the subscribed urls as signed
+// POST requests. A delivery succeeds with a 2xx response; failed deliveries
+// are retried with exponential backoff and become dead letters after the
+// last attempt. Deliveries are sent at least once, so receivers should
+// deduplicate them by event id. Subscribed urls are screened when created,
+// and private addresses are also refused when connecting, so hosts resolving
+// to them later are not reached either. Delivery errors keep only the status
+// of the response or a generic message, as subscribers can read them.
+type Worker struct {
+	store               Store
+	client              *http.Client
+	pollInterval        time.Duration
+	maxAttempts         int
+	initialBackoff      time.Duration
+	maxBackoff          time.Duration
+	expiryCheckInterval time.Duration
+	now                 func() time.Time
+}
+
+func NewWorker(store Store, cfg bootstrap.Webhooks) *Worker {
+	res := &Worker{
+		store:               store,
+		client:              newClient(cfg.Timeout),
+		pollInterval:        cfg.PollInterval,
+		maxAttempts:         cfg.MaxAttempts,
+		initialBackoff:      cfg.InitialBackoff,
+		maxBackoff:          cfg.MaxBackoff,
+		expiryCheckInterval: cfg.ExpiryCheckInterval,
+		now:                 time.Now,
+	}
+	if res.pollInterval <= 0 {
+		res.pollInterval = defaultPollInterval
+	}
+	if res.maxAttempts <= 0 {
+		res.maxAttempts = defaultMaxAttempts
+	}
+	if res.initialBackoff <= 0 {
+		res.initialBackoff = defaultInitialBackoff
+	}
+	if res.maxBackoff <= 0 {
+		res.maxBackoff = defaultMaxBackoff
+	}
+	if res.expiryCheckInterval <= 0 {
+		res.expiryCheckInterval = defaultExpiryCheckInterval
+	}
+	return res
+}
+
+// newClient returns the client of the deliveries, which doesn't use proxies
+// and refuses to connect to private addresses. A zero timeout means 10
+// seconds.
+func newClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	dialer := &net.Dialer{Timeout: timeout, Control: checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// checkAddress refuses connections to private addresses. It runs for the
+// resolved address right before connecting.
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || policy.PrivateAddress(ip) {
+		return errAddressNotAllowed
+	}
+	return nil
+}
+
+// Run delivers the due deliveries every poll interval and enqueues the
+// events of links whose activation window ended every expiry check interval,
+// until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	poll := time.NewTicker(w.pollInterval)
+	defer poll.Stop()
+	expiry := time.NewTicker(w.expiryCheckInterval)
+	defer expiry.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			// a full batch suggests more deliveries are due
+			for w.deliverDue(ctx) == batchSize && ctx.Err() == nil {
+			}
+		case <-expiry.C:
+			expired, err := w.store.EnqueueExpiredLinks(ctx, w.now())
+			if err != nil {
+				log.Printf("error while enqueuing expired links: %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf("%d expired links enqueued for webhooks", expired)
+			}
+		}
+	}
+}
+
+// backoff returns the delay before retrying a delivery failed attempts times.
+func (w *Worker) backoff(attempts int) time.Duration {
+	res := w.initialBackoff
+	for i := 1; i < attempts && res < w.maxBackoff; i++ {
+		res *= 2
+	}
+	return min(res, w.maxBackoff)
+}
+
+// deliverDue sends the deliveries due now concurrently and returns their
+// number.
+func (w *Worker) deliverDue(ctx context.Context) int {
+	now := w.now()
+	// claimed deliveries are not sent again by other workers until they
+	// have surely been completed or failed
+	deliveries, err := w.store.ClaimWebhookDeliveries(ctx, now, now.Add(w.client.Timeout+time.Minute), batchSize)
+	if err != nil {
+		log.Printf("error while claiming webhook deliveries: %v", err)
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+	return len(deliveries)
+}
+
+// deliver sends a delivery and records its outcome.
+func (w *Worker) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	// the outcome is recorded even if the worker is stopping
+	storeCtx := context.WithoutCancel(ctx)
+
+	err := w.send(ctx, delivery)
+	if err == nil {
+		if err := w.store.CompleteWebhookDelivery(storeCtx, delivery.ID); err != nil {
+			log.Printf("error while completing webhook delivery %d: %v", delivery.ID, err)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// the delivery is claimed again once its lock passes
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastError = deliveryError(err)
+	delivery.Dead = delivery.Attempts >= w.maxAttempts
+	delivery.NextAttemptAt = w.now().Add(w.backoff(delivery.Attempts))
+	if delivery.Dead {
+		log.Printf("webhook delivery %d to %s failed for the last time: %v", delivery.ID, delivery.Url, err)
+	}
+	if err := w.store.FailWebhookDelivery(storeCtx, delivery); err != nil {
+		log.Printf("error while failing webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+func (w *Worker) send(ctx context.Context, delivery *models.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "UrlShortener-Webhooks")
+	req.Header.Set(EventIdHeader, delivery.EventID)
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(DeliveryIdHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, w.now(), delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		log.Printf("error while sending webhook delivery %d: %v", delivery.ID, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	// the body is drained so the connection can be reused, but never kept
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &statusError{status: resp.StatusCode}
+	}
+	return nil
+}
+
+// statusError is returned for deliveries answered with a non-2xx status.
+type statusError struct {
+	status int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d", e.status)
+}
+
+// deliveryError returns the error kept for a failed delivery: the status of
+// the response, or a generic message for failed requests, whose errors may
+// describe the network of the service.
+func deliveryError(err error) string {
+	var statusErr *statusError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.Error()
+	case errors.Is(err, errAddressNotAllowed):
+		return errAddressNotAllowed.Error()
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "request timed out"
+	default:
+		return "request failed"
+	}
+}
@@ -0,0 +1,224 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexNov03/UrlShortener/internal/bootstrap"
+	"github.com/AlexNov03/UrlShortener/internal/models"
+	"github.com/AlexNov03/UrlShortener/internal/repository/local"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef"
+
+// receiver records the webhooks it receives, responding with the statuses
+// of fail before succeeding.
+type receiver struct {
+	mu       sync.Mutex
+	fail     []int
+	received []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.received = append(rc.received, r)
+	rc.bodies = append(rc.bodies, body)
+	if len(rc.fail) > 0 {
+		status := rc.fail[0]
+		rc.fail = rc.fail[1:]
+		w.WriteHeader(status)
+		io.WriteString(w, "try later")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setup returns a worker whose clock is controlled through the returned
+// pointer, and a repository with a subscription of a receiver to created
+// links. The worker may connect to the receiver on the loopback address.
+func setup(t *testing.T, rc *receiver) (*Worker, *local.UrlRepository, *time.Time) {
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	repo := local.NewUrlRepository()
+	require.NoError(t, repo.CreateWebhook(context.Background(), &models.WebhookSubscription{Url: server.URL,
+		Secret: testSecret, Events: []string{"link.created"}}))
+
+	now := time.Now().Add(time.Second)
+	worker := NewWorker(repo, bootstrap.Webhooks{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: 90 * time.Second})
+	worker.now = func() time.Time { return now }
+	worker.client.Transport = http.DefaultTransport
+	return worker, repo, &now
+}
+
+func TestWorkerDelivers(t *testing.T) {
+
+	rc := &receiver{}
+	worker, repo, now := setup(t, rc)
+	ctx := context.Background()
+
+	require.NoError(t, repo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "http://ya.ru"}))
+
+	assert.Equal(t, 1, worker.deliverDue(ctx))
+	require.Len(t, rc.received, 1)
+
+	r, body := rc.received[0], rc.bodies[0]
+	event := &models.WebhookEvent{}
+	require.NoError(t, json.Unmarshal(body, event))
+	assert.Equal(t, http.MethodPost, r.Method)
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	assert.Equal(t, "link.created", r.Header.Get(EventTypeHeader))
+	assert.Equal(t, event.ID, r.Header.Get(EventIdHeader))
+	assert.Equal(t, "Abc_def_ga", event.Code)
+	assert.NoError(t, Verify(testSecret, r.Header.Get(SignatureHeader), body, time.Minute, *now))
+
+	// delivered events are removed from the outbox
+	*now = now.Add(time.Hour)
+	assert.Equal(t, 0, worker.deliverDue(ctx))
+	assert.Len(t, rc.received, 1)
+}
+
+func TestWorkerRetries(t *testing.T) {
+
+	rc := &receiver{fail: []int{http.StatusInternalServerError, http.StatusServiceUnavailable}}
+	worker, repo, now := setup(t, rc)
+	ctx := context.Background()
+
+	require.NoError(t, repo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "http://ya.ru"}))
+
+	assert.Equal(t, 1, worker.deliverDue(ctx))
+
+	// the retry waits for the backoff
+	*now = now.Add(59 * time.Second)
+	assert.Equal(t, 0, worker.deliverDue(ctx))
+	*now = now.Add(time.Second)
+	assert.Equal(t, 1, worker.deliverDue(ctx))
+
+	// the backoff doubles up to its maximum
+	*now = now.Add(89 * time.Second)
+	assert.Equal(t, 0, worker.deliverDue(ctx))
+	*now = now.Add(time.Second)
+	assert.Equal(t, 1, worker.deliverDue(ctx))
+
+	require.Len(t, rc.received, 3)
+	assert.Equal(t, rc.received[0].Header.Get(EventIdHeader), rc.received[2].Header.Get(EventIdHeader))
+
+	dead, err := repo.ListDeadLetters(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+}
+
+func TestWorkerDeadLetters(t *testing.T) {
+
+	rc := &receiver{fail: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusGone}}
+	worker, repo, now := setup(t, rc)
+	ctx := context.Background()
+
+	require.NoError(t, repo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "http://ya.ru"}))
+
+	for range 3 {
+		assert.Equal(t, 1, worker.deliverDue(ctx))
+		*now = now.Add(time.Hour)
+	}
+	assert.Equal(t, 0, worker.deliverDue(ctx))
+
+	dead, err := repo.ListDeadLetters(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, 3, dead[0].Attempts)
+	// the response body is not kept
+	assert.Equal(t, "status 410", dead[0].LastError)
+
+	// a retried dead letter gets a fresh set of attempts
+	require.NoError(t, repo.RetryDeadLetter(ctx, dead[0].ID, "", *now))
+	assert.Equal(t, 1, worker.deliverDue(ctx))
+	assert.Len(t, rc.received, 4)
+
+	dead, err = repo.ListDeadLetters(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+}
+
+func TestWorkerRefusesPrivateAddresses(t *testing.T) {
+
+	rc := &receiver{}
+	worker, repo, now := setup(t, rc)
+	worker.client = newClient(time.Second)
+	ctx := context.Background()
+
+	require.NoError(t, repo.AddOriginalUrl(ctx, &models.UrlData{ShortUrl: "Abc_def_ga", OriginalUrl: "http://ya.ru"}))
+
+	for range 3 {
+		assert.Equal(t, 1, worker.deliverDue(ctx))
+		*now = now.Add(time.Hour)
+	}
+	assert.Empty(t, rc.received)
+
+	dead, err := repo.ListDeadLetters(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "destination address is not allowed", dead[0].LastError)
+}
+
+func TestCheckAddress(t *testing.T) {
+
+	tests := map[string]bool{
+		"93.184.215.14:443":      true,
+		"[2606:2800:21f::1]:443": true,
+		"127.0.0.1:8080":         false,
+		"10.1.2.3:80":            false,
+		"192.168.0.1:80":         false,
+		"169.254.169.254:80":     false,
+		"0.0.0.0:80":             false,
+		"[::1]:80":               false,
+		"[::ffff:127.0.0.1]:80":  false,
+		"[fd00::1]:80":           false,
+		"[fe80::1]:80":           false,
+	}
+
+	for address, allowed := range tests {
+		t.Run(address, func(t *testing.T) {
+			err := checkAddress("tcp", address, nil)
+			if allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, errAddressNotAllowed)
+			}
+		})
+	}
+}
+
+func TestDeliveryError(t *testing.T) {
+
+	assert.Equal(t, "status 502", deliveryError(&statusError{status: http.StatusBadGateway}))
+	assert.Equal(t, "destination address is not allowed",
+		deliveryError(fmt.Errorf("dial tcp 10.0.0.1:80: %w", errAddressNotAllowed)))
+	assert.Equal(t, "request failed",
+		deliveryError(errors.New("dial tcp 10.0.0.1:5432: connect: connection refused")))
+}
+
+func TestBackoff(t *testing.T) {
+
+	worker := NewWorker(nil, bootstrap.Webhooks{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	assert.Equal(t, time.Second, worker.backoff(1))
+	assert.Equal(t, 2*time.Second, worker.backoff(2))
+	assert.Equal(t, 8*time.Second, worker.backoff(4))
+	assert.Equal(t, 10*time.Second, worker.backoff(5))
+	assert.Equal(t, 10*time.Second, worker.backoff(100))
+}
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url ADD COLUMN IF NOT EXISTS clicks_left INTEGER CHECK (clicks_left >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url DROP COLUMN IF EXISTS clicks_left;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url
+    ADD COLUMN IF NOT EXISTS not_before TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS not_after TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS fallback_url VARCHAR(255) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url
+    DROP COLUMN IF EXISTS not_before,
+    DROP COLUMN IF EXISTS not_after,
+    DROP COLUMN IF EXISTS fallback_url;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url ADD COLUMN IF NOT EXISTS rules JSONB NOT NULL DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url DROP COLUMN IF EXISTS rules;
+-- +goose StatementEnd
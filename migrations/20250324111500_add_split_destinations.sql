@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url
+    ADD COLUMN IF NOT EXISTS destinations JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS clicks BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS variant_clicks (
+    short_url CHAR(10) NOT NULL REFERENCES url (short_url) ON DELETE CASCADE,
+    variant INTEGER NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (short_url, variant)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS variant_clicks;
+
+ALTER TABLE url
+    DROP COLUMN IF EXISTS destinations,
+    DROP COLUMN IF EXISTS clicks;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url
+    ADD COLUMN IF NOT EXISTS utm_source VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS utm_medium VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS utm_campaign VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS pass_query BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url
+    DROP COLUMN IF EXISTS utm_source,
+    DROP COLUMN IF EXISTS utm_medium,
+    DROP COLUMN IF EXISTS utm_campaign,
+    DROP COLUMN IF EXISTS pass_query;
+-- +goose StatementEnd
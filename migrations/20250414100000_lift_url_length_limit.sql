@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url
+    ALTER COLUMN original_url TYPE TEXT,
+    ALTER COLUMN fallback_url TYPE TEXT,
+    ALTER COLUMN utm_source TYPE TEXT,
+    ALTER COLUMN utm_medium TYPE TEXT,
+    ALTER COLUMN utm_campaign TYPE TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url
+    ALTER COLUMN original_url TYPE VARCHAR(255),
+    ALTER COLUMN fallback_url TYPE VARCHAR(255),
+    ALTER COLUMN utm_source TYPE VARCHAR(255),
+    ALTER COLUMN utm_medium TYPE VARCHAR(255),
+    ALTER COLUMN utm_campaign TYPE VARCHAR(255);
+-- +goose StatementEnd
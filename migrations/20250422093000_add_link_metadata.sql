@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url
+    ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS url_tags (
+    short_url CHAR(10) NOT NULL REFERENCES url (short_url) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (short_url, tag)
+);
+
+CREATE INDEX IF NOT EXISTS url_tags_lower_tag_idx ON url_tags (lower(tag));
+CREATE INDEX IF NOT EXISTS url_created_at_idx ON url (created_at DESC, short_url DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS url_created_at_idx;
+DROP TABLE IF EXISTS url_tags;
+
+ALTER TABLE url
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS notes;
+-- +goose StatementEnd
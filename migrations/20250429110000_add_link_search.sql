@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url
+    ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS original_host TEXT GENERATED ALWAYS AS (
+        lower(trim(both '[]' from substring(original_url from '^[[:alpha:]][[:alnum:]+.-]*://(?:[^@/?#]*@)?(\[[^]]*\]|[^:/?#]*)')))
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS search TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('simple', regexp_replace(title || ' ' || original_url, '[^[:alnum:]]+', ' ', 'g'))
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS url_owner_created_at_idx ON url (owner, created_at DESC, short_url DESC);
+CREATE INDEX IF NOT EXISTS url_clicks_idx ON url (clicks DESC, short_url DESC);
+CREATE INDEX IF NOT EXISTS url_original_host_idx ON url (original_host);
+CREATE INDEX IF NOT EXISTS url_original_host_reverse_idx ON url (reverse(original_host) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS url_search_idx ON url USING GIN (search);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS url_search_idx;
+DROP INDEX IF EXISTS url_original_host_reverse_idx;
+DROP INDEX IF EXISTS url_original_host_idx;
+DROP INDEX IF EXISTS url_clicks_idx;
+DROP INDEX IF EXISTS url_owner_created_at_idx;
+
+ALTER TABLE url
+    DROP COLUMN IF EXISTS search,
+    DROP COLUMN IF EXISTS original_host,
+    DROP COLUMN IF EXISTS owner;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url_tags ALTER COLUMN short_url TYPE TEXT;
+ALTER TABLE variant_clicks ALTER COLUMN short_url TYPE TEXT;
+ALTER TABLE url ALTER COLUMN short_url TYPE TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url ALTER COLUMN short_url TYPE CHAR(10);
+ALTER TABLE variant_clicks ALTER COLUMN short_url TYPE CHAR(10);
+ALTER TABLE url_tags ALTER COLUMN short_url TYPE CHAR(10);
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS url_deleted_at_idx ON url (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- deleted links can still be restored, so they are neither dropped nor
+-- turned back into active links: purge or restore them before rolling back
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM url WHERE deleted_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'url has soft-deleted links, restore or purge them before rolling back';
+    END IF;
+END
+$$;
+DROP INDEX IF EXISTS url_deleted_at_idx;
+ALTER TABLE url DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS owner_usage (
+    owner TEXT NOT NULL,
+    month DATE NOT NULL,
+    creations BIGINT NOT NULL DEFAULT 0,
+    resolutions BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (owner, month)
+);
+
+CREATE INDEX IF NOT EXISTS url_owner_active_idx ON url (owner) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS url_owner_active_idx;
+DROP TABLE IF EXISTS owner_usage;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS workspaces (
+    slug TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace TEXT NOT NULL REFERENCES workspaces (slug) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'editor', 'admin')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (workspace, name)
+);
+
+CREATE TABLE IF NOT EXISTS workspace_keys (
+    id BIGSERIAL PRIMARY KEY,
+    workspace TEXT NOT NULL,
+    member TEXT NOT NULL,
+    name TEXT NOT NULL,
+    digest TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (workspace, name),
+    FOREIGN KEY (workspace, member) REFERENCES workspace_members (workspace, name) ON DELETE CASCADE
+);
+
+-- the owners of existing links become workspaces
+INSERT INTO workspaces (slug) SELECT DISTINCT owner FROM url WHERE owner <> '' ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS workspace_keys;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+-- +goose StatementEnd
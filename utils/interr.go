@@ -1,8 +1,11 @@
 package utils
 
+// InternalError is an error with the HTTP status it should be reported with.
+// Reason is an optional machine-readable code clarifying the status.
 type InternalError struct {
 	Code    int
 	Message string
+	Reason  string
 }
 
 func (ie *InternalError) Error() string {
@@ -6,58 +6,153 @@ import (
 	"errors"
 	"log"
 	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// RequestIDHeader carries the id of a request. The server sets it on every
+// response before the handlers run, so errors can refer to the request.
+const RequestIDHeader = "X-Request-ID"
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response. Code is a stable
+// machine-readable code of the problem, Reason optionally clarifies it and
+// Errors lists the fields failing validation.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Code      string       `json:"code"`
+	Reason    string       `json:"reason,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is a field of the input failing a validation rule, named as in
+// the request. Code is the failing rule.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Codes of problems. Problems with other statuses get the code of their
+// class: client_error or internal.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeTimeout          = "timeout"
+	CodeConflict         = "conflict"
+	CodeGone             = "gone"
+	CodeTooLarge         = "payload_too_large"
+	CodeUnprocessable    = "unprocessable"
+	CodeTooManyRequests  = "too_many_requests"
+	CodeClientError      = "client_error"
+	CodeInternal         = "internal"
+	CodeUnavailable      = "unavailable"
 )
 
-type RestError struct {
-	Error string `json:"error,"`
+var problemCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusRequestTimeout:        CodeTimeout,
+	http.StatusConflict:              CodeConflict,
+	http.StatusGone:                  CodeGone,
+	http.StatusRequestEntityTooLarge: CodeTooLarge,
+	http.StatusUnprocessableEntity:   CodeUnprocessable,
+	http.StatusTooManyRequests:       CodeTooManyRequests,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+}
+
+func problemCode(status int) string {
+	if code, ok := problemCodes[status]; ok {
+		return code
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeClientError
+}
+
+// writeProblem fills in the fields of p derived from its status and writes
+// it. Details of server errors are replaced with the status text, so internal
+// messages never reach clients; they are logged with the request id instead.
+func writeProblem(w http.ResponseWriter, p *Problem) {
+	p.Type = "about:blank"
+	p.Title = http.StatusText(p.Status)
+	p.RequestID = w.Header().Get(RequestIDHeader)
+	if p.Code == "" {
+		p.Code = problemCode(p.Status)
+	}
+	if p.Status >= 500 {
+		log.Printf("internal server error (request %s): %s", p.RequestID, p.Detail)
+		p.Detail = p.Title
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
 }
 
 func ProcessInternalServerError(w http.ResponseWriter, message string) {
-	log.Printf("internal server error: %s", message)
-	w.WriteHeader(http.StatusInternalServerError)
-	json.NewEncoder(w).Encode(RestError{Error: message})
+	writeProblem(w, &Problem{Status: http.StatusInternalServerError, Detail: message})
 }
 
 func ProcessBadRequestError(w http.ResponseWriter, message string) {
-	w.WriteHeader(http.StatusBadRequest)
-	json.NewEncoder(w).Encode(RestError{Error: message})
+	writeProblem(w, &Problem{Status: http.StatusBadRequest, Detail: message})
 }
 
 func ProcessAlreadyExistsError(w http.ResponseWriter, message string) {
-	w.WriteHeader(http.StatusConflict)
-	json.NewEncoder(w).Encode(RestError{Error: message})
+	writeProblem(w, &Problem{Status: http.StatusConflict, Detail: message})
 }
 
 func ProcessUnauthorizedError(w http.ResponseWriter, message string) {
-	w.WriteHeader(http.StatusUnauthorized)
-	json.NewEncoder(w).Encode(RestError{Error: message})
+	writeProblem(w, &Problem{Status: http.StatusUnauthorized, Detail: message})
+}
+
+// ProcessValidationError reports an error of validator.Struct with the
+// failing fields, which are named after the tag name function of the
+// validator, see NewValidator.
+func ProcessValidationError(w http.ResponseWriter, err error) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		ProcessBadRequestError(w, "incorrect fields in input data")
+		return
+	}
+
+	writeProblem(w, &Problem{Status: http.StatusBadRequest, Code: CodeValidationFailed,
+		Detail: "incorrect fields in input data", Errors: fieldErrors(validationErrors)})
 }
 
 func ProcessError(w http.ResponseWriter, err error) {
 	var internalError *InternalError
 
 	if ok := errors.As(err, &internalError); ok {
+		detail := internalError.Message
 		if internalError.Code >= 500 {
-			log.Printf("internal server error: %v", err)
+			detail = err.Error()
 		}
-		w.WriteHeader(internalError.Code)
-		json.NewEncoder(w).Encode(RestError{Error: internalError.Message})
+		writeProblem(w, &Problem{Status: internalError.Code, Detail: detail, Reason: internalError.Reason})
 		return
 	}
 
 	if errors.Is(err, context.DeadlineExceeded) {
-		log.Printf("error deadline exceeded: %v", err)
-		w.WriteHeader(http.StatusRequestTimeout)
-		json.NewEncoder(w).Encode(RestError{Error: err.Error()})
+		log.Printf("error deadline exceeded (request %s): %v", w.Header().Get(RequestIDHeader), err)
+		writeProblem(w, &Problem{Status: http.StatusRequestTimeout, Detail: "request timed out"})
 		return
 	}
 
 	if errors.Is(err, context.Canceled) {
-		log.Printf("error context canceled: %v", err)
+		log.Printf("error context canceled (request %s): %v", w.Header().Get(RequestIDHeader), err)
 		return
 	}
 
-	log.Printf("unknown error: %v", err)
-	w.WriteHeader(http.StatusInternalServerError)
-	json.NewEncoder(w).Encode(RestError{Error: err.Error()})
+	writeProblem(w, &Problem{Status: http.StatusInternalServerError, Detail: err.Error()})
 }
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRule struct {
+	Target string `json:"target_url" validate:"required,url"`
+	Weight int    `validate:"min=1"`
+}
+
+type Window struct {
+	Limit int `json:"limit" validate:"max=10"`
+}
+
+type testRequest struct {
+	Window
+	Url   string     `json:"original_url" validate:"required"`
+	Kind  string     `json:"kind" validate:"omitempty,oneof=a b"`
+	Rules []testRule `json:"rules" validate:"max=2,dive"`
+}
+
+func TestProcessError(t *testing.T) {
+
+	tests := []struct {
+		Name            string
+		Err             error
+		ExpectedProblem *Problem
+	}{
+		{
+			Name: "internal error",
+			Err:  &InternalError{Code: http.StatusUnprocessableEntity, Message: "destination url is not allowed", Reason: "private_address"},
+			ExpectedProblem: &Problem{Type: "about:blank", Title: "Unprocessable Entity", Status: http.StatusUnprocessableEntity,
+				Detail: "destination url is not allowed", Code: CodeUnprocessable, Reason: "private_address", RequestID: "req-1"},
+		},
+		{
+			Name: "unmapped client status",
+			Err:  NewInternalError(http.StatusTeapot, "short and stout"),
+			ExpectedProblem: &Problem{Type: "about:blank", Title: "I'm a teapot", Status: http.StatusTeapot,
+				Detail: "short and stout", Code: CodeClientError, RequestID: "req-1"},
+		},
+		{
+			Name: "server error does not leak details",
+			Err:  fmt.Errorf("pg.UrlRepository.GetUrl: %w", NewInternalError(http.StatusInternalServerError, "connection refused")),
+			ExpectedProblem: &Problem{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError,
+				Detail: "Internal Server Error", Code: CodeInternal, RequestID: "req-1"},
+		},
+		{
+			Name: "deadline exceeded",
+			Err:  fmt.Errorf("pg.UrlRepository.GetUrl: %w", context.DeadlineExceeded),
+			ExpectedProblem: &Problem{Type: "about:blank", Title: "Request Timeout", Status: http.StatusRequestTimeout,
+				Detail: "request timed out", Code: CodeTimeout, RequestID: "req-1"},
+		},
+		{
+			Name: "unknown error",
+			Err:  errors.New("something failed"),
+			ExpectedProblem: &Problem{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError,
+				Detail: "Internal Server Error", Code: CodeInternal, RequestID: "req-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+
+			w := httptest.NewRecorder()
+			w.Header().Set(RequestIDHeader, "req-1")
+
+			ProcessError(w, tt.Err)
+
+			assert.Equal(t, tt.ExpectedProblem.Status, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			problem := &Problem{}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(problem))
+			assert.Equal(t, tt.ExpectedProblem, problem)
+		})
+	}
+}
+
+func TestProcessErrorCanceled(t *testing.T) {
+
+	w := httptest.NewRecorder()
+
+	ProcessError(w, context.Canceled)
+
+	assert.Empty(t, w.Body.String())
+}
+
+func TestProcessValidationError(t *testing.T) {
+
+	req := testRequest{
+		Window: Window{Limit: 11},
+		Kind:   "c",
+		Rules:  []testRule{{Target: "not a url", Weight: 1}, {Weight: 0}},
+	}
+
+	w := httptest.NewRecorder()
+
+	ProcessValidationError(w, NewValidator().Struct(req))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	problem := &Problem{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(problem))
+	assert.Equal(t, CodeValidationFailed, problem.Code)
+	assert.Equal(t, []FieldError{
+		{Field: "limit", Code: "max", Message: "must be at most 10"},
+		{Field: "original_url", Code: "required", Message: "is required"},
+		{Field: "kind", Code: "oneof", Message: "must be one of a, b"},
+		{Field: "rules[0].target_url", Code: "url", Message: "must be a valid url"},
+		{Field: "rules[1].target_url", Code: "required", Message: "is required"},
+		{Field: "rules[1].weight", Code: "min", Message: "must be at least 1"},
+	}, problem.Errors)
+
+	w = httptest.NewRecorder()
+
+	ProcessValidationError(w, errors.New("not a validation error"))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	problem = &Problem{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(problem))
+	assert.Equal(t, CodeBadRequest, problem.Code)
+	assert.Empty(t, problem.Errors)
+}
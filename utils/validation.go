@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// NewValidator returns the validator of request models. Fields are named
+// after their JSON names in validation errors, or after their snake-cased Go
+// names, which are the names of the query parameters, when they have none.
+// Embedded structs keep their Go names, which fieldErrors drops.
+func NewValidator() *validator.Validate {
+	res := validator.New(validator.WithRequiredStructEnabled())
+	res.RegisterTagNameFunc(fieldName)
+	return res
+}
+
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return "-"
+	}
+	if name != "" || field.Anonymous {
+		return name
+	}
+	return snakeCase(field.Name)
+}
+
+func snakeCase(name string) string {
+	var res strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				res.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		res.WriteRune(r)
+	}
+	return res.String()
+}
+
+// fieldErrors translates validation errors to the fields of the request.
+// The Go names in their paths, of the validated struct and of embedded
+// structs, don't appear in requests and are dropped.
+func fieldErrors(errs validator.ValidationErrors) []FieldError {
+	res := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		var path []string
+		segments := strings.Split(fe.Namespace(), ".")
+		for _, segment := range segments[1:] {
+			if segment != "" && !unicode.IsUpper([]rune(segment)[0]) {
+				path = append(path, segment)
+			}
+		}
+		res = append(res, FieldError{Field: strings.Join(path, "."), Code: fe.Tag(), Message: fieldMessage(fe)})
+	}
+	return res
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	var unit string
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "url":
+		return "must be a valid url"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), unit)
+	default:
+		return "is invalid"
+	}
+}